@@ -387,6 +387,11 @@ func (m *RawSample) MarshalToSizedBufferVT(dAtA []byte) (int, error) {
 		i -= len(m.unknownFields)
 		copy(dAtA[i:], m.unknownFields)
 	}
+	if m.Encoding != 0 {
+		i = encodeVarint(dAtA, i, uint64(m.Encoding))
+		i--
+		dAtA[i] = 0x10
+	}
 	if len(m.RawProfile) > 0 {
 		i -= len(m.RawProfile)
 		copy(dAtA[i:], m.RawProfile)
@@ -515,6 +520,9 @@ func (m *RawSample) SizeVT() (n int) {
 	if l > 0 {
 		n += 1 + l + sov(uint64(l))
 	}
+	if m.Encoding != 0 {
+		n += 1 + sov(uint64(m.Encoding))
+	}
 	if m.unknownFields != nil {
 		n += len(m.unknownFields)
 	}
@@ -1099,6 +1107,25 @@ func (m *RawSample) UnmarshalVT(dAtA []byte) error {
 				m.RawProfile = []byte{}
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Encoding", wireType)
+			}
+			m.Encoding = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflow
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Encoding |= RawSample_Encoding(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skip(dAtA[iNdEx:])
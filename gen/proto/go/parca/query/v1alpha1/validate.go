@@ -16,8 +16,10 @@ package queryv1alpha1
 
 import (
 	"fmt"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -27,6 +29,7 @@ func (r *QueryRangeRequest) Validate() error {
 		validation.Field(&r.Start, validation.Required),
 		validation.Field(&r.End, validation.Required, isAfter(r.Start)),
 		validation.Field(&r.Query, validation.Required),
+		validation.Field(&r.Step, isStep()),
 	)
 }
 
@@ -308,3 +311,35 @@ func (a AfterRule) Validate(t interface{}) error {
 
 	return nil
 }
+
+// StepRule validates that the step of a range query is not negative.
+type StepRule struct{}
+
+func isStep() StepRule { return StepRule{} }
+
+// Validate runs the validation function for the StepRule.
+func (r StepRule) Validate(v interface{}) error {
+	step, ok := v.(*durationpb.Duration)
+	if !ok {
+		return fmt.Errorf("step is not a duration")
+	}
+
+	if step == nil {
+		return nil
+	}
+
+	if err := step.CheckValid(); err != nil {
+		return fmt.Errorf("invalid step: %w", err)
+	}
+
+	if step.AsDuration() < 0 {
+		return fmt.Errorf("step must not be negative")
+	}
+
+	// Samples are stored with millisecond precision.
+	if d := step.AsDuration(); d > 0 && d < time.Millisecond {
+		return fmt.Errorf("step must be at least a millisecond")
+	}
+
+	return nil
+}
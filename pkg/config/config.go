@@ -49,6 +49,9 @@ type Config struct {
 
 type ObjectStorage struct {
 	Bucket *client.BucketConfig `yaml:"bucket,omitempty"`
+	// DebuginfoArchiveBuckets are read-only buckets that debug information
+	// missing from the bucket is read from, in order.
+	DebuginfoArchiveBuckets []*client.BucketConfig `yaml:"debuginfo_archive_buckets,omitempty"`
 }
 
 // Validate returns an error if the config is not valid.
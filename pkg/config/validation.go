@@ -34,6 +34,7 @@ func (v ValidRule) Validate(value interface{}) error {
 	}
 	return validation.ValidateStruct(c,
 		validation.Field(&c.Bucket, validation.Required, BucketValid),
+		validation.Field(&c.DebuginfoArchiveBuckets, validation.Each(BucketValid)),
 	)
 }
 
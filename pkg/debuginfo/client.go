@@ -16,6 +16,8 @@ package debuginfo
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -61,6 +63,46 @@ func (c *Client) Exists(ctx context.Context, buildID, hash string) (bool, error)
 }
 
 func (c *Client) Upload(ctx context.Context, buildID, hash string, r io.Reader) (uint64, error) {
+	return c.upload(ctx, &debuginfopb.UploadInfo{BuildId: buildID, Hash: hash}, r)
+}
+
+// ForceUpload uploads the debug info even if debug info was already uploaded
+// for the build ID. It fails if the content differs from the uploaded one.
+func (c *Client) ForceUpload(ctx context.Context, buildID, hash string, r io.Reader) (uint64, error) {
+	return c.upload(ctx, &debuginfopb.UploadInfo{BuildId: buildID, Hash: hash, Force: true}, r)
+}
+
+// UploadExtract uploads the debug info, of which only the sections needed for
+// symbolization are stored.
+func (c *Client) UploadExtract(ctx context.Context, buildID, hash string, r io.Reader) (uint64, error) {
+	return c.upload(ctx, &debuginfopb.UploadInfo{BuildId: buildID, Hash: hash, Extract: true}, r)
+}
+
+// UploadDWP uploads the DWARF package file (.dwp) with the split debug info
+// of the skeleton units of the object file with the given build ID.
+func (c *Client) UploadDWP(ctx context.Context, buildID string, r io.Reader) (uint64, error) {
+	return c.upload(ctx, &debuginfopb.UploadInfo{BuildId: buildID, Type: debuginfopb.UploadInfo_TYPE_DWP}, r)
+}
+
+// UploadSource uploads a source file of the object file with the given build
+// ID, with the path it has in the debug info of the object file.
+func (c *Client) UploadSource(ctx context.Context, buildID, sourcePath string, r io.Reader) (uint64, error) {
+	return c.upload(ctx, &debuginfopb.UploadInfo{
+		BuildId:    buildID,
+		Type:       debuginfopb.UploadInfo_TYPE_SOURCE,
+		SourcePath: sourcePath,
+	}, r)
+}
+
+// UploadSourceArchive uploads a tar, optionally gzip compressed, or zip
+// archive of the source files of the object file with the given build ID.
+// Files of the debug info are matched to the archive entries their paths end
+// with.
+func (c *Client) UploadSourceArchive(ctx context.Context, buildID string, r io.Reader) (uint64, error) {
+	return c.upload(ctx, &debuginfopb.UploadInfo{BuildId: buildID, Type: debuginfopb.UploadInfo_TYPE_SOURCE_ARCHIVE}, r)
+}
+
+func (c *Client) upload(ctx context.Context, info *debuginfopb.UploadInfo, r io.Reader) (uint64, error) {
 	stream, err := c.c.Upload(ctx, grpc.MaxCallSendMsgSize(MaxMsgSize))
 	if err != nil {
 		return 0, fmt.Errorf("initiate upload: %w", err)
@@ -68,10 +110,7 @@ func (c *Client) Upload(ctx context.Context, buildID, hash string, r io.Reader)
 
 	err = stream.Send(&debuginfopb.UploadRequest{
 		Data: &debuginfopb.UploadRequest_Info{
-			Info: &debuginfopb.UploadInfo{
-				BuildId: buildID,
-				Hash:    hash,
-			},
+			Info: info,
 		},
 	})
 	if err != nil {
@@ -81,7 +120,8 @@ func (c *Client) Upload(ctx context.Context, buildID, hash string, r io.Reader)
 		return 0, fmt.Errorf("send upload info: %w", err)
 	}
 
-	reader := bufio.NewReader(r)
+	checksum := sha256.New()
+	reader := io.TeeReader(bufio.NewReader(r), checksum)
 
 	buffer := make([]byte, ChunkSize)
 
@@ -116,6 +156,25 @@ func (c *Client) Upload(ctx context.Context, buildID, hash string, r io.Reader)
 		}
 	}
 
+	err = stream.Send(&debuginfopb.UploadRequest{
+		Data: &debuginfopb.UploadRequest_Trailer{
+			Trailer: &debuginfopb.UploadTrailer{
+				Size:   uint64(bytesSent),
+				Sha256: hex.EncodeToString(checksum.Sum(nil)),
+			},
+		},
+	})
+	if errors.Is(err, io.EOF) {
+		err = stream.RecvMsg(nil)
+		if err := sentinelError(err); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("send trailer: %w", err)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("send trailer: %w", err)
+	}
+
 	// It returns io.EOF when the stream completes successfully.
 	res, err := stream.CloseAndRecv()
 	if errors.Is(err, io.EOF) {
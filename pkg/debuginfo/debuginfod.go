@@ -15,6 +15,7 @@ package debuginfo
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,6 +27,8 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/thanos-io/objstore"
 	"golang.org/x/net/context"
+
+	"github.com/parca-dev/parca/pkg/logfields"
 )
 
 type DebugInfodClient interface {
@@ -55,7 +58,7 @@ type DebugInfodClientObjectStorageCache struct {
 
 // NewHTTPDebugInfodClient returns a new HTTP debug info client.
 func NewHTTPDebugInfodClient(logger log.Logger, serverURLs []string, timeoutDuration time.Duration) (*HTTPDebugInfodClient, error) {
-	logger = log.With(logger, "component", "debuginfod")
+	logger = logfields.WithComponent(logger, "debuginfod")
 	parsedURLs := make([]*url.URL, 0, len(serverURLs))
 	for _, serverURL := range serverURLs {
 		u, err := url.Parse(serverURL)
@@ -66,6 +69,7 @@ func NewHTTPDebugInfodClient(logger log.Logger, serverURLs []string, timeoutDura
 		if u.Scheme != "http" && u.Scheme != "https" {
 			return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
 		}
+		parsedURLs = append(parsedURLs, u)
 	}
 	return &HTTPDebugInfodClient{
 		logger:          logger,
@@ -84,50 +88,85 @@ func NewDebugInfodClientWithObjectStorageCache(logger log.Logger, bucket objstor
 	}, nil
 }
 
-type closer func() error
+var errIncompleteDownload = errors.New("debug info download incomplete")
 
-func (f closer) Close() error { return f() }
+// GetDebugInfo returns debug info for given buildid from the object storage
+// if it was downloaded before. Otherwise it is downloaded and stored in the
+// object storage while it is read.
+func (c *DebugInfodClientObjectStorageCache) GetDebugInfo(ctx context.Context, buildID string) (io.ReadCloser, error) {
+	logger := logfields.WithBuildID(c.logger, buildID)
 
-type readCloser struct {
-	io.Reader
-	closer
-}
+	cached, err := c.bucket.Get(ctx, objectPath(buildID))
+	if err == nil {
+		level.Debug(logger).Log("msg", "using debuginfod file cached in object storage")
+		return cached, nil
+	}
+	if !c.bucket.IsObjNotFoundErr(err) {
+		level.Warn(logger).Log("msg", "failed to read debuginfod file cached in object storage", "err", err)
+	}
 
-// GetDebugInfo returns debug info for given buildid while caching it in object storage.
-func (c *DebugInfodClientObjectStorageCache) GetDebugInfo(ctx context.Context, buildID string) (io.ReadCloser, error) {
-	logger := log.With(c.logger, "buildid", buildID)
 	debugInfo, err := c.client.GetDebugInfo(ctx, buildID)
 	if err != nil {
 		return nil, err
 	}
 
 	r, w := io.Pipe()
+	done := make(chan struct{})
 	go func() {
-		defer w.Close()
-		defer debugInfo.Close()
+		defer close(done)
 
 		// TODO(kakkoyun): Use store.upload() to upload the debug info to object storage.
 		if err := c.bucket.Upload(ctx, objectPath(buildID), r); err != nil {
 			level.Error(logger).Log("msg", "failed to upload downloaded debuginfod file", "err", err)
 		}
+		// Keep consuming in case the upload stopped early, so that reading
+		// the download never blocks.
+		_, _ = io.Copy(io.Discard, r)
 	}()
 
-	return readCloser{
-		Reader: io.TeeReader(debugInfo, w),
-		closer: closer(func() error {
-			defer debugInfo.Close()
-
-			if err := w.Close(); err != nil {
-				return err
-			}
-			return nil
-		}),
+	return &cachingReader{
+		Reader:    io.TeeReader(debugInfo, w),
+		debugInfo: debugInfo,
+		w:         w,
+		done:      done,
 	}, nil
 }
 
+// cachingReader reads a download while it is uploaded to the object storage.
+// Only downloads that were read completely are kept.
+type cachingReader struct {
+	io.Reader
+	debugInfo io.ReadCloser
+	w         *io.PipeWriter
+	done      chan struct{}
+	eof       bool
+}
+
+func (r *cachingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
+
+// Close waits for the upload to finish, so that the download is available
+// from the object storage afterwards.
+func (r *cachingReader) Close() error {
+	defer r.debugInfo.Close()
+
+	if r.eof {
+		r.w.Close()
+	} else {
+		r.w.CloseWithError(errIncompleteDownload)
+	}
+	<-r.done
+	return nil
+}
+
 // GetDebugInfo returns debug information file for given buildID by downloading it from upstream servers.
 func (c *HTTPDebugInfodClient) GetDebugInfo(ctx context.Context, buildID string) (io.ReadCloser, error) {
-	logger := log.With(c.logger, "buildid", buildID)
+	logger := logfields.WithBuildID(c.logger, buildID)
 
 	// e.g:
 	// "https://debuginfod.elfutils.org/"
@@ -141,30 +180,33 @@ func (c *HTTPDebugInfodClient) GetDebugInfo(ctx context.Context, buildID string)
 	// "https://debuginfod.centos.org/"
 	for _, u := range c.UpstreamServers {
 		serverURL := *u
-		rc, err := func(serverURL url.URL) (io.ReadCloser, error) {
-			ctx, cancel := context.WithTimeout(ctx, c.timeoutDuration)
-			defer cancel()
-
-			rc, err := c.request(ctx, serverURL, buildID)
-			if err != nil {
-				return nil, err
-			}
-			return rc, nil
-		}(serverURL)
+		// The timeout covers reading the response body as well, so the
+		// context is only canceled once the body is closed.
+		ctx, cancel := context.WithTimeout(ctx, c.timeoutDuration)
+		rc, err := c.request(ctx, serverURL, buildID)
 		if err != nil {
+			cancel()
 			level.Warn(logger).Log(
 				"msg", "failed to download debug info file from upstream debuginfod server, trying next one (if exists)",
 				"server", serverURL, "err", err,
 			)
 			continue
 		}
-		if rc != nil {
-			return rc, nil
-		}
+		return &cancelOnClose{ReadCloser: rc, cancel: cancel}, nil
 	}
 	return nil, ErrDebugInfoNotFound
 }
 
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
 func (c *HTTPDebugInfodClient) request(ctx context.Context, u url.URL, buildID string) (io.ReadCloser, error) {
 	// https://www.mankier.com/8/debuginfod#Webapi
 	// Endpoint: /buildid/BUILDID/debuginfo
@@ -17,17 +17,23 @@ package debuginfo
 import (
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"os/exec"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/dnaeon/go-vcr/recorder"
 	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
 	"golang.org/x/net/context"
+
+	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
 )
 
 func TestHTTPDebugInfodClient_request(t *testing.T) {
@@ -122,3 +128,66 @@ func TestHTTPDebugInfodClient_request(t *testing.T) {
 		})
 	}
 }
+
+func TestStoreFetchFromDebuginfod(t *testing.T) {
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/buildid/"+buildID+"/debuginfo" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&hits, 1)
+		_, _ = w.Write(original)
+	}))
+	t.Cleanup(srv.Close)
+
+	logger := log.NewNopLogger()
+	httpClient, err := NewHTTPDebugInfodClient(logger, []string{srv.URL}, 10*time.Second)
+	require.NoError(t, err)
+
+	cache := objstore.NewInMemBucket()
+	client, err := NewDebugInfodClientWithObjectStorageCache(logger, cache, httpClient)
+	require.NoError(t, err)
+
+	fetch := func() {
+		bucket := objstore.NewInMemBucket()
+		s, err := NewStore(
+			logger,
+			prometheus.NewRegistry(),
+			t.TempDir(),
+			NewObjectStoreMetadata(logger, bucket),
+			bucket,
+			client,
+			nil,
+			nil,
+		)
+		require.NoError(t, err)
+
+		objFile, source, err := s.FetchDebugInfo(context.Background(), buildID)
+		require.NoError(t, err)
+		require.Equal(t, debuginfopb.DownloadInfo_SOURCE_DEBUGINFOD, source)
+
+		content, err := os.ReadFile(objFile)
+		require.NoError(t, err)
+		require.Equal(t, original, content)
+	}
+
+	fetch()
+	require.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+	exists, err := cache.Exists(context.Background(), objectPath(buildID))
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// Subsequent fetches are served from the bucket.
+	fetch()
+	require.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+	_, err = client.GetDebugInfo(context.Background(), "0000")
+	require.ErrorIs(t, err, ErrDebugInfoNotFound)
+}
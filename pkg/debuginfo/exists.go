@@ -0,0 +1,116 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"sync"
+	"time"
+)
+
+// ExistsCacheConfig configures for how long the store remembers whether
+// anything is stored for a build ID in the object storage, instead of asking
+// the object storage again. Uploads and deletions through the store itself
+// are seen right away, others only once the cached result expired. A TTL of 0
+// disables caching the respective results.
+type ExistsCacheConfig struct {
+	// TTL of build IDs that something is stored for.
+	TTL time.Duration `yaml:"ttl"`
+	// NegativeTTL of build IDs that nothing is stored for.
+	NegativeTTL time.Duration `yaml:"negative_ttl"`
+}
+
+var DefaultExistsCacheConfig = ExistsCacheConfig{
+	TTL:         time.Minute,
+	NegativeTTL: 30 * time.Second,
+}
+
+// minExistsCacheSweepSize is the number of cached results from which on
+// expired results are removed.
+const minExistsCacheSweepSize = 1024
+
+type existsResult struct {
+	exists  bool
+	expires time.Time
+}
+
+// existsCache caches whether anything is stored for a build ID.
+type existsCache struct {
+	config ExistsCacheConfig
+	now    func() time.Time
+
+	mtx       sync.Mutex
+	results   map[string]existsResult
+	sweepSize int
+}
+
+func newExistsCache(config ExistsCacheConfig) *existsCache {
+	return &existsCache{
+		config:    config,
+		now:       time.Now,
+		results:   map[string]existsResult{},
+		sweepSize: minExistsCacheSweepSize,
+	}
+}
+
+// get returns whether anything is stored for the build ID, and whether that
+// is known at all.
+func (c *existsCache) get(buildID string) (bool, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	r, ok := c.results[buildID]
+	if !ok {
+		return false, false
+	}
+	if !c.now().Before(r.expires) {
+		delete(c.results, buildID)
+		return false, false
+	}
+	return r.exists, true
+}
+
+func (c *existsCache) set(buildID string, exists bool) {
+	ttl := c.config.TTL
+	if !exists {
+		ttl = c.config.NegativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := c.now()
+	if len(c.results) >= c.sweepSize {
+		for id, r := range c.results {
+			if !now.Before(r.expires) {
+				delete(c.results, id)
+			}
+		}
+		c.sweepSize = 2 * len(c.results)
+		if c.sweepSize < minExistsCacheSweepSize {
+			c.sweepSize = minExistsCacheSweepSize
+		}
+	}
+	c.results[buildID] = existsResult{exists: exists, expires: now.Add(ttl)}
+}
+
+// invalidate forgets whether anything is stored for the build ID, as it was
+// just changed.
+func (c *existsCache) invalidate(buildID string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	delete(c.results, buildID)
+}
@@ -0,0 +1,289 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/thanos-io/objstore"
+
+	"github.com/parca-dev/parca/pkg/logfields"
+)
+
+// Delete removes the debug information of the given build ID from the object
+// storage and the local cache. Deleting debug information that doesn't exist
+// is not an error.
+func (s *Store) Delete(ctx context.Context, buildID string) error {
+	raw := buildID
+	buildID, err := NormalizeBuildID(buildID)
+	if err != nil {
+		return fmt.Errorf("invalid build ID: %w", err)
+	}
+
+	unlock := s.locks.lock(buildID)
+	defer unlock()
+
+	key, err := s.storedBuildID(ctx, buildID, raw)
+	if err != nil {
+		return err
+	}
+	if err := s.delete(ctx, key); err != nil {
+		return err
+	}
+	// The status is tracked by the normalized build ID.
+	s.statuses.delete(buildID)
+	return nil
+}
+
+// delete removes all objects of the given build ID. The blob it refers to is
+// left for the garbage collection, as other build IDs may refer to it too.
+func (s *Store) delete(ctx context.Context, buildID string) error {
+	defer s.exists.invalidate(buildID)
+
+	sourceRefs, err := s.sourceRefs(ctx, buildID)
+	if err != nil {
+		return err
+	}
+	for _, name := range append([]string{blobRefPath(buildID), dwpRefPath(buildID), sourceArchiveRefPath(buildID), objectPath(buildID)}, sourceRefs...) {
+		if err := s.bucket.Delete(ctx, name); err != nil && !s.bucket.IsObjNotFoundErr(err) {
+			return fmt.Errorf("delete debug info object: %w", err)
+		}
+	}
+	if err := s.metadata.Delete(ctx, buildID); err != nil {
+		return fmt.Errorf("delete debug info metadata: %w", err)
+	}
+	if err := os.RemoveAll(path.Dir(s.localCachePath(buildID))); err != nil {
+		return fmt.Errorf("delete locally cached debug info: %w", err)
+	}
+	s.statuses.delete(buildID)
+
+	level.Debug(s.logger).Log("msg", "debug info deleted", logfields.BuildID, buildID)
+	return nil
+}
+
+// GarbageCollect deletes the debug information of all build IDs that are not
+// in the given set of live build IDs and that were uploaded longer than the
+// retention ago. Debug information that is being uploaded is never deleted.
+// Afterwards, stored content that no build ID refers to anymore is deleted.
+// It returns the build IDs that were deleted.
+func (s *Store) GarbageCollect(ctx context.Context, live map[string]struct{}, retention time.Duration) ([]string, error) {
+	buildIDs, err := s.buildIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, buildID := range buildIDs {
+		// Debug info uploaded before build IDs were normalized is stored
+		// by the build ID as it was uploaded.
+		if _, ok := live[buildID]; ok {
+			continue
+		}
+		if _, ok := live[strings.ToLower(buildID)]; !ok {
+			candidates = append(candidates, buildID)
+		}
+	}
+
+	var deleted []string
+	for _, buildID := range candidates {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		ok, err := s.collect(ctx, buildID, time.Now().Add(-retention))
+		if err != nil {
+			return deleted, fmt.Errorf("garbage collect debug info of build ID %q: %w", buildID, err)
+		}
+		if ok {
+			deleted = append(deleted, buildID)
+		}
+	}
+
+	blobs, err := s.collectBlobs(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return deleted, fmt.Errorf("garbage collect unreferenced debug info: %w", err)
+	}
+
+	level.Info(s.logger).Log("msg", "garbage collected debug info", "candidates", len(candidates), "deleted", len(deleted), "deleted_blobs", blobs)
+	return deleted, nil
+}
+
+// buildIDs returns all build IDs that have objects stored.
+func (s *Store) buildIDs(ctx context.Context) ([]string, error) {
+	var buildIDs []string
+	err := s.bucket.Iter(ctx, "", func(name string) error {
+		if !strings.HasSuffix(name, objstore.DirDelim) {
+			return nil
+		}
+		buildID := strings.TrimSuffix(name, objstore.DirDelim)
+		if validateInput(buildID) != nil {
+			// Not a build ID, e.g. the blobs directory.
+			return nil
+		}
+		buildIDs = append(buildIDs, buildID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list debug info objects: %w", err)
+	}
+	return buildIDs, nil
+}
+
+// collectBlobs deletes the blobs stored before the given time that no build
+// ID refers to. It returns the number of deleted blobs.
+func (s *Store) collectBlobs(ctx context.Context, before time.Time) (int, error) {
+	s.blobsMtx.Lock()
+	defer s.blobsMtx.Unlock()
+
+	buildIDs, err := s.buildIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	referenced := map[string]struct{}{}
+	for _, buildID := range buildIDs {
+		sourceRefs, err := s.sourceRefs(ctx, buildID)
+		if err != nil {
+			return 0, err
+		}
+		for _, ref := range append([]string{blobRefPath(buildID), dwpRefPath(buildID), sourceArchiveRefPath(buildID)}, sourceRefs...) {
+			contentHash, err := s.readRef(ctx, ref)
+			if err != nil {
+				if s.bucket.IsObjNotFoundErr(err) {
+					continue
+				}
+				return 0, err
+			}
+			referenced[contentHash] = struct{}{}
+		}
+	}
+
+	var unreferenced []string
+	err = s.bucket.Iter(ctx, blobsDir, func(name string) error {
+		if _, ok := referenced[path.Base(name)]; !ok {
+			unreferenced = append(unreferenced, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list blobs: %w", err)
+	}
+
+	deleted := 0
+	for _, name := range unreferenced {
+		attrs, err := s.bucket.Attributes(ctx, name)
+		if err != nil {
+			if s.bucket.IsObjNotFoundErr(err) {
+				continue
+			}
+			return deleted, err
+		}
+		if attrs.LastModified.After(before) {
+			continue
+		}
+		if err := s.bucket.Delete(ctx, name); err != nil && !s.bucket.IsObjNotFoundErr(err) {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// collect deletes the debug information of the given build ID if it was
+// uploaded before the given time and is not being uploaded right now.
+func (s *Store) collect(ctx context.Context, buildID string, before time.Time) (bool, error) {
+	// Holding the lock guarantees that no upload of the build ID is in
+	// progress in this process, uploads by other replicas are recognized by
+	// their metadata.
+	unlock := s.locks.lock(buildID)
+	defer unlock()
+
+	metadata, err := s.metadata.Fetch(ctx, buildID)
+	if err != nil && !errors.Is(err, ErrMetadataNotFound) {
+		return false, err
+	}
+	if metadata != nil && metadata.State == MetadataStateUploading && !isStale(metadata) {
+		return false, nil
+	}
+
+	attrs, err := s.bucket.Attributes(ctx, blobRefPath(buildID))
+	if err != nil && s.bucket.IsObjNotFoundErr(err) {
+		attrs, err = s.bucket.Attributes(ctx, objectPath(buildID))
+	}
+	if err != nil && s.bucket.IsObjNotFoundErr(err) {
+		attrs, err = s.bucket.Attributes(ctx, dwpRefPath(buildID))
+	}
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if attrs.LastModified.After(before) {
+		return false, nil
+	}
+
+	if err := s.delete(ctx, buildID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// buildIDLocks is a set of mutexes keyed by build ID.
+type buildIDLocks struct {
+	mtx   sync.Mutex
+	locks map[string]*buildIDLock
+}
+
+type buildIDLock struct {
+	mtx  sync.Mutex
+	refs int
+}
+
+func newBuildIDLocks() *buildIDLocks {
+	return &buildIDLocks{locks: map[string]*buildIDLock{}}
+}
+
+// lock locks the mutex of the given build ID and returns a function to
+// unlock it.
+func (l *buildIDLocks) lock(buildID string) func() {
+	l.mtx.Lock()
+	bl, ok := l.locks[buildID]
+	if !ok {
+		bl = &buildIDLock{}
+		l.locks[buildID] = bl
+	}
+	bl.refs++
+	l.mtx.Unlock()
+
+	bl.mtx.Lock()
+	return func() {
+		bl.mtx.Unlock()
+
+		l.mtx.Lock()
+		bl.refs--
+		if bl.refs == 0 {
+			delete(l.locks, buildID)
+		}
+		l.mtx.Unlock()
+	}
+}
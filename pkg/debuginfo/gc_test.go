@@ -0,0 +1,174 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore/providers/filesystem"
+)
+
+const (
+	liveBuildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+	deadBuildID = "73656374696f6e"
+)
+
+func newTestGCStore(t *testing.T) (*Store, *Client, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	bucket, err := filesystem.NewBucket(dir)
+	require.NoError(t, err)
+
+	s, c := newTestStoreClientWithBucket(t, bucket, WithAllowMissingBuildID(true))
+
+	ctx := context.Background()
+	for buildID, file := range map[string]string{
+		liveBuildID: "testdata/validelf_withbuildid",
+		deadBuildID: "testdata/validelf_withsections",
+	} {
+		f, err := os.Open(file)
+		require.NoError(t, err)
+		_, err = c.Upload(ctx, buildID, "abcd", f)
+		f.Close()
+		require.NoError(t, err)
+	}
+
+	return s, c, dir
+}
+
+func TestStoreGarbageCollect(t *testing.T) {
+	s, _, dir := newTestGCStore(t)
+	ctx := context.Background()
+
+	liveHash, err := s.blobRef(ctx, liveBuildID)
+	require.NoError(t, err)
+	deadHash, err := s.blobRef(ctx, deadBuildID)
+	require.NoError(t, err)
+
+	// Nothing is old enough to be collected.
+	deleted, err := s.GarbageCollect(ctx, map[string]struct{}{liveBuildID: {}}, time.Hour)
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+	require.FileExists(t, filepath.Join(dir, deadBuildID, "blob"))
+
+	deleted, err = s.GarbageCollect(ctx, map[string]struct{}{liveBuildID: {}}, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{deadBuildID}, deleted)
+
+	require.FileExists(t, filepath.Join(dir, liveBuildID, "blob"))
+	require.FileExists(t, filepath.Join(dir, liveBuildID, "metadata"))
+	require.NoFileExists(t, filepath.Join(dir, deadBuildID, "blob"))
+	require.NoFileExists(t, filepath.Join(dir, deadBuildID, "metadata"))
+	require.NoFileExists(t, s.localCachePath(deadBuildID))
+	require.FileExists(t, filepath.Join(dir, blobPath(liveHash)))
+	require.NoFileExists(t, filepath.Join(dir, blobPath(deadHash)))
+
+	_, ok := s.DebugInfoStatus(deadBuildID)
+	require.False(t, ok)
+
+	// The live object can still be used.
+	_, _, err = s.FetchDebugInfo(ctx, liveBuildID)
+	require.NoError(t, err)
+}
+
+func TestStoreGarbageCollectDWP(t *testing.T) {
+	s, c, dir := newTestGCStore(t)
+	ctx := context.Background()
+
+	for _, buildID := range []string{liveBuildID, deadBuildID} {
+		f, err := os.Open("../symbolizer/testdata/splitdwarf/split.dwp")
+		require.NoError(t, err)
+		_, err = c.UploadDWP(ctx, buildID, f)
+		f.Close()
+		require.NoError(t, err)
+	}
+	dwpHash, err := s.readRef(ctx, dwpRefPath(liveBuildID))
+	require.NoError(t, err)
+
+	deleted, err := s.GarbageCollect(ctx, map[string]struct{}{liveBuildID: {}}, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{deadBuildID}, deleted)
+	require.NoFileExists(t, filepath.Join(dir, deadBuildID, "dwp"))
+
+	// The DWARF package file is still referenced by the live build ID.
+	require.FileExists(t, filepath.Join(dir, liveBuildID, "dwp"))
+	require.FileExists(t, filepath.Join(dir, blobPath(dwpHash)))
+}
+
+func TestStoreGarbageCollectSkipsUploading(t *testing.T) {
+	s, _, dir := newTestGCStore(t)
+	ctx := context.Background()
+
+	// Simulate an upload of the same build ID in progress elsewhere.
+	require.NoError(t, s.metadata.Delete(ctx, deadBuildID))
+	require.NoError(t, s.metadata.MarkAsUploading(ctx, deadBuildID))
+
+	deleted, err := s.GarbageCollect(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{liveBuildID}, deleted)
+	require.FileExists(t, filepath.Join(dir, deadBuildID, "blob"))
+
+	// An upload in progress in this process blocks the collection until it's
+	// done.
+	unlock := s.locks.lock(deadBuildID)
+	require.NoError(t, NewObjectStoreMetadata(log.NewNopLogger(), s.bucket).MarkAsUploaded(ctx, deadBuildID, "abcd", nil))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		deleted, err = s.GarbageCollect(ctx, nil, 0)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("garbage collection did not wait for the upload")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	<-done
+
+	require.NoError(t, err)
+	require.Equal(t, []string{deadBuildID}, deleted)
+	require.NoFileExists(t, filepath.Join(dir, deadBuildID, "blob"))
+}
+
+func TestStoreDelete(t *testing.T) {
+	s, c, dir := newTestGCStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Delete(ctx, liveBuildID))
+	require.NoFileExists(t, filepath.Join(dir, liveBuildID, "blob"))
+	require.NoFileExists(t, filepath.Join(dir, liveBuildID, "metadata"))
+
+	// Deleting again is a no-op.
+	require.NoError(t, s.Delete(ctx, liveBuildID))
+
+	exists, err := c.Exists(ctx, liveBuildID, "abcd")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// The debug info can be uploaded again after it was deleted.
+	f, err := os.Open("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = c.Upload(ctx, liveBuildID, "abcd", f)
+	require.NoError(t, err)
+}
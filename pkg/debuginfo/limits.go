@@ -0,0 +1,127 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrUploadTooLarge is returned when an uploaded file is larger than allowed.
+var ErrUploadTooLarge = errors.New("upload too large")
+
+// UploadLimitsConfig limits the uploads to the store, so that a misbehaving
+// client can't thrash the object storage. A limit of 0 disables it.
+type UploadLimitsConfig struct {
+	// MaxSize is the maximum size of an uploaded file in bytes.
+	MaxSize int64 `yaml:"max_size"`
+	// Interval is the minimum time between two uploads of debug info for the
+	// same build ID. Failed uploads count as well.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// minUploadLimiterSweepSize is the number of remembered uploads from which on
+// the ones older than the interval are forgotten.
+const minUploadLimiterSweepSize = 1024
+
+// uploadLimiter enforces the limits of uploads.
+type uploadLimiter struct {
+	config UploadLimitsConfig
+	now    func() time.Time
+
+	mtx       sync.Mutex
+	uploads   map[string]time.Time
+	sweepSize int
+}
+
+func newUploadLimiter(config UploadLimitsConfig) *uploadLimiter {
+	return &uploadLimiter{
+		config:    config,
+		now:       time.Now,
+		uploads:   map[string]time.Time{},
+		sweepSize: minUploadLimiterSweepSize,
+	}
+}
+
+// allow returns whether debug info for the build ID may be uploaded now, and
+// if so counts it as uploaded.
+func (l *uploadLimiter) allow(buildID string) bool {
+	if l.config.Interval <= 0 {
+		return true
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+	if last, ok := l.uploads[buildID]; ok && now.Sub(last) < l.config.Interval {
+		return false
+	}
+
+	if len(l.uploads) >= l.sweepSize {
+		for id, last := range l.uploads {
+			if now.Sub(last) >= l.config.Interval {
+				delete(l.uploads, id)
+			}
+		}
+		l.sweepSize = 2 * len(l.uploads)
+		if l.sweepSize < minUploadLimiterSweepSize {
+			l.sweepSize = minUploadLimiterSweepSize
+		}
+	}
+	l.uploads[buildID] = now
+	return true
+}
+
+// limitSize returns a reader that fails with ErrUploadTooLarge once more than
+// the maximum size was read from r.
+func (l *uploadLimiter) limitSize(r io.Reader) io.Reader {
+	if l.config.MaxSize <= 0 {
+		return r
+	}
+	return &sizeLimitReader{r: r, max: l.config.MaxSize, remaining: l.config.MaxSize}
+}
+
+// sizeLimitReader reads at most a number of bytes from its reader, and fails
+// if there is more to read.
+type sizeLimitReader struct {
+	r         io.Reader
+	max       int64
+	remaining int64
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	// One byte more than allowed is read, to tell an upload of exactly the
+	// maximum size apart from a larger one.
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, fmt.Errorf("%w: exceeds the maximum size of %d bytes", ErrUploadTooLarge, r.max)
+	}
+	return n, err
+}
+
+// Verify verifies the data read by the underlying reader, if it can.
+func (r *sizeLimitReader) Verify() error {
+	if v, ok := r.r.(verifier); ok {
+		return v.Verify()
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+	"github.com/parca-dev/parca/pkg/symbol/elfutils"
+)
+
+// Manifest lists which of the sections needed for symbolization an uploaded
+// ELF object file has, so that it is known whether it is useful before
+// relying on it.
+type Manifest struct {
+	HasDWARFLineInfo     bool `json:"has_dwarf_line_info"`
+	HasSymtab            bool `json:"has_symtab"`
+	HasBuildID           bool `json:"has_build_id"`
+	IsSplitDWARFSkeleton bool `json:"is_split_dwarf_skeleton"`
+}
+
+// readManifest returns the manifest of the object file, or nil if it is not
+// an ELF object file.
+func readManifest(objFile string) (*Manifest, error) {
+	if !elfutils.IsELF(objFile) {
+		return nil, nil
+	}
+
+	hasLineInfo, err := elfutils.HasDWARFLineInfo(objFile)
+	if err != nil {
+		return nil, err
+	}
+	hasSymtab, err := elfutils.HasSymtab(objFile)
+	if err != nil {
+		return nil, err
+	}
+	_, err = elfutils.GNUBuildID(objFile)
+	if err != nil && !errors.Is(err, elfutils.ErrNoBuildID) {
+		return nil, err
+	}
+	hasBuildID := err == nil
+	isSkeleton, err := elfutils.HasSplitDWARF(objFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manifest{
+		HasDWARFLineInfo:     hasLineInfo,
+		HasSymtab:            hasSymtab,
+		HasBuildID:           hasBuildID,
+		IsSplitDWARFSkeleton: isSkeleton,
+	}, nil
+}
+
+// DebugInfoManifest returns the manifest recorded when the debug info of the
+// build ID was uploaded.
+func (s *Store) DebugInfoManifest(ctx context.Context, req *debuginfopb.DebugInfoManifestRequest) (*debuginfopb.DebugInfoManifestResponse, error) {
+	buildID, err := NormalizeBuildID(req.BuildId)
+	if err != nil {
+		err = fmt.Errorf("invalid build ID: %w", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	key, err := s.storedBuildID(ctx, buildID, req.BuildId)
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := s.metadata.Fetch(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrMetadataNotFound) {
+			return nil, status.Error(codes.NotFound, "no debug info was uploaded")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if md.State != MetadataStateUploaded {
+		return nil, status.Errorf(codes.NotFound, "debug info is not uploaded, its state is %s", md.State)
+	}
+	if md.Manifest == nil {
+		return nil, status.Error(codes.NotFound, "no manifest was recorded for the uploaded debug info")
+	}
+
+	return &debuginfopb.DebugInfoManifestResponse{
+		Manifest: &debuginfopb.Manifest{
+			HasDwarfLineInfo:     md.Manifest.HasDWARFLineInfo,
+			HasSymtab:            md.Manifest.HasSymtab,
+			HasBuildId:           md.Manifest.HasBuildID,
+			IsSplitDwarfSkeleton: md.Manifest.IsSplitDWARFSkeleton,
+		},
+	}, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+)
+
+func TestStoreDebugInfoManifest(t *testing.T) {
+	ctx := context.Background()
+
+	manifest := func(s *Store, buildID string) (*debuginfopb.Manifest, error) {
+		res, err := s.DebugInfoManifest(ctx, &debuginfopb.DebugInfoManifestRequest{BuildId: buildID})
+		if err != nil {
+			return nil, err
+		}
+		return res.Manifest, nil
+	}
+
+	for _, tc := range []struct {
+		name     string
+		file     string
+		buildID  string
+		manifest *debuginfopb.Manifest
+	}{{
+		name:    "with DWARF",
+		file:    "../symbolizer/testdata/e94c2ed1e1276255de44b79f0e74234cf7c70bb3/debuginfo",
+		buildID: "e94c2ed1e1276255de44b79f0e74234cf7c70bb3",
+		manifest: &debuginfopb.Manifest{
+			HasDwarfLineInfo: true,
+			HasSymtab:        true,
+			HasBuildId:       true,
+		},
+	}, {
+		name:    "stripped",
+		file:    "testdata/validelf_withbuildid",
+		buildID: "af2cabd35504fd7b26613123a1f5334b39e7d7ed",
+		manifest: &debuginfopb.Manifest{
+			HasSymtab:  true,
+			HasBuildId: true,
+		},
+	}, {
+		name:    "without line table",
+		file:    "../symbolizer/testdata/nolineprogram/debuginfo",
+		buildID: "e94c2ed1e1276255de44b79f0e74234cf7c70bb3",
+		manifest: &debuginfopb.Manifest{
+			HasSymtab:  true,
+			HasBuildId: true,
+		},
+	}, {
+		name:    "split DWARF skeleton",
+		file:    "../symbolizer/testdata/splitdwarf/split",
+		buildID: "7fafb54cb77898ceb45f6b2c5f194432ac5a401b",
+		manifest: &debuginfopb.Manifest{
+			HasDwarfLineInfo:     true,
+			HasSymtab:            true,
+			HasBuildId:           true,
+			IsSplitDwarfSkeleton: true,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, c := newTestStoreClient(t)
+
+			_, err := manifest(s, tc.buildID)
+			require.Equal(t, codes.NotFound, status.Code(err))
+
+			f, err := os.Open(tc.file)
+			require.NoError(t, err)
+			defer f.Close()
+
+			_, err = c.Upload(ctx, tc.buildID, "abcd", f)
+			require.NoError(t, err)
+
+			m, err := manifest(s, tc.buildID)
+			require.NoError(t, err)
+			require.True(t, proto.Equal(tc.manifest, m), "got %v", m)
+		})
+	}
+}
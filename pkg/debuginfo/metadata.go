@@ -25,6 +25,8 @@ import (
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/thanos-io/objstore"
+
+	"github.com/parca-dev/parca/pkg/logfields"
 )
 
 var (
@@ -93,7 +95,7 @@ type ObjectStoreMetadata struct {
 }
 
 func NewObjectStoreMetadata(logger log.Logger, bucket objstore.Bucket) *ObjectStoreMetadata {
-	return &ObjectStoreMetadata{logger: log.With(logger, "component", "debuginfo-metadata"), bucket: bucket}
+	return &ObjectStoreMetadata{logger: logfields.WithComponent(logger, "debuginfo-metadata"), bucket: bucket}
 }
 
 type Metadata struct {
@@ -102,6 +104,9 @@ type Metadata struct {
 	Hash             string        `json:"hash"`
 	UploadStartedAt  int64         `json:"upload_started_at"`
 	UploadFinishedAt int64         `json:"upload_finished_at"`
+	// Manifest is only recorded for ELF object files uploaded since it was
+	// introduced.
+	Manifest *Manifest `json:"manifest,omitempty"`
 }
 
 func (m *ObjectStoreMetadata) MarkAsCorrupted(ctx context.Context, buildID string) error {
@@ -110,7 +115,7 @@ func (m *ObjectStoreMetadata) MarkAsCorrupted(ctx context.Context, buildID strin
 	}); err != nil {
 		return fmt.Errorf("failed to write metadata: %w", err)
 	}
-	level.Debug(m.logger).Log("msg", "marked as corrupted", "buildid", buildID)
+	level.Debug(m.logger).Log("msg", "marked as corrupted", logfields.BuildID, buildID)
 	return nil
 }
 
@@ -136,11 +141,11 @@ func (m *ObjectStoreMetadata) MarkAsUploading(ctx context.Context, buildID strin
 		return fmt.Errorf("failed to write metadata: %w", err)
 	}
 
-	level.Debug(m.logger).Log("msg", "marked as uploading", "buildid", buildID)
+	level.Debug(m.logger).Log("msg", "marked as uploading", logfields.BuildID, buildID)
 	return nil
 }
 
-func (m *ObjectStoreMetadata) MarkAsUploaded(ctx context.Context, buildID, hash string) error {
+func (m *ObjectStoreMetadata) MarkAsUploaded(ctx context.Context, buildID, hash string, manifest *Manifest) error {
 	r, err := m.bucket.Get(ctx, metadataObjectPath(buildID))
 	if err != nil {
 		level.Error(m.logger).Log("msg", "expected metadata file", "err", err)
@@ -169,6 +174,7 @@ func (m *ObjectStoreMetadata) MarkAsUploaded(ctx context.Context, buildID, hash
 	metaData.State = MetadataStateUploaded
 	metaData.BuildID = buildID
 	metaData.Hash = hash
+	metaData.Manifest = manifest
 	metaData.UploadFinishedAt = time.Now().Unix()
 
 	metadataBytes, _ := json.MarshalIndent(&metaData, "", "\t")
@@ -178,7 +184,7 @@ func (m *ObjectStoreMetadata) MarkAsUploaded(ctx context.Context, buildID, hash
 		return err
 	}
 
-	level.Debug(m.logger).Log("msg", "marked as uploaded", "buildid", buildID)
+	level.Debug(m.logger).Log("msg", "marked as uploaded", logfields.BuildID, buildID)
 	return nil
 }
 
@@ -204,6 +210,15 @@ func (m *ObjectStoreMetadata) Fetch(ctx context.Context, buildID string) (*Metad
 	return metaData, nil
 }
 
+// Delete removes the metadata of the given build ID, if any.
+func (m *ObjectStoreMetadata) Delete(ctx context.Context, buildID string) error {
+	if err := m.bucket.Delete(ctx, metadataObjectPath(buildID)); err != nil && !m.bucket.IsObjNotFoundErr(err) {
+		return err
+	}
+	level.Debug(m.logger).Log("msg", "deleted metadata", logfields.BuildID, buildID)
+	return nil
+}
+
 func (m *ObjectStoreMetadata) write(ctx context.Context, buildID string, md *Metadata) error {
 	metadataBytes, _ := json.MarshalIndent(md, "", "\t")
 	r := bytes.NewReader(metadataBytes)
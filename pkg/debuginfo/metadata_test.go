@@ -51,10 +51,14 @@ func TestMetadata(t *testing.T) {
 
 	store, err := NewStore(
 		logger,
+		prometheus.NewRegistry(),
 		cacheDir,
 		NewObjectStoreMetadata(logger, bucket),
 		bucket,
 		NopDebugInfodClient{},
+		nil,
+		nil,
+		WithAllowMissingBuildID(true),
 	)
 	require.NoError(t, err)
 
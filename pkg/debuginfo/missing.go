@@ -0,0 +1,130 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+	metastorepb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+)
+
+// missingDebugInfoBatchSize is the number of unsymbolized locations read from
+// the metastore at once.
+const missingDebugInfoBatchSize = 10000
+
+// MissingDebugInfo returns the build IDs of the mappings of all unsymbolized
+// locations that have no debug info uploaded, along with the number of their
+// locations.
+func (s *Store) MissingDebugInfo(ctx context.Context, _ *debuginfopb.MissingDebugInfoRequest) (*debuginfopb.MissingDebugInfoResponse, error) {
+	if s.metastore == nil {
+		return nil, status.Error(codes.Unimplemented, "the debug info store has no metastore to find unsymbolized locations in")
+	}
+
+	locationsByMapping, err := s.unsymbolizedLocationsByMapping(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if len(locationsByMapping) == 0 {
+		return &debuginfopb.MissingDebugInfoResponse{}, nil
+	}
+
+	mappingIDs := make([]string, 0, len(locationsByMapping))
+	for id := range locationsByMapping {
+		mappingIDs = append(mappingIDs, id)
+	}
+	mres, err := s.metastore.Mappings(ctx, &metastorepb.MappingsRequest{MappingIds: mappingIDs})
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Errorf("get mappings: %w", err).Error())
+	}
+
+	locationsByBuildID := map[string]uint64{}
+	for i, m := range mres.Mappings {
+		if m == nil || m.BuildId == "" {
+			// Locations without a build ID can't be symbolized with debug
+			// info in the first place.
+			continue
+		}
+		locationsByBuildID[m.BuildId] += locationsByMapping[mappingIDs[i]]
+	}
+
+	missing := []*debuginfopb.MissingDebugInfo{}
+	for buildID, locations := range locationsByBuildID {
+		uploaded, err := s.uploaded(ctx, buildID)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if uploaded {
+			continue
+		}
+		missing = append(missing, &debuginfopb.MissingDebugInfo{
+			BuildId:   buildID,
+			Locations: locations,
+		})
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Locations != missing[j].Locations {
+			return missing[i].Locations > missing[j].Locations
+		}
+		return missing[i].BuildId < missing[j].BuildId
+	})
+
+	return &debuginfopb.MissingDebugInfoResponse{Missing: missing}, nil
+}
+
+// unsymbolizedLocationsByMapping returns the number of unsymbolized locations
+// by the ID of their mapping.
+func (s *Store) unsymbolizedLocationsByMapping(ctx context.Context) (map[string]uint64, error) {
+	locationsByMapping := map[string]uint64{}
+	minKey := ""
+	for {
+		res, err := s.metastore.UnsymbolizedLocations(ctx, &metastorepb.UnsymbolizedLocationsRequest{
+			Limit:  missingDebugInfoBatchSize,
+			MinKey: minKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get unsymbolized locations: %w", err)
+		}
+		for _, loc := range res.Locations {
+			locationsByMapping[loc.MappingId]++
+		}
+		if len(res.Locations) < missingDebugInfoBatchSize {
+			return locationsByMapping, nil
+		}
+		minKey = res.MaxKey
+	}
+}
+
+// uploaded returns true if debug info was uploaded for the build ID.
+func (s *Store) uploaded(ctx context.Context, buildID string) (bool, error) {
+	found, err := s.find(ctx, buildID)
+	if err != nil || !found {
+		return false, err
+	}
+
+	md, err := s.metadata.Fetch(ctx, buildID)
+	if err != nil {
+		if errors.Is(err, ErrMetadataNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("fetch debug info metadata: %w", err)
+	}
+	return md.State == MetadataStateUploaded, nil
+}
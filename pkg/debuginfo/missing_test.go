@@ -0,0 +1,92 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+	metastorepb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/metastore"
+	"github.com/parca-dev/parca/pkg/metastoretest"
+)
+
+func TestStoreMissingDebugInfo(t *testing.T) {
+	s, c := newTestStoreClient(t)
+	ctx := context.Background()
+
+	_, err := s.MissingDebugInfo(ctx, &debuginfopb.MissingDebugInfoRequest{})
+	require.Equal(t, "rpc error: code = Unimplemented desc = the debug info store has no metastore to find unsymbolized locations in", err.Error())
+
+	s.metastore = metastore.NewInProcessClient(metastoretest.NewTestMetastore(
+		t,
+		log.NewNopLogger(),
+		prometheus.NewRegistry(),
+		trace.NewNoopTracerProvider().Tracer(""),
+	))
+
+	const (
+		uploadedBuildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+		missingBuildID  = "1111111111111111111111111111111111111111"
+	)
+	f, err := os.Open("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = c.Upload(ctx, uploadedBuildID, "abcd", f)
+	require.NoError(t, err)
+
+	mres, err := s.metastore.GetOrCreateMappings(ctx, &metastorepb.GetOrCreateMappingsRequest{
+		Mappings: []*metastorepb.Mapping{{
+			Start:   0x401000,
+			Limit:   0x402000,
+			BuildId: uploadedBuildID,
+		}, {
+			Start:   0x7f3c1a628000,
+			Limit:   0x7f3c1a7bd000,
+			Offset:  0x28000,
+			BuildId: missingBuildID,
+		}, {
+			// Without a build ID there is no debug info to upload.
+			Start: 0x7f3c1a900000,
+			Limit: 0x7f3c1a910000,
+			File:  "/tmp/perf-1234.map",
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(mres.Mappings))
+
+	_, err = s.metastore.GetOrCreateLocations(ctx, &metastorepb.GetOrCreateLocationsRequest{
+		Locations: []*metastorepb.Location{
+			{MappingId: mres.Mappings[0].Id, Address: 0x401151},
+			{MappingId: mres.Mappings[1].Id, Address: 0x7f3c1a6a1d90},
+			{MappingId: mres.Mappings[1].Id, Address: 0x7f3c1a6a2e40},
+			{MappingId: mres.Mappings[2].Id, Address: 0x7f3c1a901000},
+		},
+	})
+	require.NoError(t, err)
+
+	res, err := s.MissingDebugInfo(ctx, &debuginfopb.MissingDebugInfoRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res.Missing))
+	require.Equal(t, missingBuildID, res.Missing[0].BuildId)
+	require.Equal(t, uint64(2), res.Missing[0].Locations)
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"time"
+
+	"github.com/parca-dev/parca/pkg/signedupload"
+)
+
+type Option func(*Store)
+
+// WithAllowMissingBuildID makes the store accept uploads of object files that
+// don't have a GNU build ID note to verify the claimed build ID against.
+func WithAllowMissingBuildID(allow bool) Option {
+	return func(s *Store) {
+		s.allowMissingBuildID = allow
+	}
+}
+
+// WithCompression sets the compression of newly uploaded objects. Stored
+// objects are read back regardless of how they were compressed. Defaults to
+// none.
+func WithCompression(c Compression) Option {
+	return func(s *Store) {
+		s.compression = c
+	}
+}
+
+// WithRetry configures how fetching debug info from the object storage is
+// retried on transient errors. Defaults to DefaultRetryConfig.
+func WithRetry(config RetryConfig) Option {
+	return func(s *Store) {
+		s.retry = config
+	}
+}
+
+// WithExistsCache configures for how long the store caches whether anything is
+// stored for a build ID. Defaults to DefaultExistsCacheConfig.
+func WithExistsCache(config ExistsCacheConfig) Option {
+	return func(s *Store) {
+		s.exists = newExistsCache(config)
+	}
+}
+
+// WithUploadLimits limits the size and rate of uploads to the store.
+func WithUploadLimits(config UploadLimitsConfig) Option {
+	return func(s *Store) {
+		s.limits = newUploadLimiter(config)
+	}
+}
+
+// WithDownloadTempDir makes the store download debug info to the configured
+// temp directory. The temp files left behind in it by downloads that didn't
+// finish, e.g. because of a crash, are removed when the store is created.
+func WithDownloadTempDir(config DownloadTempDirConfig) Option {
+	return func(s *Store) {
+		s.downloadTempDir = &config
+	}
+}
+
+// WithSignedUploadClient makes the store let clients upload debug info
+// directly to the object storage, with URLs signed by c that expire after the
+// given duration.
+func WithSignedUploadClient(c signedupload.Client, expiry time.Duration) Option {
+	return func(s *Store) {
+		s.signedUpload = c
+		s.signedUploadExpiry = expiry
+	}
+}
+
+// WithOnUploaded registers f to be called with the build ID of every debug
+// info or DWARF package file uploaded to the store, e.g. to symbolize what
+// was left unsymbolized without it.
+func WithOnUploaded(f func(buildID string)) Option {
+	return func(s *Store) {
+		s.onUploaded = append(s.onUploaded, f)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/thanos-io/objstore"
+)
+
+// defaultRangeReadBlockSize is the minimum number of bytes fetched by a
+// ranged read of an object.
+const defaultRangeReadBlockSize = 1 << 20
+
+// bucketReaderAt reads an object of a bucket with ranged reads, so that only
+// the parts of the object that are read are fetched. Each ranged read fetches
+// at least blockSize bytes, and the last fetched block is kept to serve
+// subsequent reads from. It is not safe for concurrent use.
+type bucketReaderAt struct {
+	ctx       context.Context
+	bucket    objstore.BucketReader
+	name      string
+	size      int64
+	blockSize int64
+
+	blockOff int64
+	block    []byte
+}
+
+func newBucketReaderAt(ctx context.Context, bucket objstore.BucketReader, name string, size, blockSize int64) *bucketReaderAt {
+	return &bucketReaderAt{
+		ctx:       ctx,
+		bucket:    bucket,
+		name:      name,
+		size:      size,
+		blockSize: blockSize,
+	}
+}
+
+func (r *bucketReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	n := 0
+	for n < len(p) && off < r.size {
+		if off < r.blockOff || off >= r.blockOff+int64(len(r.block)) {
+			if err := r.fetch(off, int64(len(p)-n)); err != nil {
+				return n, err
+			}
+		}
+		c := copy(p[n:], r.block[off-r.blockOff:])
+		n += c
+		off += int64(c)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *bucketReaderAt) fetch(off, length int64) error {
+	if length < r.blockSize {
+		length = r.blockSize
+	}
+	if off+length > r.size {
+		length = r.size - off
+	}
+
+	rc, err := r.bucket.GetRange(r.ctx, r.name, off, length)
+	if err != nil {
+		return fmt.Errorf("get range: %w", err)
+	}
+	defer rc.Close()
+
+	block := make([]byte, length)
+	if _, err := io.ReadFull(rc, block); err != nil {
+		return fmt.Errorf("read range: %w", err)
+	}
+	r.blockOff, r.block = off, block
+	return nil
+}
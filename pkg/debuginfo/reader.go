@@ -16,8 +16,13 @@ package debuginfo
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"strings"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -29,6 +34,16 @@ type UploadReader struct {
 	stream debuginfopb.DebugInfoService_UploadServer
 	cur    io.Reader
 	size   uint64
+
+	hash    hash.Hash
+	trailer *debuginfopb.UploadTrailer
+}
+
+func NewUploadReader(stream debuginfopb.DebugInfoService_UploadServer) *UploadReader {
+	return &UploadReader{
+		stream: stream,
+		hash:   sha256.New(),
+	}
 }
 
 func (r *UploadReader) Read(p []byte) (int, error) {
@@ -61,9 +76,58 @@ func (r *UploadReader) Read(p []byte) (int, error) {
 	}
 
 	r.size += uint64(i)
+	r.hash.Write(p[:i])
 	return i, nil
 }
 
+// Verify returns an error if the size or checksum of the data read doesn't
+// match the trailer of the upload. Uploads without a trailer are not
+// verified, as older clients don't send one.
+func (r *UploadReader) Verify() error {
+	if r.trailer == nil {
+		return nil
+	}
+	return verifyTrailer(r.trailer, r.size, r.hash)
+}
+
+func verifyTrailer(trailer *debuginfopb.UploadTrailer, size uint64, h hash.Hash) error {
+	if trailer.Size != size {
+		return fmt.Errorf("upload size mismatch: received %d bytes, expected %d bytes", size, trailer.Size)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(sum, trailer.Sha256) {
+		return fmt.Errorf("upload checksum mismatch: received data with SHA-256 %q, expected %q", sum, trailer.Sha256)
+	}
+	return nil
+}
+
+// trailerReader reads data uploaded outside of the Upload RPC, and verifies
+// it against the trailer that was sent separately.
+type trailerReader struct {
+	r       io.Reader
+	size    uint64
+	hash    hash.Hash
+	trailer *debuginfopb.UploadTrailer
+}
+
+func newTrailerReader(r io.Reader, trailer *debuginfopb.UploadTrailer) *trailerReader {
+	return &trailerReader{
+		r:       r,
+		hash:    sha256.New(),
+		trailer: trailer,
+	}
+}
+
+func (r *trailerReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.size += uint64(n)
+	r.hash.Write(p[:n])
+	return n, err
+}
+
+func (r *trailerReader) Verify() error {
+	return verifyTrailer(r.trailer, r.size, r.hash)
+}
+
 func (r *UploadReader) next() (io.Reader, error) {
 	err := contextError(r.stream.Context())
 	if err != nil {
@@ -78,6 +142,15 @@ func (r *UploadReader) next() (io.Reader, error) {
 		return nil, fmt.Errorf("receive from stream: %w", err)
 	}
 
+	if r.trailer != nil {
+		return nil, errors.New("received upload data after the trailer")
+	}
+	if trailer := req.GetTrailer(); trailer != nil {
+		r.trailer = trailer
+		// The trailer must be the last message of the stream.
+		return r.next()
+	}
+
 	return bytes.NewBuffer(req.GetChunkData()), nil
 }
 
@@ -91,3 +164,34 @@ func contextError(ctx context.Context) error {
 		return nil
 	}
 }
+
+// blobReader reads the content of a stored blob, and fails at its end with
+// ErrDebugInfoCorrupted if the content doesn't match the content hash the
+// blob is stored under, in which case corrupted is called.
+type blobReader struct {
+	io.ReadCloser
+	contentHash string
+	hash        hash.Hash
+	corrupted   func()
+}
+
+func newBlobReader(r io.ReadCloser, contentHash string, corrupted func()) *blobReader {
+	return &blobReader{
+		ReadCloser:  r,
+		contentHash: contentHash,
+		hash:        sha256.New(),
+		corrupted:   corrupted,
+	}
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if sum := hex.EncodeToString(r.hash.Sum(nil)); sum != r.contentHash {
+			r.corrupted()
+			return n, fmt.Errorf("blob checksum mismatch: read data with SHA-256 %q, expected %q: %w", sum, r.contentHash, ErrDebugInfoCorrupted)
+		}
+	}
+	return n, err
+}
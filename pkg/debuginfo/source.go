@@ -0,0 +1,255 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+	"github.com/parca-dev/parca/pkg/logfields"
+)
+
+// maxSourceContextLines is the maximum number of source lines returned before
+// and after a symbolized line.
+const maxSourceContextLines = 50
+
+// uploadSource stores a source file of the given build ID, under the path it
+// has in the debug info of the object file. It is replaced by every upload.
+func (s *Store) uploadSource(ctx context.Context, buildID, sourcePath string, r io.Reader) error {
+	if err := validateInput(buildID); err != nil {
+		err = fmt.Errorf("invalid build ID: %w", err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if sourcePath == "" {
+		return status.Error(codes.InvalidArgument, "invalid source path: empty")
+	}
+
+	level.Debug(s.logger).Log("msg", "trying to upload source file", logfields.BuildID, buildID, "path", sourcePath)
+
+	unlock := s.locks.lock(buildID)
+	defer unlock()
+	defer s.exists.invalidate(buildID)
+
+	sourceFile, blobHash, err := s.receive(r, "source-upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sourceFile)
+
+	if err := s.storeBlobRef(ctx, buildID, sourceRefPath(buildID, sourcePath), blobHash, sourceFile); err != nil {
+		level.Error(s.logger).Log("msg", "failed to store source file", logfields.BuildID, buildID, "err", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// addSourceContext adds the given number of source lines before and after
+// every symbolized line whose source file was uploaded for the build ID,
+// either on its own or as part of the source archive. Lines without an
+// uploaded source file are left as they are.
+func (s *Store) addSourceContext(ctx context.Context, buildID string, n uint32, addresses []*debuginfopb.SymbolizedAddress) {
+	if n > maxSourceContextLines {
+		n = maxSourceContextLines
+	}
+
+	sources := map[string][]string{}
+	var missing []string
+	for _, addr := range addresses {
+		for _, line := range addr.Lines {
+			if line.Filename == "" || line.Line <= 0 {
+				continue
+			}
+			if _, ok := sources[line.Filename]; ok {
+				continue
+			}
+			source, err := s.readSource(ctx, buildID, line.Filename)
+			if err != nil && !errors.Is(err, ErrDebugInfoNotFound) {
+				level.Debug(s.logger).Log("msg", "failed to read source file", logfields.BuildID, buildID, "path", line.Filename, "err", err)
+			}
+			if source == nil {
+				missing = append(missing, line.Filename)
+			}
+			sources[line.Filename] = source
+		}
+	}
+
+	var (
+		archived    map[string][]string
+		archiveHash string
+	)
+	if len(missing) > 0 {
+		var err error
+		archived, archiveHash, err = s.readArchiveSources(ctx, buildID, missing)
+		if err != nil && !errors.Is(err, ErrDebugInfoNotFound) {
+			level.Debug(s.logger).Log("msg", "failed to read source archive", logfields.BuildID, buildID, "err", err)
+		}
+	}
+
+	for _, addr := range addresses {
+		for _, line := range addr.Lines {
+			if line.Filename == "" || line.Line <= 0 {
+				continue
+			}
+			source := sources[line.Filename]
+			if source == nil {
+				if source = archived[line.Filename]; source != nil {
+					line.SourceArchiveHash = archiveHash
+				}
+			}
+			line.SourceContext = sourceContext(source, line.Line, int64(n))
+		}
+	}
+}
+
+// Source returns the lines around a line of a source file of the given build
+// ID, read from the uploaded source file or the uploaded source archive.
+func (s *Store) Source(ctx context.Context, req *debuginfopb.SourceRequest) (*debuginfopb.SourceResponse, error) {
+	buildID, err := NormalizeBuildID(req.BuildId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.Filename == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid filename: empty")
+	}
+	if req.Line <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid line: must be positive")
+	}
+	n := req.ContextLines
+	if n > maxSourceContextLines {
+		n = maxSourceContextLines
+	}
+	key, err := s.storedBuildID(ctx, buildID, req.BuildId)
+	if err != nil {
+		return nil, err
+	}
+
+	var archiveHash string
+	source, err := s.readSource(ctx, key, req.Filename)
+	if errors.Is(err, ErrDebugInfoNotFound) {
+		var archived map[string][]string
+		archived, archiveHash, err = s.readArchiveSources(ctx, key, []string{req.Filename})
+		source = archived[req.Filename]
+	}
+	if err != nil && !errors.Is(err, ErrDebugInfoNotFound) {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	lines := sourceContext(source, req.Line, int64(n))
+	if len(lines) == 0 {
+		return nil, status.Error(codes.NotFound, "source not found")
+	}
+	return &debuginfopb.SourceResponse{Lines: lines, SourceArchiveHash: archiveHash}, nil
+}
+
+// readSource returns the lines of the source file with the given path of the
+// build ID.
+func (s *Store) readSource(ctx context.Context, buildID, sourcePath string) ([]string, error) {
+	contentHash, err := s.readRef(ctx, sourceRefPath(buildID, sourcePath))
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			return nil, ErrDebugInfoNotFound
+		}
+		return nil, err
+	}
+
+	r, err := s.bucket.Get(ctx, blobPath(contentHash))
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			return nil, ErrDebugInfoNotFound
+		}
+		return nil, err
+	}
+	defer r.Close()
+
+	dr, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	defer dr.Close()
+
+	b, err := io.ReadAll(dr)
+	if err != nil {
+		return nil, fmt.Errorf("read source file: %w", err)
+	}
+	return splitLines(b), nil
+}
+
+// splitLines returns the lines of a source file, without line endings.
+func splitLines(b []byte) []string {
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// sourceContext returns the n lines of the source before and after the given
+// line, and the line itself.
+func sourceContext(source []string, line, n int64) []*debuginfopb.SourceLine {
+	if line > int64(len(source)) {
+		return nil
+	}
+
+	first, last := line-n, line+n
+	if first < 1 {
+		first = 1
+	}
+	if last > int64(len(source)) {
+		last = int64(len(source))
+	}
+
+	context := make([]*debuginfopb.SourceLine, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		context = append(context, &debuginfopb.SourceLine{Line: i, Text: source[i-1]})
+	}
+	return context
+}
+
+// sourcesDir is where the content hashes of the source files of a build ID
+// are stored.
+func sourcesDir(buildID string) string {
+	return path.Join(buildID, "sources")
+}
+
+// sourceRefPath is where the content hash of the source file with the given
+// path of a build ID is stored. The path is hashed, as it can be anything.
+func sourceRefPath(buildID, sourcePath string) string {
+	h := sha256.Sum256([]byte(sourcePath))
+	return path.Join(sourcesDir(buildID), hex.EncodeToString(h[:]))
+}
+
+// sourceRefs returns the references to the source files of the build ID.
+func (s *Store) sourceRefs(ctx context.Context, buildID string) ([]string, error) {
+	var refs []string
+	err := s.bucket.Iter(ctx, sourcesDir(buildID), func(name string) error {
+		refs = append(refs, name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list source files: %w", err)
+	}
+	return refs, nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/parca-dev/parca/pkg/logfields"
+)
+
+// uploadSourceArchive stores an archive of the source files of the given build
+// ID. It is replaced by every upload.
+func (s *Store) uploadSourceArchive(ctx context.Context, buildID string, r io.Reader) error {
+	if err := validateInput(buildID); err != nil {
+		err = fmt.Errorf("invalid build ID: %w", err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	level.Debug(s.logger).Log("msg", "trying to upload source archive", logfields.BuildID, buildID)
+
+	unlock := s.locks.lock(buildID)
+	defer unlock()
+	defer s.exists.invalidate(buildID)
+
+	archiveFile, blobHash, err := s.receive(r, "source-archive-upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archiveFile)
+
+	files := 0
+	err = walkSourceArchive(archiveFile, func(string, func() (io.Reader, error)) error {
+		files++
+		return nil
+	})
+	if err == nil && files == 0 {
+		err = errors.New("no files")
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid source archive: %w", err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.storeBlobRef(ctx, buildID, sourceArchiveRefPath(buildID), blobHash, archiveFile); err != nil {
+		level.Error(s.logger).Log("msg", "failed to store source archive", logfields.BuildID, buildID, "err", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// readArchiveSources returns the lines of the source files with the given
+// paths that are in the source archive of the build ID, along with the content
+// hash of the archive.
+func (s *Store) readArchiveSources(ctx context.Context, buildID string, sourcePaths []string) (map[string][]string, string, error) {
+	contentHash, err := s.readRef(ctx, sourceArchiveRefPath(buildID))
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			return nil, "", ErrDebugInfoNotFound
+		}
+		return nil, "", err
+	}
+
+	r, err := s.bucket.Get(ctx, blobPath(contentHash))
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			return nil, "", ErrDebugInfoNotFound
+		}
+		return nil, "", err
+	}
+	defer r.Close()
+
+	dr, err := decompress(r)
+	if err != nil {
+		return nil, "", err
+	}
+	defer dr.Close()
+
+	// Zip archives can only be read from files.
+	f, err := os.CreateTemp(s.cacheDir, "source-archive-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("create temporary file for source archive: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := io.Copy(f, dr); err != nil {
+		return nil, "", fmt.Errorf("read source archive: %w", err)
+	}
+
+	// The longest entry a path ends with is the best match.
+	sources := map[string][]string{}
+	matched := map[string]string{}
+	err = walkSourceArchive(f.Name(), func(name string, open func() (io.Reader, error)) error {
+		name = strings.TrimPrefix(path.Clean("/"+name), "/")
+		var read []string
+		for _, sourcePath := range sourcePaths {
+			if sourcePath != name && !strings.HasSuffix(sourcePath, "/"+name) || len(matched[sourcePath]) >= len(name) {
+				continue
+			}
+			if read == nil {
+				r, err := open()
+				if err != nil {
+					return err
+				}
+				b, err := io.ReadAll(r)
+				if err != nil {
+					return err
+				}
+				read = splitLines(b)
+			}
+			matched[sourcePath] = name
+			sources[sourcePath] = read
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("read source archive: %w", err)
+	}
+	return sources, contentHash, nil
+}
+
+var zipMagic = []byte("PK\x03\x04")
+
+// walkSourceArchive calls f with the name of every regular file in the tar,
+// optionally gzip compressed, or zip archive, and a function to read it.
+func walkSourceArchive(file string, f func(name string, open func() (io.Reader, error)) error) error {
+	a, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(a, magic); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if _, err := a.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if bytes.Equal(magic, zipMagic) {
+		fi, err := a.Stat()
+		if err != nil {
+			return err
+		}
+		zr, err := zip.NewReader(a, fi.Size())
+		if err != nil {
+			return err
+		}
+		for _, zf := range zr.File {
+			if !zf.Mode().IsRegular() {
+				continue
+			}
+			var rc io.ReadCloser
+			err := f(zf.Name, func() (io.Reader, error) {
+				var err error
+				rc, err = zf.Open()
+				return rc, err
+			})
+			if rc != nil {
+				rc.Close()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var r io.Reader = a
+	if bytes.HasPrefix(magic, gzipMagic) {
+		gr, err := gzip.NewReader(a)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		r = gr
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := f(hdr.Name, func() (io.Reader, error) { return tr, nil }); err != nil {
+			return err
+		}
+	}
+}
+
+// sourceArchiveRefPath is where the content hash of the source archive of a
+// build ID is stored.
+func sourceArchiveRefPath(buildID string) string {
+	return path.Join(buildID, "source-archive")
+}
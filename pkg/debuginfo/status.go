@@ -0,0 +1,86 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"sync"
+	"time"
+)
+
+type StatusState int64
+
+const (
+	StatusStateUnknown StatusState = iota
+	// The debug info file is neither uploaded nor available from debuginfod.
+	StatusStateNotUploaded
+	// The debug info file is available.
+	StatusStateUploaded
+	// The debug info file is corrupted.
+	StatusStateCorrupted
+	// The debug info file was successfully used for symbolization.
+	StatusStateSymbolized
+)
+
+var statusStateStr = map[StatusState]string{
+	StatusStateUnknown:     "STATUS_STATE_UNKNOWN",
+	StatusStateNotUploaded: "STATUS_STATE_NOT_UPLOADED",
+	StatusStateUploaded:    "STATUS_STATE_UPLOADED",
+	StatusStateCorrupted:   "STATUS_STATE_CORRUPTED",
+	StatusStateSymbolized:  "STATUS_STATE_SYMBOLIZED",
+}
+
+func (s StatusState) String() string {
+	val, ok := statusStateStr[s]
+	if !ok {
+		return "<not found>"
+	}
+	return val
+}
+
+// Status is the last known state of the debug info of a build ID.
+type Status struct {
+	State     StatusState
+	UpdatedAt time.Time
+}
+
+type statuses struct {
+	mtx sync.RWMutex
+	m   map[string]Status
+}
+
+func newStatuses() *statuses {
+	return &statuses{m: map[string]Status{}}
+}
+
+func (s *statuses) get(buildID string) (Status, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	st, ok := s.m[buildID]
+	return st, ok
+}
+
+func (s *statuses) set(buildID string, state StatusState) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.m[buildID] = Status{State: state, UpdatedAt: time.Now()}
+}
+
+func (s *statuses) delete(buildID string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.m, buildID)
+}
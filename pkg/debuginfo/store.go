@@ -16,27 +16,48 @@ package debuginfo
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"debug/elf"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/nanmu42/limitio"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/thanos-io/objstore"
 	"github.com/thanos-io/objstore/client"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
 
 	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+	metastorepb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/logfields"
+	"github.com/parca-dev/parca/pkg/signedupload"
+	"github.com/parca-dev/parca/pkg/symbol"
 	"github.com/parca-dev/parca/pkg/symbol/elfutils"
 )
 
-var ErrDebugInfoNotFound = errors.New("debug info not found")
+var (
+	ErrDebugInfoNotFound = errors.New("debug info not found")
+	// errUploadedRecently is returned for uploads of debug info for a build ID
+	// that debug info was uploaded for within the upload interval.
+	errUploadedRecently = status.Error(codes.AlreadyExists, "debuginfo was uploaded recently, try again later")
+	// ErrDebugInfoCorrupted is returned if stored debug info doesn't match
+	// the checksum recorded when it was uploaded.
+	ErrDebugInfoCorrupted = errors.New("debug info corrupted")
+)
 
 type CacheProvider string
 
@@ -47,6 +68,68 @@ const (
 type Config struct {
 	Bucket *client.BucketConfig `yaml:"bucket"`
 	Cache  *CacheConfig         `yaml:"cache"`
+	Retry  *RetryConfig         `yaml:"retry"`
+	// Compression of uploaded debug information files. Defaults to none.
+	Compression Compression `yaml:"compression"`
+	// Debuginfod configures the upstream servers debug information files
+	// missing from the bucket are fetched from.
+	Debuginfod *DebuginfodConfig `yaml:"debuginfod"`
+	// Prefix of the keys of all objects stored in the bucket, e.g. to share
+	// the bucket with other data. Defaults to the root of the bucket.
+	Prefix string `yaml:"prefix"`
+	// ArchiveBuckets are read-only buckets, tried in order, that debug
+	// information missing from the bucket is read from. The same prefix
+	// applies to them.
+	ArchiveBuckets []*client.BucketConfig `yaml:"archive_buckets"`
+}
+
+// PrefixedBucket returns the bucket with the keys of all objects prefixed by
+// the configured prefix, the bucket itself if there is none.
+func (c *Config) PrefixedBucket(bucket objstore.Bucket) objstore.Bucket {
+	return objstore.NewPrefixedBucket(bucket, c.Prefix)
+}
+
+// TieredBucket returns the bucket reading the objects missing from it from
+// the archive buckets. Objects are only written to the bucket itself.
+func (c *Config) TieredBucket(logger log.Logger, reg prometheus.Registerer, bucket objstore.Bucket) (objstore.Bucket, error) {
+	archives := make([]objstore.BucketReader, 0, len(c.ArchiveBuckets))
+	for i, cfg := range c.ArchiveBuckets {
+		b, err := yaml.Marshal(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("marshal archive bucket config: %w", err)
+		}
+		// The metrics of every bucket are registered under the same names.
+		archive, err := client.NewBucket(logger, b, prometheus.WrapRegistererWithPrefix(fmt.Sprintf("parca_debuginfo_archive_%d_", i), reg), "parca")
+		if err != nil {
+			return nil, fmt.Errorf("create archive bucket: %w", err)
+		}
+		archives = append(archives, archive)
+	}
+	return NewTieredBucket(bucket, archives...), nil
+}
+
+// DebuginfodConfig configures the upstream debuginfod servers. Files
+// downloaded from them are cached in the bucket, so each file is only fetched
+// once.
+type DebuginfodConfig struct {
+	// Ordered list of servers to try.
+	UpstreamServers []string      `yaml:"upstream_servers"`
+	Timeout         time.Duration `yaml:"timeout"`
+}
+
+// RetryConfig configures how fetching debug information from the object
+// storage is retried on transient errors. The delay between attempts grows
+// exponentially from the base delay up to the max delay, with jitter.
+type RetryConfig struct {
+	MaxRetries int           `yaml:"max_retries"`
+	BaseDelay  time.Duration `yaml:"base_delay"`
+	MaxDelay   time.Duration `yaml:"max_delay"`
+}
+
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries: 3,
+	BaseDelay:  100 * time.Millisecond,
+	MaxDelay:   5 * time.Second,
 }
 
 type FilesystemCacheConfig struct {
@@ -61,8 +144,9 @@ type CacheConfig struct {
 type MetadataManager interface {
 	MarkAsCorrupted(ctx context.Context, buildID string) error
 	MarkAsUploading(ctx context.Context, buildID string) error
-	MarkAsUploaded(ctx context.Context, buildID, hash string) error
+	MarkAsUploaded(ctx context.Context, buildID, hash string, manifest *Manifest) error
 	Fetch(ctx context.Context, buildID string) (*Metadata, error)
+	Delete(ctx context.Context, buildID string) error
 }
 
 type Store struct {
@@ -75,38 +159,135 @@ type Store struct {
 
 	metadata         MetadataManager
 	debuginfodClient DebugInfodClient
+	symbolizer       *symbol.Symbolizer
+	// metastore is used to find the object files missing debug info.
+	metastore metastorepb.MetastoreServiceClient
+
+	// allowMissingBuildID accepts uploads of object files that don't have a
+	// GNU build ID note to verify the claimed build ID against.
+	allowMissingBuildID bool
+
+	// compression is used for newly uploaded objects. Stored objects are
+	// read back regardless of how they were compressed.
+	compression Compression
+
+	retry        RetryConfig
+	fetchRetries prometheus.Counter
+
+	// rangeReadBlockSize is the minimum number of bytes fetched by the
+	// ranged reads of stored objects.
+	rangeReadBlockSize int64
+
+	// exists caches whether anything is stored for a build ID, to not ask
+	// the object storage for every request.
+	exists *existsCache
+
+	statuses *statuses
+
+	// onUploaded are called with the build ID of every uploaded debug info.
+	onUploaded []func(buildID string)
+
+	// signedUpload issues the signed URLs debug info is uploaded to directly,
+	// if the object storage supports them, valid for signedUploadExpiry.
+	signedUpload       signedupload.Client
+	signedUploadExpiry time.Duration
+
+	// limits limits the size and rate of uploads.
+	limits *uploadLimiter
+	// downloads are the temp files debug info is downloaded to, in
+	// downloadTempDir if it is configured.
+	downloads       *tempDir
+	downloadTempDir *DownloadTempDirConfig
+
+	// locks serializes uploads and deletions of the same build ID.
+	locks *buildIDLocks
+	// blobsMtx is held exclusively while unreferenced blobs are collected.
+	blobsMtx sync.RWMutex
 }
 
 // NewStore returns a new debug info store.
 func NewStore(
 	logger log.Logger,
+	reg prometheus.Registerer,
 	cacheDir string,
 	metadata MetadataManager,
 	bucket objstore.Bucket,
 	debuginfodClient DebugInfodClient,
+	symbolizer *symbol.Symbolizer,
+	metastore metastorepb.MetastoreServiceClient,
+	opts ...Option,
 ) (*Store, error) {
-	return &Store{
-		logger:           log.With(logger, "component", "debuginfo"),
+	fetchRetries := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parca_debuginfo_fetch_retries_total",
+		Help: "Total number of retried attempts to fetch debug information from the object storage.",
+	})
+	if err := reg.Register(fetchRetries); err != nil {
+		return nil, fmt.Errorf("register fetch retries metric: %w", err)
+	}
+
+	logger = logfields.WithComponent(logger, "debuginfo")
+	s := &Store{
+		logger:           logger,
 		bucket:           bucket,
 		cacheDir:         cacheDir,
 		metadata:         metadata,
 		debuginfodClient: debuginfodClient,
-	}, nil
+		symbolizer:       symbolizer,
+		metastore:        metastore,
+		retry:            DefaultRetryConfig,
+		fetchRetries:     fetchRetries,
+		exists:           newExistsCache(DefaultExistsCacheConfig),
+
+		compression:        CompressionNone,
+		rangeReadBlockSize: defaultRangeReadBlockSize,
+
+		statuses:  newStatuses(),
+		limits:    newUploadLimiter(UploadLimitsConfig{}),
+		downloads: newTempDir(logger, cacheDir, 0),
+		locks:     newBuildIDLocks(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.compression.validate(); err != nil {
+		return nil, err
+	}
+	if s.downloadTempDir != nil {
+		dir := s.downloadTempDir.Directory
+		if dir == "" {
+			dir = cacheDir
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("create download temp directory: %w", err)
+		}
+
+		s.downloads = newTempDir(logger, dir, s.downloadTempDir.MaxSize)
+		if err := s.downloads.cleanup(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
 }
 
 func (s *Store) Exists(ctx context.Context, req *debuginfopb.ExistsRequest) (*debuginfopb.ExistsResponse, error) {
-	buildID := req.BuildId
-	if err := validateInput(buildID); err != nil {
+	buildID, err := NormalizeBuildID(req.BuildId)
+	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	key, err := s.storedBuildID(ctx, buildID, req.BuildId)
+	if err != nil {
+		return nil, err
+	}
 
-	found, err := s.find(ctx, buildID)
+	found, err := s.find(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
 	if found {
-		metadataFile, err := s.metadata.Fetch(ctx, buildID)
+		metadataFile, err := s.metadata.Fetch(ctx, key)
 		if err != nil {
 			if errors.Is(err, ErrMetadataNotFound) {
 				return &debuginfopb.ExistsResponse{Exists: false}, nil
@@ -138,23 +319,145 @@ func (s *Store) Upload(stream debuginfopb.DebugInfoService_UploadServer) error {
 		return status.Errorf(codes.Unknown, msg)
 	}
 
+	buildID, err := NormalizeBuildID(req.GetInfo().BuildId)
+	if err != nil {
+		err = fmt.Errorf("invalid build ID: %w", err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	var (
-		buildID = req.GetInfo().BuildId
 		hash    = req.GetInfo().Hash
-		r       = &UploadReader{stream: stream}
+		force   = req.GetInfo().Force
+		extract = req.GetInfo().Extract
+		r       = NewUploadReader(stream)
+		limited = s.limits.limitSize(r)
 	)
-	if err := s.upload(stream.Context(), buildID, hash, r); err != nil {
+	switch req.GetInfo().Type {
+	case debuginfopb.UploadInfo_TYPE_DWP:
+		err = s.uploadDWP(stream.Context(), buildID, limited)
+	case debuginfopb.UploadInfo_TYPE_SOURCE:
+		err = s.uploadSource(stream.Context(), buildID, req.GetInfo().SourcePath, limited)
+	case debuginfopb.UploadInfo_TYPE_SOURCE_ARCHIVE:
+		err = s.uploadSourceArchive(stream.Context(), buildID, limited)
+	default:
+		if !s.limits.allow(buildID) {
+			return errUploadedRecently
+		}
+		err = s.upload(stream.Context(), buildID, hash, force, extract, limited)
+	}
+	if err != nil {
 		return err
 	}
 
-	level.Debug(s.logger).Log("msg", "debug info uploaded", "buildid", buildID)
+	level.Debug(s.logger).Log("msg", "debug info uploaded", logfields.BuildID, buildID)
+	if t := req.GetInfo().Type; t != debuginfopb.UploadInfo_TYPE_SOURCE && t != debuginfopb.UploadInfo_TYPE_SOURCE_ARCHIVE {
+		for _, f := range s.onUploaded {
+			f(buildID)
+		}
+	}
 	return stream.SendAndClose(&debuginfopb.UploadResponse{
 		BuildId: buildID,
 		Size:    r.size,
 	})
 }
 
-func (s *Store) upload(ctx context.Context, buildID, hash string, r io.Reader) error {
+func (s *Store) InitiateUpload(ctx context.Context, req *debuginfopb.InitiateUploadRequest) (*debuginfopb.InitiateUploadResponse, error) {
+	buildID, err := NormalizeBuildID(req.BuildId)
+	if err != nil {
+		err = fmt.Errorf("invalid build ID: %w", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := validateInput(req.Hash); err != nil {
+		err = fmt.Errorf("invalid hash: %w", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if s.signedUpload == nil {
+		return &debuginfopb.InitiateUploadResponse{
+			UploadStrategy: debuginfopb.InitiateUploadResponse_UPLOAD_STRATEGY_GRPC,
+		}, nil
+	}
+
+	if !s.limits.allow(buildID) {
+		return nil, errUploadedRecently
+	}
+
+	signedURL, err := s.signedUpload.SignedPUT(ctx, signedUploadPath(buildID), time.Now().Add(s.signedUploadExpiry))
+	if err != nil {
+		level.Error(s.logger).Log("msg", "failed to sign upload URL", logfields.BuildID, buildID, "err", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &debuginfopb.InitiateUploadResponse{
+		UploadStrategy: debuginfopb.InitiateUploadResponse_UPLOAD_STRATEGY_SIGNED_URL,
+		SignedUrl:      signedURL,
+	}, nil
+}
+
+// CompleteUpload stores the debug info that was uploaded to a signed URL, once
+// it is verified like the debug info uploaded with the Upload RPC.
+func (s *Store) CompleteUpload(ctx context.Context, req *debuginfopb.CompleteUploadRequest) (*debuginfopb.CompleteUploadResponse, error) {
+	if s.signedUpload == nil {
+		return nil, status.Error(codes.FailedPrecondition, "signed uploads are not enabled")
+	}
+	buildID, err := NormalizeBuildID(req.BuildId)
+	if err != nil {
+		err = fmt.Errorf("invalid build ID: %w", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.Trailer == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing upload trailer")
+	}
+
+	name := signedUploadPath(buildID)
+	// The size of the signed upload is known before it's read.
+	if s.limits.config.MaxSize > 0 {
+		attrs, err := s.bucket.Attributes(ctx, name)
+		if err != nil {
+			if s.bucket.IsObjNotFoundErr(err) {
+				return nil, status.Error(codes.NotFound, "no debug info was uploaded")
+			}
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if attrs.Size > s.limits.config.MaxSize {
+			if err := s.bucket.Delete(ctx, name); err != nil {
+				level.Warn(s.logger).Log("msg", "failed to delete signed upload", logfields.BuildID, buildID, "err", err)
+			}
+			return nil, status.Errorf(codes.ResourceExhausted, "%v: exceeds the maximum size of %d bytes", ErrUploadTooLarge, s.limits.config.MaxSize)
+		}
+	}
+
+	rc, err := s.bucket.Get(ctx, name)
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			return nil, status.Error(codes.NotFound, "no debug info was uploaded")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer rc.Close()
+
+	r := newTrailerReader(rc, req.Trailer)
+	uploadErr := s.upload(ctx, buildID, req.Hash, req.Force, req.Extract, s.limits.limitSize(r))
+	// The uploaded object is only staged, it is either stored as a blob now
+	// or has to be uploaded again.
+	if err := s.bucket.Delete(ctx, name); err != nil {
+		level.Warn(s.logger).Log("msg", "failed to delete signed upload", logfields.BuildID, buildID, "err", err)
+	}
+	if uploadErr != nil {
+		return nil, uploadErr
+	}
+
+	level.Debug(s.logger).Log("msg", "debug info uploaded with signed URL", logfields.BuildID, buildID)
+	for _, f := range s.onUploaded {
+		f(buildID)
+	}
+	return &debuginfopb.CompleteUploadResponse{
+		BuildId: buildID,
+		Size:    r.size,
+	}, nil
+}
+
+func (s *Store) upload(ctx context.Context, buildID, hash string, force, extract bool, r io.Reader) error {
 	if err := validateInput(buildID); err != nil {
 		err = fmt.Errorf("invalid build ID: %w", err)
 		return status.Error(codes.InvalidArgument, err.Error())
@@ -165,7 +468,11 @@ func (s *Store) upload(ctx context.Context, buildID, hash string, r io.Reader) e
 		return status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	level.Debug(s.logger).Log("msg", "trying to upload debug info", "buildid", buildID)
+	level.Debug(s.logger).Log("msg", "trying to upload debug info", logfields.BuildID, buildID)
+
+	unlock := s.locks.lock(buildID)
+	defer unlock()
+	defer s.exists.invalidate(buildID)
 
 	metadataFile, err := s.metadata.Fetch(ctx, buildID)
 	if err == nil {
@@ -176,7 +483,10 @@ func (s *Store) upload(ctx context.Context, buildID, hash string, r io.Reader) e
 			// Corrupted. Re-upload.
 		case MetadataStateUploaded:
 			// The debug info was fully uploaded.
-			return status.Error(codes.AlreadyExists, "debuginfo already exists")
+			if !force {
+				return status.Error(codes.AlreadyExists, "debuginfo already exists")
+			}
+			return s.reupload(ctx, buildID, extract, r)
 		case MetadataStateUploading:
 			if !isStale(metadataFile) {
 				return status.Error(codes.AlreadyExists, "debuginfo already exists, being uploaded right now")
@@ -199,33 +509,37 @@ func (s *Store) upload(ctx context.Context, buildID, hash string, r io.Reader) e
 	if found {
 		if hash != "" && metadataFile != nil {
 			if metadataFile.Hash == hash {
-				level.Debug(s.logger).Log("msg", "debug info already exists", "buildid", buildID)
+				level.Debug(s.logger).Log("msg", "debug info already exists", logfields.BuildID, buildID)
 				return status.Error(codes.AlreadyExists, "debuginfo already exists")
 			}
 		}
 
 		objFile, _, err := s.FetchDebugInfo(ctx, buildID)
-		if err != nil {
+		if err != nil && !errors.Is(err, ErrDebugInfoNotFound) {
 			return status.Error(codes.Internal, err.Error())
 		}
 
-		if err := elfutils.ValidateFile(objFile); err != nil {
-			// Failed to validate. Mark the file as corrupted, and let the client try to upload it again.
-			if err := s.metadata.MarkAsCorrupted(ctx, buildID); err != nil {
-				level.Warn(s.logger).Log("msg", "failed to update metadata as corrupted", "err", err)
+		// If only the metadata exists, e.g. because a previous upload was
+		// discarded, let the client upload the object file.
+		if err == nil {
+			if err := elfutils.ValidateFile(objFile); err != nil {
+				// Failed to validate. Mark the file as corrupted, and let the client try to upload it again.
+				if err := s.metadata.MarkAsCorrupted(ctx, buildID); err != nil {
+					level.Warn(s.logger).Log("msg", "failed to update metadata as corrupted", "err", err)
+				}
+				level.Error(s.logger).Log("msg", "failed to validate object file", logfields.BuildID, buildID)
+				// Client will retry.
+				return status.Error(codes.Internal, err.Error())
 			}
-			level.Error(s.logger).Log("msg", "failed to validate object file", "buildid", buildID)
-			// Client will retry.
-			return status.Error(codes.Internal, err.Error())
-		}
 
-		// Valid.
-		hasDWARF, err := elfutils.HasDWARF(objFile)
-		if err != nil {
-			level.Debug(s.logger).Log("msg", "failed to check for DWARF", "err", err)
-		}
-		if hasDWARF {
-			return status.Error(codes.AlreadyExists, "debuginfo already exists")
+			// Valid.
+			hasDWARF, err := elfutils.HasDWARF(objFile)
+			if err != nil {
+				level.Debug(s.logger).Log("msg", "failed to check for DWARF", "err", err)
+			}
+			if hasDWARF {
+				return status.Error(codes.AlreadyExists, "debuginfo already exists")
+			}
 		}
 	}
 
@@ -244,20 +558,37 @@ func (s *Store) upload(ctx context.Context, buildID, hash string, r io.Reader) e
 	b := bytes.NewBuffer(nil)
 	w := limitio.NewWriter(b, 64, true)
 
-	// Here we're optimistically uploading the received stream directly to the bucket,
-	// and if something goes wrong we mark it as corrupted, so it could be overwritten in subsequent calls.
-	// We only want to make sure we don't read a corrupted file while symbolizing.
-	// Ww also wanted to prevent any form of buffering for this data on the server-side,
-	// thus the optimistic writes directly to the object-store while also writing the header of the file into a buffer,
-	// so we can validate the ELF header.
-	if err := s.bucket.Upload(ctx, objectPath(buildID), io.TeeReader(r, w)); err != nil {
-		msg := "failed to upload"
-		level.Error(s.logger).Log("msg", msg, "err", err)
-		return status.Errorf(codes.Unknown, msg)
+	// The received stream is written to a temporary file in the local cache
+	// directory instead of buffering it in memory. It is only stored in the
+	// bucket once it has been verified, and its content hash is known.
+	tmpfile, err := os.CreateTemp(s.cacheDir, "debuginfo-upload-*")
+	if err != nil {
+		err = fmt.Errorf("failed to create temporary file for upload: %w", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+
+	contentHash := sha256.New()
+	checksum := crc32.NewIEEE()
+	if _, err := io.Copy(io.MultiWriter(w, tmpfile, contentHash, checksum), r); err != nil {
+		if errors.Is(err, ErrUploadTooLarge) {
+			// Let the client upload smaller debug info, e.g. an extract.
+			s.statuses.set(buildID, StatusStateCorrupted)
+			if err := s.metadata.MarkAsCorrupted(ctx, buildID); err != nil {
+				level.Warn(s.logger).Log("msg", "failed to update metadata as corrupted", "err", err)
+			}
+		}
+		return s.readUploadError(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		err = fmt.Errorf("failed to close temporary file for upload: %w", err)
+		return status.Error(codes.Internal, err.Error())
 	}
 
 	if err := elfutils.ValidateHeader(b); err != nil {
 		// Failed to validate. Mark the incoming stream as corrupted, and let the client try to upload it again.
+		s.statuses.set(buildID, StatusStateCorrupted)
 		if err := s.metadata.MarkAsCorrupted(ctx, buildID); err != nil {
 			err = fmt.Errorf("failed to update metadata after uploaded, as corrupted: %w", err)
 			return status.Error(codes.Internal, err.Error())
@@ -265,11 +596,361 @@ func (s *Store) upload(ctx context.Context, buildID, hash string, r io.Reader) e
 		return status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	if err := s.metadata.MarkAsUploaded(ctx, buildID, hash); err != nil {
+	if v, ok := r.(verifier); ok {
+		if err := v.Verify(); err != nil {
+			return s.discardUpload(ctx, buildID, err)
+		}
+	}
+
+	if err := s.validateBuildID(buildID, tmpfile.Name()); err != nil {
+		return s.discardUpload(ctx, buildID, err)
+	}
+
+	objFile, blobHash := tmpfile.Name(), hex.EncodeToString(contentHash.Sum(nil))
+	// Only the debug info of ELF files can be extracted, Mach-O files are
+	// usually uploaded as the DWARF file of their dSYM bundle already, and
+	// PDB files only hold debug info.
+	if extract && elfutils.IsELF(objFile) {
+		objFile, blobHash, err = s.extractDebugInfo(objFile)
+		if err != nil {
+			return s.discardUpload(ctx, buildID, err)
+		}
+		defer os.Remove(objFile)
+	}
+	if err := s.storeBlob(ctx, buildID, blobHash, objFile); err != nil {
+		level.Error(s.logger).Log("msg", "failed to store debug info", logfields.BuildID, buildID, "err", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	// Stripped object files can refer to this one as their separate debug
+	// file by its checksum.
+	hasDWARF, err := elfutils.HasDWARF(tmpfile.Name())
+	if err != nil {
+		level.Debug(s.logger).Log("msg", "failed to check for DWARF", "err", err)
+	}
+	if hasDWARF {
+		if err := s.bucket.Upload(ctx, debugLinkRefPath(checksum.Sum32()), strings.NewReader(blobHash)); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to index debug info by checksum", logfields.BuildID, buildID, "err", err)
+		}
+	}
+
+	manifest, err := readManifest(objFile)
+	if err != nil {
+		level.Debug(s.logger).Log("msg", "failed to read manifest of debug info", logfields.BuildID, buildID, "err", err)
+	}
+	if err := s.metadata.MarkAsUploaded(ctx, buildID, hash, manifest); err != nil {
 		err = fmt.Errorf("failed to update metadata after uploaded: %w", err)
 		return status.Error(codes.Internal, err.Error())
 	}
+	s.statuses.set(buildID, StatusStateUploaded)
+	if s.symbolizer != nil {
+		s.symbolizer.Invalidate(buildID)
+	}
+
+	// The verified file replaces anything cached locally for the build ID.
+	localPath := s.localCachePath(buildID)
+	if err := os.MkdirAll(path.Dir(localPath), 0o700); err != nil {
+		level.Debug(s.logger).Log("msg", "failed to create debug info cache directory", "err", err)
+		return nil
+	}
+	if err := os.Rename(objFile, localPath); err != nil {
+		level.Debug(s.logger).Log("msg", "failed to cache uploaded debug info", "err", err)
+	}
+
+	return nil
+}
+
+// reupload reads the forced upload of debug info that was already uploaded
+// for the given build ID, and verifies that its content is identical. The
+// stored debug info is left as is either way.
+func (s *Store) reupload(ctx context.Context, buildID string, extract bool, r io.Reader) error {
+	uploadedHash, err := s.uploadedContentHash(ctx, buildID)
+	if err != nil {
+		err = fmt.Errorf("failed to hash uploaded debuginfo: %w", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	var contentHash string
+	if extract {
+		// What is stored is the extract of the uploaded object file.
+		objFile, _, err := s.receive(r, "debuginfo-upload-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(objFile)
 
+		extracted, extractedHash, err := s.extractDebugInfo(objFile)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		os.Remove(extracted)
+		contentHash = extractedHash
+	} else {
+		h := sha256.New()
+		if _, err := io.Copy(h, r); err != nil {
+			return s.readUploadError(err)
+		}
+		if v, ok := r.(verifier); ok {
+			if err := v.Verify(); err != nil {
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+		}
+		contentHash = hex.EncodeToString(h.Sum(nil))
+	}
+
+	if contentHash != uploadedHash {
+		level.Warn(s.logger).Log("msg", "rejected upload of debug info with different content than uploaded before", logfields.BuildID, buildID)
+		return status.Errorf(codes.FailedPrecondition, "debuginfo for build ID %q already exists with different content", buildID)
+	}
+	level.Debug(s.logger).Log("msg", "forced upload of debug info is identical to the uploaded one", logfields.BuildID, buildID)
+	return nil
+}
+
+// extractDebugInfo extracts the parts of the given object file that are
+// needed for symbolization into a temporary file, and returns it with its hex
+// encoded SHA-256 hash.
+func (s *Store) extractDebugInfo(objFile string) (string, string, error) {
+	tmpfile, err := os.CreateTemp(s.cacheDir, "debuginfo-extract-*")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temporary file for extract: %w", err)
+	}
+	tmpfile.Close()
+
+	if err := elfutils.ExtractDebugInfo(tmpfile.Name(), objFile); err != nil {
+		os.Remove(tmpfile.Name())
+		return "", "", fmt.Errorf("failed to extract debug info: %w", err)
+	}
+
+	f, err := os.Open(tmpfile.Name())
+	if err != nil {
+		os.Remove(tmpfile.Name())
+		return "", "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		os.Remove(tmpfile.Name())
+		return "", "", err
+	}
+	return tmpfile.Name(), hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadedContentHash returns the hex encoded SHA-256 hash of the uploaded
+// debug info of the given build ID.
+func (s *Store) uploadedContentHash(ctx context.Context, buildID string) (string, error) {
+	contentHash, err := s.blobRef(ctx, buildID)
+	if err == nil || !s.bucket.IsObjNotFoundErr(err) {
+		return contentHash, err
+	}
+
+	// Object files stored before they were content addressed need to be
+	// hashed.
+	rc, err := s.bucket.Get(ctx, objectPath(buildID))
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	r, err := decompress(rc)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadDWP stores the DWARF package file of the given build ID, which holds
+// the split debug info of the skeleton units of its object file. A DWARF
+// package file has no build ID of its own, it is replaced by every upload.
+func (s *Store) uploadDWP(ctx context.Context, buildID string, r io.Reader) error {
+	if err := validateInput(buildID); err != nil {
+		err = fmt.Errorf("invalid build ID: %w", err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	level.Debug(s.logger).Log("msg", "trying to upload DWARF package file", logfields.BuildID, buildID)
+
+	unlock := s.locks.lock(buildID)
+	defer unlock()
+	defer s.exists.invalidate(buildID)
+
+	dwpFile, blobHash, err := s.receive(r, "dwp-upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(dwpFile)
+
+	if err := elfutils.ValidateDWP(dwpFile); err != nil {
+		err = fmt.Errorf("invalid DWARF package file: %w", err)
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.storeBlobRef(ctx, buildID, dwpRefPath(buildID), blobHash, dwpFile); err != nil {
+		level.Error(s.logger).Log("msg", "failed to store DWARF package file", logfields.BuildID, buildID, "err", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	// Any locally cached DWARF package file of the build ID is outdated, and
+	// so is what was resolved without it.
+	dwpFiles, err := filepath.Glob(elfutils.DWPPath(path.Join(s.cacheDir, buildID, "*")))
+	if err == nil {
+		for _, dwpFile := range dwpFiles {
+			os.Remove(dwpFile)
+		}
+	}
+	if s.symbolizer != nil {
+		s.symbolizer.Invalidate(buildID)
+	}
+	return nil
+}
+
+// receive writes the uploaded file to a temporary file in the local cache
+// directory, and verifies it. It returns the path of the temporary file, which
+// the caller has to remove, and the hex encoded SHA-256 hash of its content.
+func (s *Store) receive(r io.Reader, pattern string) (string, string, error) {
+	tmpfile, err := os.CreateTemp(s.cacheDir, pattern)
+	if err != nil {
+		err = fmt.Errorf("failed to create temporary file for upload: %w", err)
+		return "", "", status.Error(codes.Internal, err.Error())
+	}
+	defer tmpfile.Close()
+
+	contentHash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpfile, contentHash), r); err != nil {
+		os.Remove(tmpfile.Name())
+		return "", "", s.readUploadError(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		os.Remove(tmpfile.Name())
+		err = fmt.Errorf("failed to close temporary file for upload: %w", err)
+		return "", "", status.Error(codes.Internal, err.Error())
+	}
+
+	if v, ok := r.(verifier); ok {
+		if err := v.Verify(); err != nil {
+			os.Remove(tmpfile.Name())
+			return "", "", status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return tmpfile.Name(), hex.EncodeToString(contentHash.Sum(nil)), nil
+}
+
+// readUploadError returns the status of an upload that failed to be read.
+func (s *Store) readUploadError(err error) error {
+	if errors.Is(err, ErrUploadTooLarge) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	msg := "failed to upload"
+	level.Error(s.logger).Log("msg", msg, "err", err)
+	return status.Errorf(codes.Unknown, msg)
+}
+
+// verifier is implemented by readers that can check the integrity of the
+// data they returned once it was read completely.
+type verifier interface {
+	Verify() error
+}
+
+// discardUpload marks the upload of the given build ID that failed
+// validation for the given reason as corrupted, and lets the client upload it
+// again.
+func (s *Store) discardUpload(ctx context.Context, buildID string, reason error) error {
+	s.statuses.set(buildID, StatusStateCorrupted)
+	if err := s.metadata.MarkAsCorrupted(ctx, buildID); err != nil {
+		err = fmt.Errorf("failed to update metadata after uploaded, as corrupted: %w", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, reason.Error())
+}
+
+// storeBlob stores the object file under its content hash, unless identical
+// content is already stored, and points the build ID at it.
+func (s *Store) storeBlob(ctx context.Context, buildID, contentHash, objFile string) error {
+	// Blobs must not be garbage collected while a reference to them is
+	// being created.
+	s.blobsMtx.RLock()
+	defer s.blobsMtx.RUnlock()
+
+	if err := s.uploadBlob(ctx, buildID, contentHash, objFile); err != nil {
+		return err
+	}
+	if err := s.bucket.Upload(ctx, blobRefPath(buildID), strings.NewReader(contentHash)); err != nil {
+		return fmt.Errorf("upload blob reference: %w", err)
+	}
+
+	// An object uploaded before content addressing was introduced is
+	// superseded by the blob.
+	if err := s.bucket.Delete(ctx, objectPath(buildID)); err != nil && !s.bucket.IsObjNotFoundErr(err) {
+		level.Warn(s.logger).Log("msg", "failed to delete superseded object", logfields.BuildID, buildID, "err", err)
+	}
+	return nil
+}
+
+// storeBlobRef stores the given file of the build ID under its content hash,
+// unless identical content is already stored, and points the given reference
+// at it.
+func (s *Store) storeBlobRef(ctx context.Context, buildID, ref, contentHash, file string) error {
+	s.blobsMtx.RLock()
+	defer s.blobsMtx.RUnlock()
+
+	if err := s.uploadBlob(ctx, buildID, contentHash, file); err != nil {
+		return err
+	}
+	if err := s.bucket.Upload(ctx, ref, strings.NewReader(contentHash)); err != nil {
+		return fmt.Errorf("upload blob reference: %w", err)
+	}
+	return nil
+}
+
+// uploadBlob stores the given file under its content hash, unless identical
+// content is already stored. The caller must hold the blobs read lock.
+func (s *Store) uploadBlob(ctx context.Context, buildID, contentHash, file string) error {
+	exists, err := s.bucket.Exists(ctx, blobPath(contentHash))
+	if err != nil {
+		return fmt.Errorf("check for existing blob: %w", err)
+	}
+	if exists {
+		level.Debug(s.logger).Log("msg", "debug info with identical content already stored", logfields.BuildID, buildID, "hash", contentHash)
+		return nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("open object file: %w", err)
+	}
+	defer f.Close()
+
+	body, err := compress(f, s.compression)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := s.bucket.Upload(ctx, blobPath(contentHash), body); err != nil {
+		return fmt.Errorf("upload blob: %w", err)
+	}
+	return nil
+}
+
+// validateBuildID returns an error if the GNU build ID, or the UUID of Mach-O
+// files, of the given object file doesn't match the claimed build ID.
+func (s *Store) validateBuildID(buildID, objFile string) error {
+	id, err := elfutils.BuildID(objFile)
+	if err != nil {
+		if errors.Is(err, elfutils.ErrNoBuildID) && s.allowMissingBuildID {
+			return nil
+		}
+		return fmt.Errorf("failed to verify build ID: %w", err)
+	}
+	if !strings.EqualFold(id, buildID) {
+		return fmt.Errorf("build ID mismatch: object file has build ID %q, uploaded as %q", id, buildID)
+	}
 	return nil
 }
 
@@ -278,12 +959,17 @@ func isStale(metadataFile *Metadata) bool {
 }
 
 func (s *Store) Download(req *debuginfopb.DownloadRequest, stream debuginfopb.DebugInfoService_DownloadServer) error {
-	if err := validateInput(req.BuildId); err != nil {
+	buildID, err := NormalizeBuildID(req.BuildId)
+	if err != nil {
 		return status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	ctx := stream.Context()
-	found, err := s.find(ctx, req.BuildId)
+	key, err := s.storedBuildID(ctx, buildID, req.BuildId)
+	if err != nil {
+		return err
+	}
+	found, err := s.find(ctx, key)
 	if err != nil {
 		return err
 	}
@@ -292,7 +978,7 @@ func (s *Store) Download(req *debuginfopb.DownloadRequest, stream debuginfopb.De
 		return status.Error(codes.NotFound, "debuginfo not found")
 	}
 
-	metadata, err := s.metadata.Fetch(ctx, req.BuildId)
+	metadata, err := s.metadata.Fetch(ctx, key)
 	if err != nil {
 		return status.Error(codes.Internal, err.Error())
 	}
@@ -304,11 +990,14 @@ func (s *Store) Download(req *debuginfopb.DownloadRequest, stream debuginfopb.De
 		return status.Error(codes.Unavailable, "debuginfo is being uploaded")
 	}
 
-	objFile, source, err := s.FetchDebugInfo(ctx, req.BuildId)
+	objFile, source, err := s.fetchDebugInfo(ctx, buildID, key)
 	if err != nil {
 		if errors.Is(err, ErrDebugInfoNotFound) {
 			return status.Error(codes.NotFound, err.Error())
 		}
+		if errors.Is(err, ErrDebugInfoCorrupted) {
+			return status.Error(codes.FailedPrecondition, "debuginfo is corrupted")
+		}
 		return status.Error(codes.Internal, err.Error())
 	}
 
@@ -351,6 +1040,112 @@ func (s *Store) Download(req *debuginfopb.DownloadRequest, stream debuginfopb.De
 	return nil
 }
 
+// Symbolize resolves the source lines of the given addresses using the debug
+// information of the given build ID, without storing anything.
+func (s *Store) Symbolize(ctx context.Context, req *debuginfopb.SymbolizeRequest) (*debuginfopb.SymbolizeResponse, error) {
+	buildID, err := NormalizeBuildID(req.BuildId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	key, err := s.storedBuildID(ctx, buildID, req.BuildId)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]*metastorepb.Location, 0, len(req.Addresses))
+	for _, addr := range req.Addresses {
+		locations = append(locations, &metastorepb.Location{Address: addr})
+	}
+
+	locationsLines, err := s.symbolizer.Symbolize(
+		ctx,
+		&metastorepb.Mapping{BuildId: buildID},
+		locations,
+		func(ctx context.Context) (string, error) {
+			objFile, _, err := s.fetchDebugInfo(ctx, buildID, key)
+			return objFile, err
+		},
+	)
+	// Addresses that can't be resolved are returned without lines.
+	var addrErrs symbol.AddressErrors
+	if err != nil && !errors.As(err, &addrErrs) {
+		if errors.Is(err, ErrDebugInfoNotFound) {
+			return nil, status.Error(codes.NotFound, "debuginfo not found")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	addresses := make([]*debuginfopb.SymbolizedAddress, 0, len(req.Addresses))
+	for i, addr := range req.Addresses {
+		lines := make([]*debuginfopb.SymbolizedLine, 0, len(locationsLines[i]))
+		for _, line := range locationsLines[i] {
+			lines = append(lines, &debuginfopb.SymbolizedLine{
+				FunctionName: line.Function.Name,
+				SystemName:   line.Function.SystemName,
+				Filename:     line.Function.Filename,
+				Line:         line.Line,
+			})
+		}
+		addresses = append(addresses, &debuginfopb.SymbolizedAddress{
+			Address: addr,
+			Lines:   lines,
+		})
+	}
+	if req.SourceContextLines > 0 {
+		s.addSourceContext(ctx, key, req.SourceContextLines, addresses)
+	}
+
+	return &debuginfopb.SymbolizeResponse{Addresses: addresses}, nil
+}
+
+// maxBuildIDLength is the maximum length of hex encoded build IDs, to reject
+// anything that can't be a build ID early. The longest are hex encoded Go
+// build IDs.
+const maxBuildIDLength = 512
+
+// NormalizeBuildID returns the form of the hex encoded build ID that debug
+// info is stored and looked up by: lowercase, without surrounding whitespace,
+// and with the leading zero that was dropped from an odd number of digits.
+// Debug info that was stored by the build ID as it was uploaded before, e.g.
+// in uppercase, is still found by it, see storedBuildID.
+func NormalizeBuildID(buildID string) (string, error) {
+	buildID = strings.ToLower(strings.TrimSpace(buildID))
+	if len(buildID)%2 == 1 {
+		buildID = "0" + buildID
+	}
+	if err := validateInput(buildID); err != nil {
+		return "", err
+	}
+	if len(buildID) > maxBuildIDLength {
+		return "", fmt.Errorf("unexpectedly long input: %d characters", len(buildID))
+	}
+	return buildID, nil
+}
+
+// storedBuildID returns the key the debug info of the given normalized build ID
+// is stored by in the object storage. Debug info uploaded before build IDs were
+// normalized is stored by the build ID as it was uploaded, so the raw build ID
+// it was normalized from is looked up if nothing is stored by the normalized
+// one.
+func (s *Store) storedBuildID(ctx context.Context, buildID, raw string) (string, error) {
+	if _, err := os.Stat(s.localCachePath(buildID)); err == nil {
+		return buildID, nil
+	}
+	found, err := s.find(ctx, buildID)
+	if err != nil || found {
+		return buildID, err
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == buildID || validateInput(raw) != nil {
+		return buildID, nil
+	}
+	found, err = s.find(ctx, raw)
+	if err != nil || !found {
+		return buildID, err
+	}
+	return raw, nil
+}
+
 func validateInput(id string) error {
 	_, err := hex.DecodeString(id)
 	if err != nil {
@@ -364,6 +1159,10 @@ func validateInput(id string) error {
 }
 
 func (s *Store) find(ctx context.Context, key string) (bool, error) {
+	if found, ok := s.exists.get(key); ok {
+		return found, nil
+	}
+
 	found := false
 	err := s.bucket.Iter(ctx, key, func(_ string) error {
 		// We just need any debug files to be present, so if a file under the directory for the build ID exists,
@@ -374,21 +1173,56 @@ func (s *Store) find(ctx context.Context, key string) (bool, error) {
 	if err != nil {
 		return false, status.Error(codes.Internal, err.Error())
 	}
+	s.exists.set(key, found)
 	return found, nil
 }
 
 func (s *Store) FetchDebugInfo(ctx context.Context, buildID string) (string, debuginfopb.DownloadInfo_Source, error) {
-	logger := log.With(s.logger, "buildid", buildID)
+	// The symbolizer doesn't know the build ID the debug info was uploaded
+	// with, build IDs are either printed in lowercase or in uppercase.
+	key, err := s.storedBuildID(ctx, buildID, strings.ToUpper(buildID))
+	if err != nil {
+		return "", debuginfopb.DownloadInfo_SOURCE_UNKNOWN_UNSPECIFIED, fmt.Errorf("failed to fetch: %w", err)
+	}
+	return s.fetchDebugInfo(ctx, buildID, key)
+}
+
+// fetchDebugInfo fetches the debug info of the given build ID, which is stored
+// in the object storage by the given key.
+func (s *Store) fetchDebugInfo(ctx context.Context, buildID, key string) (string, debuginfopb.DownloadInfo_Source, error) {
+	logger := logfields.WithBuildID(s.logger, buildID)
 
 	source := debuginfopb.DownloadInfo_SOURCE_UNKNOWN_UNSPECIFIED
-	objFile, err := s.fetchFromObjectStore(ctx, buildID)
+	objFile, err := s.fetchFromObjectStore(ctx, key)
 	if err != nil {
-		// It's ok if we don't have the symbols for given BuildID, it happens too often.
-		level.Warn(logger).Log("msg", "failed to fetch object", "err", err)
+		fetchErr := err
+		corrupted := errors.Is(err, ErrDebugInfoCorrupted)
+		if corrupted {
+			level.Warn(logger).Log("msg", "stored debug information is corrupted", "err", err)
+			// Mark the debug info as corrupted, and let the client upload it again.
+			if err := s.metadata.MarkAsCorrupted(ctx, key); err != nil {
+				level.Warn(logger).Log("msg", "failed to mark debug information as corrupted", "err", err)
+			}
+		} else {
+			// It's ok if we don't have the symbols for given BuildID, it happens too often.
+			level.Warn(logger).Log("msg", "failed to fetch object", "err", err)
+		}
 
 		// Let's try to find a debug file from debuginfod servers.
 		objFile, err = s.fetchDebuginfodFile(ctx, buildID)
 		if err != nil {
+			if corrupted {
+				s.statuses.set(buildID, StatusStateCorrupted)
+				return "", source, fmt.Errorf("failed to fetch: %w", ErrDebugInfoCorrupted)
+			}
+			if !errors.Is(fetchErr, ErrDebugInfoNotFound) {
+				// The stored debug info couldn't be fetched, it isn't
+				// missing.
+				return "", source, fmt.Errorf("failed to fetch: %w", fetchErr)
+			}
+			if errors.Is(err, ErrDebugInfoNotFound) {
+				s.statuses.set(buildID, StatusStateNotUploaded)
+			}
 			return "", source, fmt.Errorf("failed to fetch: %w", err)
 		}
 		source = debuginfopb.DownloadInfo_SOURCE_DEBUGINFOD
@@ -396,11 +1230,13 @@ func (s *Store) FetchDebugInfo(ctx context.Context, buildID string) (string, deb
 		source = debuginfopb.DownloadInfo_SOURCE_UPLOAD
 	}
 
+	state := StatusStateUploaded
 	// Let's make sure we have the best version of the debug file.
 	if err := elfutils.ValidateFile(objFile); err != nil {
+		state = StatusStateCorrupted
 		level.Warn(logger).Log("msg", "failed to validate debug information", "err", err)
 		// Mark the file as corrupted, and let the client try to upload it again.
-		err := s.metadata.MarkAsCorrupted(ctx, buildID)
+		err := s.metadata.MarkAsCorrupted(ctx, key)
 		if err != nil {
 			level.Warn(logger).Log(
 				"msg", "failed to mark debug information",
@@ -414,6 +1250,7 @@ func (s *Store) FetchDebugInfo(ctx context.Context, buildID string) (string, deb
 			} else {
 				objFile = dbgFile
 				source = debuginfopb.DownloadInfo_SOURCE_DEBUGINFOD
+				state = StatusStateUploaded
 			}
 		}
 	}
@@ -424,47 +1261,363 @@ func (s *Store) FetchDebugInfo(ctx context.Context, buildID string) (string, deb
 			level.Debug(logger).Log("msg", "failed to check for DWARF", "err", err)
 		}
 		if !hasDWARF {
-			// Try to download a better version from debuginfod servers.
-			dbgFile, err := s.fetchDebuginfodFile(ctx, buildID)
-			if err != nil {
-				level.Warn(logger).Log("msg", "failed to fetch debuginfod file", "err", err)
-			} else {
+			if dbgFile, err := s.fetchDebugLinkFile(ctx, key, objFile); err == nil {
 				objFile = dbgFile
-				source = debuginfopb.DownloadInfo_SOURCE_DEBUGINFOD
+			} else {
+				if !errors.Is(err, elfutils.ErrNoDebugLink) {
+					level.Debug(logger).Log("msg", "failed to fetch separate debug file", "err", err)
+				}
+
+				// Try to download a better version from debuginfod servers.
+				dbgFile, err := s.fetchDebuginfodFile(ctx, buildID)
+				if err != nil {
+					level.Warn(logger).Log("msg", "failed to fetch debuginfod file", "err", err)
+				} else {
+					objFile = dbgFile
+					source = debuginfopb.DownloadInfo_SOURCE_DEBUGINFOD
+				}
 			}
 		}
 	}
 
+	// Object files with split DWARF need their DWARF package file to be
+	// symbolized completely.
+	if err := s.fetchDWP(ctx, key, objFile); err != nil {
+		level.Debug(logger).Log("msg", "failed to fetch DWARF package file", "err", err)
+	}
+
+	s.statuses.set(buildID, state)
 	return objFile, source, nil
 }
 
+// DebugInfoStatus returns the last known status of the debug info of the given build ID.
+func (s *Store) DebugInfoStatus(buildID string) (Status, bool) {
+	return s.statuses.get(buildID)
+}
+
+// MarkSymbolized records that the debug info of the given build ID was
+// successfully used for symbolization.
+func (s *Store) MarkSymbolized(buildID string) {
+	s.statuses.set(buildID, StatusStateSymbolized)
+}
+
+// HasDebugInfo reports whether the debug info of the given build ID is cached
+// locally or stored in the object storage, without fetching it. Debug info
+// that only debuginfod servers have is not looked for.
+func (s *Store) HasDebugInfo(ctx context.Context, buildID string) (bool, error) {
+	if _, err := os.Stat(s.localCachePath(buildID)); err == nil {
+		return true, nil
+	}
+	buildID, err := s.storedBuildID(ctx, buildID, strings.ToUpper(buildID))
+	if err != nil {
+		return false, err
+	}
+	if found, ok := s.exists.get(buildID); ok && !found {
+		return false, nil
+	}
+
+	name, err := s.objectName(ctx, buildID)
+	if err != nil {
+		return false, err
+	}
+	return s.bucket.Exists(ctx, name)
+}
+
 func (s *Store) fetchFromObjectStore(ctx context.Context, buildID string) (string, error) {
-	logger := log.With(s.logger, "buildid", buildID)
+	logger := logfields.WithBuildID(s.logger, buildID)
 
 	objFile := s.localCachePath(buildID)
 	// Check if it's already cached locally; if not download.
 	if _, err := os.Stat(objFile); os.IsNotExist(err) {
-		// Download the debuginfo file from the bucket.
-		r, err := s.bucket.Get(ctx, objectPath(buildID))
+		if found, ok := s.exists.get(buildID); ok && !found {
+			return "", ErrDebugInfoNotFound
+		}
+		err := backoff.RetryNotify(
+			func() error {
+				return s.downloadFromObjectStore(ctx, buildID, objFile)
+			},
+			backoff.WithContext(s.retryBackOff(), ctx),
+			func(err error, d time.Duration) {
+				s.fetchRetries.Inc()
+				level.Debug(logger).Log("msg", "failed to fetch object from object storage, retrying", "err", err, "backoff", d)
+			},
+		)
 		if err != nil {
-			if s.bucket.IsObjNotFoundErr(err) {
-				level.Debug(logger).Log("msg", "failed to fetch object from object storage", "err", err)
-				return "", ErrDebugInfoNotFound
-			}
-			return "", fmt.Errorf("failed to fetch object: %w", err)
+			return "", err
 		}
+	}
+
+	return objFile, nil
+}
 
-		// Cache the file locally.
-		if err := s.cache(objFile, r); err != nil {
-			return "", fmt.Errorf("failed to fetch debug info file: %w", err)
+// downloadFromObjectStore downloads the debuginfo file from the bucket and
+// caches it locally. Errors that can't be resolved by retrying are marked as
+// permanent.
+func (s *Store) downloadFromObjectStore(ctx context.Context, buildID, objFile string) error {
+	name, err := s.objectName(ctx, buildID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch object: %w", err)
+	}
+
+	// Only the parts needed for symbolization are read from large
+	// uncompressed ELF files, anything else is downloaded completely.
+	extracted, err := s.extractFromObjectStore(ctx, name, objFile)
+	if err != nil {
+		level.Debug(s.logger).Log("msg", "failed to extract debug info with ranged reads, downloading the whole object", logfields.BuildID, buildID, "err", err)
+	}
+	if extracted {
+		return nil
+	}
+
+	r, err := s.bucket.Get(ctx, name)
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			level.Debug(s.logger).Log("msg", "failed to fetch object from object storage", logfields.BuildID, buildID, "err", err)
+			return backoff.Permanent(ErrDebugInfoNotFound)
 		}
+		return fmt.Errorf("failed to fetch object: %w", err)
 	}
+	defer r.Close()
 
-	return objFile, nil
+	dr, err := decompress(r)
+	if err != nil {
+		return fmt.Errorf("failed to fetch object: %w", err)
+	}
+	defer dr.Close()
+
+	// Cache the file locally.
+	if err := s.cache(objFile, s.verifyObject(ctx, name, dr)); err != nil {
+		if errors.Is(err, ErrDebugInfoNotFound) || errors.Is(err, ErrDebugInfoCorrupted) {
+			return backoff.Permanent(fmt.Errorf("failed to fetch debug info file: %w", err))
+		}
+		return fmt.Errorf("failed to fetch debug info file: %w", err)
+	}
+	return nil
+}
+
+// extractFromObjectStore extracts the debug info of the stored ELF file with
+// the given name into the local objFile, fetching only the byte ranges of the
+// object that are needed for symbolization. Objects that fit into a single
+// ranged read, that are compressed or that aren't ELF files are not
+// extracted. As the object isn't read completely, its content hash can't be
+// verified.
+func (s *Store) extractFromObjectStore(ctx context.Context, name, objFile string) (bool, error) {
+	attrs, err := s.bucket.Attributes(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("get object attributes: %w", err)
+	}
+	if attrs.Size <= s.rangeReadBlockSize {
+		return false, nil
+	}
+
+	r := newBucketReaderAt(ctx, s.bucket, name, attrs.Size, s.rangeReadBlockSize)
+	magic := make([]byte, len(elf.ELFMAG))
+	if _, err := r.ReadAt(magic, 0); err != nil {
+		return false, fmt.Errorf("read magic bytes: %w", err)
+	}
+	if string(magic) != elf.ELFMAG {
+		return false, nil
+	}
+
+	tmpfile, err := s.downloads.create()
+	if err != nil {
+		return false, fmt.Errorf("create temp file: %w", err)
+	}
+	tmpfile.Close()
+	defer s.downloads.release(tmpfile.Name())
+
+	if err := elfutils.ExtractDebugInfoFrom(tmpfile.Name(), r); err != nil {
+		return false, err
+	}
+
+	if err := os.MkdirAll(path.Dir(objFile), 0o700); err != nil {
+		return false, fmt.Errorf("create debug info file directory: %w", err)
+	}
+	if err := os.Rename(tmpfile.Name(), objFile); err != nil {
+		return false, fmt.Errorf("atomically move extracted debug info file: %w", err)
+	}
+	return true, nil
+}
+
+// getObject returns a reader of the stored object file of the given build ID.
+func (s *Store) getObject(ctx context.Context, buildID string) (io.ReadCloser, error) {
+	name, err := s.objectName(ctx, buildID)
+	if err != nil {
+		return nil, err
+	}
+	return s.bucket.Get(ctx, name)
+}
+
+// objectName returns the name of the stored object file of the given build
+// ID. It resolves the blob the build ID refers to, or falls back to an object
+// stored under the build ID itself.
+func (s *Store) objectName(ctx context.Context, buildID string) (string, error) {
+	contentHash, err := s.blobRef(ctx, buildID)
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			return objectPath(buildID), nil
+		}
+		return "", err
+	}
+	return blobPath(contentHash), nil
+}
+
+// blobRef returns the content hash of the blob the given build ID refers to.
+func (s *Store) blobRef(ctx context.Context, buildID string) (string, error) {
+	return s.readRef(ctx, blobRefPath(buildID))
+}
+
+// readRef returns the content hash stored in the given object.
+func (s *Store) readRef(ctx context.Context, name string) (string, error) {
+	r, err := s.bucket.Get(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("read blob reference: %w", err)
+	}
+	contentHash := string(b)
+	if err := validateInput(contentHash); err != nil {
+		return "", fmt.Errorf("invalid blob reference: %w", err)
+	}
+	return contentHash, nil
+}
+
+// fetchDebugLinkFile fetches the separate debug file that the given object
+// file of the build ID refers to with its .gnu_debuglink section, if such a
+// file was uploaded.
+func (s *Store) fetchDebugLinkFile(ctx context.Context, buildID, objFile string) (string, error) {
+	_, crc, err := elfutils.DebugLink(objFile)
+	if err != nil {
+		return "", err
+	}
+
+	dbgFile := path.Join(s.cacheDir, buildID, "debuglink")
+	if _, err := os.Stat(dbgFile); err == nil {
+		return dbgFile, nil
+	}
+
+	if err := s.fetchRef(ctx, debugLinkRefPath(crc), dbgFile); err != nil {
+		return "", err
+	}
+
+	// Make sure that the checksum didn't collide with an unrelated file.
+	f, err := os.Open(dbgFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	checksum := crc32.NewIEEE()
+	if _, err := io.Copy(checksum, f); err != nil {
+		return "", err
+	}
+	if checksum.Sum32() != crc {
+		os.Remove(dbgFile)
+		return "", fmt.Errorf("separate debug file checksum mismatch: %w", ErrDebugInfoNotFound)
+	}
+
+	return dbgFile, nil
+}
+
+// fetchDWP fetches the DWARF package file of the given build ID next to the
+// object file, if the object file has split DWARF and such a file was
+// uploaded.
+func (s *Store) fetchDWP(ctx context.Context, buildID, objFile string) error {
+	dwpFile := elfutils.DWPPath(objFile)
+	if _, err := os.Stat(dwpFile); err == nil {
+		return nil
+	}
+
+	split, err := elfutils.HasSplitDWARF(objFile)
+	if err != nil || !split {
+		return err
+	}
+	return s.fetchRef(ctx, dwpRefPath(buildID), dwpFile)
+}
+
+// fetchRef caches the blob that the given reference points at in the given
+// local path.
+func (s *Store) fetchRef(ctx context.Context, ref, localPath string) error {
+	contentHash, err := s.readRef(ctx, ref)
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			return ErrDebugInfoNotFound
+		}
+		return err
+	}
+
+	r, err := s.bucket.Get(ctx, blobPath(contentHash))
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			return ErrDebugInfoNotFound
+		}
+		return err
+	}
+	defer r.Close()
+
+	dr, err := decompress(r)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+
+	return s.cache(localPath, s.verifyObject(ctx, blobPath(contentHash), dr))
+}
+
+// verifyObject returns a reader of the content of the object with the given
+// name, that fails with ErrDebugInfoCorrupted if the object is a blob and its
+// content doesn't match its content hash. Corrupted blobs are moved out of the
+// way, so that they aren't used anymore and can be uploaded again. Objects
+// stored before they were content addressed have no checksum to verify.
+func (s *Store) verifyObject(ctx context.Context, name string, r io.ReadCloser) io.ReadCloser {
+	dir, contentHash := path.Split(name)
+	if path.Clean(dir) != blobsDir {
+		return r
+	}
+	return newBlobReader(r, contentHash, func() {
+		if err := s.quarantineBlob(ctx, contentHash); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to quarantine corrupted blob", "hash", contentHash, "err", err)
+		}
+	})
+}
+
+// quarantineBlob moves the blob with the given content hash to the
+// quarantine, where it is kept for inspection.
+func (s *Store) quarantineBlob(ctx context.Context, contentHash string) error {
+	s.blobsMtx.Lock()
+	defer s.blobsMtx.Unlock()
+
+	r, err := s.bucket.Get(ctx, blobPath(contentHash))
+	if err != nil {
+		if s.bucket.IsObjNotFoundErr(err) {
+			// Already quarantined.
+			return nil
+		}
+		return err
+	}
+	defer r.Close()
+
+	if err := s.bucket.Upload(ctx, quarantinePath(contentHash), r); err != nil {
+		return fmt.Errorf("upload quarantined blob: %w", err)
+	}
+	return s.bucket.Delete(ctx, blobPath(contentHash))
+}
+
+func (s *Store) retryBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = s.retry.BaseDelay
+	b.MaxInterval = s.retry.MaxDelay
+	// Retries are bounded by count, not by time.
+	b.MaxElapsedTime = 0
+	return backoff.WithMaxRetries(b, uint64(s.retry.MaxRetries))
 }
 
 func (s *Store) fetchDebuginfodFile(ctx context.Context, buildID string) (string, error) {
-	logger := log.With(s.logger, "buildid", buildID)
+	logger := logfields.WithBuildID(s.logger, buildID)
 	level.Debug(logger).Log("msg", "attempting to download from debuginfod servers")
 
 	objFile := s.localCachePath(buildID)
@@ -491,11 +1644,11 @@ func (s *Store) localCachePath(buildID string) string {
 }
 
 func (s *Store) cache(localPath string, r io.ReadCloser) error {
-	tmpfile, err := os.CreateTemp(s.cacheDir, "symbol-download-*")
+	tmpfile, err := s.downloads.create()
 	if err != nil {
 		return fmt.Errorf("create temp file: %w", err)
 	}
-	defer os.Remove(tmpfile.Name())
+	defer s.downloads.release(tmpfile.Name())
 
 	written, err := io.Copy(tmpfile, r)
 	if err != nil {
@@ -519,6 +1672,45 @@ func (s *Store) cache(localPath string, r io.ReadCloser) error {
 	return nil
 }
 
+// objectPath is where object files were stored before they were content
+// addressed.
 func objectPath(buildID string) string {
 	return path.Join(buildID, "debuginfo")
 }
+
+// blobRefPath is where the content hash of the object file of a build ID is
+// stored.
+func blobRefPath(buildID string) string {
+	return path.Join(buildID, "blob")
+}
+
+// dwpRefPath is where the content hash of the DWARF package file of a build
+// ID is stored.
+func dwpRefPath(buildID string) string {
+	return path.Join(buildID, "dwp")
+}
+
+// debugLinkRefPath is where the content hash of the object file with the
+// given CRC32 checksum is stored, as used by .gnu_debuglink sections.
+func debugLinkRefPath(crc uint32) string {
+	return path.Join("debuglinks", fmt.Sprintf("%08x", crc))
+}
+
+const blobsDir = "blobs"
+
+// blobPath is where the object file with the given content hash is stored.
+func blobPath(contentHash string) string {
+	return path.Join(blobsDir, contentHash)
+}
+
+// signedUploadPath is where the debug info uploaded to a signed URL for a
+// build ID is staged until the upload is completed.
+func signedUploadPath(buildID string) string {
+	return path.Join("uploads", buildID)
+}
+
+// quarantinePath is where the blob with the given content hash is kept once
+// it was found to be corrupted.
+func quarantinePath(contentHash string) string {
+	return path.Join("quarantine", contentHash)
+}
@@ -16,23 +16,35 @@ package debuginfo
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"debug/elf"
 	"encoding/hex"
+	"errors"
 	"io"
 	stdlog "log"
 	"net"
 	"os"
+	"path"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
 	"github.com/thanos-io/objstore/client"
 	"github.com/thanos-io/objstore/providers/filesystem"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"gopkg.in/yaml.v2"
 
 	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+	"github.com/parca-dev/parca/pkg/objstoretest"
 )
 
 func TestStore(t *testing.T) {
@@ -58,10 +70,14 @@ func TestStore(t *testing.T) {
 
 	s, err := NewStore(
 		logger,
+		prometheus.NewRegistry(),
 		cacheDir,
 		NewObjectStoreMetadata(logger, bucket),
 		bucket,
 		NopDebugInfodClient{},
+		nil,
+		nil,
+		WithAllowMissingBuildID(true),
 	)
 	require.NoError(t, err)
 
@@ -110,7 +126,7 @@ func TestStore(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 7079, int(size))
 
-	obj, err := s.bucket.Get(context.Background(), hex.EncodeToString([]byte("section"))+"/debuginfo")
+	obj, err := s.getObject(context.Background(), hex.EncodeToString([]byte("section")))
 	require.NoError(t, err)
 
 	content, err := io.ReadAll(obj)
@@ -140,3 +156,1027 @@ func TestStore(t *testing.T) {
 	require.Equal(t, debuginfopb.DownloadInfo_SOURCE_UPLOAD, downloader.Info().Source)
 	require.NoError(t, downloader.Close())
 }
+
+func newTestStoreClient(t *testing.T, opts ...Option) (*Store, *Client) {
+	t.Helper()
+
+	return newTestStoreClientWithBucket(t, objstore.NewInMemBucket(), opts...)
+}
+
+func newTestStoreClientWithBucket(t *testing.T, bucket objstore.Bucket, opts ...Option) (*Store, *Client) {
+	t.Helper()
+
+	logger := log.NewNopLogger()
+	s, err := NewStore(
+		logger,
+		prometheus.NewRegistry(),
+		t.TempDir(),
+		NewObjectStoreMetadata(logger, bucket),
+		bucket,
+		NopDebugInfodClient{},
+		nil,
+		nil,
+		opts...,
+	)
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	t.Cleanup(grpcServer.GracefulStop)
+	debuginfopb.RegisterDebugInfoServiceServer(grpcServer, s)
+	go func() {
+		err := grpcServer.Serve(lis)
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			stdlog.Fatalf("failed to serve: %v", err)
+		}
+	}()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return s, NewDebugInfoClient(conn)
+}
+
+func TestStoreUploadValidatesBuildID(t *testing.T) {
+	s, c := newTestStoreClient(t, WithAllowMissingBuildID(true))
+	ctx := context.Background()
+
+	f, err := os.Open("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = c.Upload(ctx, "0000000000000000000000000000000000000000", "abcd", f)
+	require.ErrorContains(t, err, "code = InvalidArgument")
+	require.ErrorContains(t, err, "build ID mismatch")
+
+	// The mismatching object must not be kept around.
+	exists, err := s.bucket.Exists(ctx, blobRefPath("0000000000000000000000000000000000000000"))
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	_, err = c.Upload(ctx, "af2cabd35504fd7b26613123a1f5334b39e7d7ed", "abcd", f)
+	require.NoError(t, err)
+}
+
+func TestNormalizeBuildID(t *testing.T) {
+	for _, tc := range []struct {
+		buildID  string
+		expected string
+		err      bool
+	}{
+		{buildID: "af2cabd35504fd7b26613123a1f5334b39e7d7ed", expected: "af2cabd35504fd7b26613123a1f5334b39e7d7ed"},
+		{buildID: "AF2CABD35504FD7B26613123A1F5334B39E7D7ED", expected: "af2cabd35504fd7b26613123a1f5334b39e7d7ed"},
+		{buildID: " af2cabd35504fd7b26613123a1f5334b39e7d7ed\n", expected: "af2cabd35504fd7b26613123a1f5334b39e7d7ed"},
+		{buildID: "f2cabd35504fd7b26613123a1f5334b39e7d7ed", expected: "0f2cabd35504fd7b26613123a1f5334b39e7d7ed"},
+		{buildID: "", err: true},
+		{buildID: "ab", err: true},
+		{buildID: "not-a-build-id", err: true},
+		{buildID: strings.Repeat("ab", maxBuildIDLength), err: true},
+	} {
+		buildID, err := NormalizeBuildID(tc.buildID)
+		if tc.err {
+			require.Error(t, err, tc.buildID)
+			continue
+		}
+		require.NoError(t, err, tc.buildID)
+		require.Equal(t, tc.expected, buildID)
+	}
+}
+
+func TestStoreNormalizesBuildID(t *testing.T) {
+	const (
+		lower = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+		upper = "AF2CABD35504FD7B26613123A1F5334B39E7D7ED"
+	)
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		uploaded string
+		lookedUp string
+	}{
+		{name: "uppercase uploaded", uploaded: upper, lookedUp: lower},
+		{name: "lowercase uploaded", uploaded: lower, lookedUp: upper},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, c := newTestStoreClient(t)
+
+			_, err := c.Upload(ctx, tc.uploaded, "abcd", bytes.NewReader(original))
+			require.NoError(t, err)
+
+			exists, err := c.Exists(ctx, tc.lookedUp, "abcd")
+			require.NoError(t, err)
+			require.True(t, exists)
+
+			downloader, err := c.Downloader(ctx, tc.lookedUp)
+			require.NoError(t, err)
+			defer downloader.Close()
+			buf := bytes.NewBuffer(nil)
+			_, err = downloader.Download(ctx, buf)
+			require.NoError(t, err)
+			require.Equal(t, original, buf.Bytes())
+
+			// Everything is stored under the normalized build ID.
+			exists, err = s.bucket.Exists(ctx, blobRefPath(lower))
+			require.NoError(t, err)
+			require.True(t, exists)
+		})
+	}
+}
+
+func TestStoreFindsDebugInfoStoredBeforeNormalization(t *testing.T) {
+	const (
+		lower = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+		upper = "AF2CABD35504FD7B26613123A1F5334B39E7D7ED"
+	)
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	_, c := newTestStoreClientWithBucket(t, bucket)
+	_, err = c.Upload(ctx, lower, "abcd", bytes.NewReader(original))
+	require.NoError(t, err)
+
+	// Before build IDs were normalized, the debug info was stored by the
+	// build ID as it was uploaded.
+	for name, content := range bucket.Objects() {
+		if !strings.HasPrefix(name, lower+"/") {
+			continue
+		}
+		require.NoError(t, bucket.Upload(ctx, upper+strings.TrimPrefix(name, lower), bytes.NewReader(content)))
+		require.NoError(t, bucket.Delete(ctx, name))
+	}
+
+	s, c := newTestStoreClientWithBucket(t, bucket)
+
+	exists, err := c.Exists(ctx, upper, "abcd")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	downloader, err := c.Downloader(ctx, upper)
+	require.NoError(t, err)
+	defer downloader.Close()
+	buf := bytes.NewBuffer(nil)
+	_, err = downloader.Download(ctx, buf)
+	require.NoError(t, err)
+	require.Equal(t, original, buf.Bytes())
+
+	// The symbolizer looks up debug info by the normalized build ID.
+	has, err := s.HasDebugInfo(ctx, lower)
+	require.NoError(t, err)
+	require.True(t, has)
+	_, _, err = s.FetchDebugInfo(ctx, lower)
+	require.NoError(t, err)
+}
+
+func TestStoreUploadWithoutBuildID(t *testing.T) {
+	ctx := context.Background()
+
+	_, c := newTestStoreClient(t)
+	f, err := os.Open("testdata/validelf_withsections")
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = c.Upload(ctx, hex.EncodeToString([]byte("section")), "abcd", f)
+	require.ErrorContains(t, err, "code = InvalidArgument")
+	require.ErrorContains(t, err, "no GNU build ID note")
+
+	_, c = newTestStoreClient(t, WithAllowMissingBuildID(true))
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	size, err := c.Upload(ctx, hex.EncodeToString([]byte("section")), "abcd", f)
+	require.NoError(t, err)
+	require.Equal(t, 7079, int(size))
+}
+
+func TestStoreDebugInfoStatus(t *testing.T) {
+	s, c := newTestStoreClient(t)
+	ctx := context.Background()
+
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+	_, ok := s.DebugInfoStatus(buildID)
+	require.False(t, ok)
+
+	_, _, err := s.FetchDebugInfo(ctx, buildID)
+	require.ErrorIs(t, err, ErrDebugInfoNotFound)
+
+	st, ok := s.DebugInfoStatus(buildID)
+	require.True(t, ok)
+	require.Equal(t, StatusStateNotUploaded, st.State)
+
+	f, err := os.Open("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = c.Upload(ctx, buildID, "abcd", f)
+	require.NoError(t, err)
+
+	st, ok = s.DebugInfoStatus(buildID)
+	require.True(t, ok)
+	require.Equal(t, StatusStateUploaded, st.State)
+
+	s.MarkSymbolized(buildID)
+	st, ok = s.DebugInfoStatus(buildID)
+	require.True(t, ok)
+	require.Equal(t, StatusStateSymbolized, st.State)
+}
+
+func TestStoreFetchRetriesTransientErrors(t *testing.T) {
+	cacheDir := t.TempDir()
+	logger := log.NewNopLogger()
+
+	bucket := objstoretest.NewBucket(0)
+	require.NoError(t, bucket.Upload(context.Background(), objectPath("abcd"), bytes.NewBufferString("debuginfo")))
+	bucket.Inject(objstoretest.Fault{
+		Op:    objstoretest.OpGet,
+		Match: func(name string) bool { return name == objectPath("abcd") },
+		Err:   errors.New("service unavailable"),
+		Times: 2,
+	})
+	s, err := NewStore(
+		logger,
+		prometheus.NewRegistry(),
+		cacheDir,
+		NewObjectStoreMetadata(logger, bucket),
+		bucket,
+		NopDebugInfodClient{},
+		nil,
+		nil,
+		WithRetry(RetryConfig{
+			MaxRetries: 3,
+			BaseDelay:  time.Millisecond,
+			MaxDelay:   10 * time.Millisecond,
+		}),
+	)
+	require.NoError(t, err)
+
+	objFile, err := s.fetchFromObjectStore(context.Background(), "abcd")
+	require.NoError(t, err)
+	require.Equal(t, 3, bucket.ObjectCalls(objstoretest.OpGet, objectPath("abcd")))
+	require.Equal(t, float64(2), testutil.ToFloat64(s.fetchRetries))
+
+	content, err := os.ReadFile(objFile)
+	require.NoError(t, err)
+	require.Equal(t, "debuginfo", string(content))
+}
+
+func TestStoreFetchDoesNotRetryNotFound(t *testing.T) {
+	cacheDir := t.TempDir()
+	logger := log.NewNopLogger()
+
+	bucket := objstoretest.NewBucket(0)
+	s, err := NewStore(
+		logger,
+		prometheus.NewRegistry(),
+		cacheDir,
+		NewObjectStoreMetadata(logger, bucket),
+		bucket,
+		NopDebugInfodClient{},
+		nil,
+		nil,
+		WithRetry(RetryConfig{
+			MaxRetries: 3,
+			BaseDelay:  time.Millisecond,
+			MaxDelay:   10 * time.Millisecond,
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = s.fetchFromObjectStore(context.Background(), "abcd")
+	require.ErrorIs(t, err, ErrDebugInfoNotFound)
+	require.Equal(t, 1, bucket.ObjectCalls(objstoretest.OpGet, objectPath("abcd")))
+	require.Equal(t, float64(0), testutil.ToFloat64(s.fetchRetries))
+}
+
+func TestStoreExistsCache(t *testing.T) {
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+
+	bucket := objstoretest.NewBucket(0)
+	s, c := newTestStoreClientWithBucket(t, bucket)
+	ctx := context.Background()
+
+	now := time.Now()
+	s.exists.now = func() time.Time { return now }
+
+	// Missing debug info is cached for the negative TTL.
+	exists, err := c.Exists(ctx, buildID, "abcd")
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = c.Exists(ctx, buildID, "abcd")
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.Equal(t, 1, bucket.Calls(objstoretest.OpIter))
+
+	_, err = s.fetchFromObjectStore(ctx, buildID)
+	require.ErrorIs(t, err, ErrDebugInfoNotFound)
+	require.Equal(t, 1, bucket.Calls(objstoretest.OpIter))
+
+	now = now.Add(DefaultExistsCacheConfig.NegativeTTL)
+	exists, err = c.Exists(ctx, buildID, "abcd")
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.Equal(t, 2, bucket.Calls(objstoretest.OpIter))
+
+	// Uploads are seen right away, and then cached for the TTL.
+	f, err := os.Open("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = c.Upload(ctx, buildID, "abcd", f)
+	require.NoError(t, err)
+
+	exists, err = c.Exists(ctx, buildID, "abcd")
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = c.Exists(ctx, buildID, "abcd")
+	require.NoError(t, err)
+	require.True(t, exists)
+	calls := bucket.Calls(objstoretest.OpIter)
+
+	now = now.Add(DefaultExistsCacheConfig.TTL - time.Second)
+	exists, err = c.Exists(ctx, buildID, "abcd")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, calls, bucket.Calls(objstoretest.OpIter))
+
+	// Deletions are seen right away.
+	require.NoError(t, s.Delete(ctx, buildID))
+	exists, err = c.Exists(ctx, buildID, "abcd")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestStoreUploadCompressed(t *testing.T) {
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		compression Compression
+		magic       []byte
+	}{
+		{compression: CompressionNone, magic: []byte{0x7f, 'E', 'L', 'F'}},
+		{compression: CompressionGzip, magic: gzipMagic},
+		{compression: CompressionZstd, magic: zstdMagic},
+	} {
+		t.Run(string(tc.compression), func(t *testing.T) {
+			ctx := context.Background()
+			s, c := newTestStoreClient(t, WithCompression(tc.compression))
+
+			size, err := c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+			require.NoError(t, err)
+			require.Equal(t, len(original), int(size))
+
+			contentHash, err := s.blobRef(ctx, buildID)
+			require.NoError(t, err)
+			obj, err := s.bucket.Get(ctx, blobPath(contentHash))
+			require.NoError(t, err)
+			stored, err := io.ReadAll(obj)
+			require.NoError(t, err)
+			require.True(t, bytes.HasPrefix(stored, tc.magic))
+
+			// Drop the locally cached file to read the object back from the bucket.
+			require.NoError(t, os.RemoveAll(s.cacheDir))
+			require.NoError(t, os.MkdirAll(s.cacheDir, 0o700))
+
+			objFile, source, err := s.FetchDebugInfo(ctx, buildID)
+			require.NoError(t, err)
+			require.Equal(t, debuginfopb.DownloadInfo_SOURCE_UPLOAD, source)
+
+			content, err := os.ReadFile(objFile)
+			require.NoError(t, err)
+			require.Equal(t, original, content)
+		})
+	}
+}
+
+func TestStoreFetchRawWithCompressionEnabled(t *testing.T) {
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+
+	// Objects uploaded before compression was enabled are stored as is.
+	s, _ := newTestStoreClient(t, WithCompression(CompressionZstd))
+	require.NoError(t, s.bucket.Upload(context.Background(), objectPath(buildID), bytes.NewReader(original)))
+
+	objFile, err := s.fetchFromObjectStore(context.Background(), buildID)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(objFile)
+	require.NoError(t, err)
+	require.Equal(t, original, content)
+}
+
+func TestStoreFetchDetectsCorruption(t *testing.T) {
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+	ctx := context.Background()
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	bucket, err := filesystem.NewBucket(dir)
+	require.NoError(t, err)
+	s, c := newTestStoreClientWithBucket(t, bucket)
+
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+	require.NoError(t, err)
+	contentHash, err := s.blobRef(ctx, buildID)
+	require.NoError(t, err)
+
+	// Corrupt the stored object at rest.
+	corrupted := append([]byte{}, original...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(path.Join(dir, blobPath(contentHash)), corrupted, 0o600))
+	require.NoError(t, os.RemoveAll(path.Dir(s.localCachePath(buildID))))
+
+	_, _, err = s.FetchDebugInfo(ctx, buildID)
+	require.ErrorIs(t, err, ErrDebugInfoCorrupted)
+	_, err = os.Stat(s.localCachePath(buildID))
+	require.True(t, os.IsNotExist(err))
+
+	st, ok := s.DebugInfoStatus(buildID)
+	require.True(t, ok)
+	require.Equal(t, StatusStateCorrupted, st.State)
+	md, err := s.metadata.Fetch(ctx, buildID)
+	require.NoError(t, err)
+	require.Equal(t, MetadataStateCorrupted, md.State)
+
+	// The corrupted object is moved out of the way.
+	exists, err := bucket.Exists(ctx, blobPath(contentHash))
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = bucket.Exists(ctx, quarantinePath(contentHash))
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// Uploading it again makes it usable again.
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+	require.NoError(t, err)
+	objFile, _, err := s.FetchDebugInfo(ctx, buildID)
+	require.NoError(t, err)
+	content, err := os.ReadFile(objFile)
+	require.NoError(t, err)
+	require.Equal(t, original, content)
+}
+
+func TestStorePrefix(t *testing.T) {
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+	ctx := context.Background()
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	bucket, err := filesystem.NewBucket(dir)
+	require.NoError(t, err)
+	cfg := Config{Prefix: "shared/parca"}
+	s, c := newTestStoreClientWithBucket(t, cfg.PrefixedBucket(bucket))
+
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+	require.NoError(t, err)
+	exists, err := c.Exists(ctx, buildID, "abcd")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	require.NoError(t, os.RemoveAll(path.Dir(s.localCachePath(buildID))))
+	objFile, _, err := s.FetchDebugInfo(ctx, buildID)
+	require.NoError(t, err)
+	content, err := os.ReadFile(objFile)
+	require.NoError(t, err)
+	require.Equal(t, original, content)
+
+	// All objects are stored under the prefix.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "shared", entries[0].Name())
+
+	contentHash, err := s.blobRef(ctx, buildID)
+	require.NoError(t, err)
+	for _, name := range []string{metadataObjectPath(buildID), blobRefPath(buildID), blobPath(contentHash)} {
+		_, err := os.Stat(path.Join(dir, "shared/parca", name))
+		require.NoError(t, err, name)
+	}
+
+	deleted, err := s.GarbageCollect(ctx, map[string]struct{}{}, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{buildID}, deleted)
+	for _, name := range []string{blobRefPath(buildID), blobPath(contentHash)} {
+		_, err := os.Stat(path.Join(dir, "shared/parca", name))
+		require.True(t, os.IsNotExist(err), name)
+	}
+}
+
+// rangeBucket records the ranged reads of objects, and fails reads of whole
+// blobs.
+type rangeBucket struct {
+	objstore.Bucket
+
+	mtx    sync.Mutex
+	ranges [][2]int64
+}
+
+func (b *rangeBucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	if path.Dir(name) == blobsDir {
+		return nil, errors.New("unexpected read of the whole object")
+	}
+	return b.Bucket.Get(ctx, name)
+}
+
+func (b *rangeBucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	b.mtx.Lock()
+	b.ranges = append(b.ranges, [2]int64{off, off + length})
+	b.mtx.Unlock()
+	return b.Bucket.GetRange(ctx, name, off, length)
+}
+
+func TestStoreFetchRanged(t *testing.T) {
+	ctx := context.Background()
+
+	// The test binary is large enough to span many ranged reads.
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	original, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	ef, err := elf.NewFile(bytes.NewReader(original))
+	require.NoError(t, err)
+	text := ef.Section(".text")
+	require.NotNil(t, text)
+
+	bucket := &rangeBucket{Bucket: objstore.NewInMemBucket()}
+	s, _ := newTestStoreClientWithBucket(t, bucket, WithAllowMissingBuildID(true))
+	s.rangeReadBlockSize = 64 << 10
+
+	const buildID = "abcd"
+	sum := sha256.Sum256(original)
+	contentHash := hex.EncodeToString(sum[:])
+	require.NoError(t, bucket.Upload(ctx, blobPath(contentHash), bytes.NewReader(original)))
+	require.NoError(t, bucket.Upload(ctx, blobRefPath(buildID), bytes.NewBufferString(contentHash)))
+
+	objFile, err := s.fetchFromObjectStore(ctx, buildID)
+	require.NoError(t, err)
+
+	// The code isn't needed for symbolization and is never read.
+	var read int64
+	for _, r := range bucket.ranges {
+		read += r[1] - r[0]
+		require.False(t, r[0] >= int64(text.Offset) && r[0] < int64(text.Offset+text.FileSize), "read range %v of .text", r)
+	}
+	require.Less(t, read, int64(len(original)))
+
+	f, err := elf.Open(objFile)
+	require.NoError(t, err)
+	defer f.Close()
+	for _, name := range []string{".gopclntab", ".note.go.buildid"} {
+		want, err := ef.Section(name).Data()
+		require.NoError(t, err)
+		got, err := f.Section(name).Data()
+		require.NoError(t, err)
+		require.Equal(t, want, got, name)
+	}
+}
+
+// uploadChunks uploads the data in chunks of the given size using the raw
+// upload stream, followed by the given trailer if any.
+func uploadChunks(ctx context.Context, c *Client, buildID string, data []byte, chunkSize int, trailer *debuginfopb.UploadTrailer) (*debuginfopb.UploadResponse, error) {
+	stream, err := c.c.Upload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Send(&debuginfopb.UploadRequest{
+		Data: &debuginfopb.UploadRequest_Info{
+			Info: &debuginfopb.UploadInfo{BuildId: buildID, Hash: "abcd"},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	for len(data) > 0 {
+		n := chunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		if err := stream.Send(&debuginfopb.UploadRequest{
+			Data: &debuginfopb.UploadRequest_ChunkData{ChunkData: data[:n]},
+		}); err != nil {
+			return nil, err
+		}
+		data = data[n:]
+	}
+
+	if trailer != nil {
+		if err := stream.Send(&debuginfopb.UploadRequest{
+			Data: &debuginfopb.UploadRequest_Trailer{Trailer: trailer},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+func TestStoreUploadVerifiesTrailer(t *testing.T) {
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+	sum := sha256.Sum256(original)
+	trailer := &debuginfopb.UploadTrailer{
+		Size:   uint64(len(original)),
+		Sha256: hex.EncodeToString(sum[:]),
+	}
+
+	t.Run("multiple chunks", func(t *testing.T) {
+		ctx := context.Background()
+		s, c := newTestStoreClient(t)
+
+		res, err := uploadChunks(ctx, c, buildID, original, 512, trailer)
+		require.NoError(t, err)
+		require.Equal(t, uint64(len(original)), res.Size)
+
+		obj, err := s.getObject(ctx, buildID)
+		require.NoError(t, err)
+		stored, err := io.ReadAll(obj)
+		require.NoError(t, err)
+		require.Equal(t, original, stored)
+	})
+
+	t.Run("without trailer", func(t *testing.T) {
+		_, c := newTestStoreClient(t)
+
+		_, err := uploadChunks(context.Background(), c, buildID, original, 512, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		ctx := context.Background()
+		s, c := newTestStoreClient(t)
+
+		_, err := uploadChunks(ctx, c, buildID, original[:len(original)-100], 512, trailer)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.ErrorContains(t, err, "upload size mismatch")
+
+		exists, err := s.bucket.Exists(ctx, blobRefPath(buildID))
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		st, ok := s.DebugInfoStatus(buildID)
+		require.True(t, ok)
+		require.Equal(t, StatusStateCorrupted, st.State)
+
+		// The client can upload the object again.
+		_, err = uploadChunks(ctx, c, buildID, original, 512, trailer)
+		require.NoError(t, err)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		ctx := context.Background()
+		s, c := newTestStoreClient(t)
+
+		corrupted := append([]byte{}, original...)
+		corrupted[len(corrupted)-1] ^= 0xff
+
+		_, err := uploadChunks(ctx, c, buildID, corrupted, 512, trailer)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.ErrorContains(t, err, "upload checksum mismatch")
+
+		exists, err := s.bucket.Exists(ctx, blobRefPath(buildID))
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+}
+
+func TestStoreUploadDeduplicatesContent(t *testing.T) {
+	ctx := context.Background()
+	s, c := newTestStoreClient(t, WithAllowMissingBuildID(true))
+
+	original, err := os.ReadFile("testdata/validelf_withsections")
+	require.NoError(t, err)
+
+	buildIDs := []string{hex.EncodeToString([]byte("section")), hex.EncodeToString([]byte("duplicate"))}
+	for _, buildID := range buildIDs {
+		_, err := c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+		require.NoError(t, err)
+	}
+
+	var blobs []string
+	require.NoError(t, s.bucket.Iter(ctx, blobsDir, func(name string) error {
+		blobs = append(blobs, name)
+		return nil
+	}))
+	sum := sha256.Sum256(original)
+	require.Equal(t, []string{blobPath(hex.EncodeToString(sum[:]))}, blobs)
+
+	for _, buildID := range buildIDs {
+		// Drop the locally cached file to read the object back from the bucket.
+		require.NoError(t, os.RemoveAll(path.Dir(s.localCachePath(buildID))))
+
+		objFile, err := s.fetchFromObjectStore(ctx, buildID)
+		require.NoError(t, err)
+		content, err := os.ReadFile(objFile)
+		require.NoError(t, err)
+		require.Equal(t, original, content)
+	}
+
+	// The content is kept as long as any build ID refers to it.
+	deleted, err := s.GarbageCollect(ctx, map[string]struct{}{buildIDs[1]: {}}, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{buildIDs[0]}, deleted)
+
+	require.NoError(t, os.RemoveAll(path.Dir(s.localCachePath(buildIDs[1]))))
+	_, err = s.fetchFromObjectStore(ctx, buildIDs[1])
+	require.NoError(t, err)
+}
+
+func TestStoreReupload(t *testing.T) {
+	ctx := context.Background()
+	s, c := newTestStoreClient(t, WithAllowMissingBuildID(true))
+
+	original, err := os.ReadFile("testdata/validelf_withsections")
+	require.NoError(t, err)
+	divergent, err := os.ReadFile("testdata/validelf_nosections")
+	require.NoError(t, err)
+
+	buildID := hex.EncodeToString([]byte("reupload"))
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+	require.NoError(t, err)
+
+	// Debug info is immutable, uploading it again is a no-op.
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(divergent))
+	require.ErrorIs(t, err, ErrDebugInfoAlreadyExists)
+
+	size, err := c.ForceUpload(ctx, buildID, "abcd", bytes.NewReader(original))
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(original)), size)
+
+	_, err = c.ForceUpload(ctx, buildID, "abcd", bytes.NewReader(divergent))
+	require.ErrorContains(t, err, "code = FailedPrecondition")
+	require.ErrorContains(t, err, "already exists with different content")
+
+	// The uploaded debug info is left as is.
+	require.NoError(t, os.RemoveAll(path.Dir(s.localCachePath(buildID))))
+	objFile, err := s.fetchFromObjectStore(ctx, buildID)
+	require.NoError(t, err)
+	content, err := os.ReadFile(objFile)
+	require.NoError(t, err)
+	require.Equal(t, original, content)
+
+	md, err := s.metadata.Fetch(ctx, buildID)
+	require.NoError(t, err)
+	require.Equal(t, MetadataStateUploaded, md.State)
+}
+
+type fakeSignedUploadClient struct{}
+
+func (fakeSignedUploadClient) SignedPUT(_ context.Context, objectKey string, _ time.Time) (string, error) {
+	return "https://signed.example.com/" + objectKey, nil
+}
+
+func (fakeSignedUploadClient) Close() error {
+	return nil
+}
+
+func TestStoreSignedUpload(t *testing.T) {
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+	sum := sha256.Sum256(original)
+	trailer := &debuginfopb.UploadTrailer{
+		Size:   uint64(len(original)),
+		Sha256: hex.EncodeToString(sum[:]),
+	}
+
+	t.Run("not enabled", func(t *testing.T) {
+		ctx := context.Background()
+		s, _ := newTestStoreClient(t)
+
+		res, err := s.InitiateUpload(ctx, &debuginfopb.InitiateUploadRequest{BuildId: buildID, Hash: "abcd"})
+		require.NoError(t, err)
+		require.Equal(t, debuginfopb.InitiateUploadResponse_UPLOAD_STRATEGY_GRPC, res.UploadStrategy)
+		require.Empty(t, res.SignedUrl)
+
+		_, err = s.CompleteUpload(ctx, &debuginfopb.CompleteUploadRequest{BuildId: buildID, Hash: "abcd", Trailer: trailer})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("uploaded", func(t *testing.T) {
+		ctx := context.Background()
+		var uploaded []string
+		s, _ := newTestStoreClient(t,
+			WithSignedUploadClient(fakeSignedUploadClient{}, time.Minute),
+			WithOnUploaded(func(buildID string) { uploaded = append(uploaded, buildID) }),
+		)
+
+		res, err := s.InitiateUpload(ctx, &debuginfopb.InitiateUploadRequest{BuildId: buildID, Hash: "abcd"})
+		require.NoError(t, err)
+		require.Equal(t, debuginfopb.InitiateUploadResponse_UPLOAD_STRATEGY_SIGNED_URL, res.UploadStrategy)
+		require.Equal(t, "https://signed.example.com/uploads/"+buildID, res.SignedUrl)
+
+		// The client uploads to the signed URL.
+		require.NoError(t, s.bucket.Upload(ctx, signedUploadPath(buildID), bytes.NewReader(original)))
+
+		completed, err := s.CompleteUpload(ctx, &debuginfopb.CompleteUploadRequest{BuildId: buildID, Hash: "abcd", Trailer: trailer})
+		require.NoError(t, err)
+		require.Equal(t, uint64(len(original)), completed.Size)
+		require.Equal(t, []string{buildID}, uploaded)
+
+		obj, err := s.getObject(ctx, buildID)
+		require.NoError(t, err)
+		stored, err := io.ReadAll(obj)
+		require.NoError(t, err)
+		require.Equal(t, original, stored)
+
+		exists, err := s.bucket.Exists(ctx, signedUploadPath(buildID))
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("nothing uploaded", func(t *testing.T) {
+		s, _ := newTestStoreClient(t, WithSignedUploadClient(fakeSignedUploadClient{}, time.Minute))
+
+		_, err := s.CompleteUpload(context.Background(), &debuginfopb.CompleteUploadRequest{BuildId: buildID, Hash: "abcd", Trailer: trailer})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		ctx := context.Background()
+		s, _ := newTestStoreClient(t, WithSignedUploadClient(fakeSignedUploadClient{}, time.Minute))
+
+		corrupted := append([]byte{}, original...)
+		corrupted[len(corrupted)-1] ^= 0xff
+		require.NoError(t, s.bucket.Upload(ctx, signedUploadPath(buildID), bytes.NewReader(corrupted)))
+
+		_, err := s.CompleteUpload(ctx, &debuginfopb.CompleteUploadRequest{BuildId: buildID, Hash: "abcd", Trailer: trailer})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.ErrorContains(t, err, "upload checksum mismatch")
+
+		exists, err := s.bucket.Exists(ctx, blobRefPath(buildID))
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("build ID mismatch", func(t *testing.T) {
+		const otherBuildID = "0000000000000000000000000000000000000000"
+		ctx := context.Background()
+		s, _ := newTestStoreClient(t, WithSignedUploadClient(fakeSignedUploadClient{}, time.Minute))
+
+		require.NoError(t, s.bucket.Upload(ctx, signedUploadPath(otherBuildID), bytes.NewReader(original)))
+
+		_, err := s.CompleteUpload(ctx, &debuginfopb.CompleteUploadRequest{BuildId: otherBuildID, Hash: "abcd", Trailer: trailer})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.ErrorContains(t, err, "build ID mismatch")
+
+		exists, err := s.bucket.Exists(ctx, blobRefPath(otherBuildID))
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+}
+
+func TestStoreUploadLimits(t *testing.T) {
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+
+	t.Run("size", func(t *testing.T) {
+		ctx := context.Background()
+		s, c := newTestStoreClient(t, WithUploadLimits(UploadLimitsConfig{MaxSize: int64(len(original)) - 1}))
+
+		_, err := c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+		require.Equal(t, codes.ResourceExhausted, status.Code(errors.Unwrap(err)), err)
+		exists, err := s.bucket.Exists(ctx, blobRefPath(buildID))
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		// A file of exactly the maximum size is accepted.
+		s.limits = newUploadLimiter(UploadLimitsConfig{MaxSize: int64(len(original))})
+		_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+		require.NoError(t, err)
+	})
+
+	t.Run("signed upload size", func(t *testing.T) {
+		ctx := context.Background()
+		s, _ := newTestStoreClient(t,
+			WithSignedUploadClient(fakeSignedUploadClient{}, time.Minute),
+			WithUploadLimits(UploadLimitsConfig{MaxSize: int64(len(original)) - 1}),
+		)
+
+		_, err := s.InitiateUpload(ctx, &debuginfopb.InitiateUploadRequest{BuildId: buildID, Hash: "abcd"})
+		require.NoError(t, err)
+		require.NoError(t, s.bucket.Upload(ctx, signedUploadPath(buildID), bytes.NewReader(original)))
+
+		sum := sha256.Sum256(original)
+		_, err = s.CompleteUpload(ctx, &debuginfopb.CompleteUploadRequest{
+			BuildId: buildID,
+			Hash:    "abcd",
+			Trailer: &debuginfopb.UploadTrailer{Size: uint64(len(original)), Sha256: hex.EncodeToString(sum[:])},
+		})
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		// The staged upload is discarded without being read.
+		exists, err := s.bucket.Exists(ctx, signedUploadPath(buildID))
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		ctx := context.Background()
+		s, c := newTestStoreClient(t, WithUploadLimits(UploadLimitsConfig{Interval: time.Hour}))
+		now := time.Now()
+		s.limits.now = func() time.Time { return now }
+
+		// The first upload fails, which counts towards the limit as well.
+		corrupted := append([]byte{}, original...)
+		corrupted[0] ^= 0xff
+		_, err := c.Upload(ctx, buildID, "abcd", bytes.NewReader(corrupted))
+		require.Equal(t, codes.InvalidArgument, status.Code(errors.Unwrap(err)), err)
+
+		// Nothing is stored for the build ID, so the client is only told that
+		// debug info exists because it was uploaded recently.
+		_, err = c.ForceUpload(ctx, buildID, "abcd", bytes.NewReader(original))
+		require.ErrorIs(t, err, ErrDebugInfoAlreadyExists)
+		exists, err := s.bucket.Exists(ctx, blobRefPath(buildID))
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		// Other build IDs aren't limited.
+		_, err = c.Upload(ctx, "0123", "abcd", bytes.NewReader(corrupted))
+		require.Equal(t, codes.InvalidArgument, status.Code(errors.Unwrap(err)), err)
+
+		now = now.Add(time.Hour)
+		_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+		require.NoError(t, err)
+	})
+}
+
+func TestStoreDownloadTempDir(t *testing.T) {
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+
+	bucket := objstore.NewInMemBucket()
+	require.NoError(t, bucket.Upload(context.Background(), objectPath(buildID), bytes.NewReader(original)))
+
+	dir := path.Join(t.TempDir(), "downloads")
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	leftover := func(name string) string {
+		f := path.Join(dir, name)
+		require.NoError(t, os.WriteFile(f, []byte("leftover"), 0o600))
+		return f
+	}
+	exists := func(f string) bool {
+		_, err := os.Stat(f)
+		return err == nil
+	}
+
+	// The temp files left behind by a crash are removed on startup, other
+	// files are left alone.
+	crashed := leftover(downloadTempPrefix + "1")
+	other := leftover("other")
+	s, _ := newTestStoreClientWithBucket(t, bucket, WithDownloadTempDir(DownloadTempDirConfig{Directory: dir}))
+	require.False(t, exists(crashed))
+	require.True(t, exists(other))
+
+	// The temp files are cleaned up after every fetch, except the ones of
+	// downloads in progress.
+	active, err := s.downloads.create()
+	require.NoError(t, err)
+	crashed = leftover(downloadTempPrefix + "2")
+	_, err = s.fetchFromObjectStore(context.Background(), buildID)
+	require.NoError(t, err)
+	require.False(t, exists(crashed))
+	require.True(t, exists(active.Name()))
+	require.NoError(t, s.downloads.cleanup())
+	require.True(t, exists(active.Name()))
+
+	// No download is started while the ones in progress exceed the size.
+	s.downloads = newTempDir(s.logger, dir, 4)
+	active, err = s.downloads.create()
+	require.NoError(t, err)
+	_, err = active.Write([]byte("full"))
+	require.NoError(t, err)
+	require.NoError(t, active.Close())
+	_, err = s.downloads.create()
+	require.ErrorIs(t, err, ErrTempDirFull)
+
+	s.downloads.release(active.Name())
+	require.False(t, exists(active.Name()))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// downloadTempPrefix is the prefix of the names of the temp files debug info
+// is downloaded to, before it is moved into the cache directory.
+const downloadTempPrefix = "symbol-download-"
+
+// ErrTempDirFull is returned when debug info can't be downloaded, because
+// the temp files of the downloads in progress exceed their maximum size.
+var ErrTempDirFull = errors.New("download temp directory full")
+
+// DownloadTempDirConfig configures the directory debug info is downloaded to
+// before it is moved into the cache directory. It has to be on the same
+// filesystem as the cache directory.
+type DownloadTempDirConfig struct {
+	// Directory defaults to the cache directory.
+	Directory string `yaml:"directory"`
+	// MaxSize is the maximum total size in bytes of the temp files, no
+	// download is started while they exceed it. 0 means unlimited.
+	MaxSize int64 `yaml:"max_size"`
+}
+
+// tempDir keeps track of the temp files of the downloads in progress, so
+// that the ones left behind, e.g. by a crash, can be removed without
+// affecting them.
+type tempDir struct {
+	logger  log.Logger
+	dir     string
+	maxSize int64
+
+	mtx sync.Mutex
+	// active are the names of the temp files of the downloads in progress.
+	active map[string]struct{}
+}
+
+func newTempDir(logger log.Logger, dir string, maxSize int64) *tempDir {
+	return &tempDir{
+		logger:  logger,
+		dir:     dir,
+		maxSize: maxSize,
+		active:  map[string]struct{}{},
+	}
+}
+
+// create creates a temp file that is not cleaned up until it is released.
+func (d *tempDir) create() (*os.File, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if d.maxSize > 0 && d.sizeLocked() >= d.maxSize {
+		return nil, ErrTempDirFull
+	}
+
+	f, err := os.CreateTemp(d.dir, downloadTempPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	d.active[filepath.Base(f.Name())] = struct{}{}
+	return f, nil
+}
+
+// release removes the temp file, unless it was moved, along with the temp
+// files of downloads that didn't finish.
+func (d *tempDir) release(name string) {
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		level.Warn(d.logger).Log("msg", "failed to remove temp file", "file", name, "err", err)
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	delete(d.active, filepath.Base(name))
+	if err := d.cleanupLocked(); err != nil {
+		level.Warn(d.logger).Log("msg", "failed to clean up temp files", "err", err)
+	}
+}
+
+// cleanup removes the temp files that don't belong to a download in
+// progress.
+func (d *tempDir) cleanup() error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	return d.cleanupLocked()
+}
+
+func (d *tempDir) cleanupLocked() error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read temp directory: %w", err)
+	}
+
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasPrefix(e.Name(), downloadTempPrefix) {
+			continue
+		}
+		if _, ok := d.active[e.Name()]; ok {
+			continue
+		}
+		name := filepath.Join(d.dir, e.Name())
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove stale temp file: %w", err)
+		}
+		level.Debug(d.logger).Log("msg", "removed stale temp file", "file", name)
+	}
+	return nil
+}
+
+// sizeLocked returns the total size of the temp files of the downloads in
+// progress.
+func (d *tempDir) sizeLocked() int64 {
+	var size int64
+	for name := range d.active {
+		if fi, err := os.Stat(filepath.Join(d.dir, name)); err == nil {
+			size += fi.Size()
+		}
+	}
+	return size
+}
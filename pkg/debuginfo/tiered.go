@@ -0,0 +1,148 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/thanos-io/objstore"
+)
+
+// tieredBucket reads objects missing from its primary bucket from the
+// read-only archive buckets, in order. Objects are only written to and
+// deleted from the primary bucket.
+type tieredBucket struct {
+	objstore.Bucket
+
+	archives []objstore.BucketReader
+}
+
+// NewTieredBucket returns a bucket that falls back to the archive buckets in
+// order for objects that are not in the primary bucket, e.g. to keep older
+// debug info in cheaper storage without migrating it. Not found errors are the
+// ones of the primary bucket.
+func NewTieredBucket(primary objstore.Bucket, archives ...objstore.BucketReader) objstore.Bucket {
+	if len(archives) == 0 {
+		return primary
+	}
+	return &tieredBucket{
+		Bucket:   primary,
+		archives: archives,
+	}
+}
+
+func (b *tieredBucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	var r io.ReadCloser
+	err := b.read(func(bkt objstore.BucketReader) error {
+		var err error
+		r, err = bkt.Get(ctx, name)
+		return err
+	})
+	return r, err
+}
+
+func (b *tieredBucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	var r io.ReadCloser
+	err := b.read(func(bkt objstore.BucketReader) error {
+		var err error
+		r, err = bkt.GetRange(ctx, name, off, length)
+		return err
+	})
+	return r, err
+}
+
+func (b *tieredBucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	var attrs objstore.ObjectAttributes
+	err := b.read(func(bkt objstore.BucketReader) error {
+		var err error
+		attrs, err = bkt.Attributes(ctx, name)
+		return err
+	})
+	return attrs, err
+}
+
+func (b *tieredBucket) Exists(ctx context.Context, name string) (bool, error) {
+	exists, err := b.Bucket.Exists(ctx, name)
+	if err != nil || exists {
+		return exists, err
+	}
+	for _, archive := range b.archives {
+		exists, err := archive.Exists(ctx, name)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+// Iter calls f for the entries of the directory in any of the buckets, in
+// sorted order.
+func (b *tieredBucket) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	entries := map[string]struct{}{}
+	collect := func(name string) error {
+		entries[name] = struct{}{}
+		return nil
+	}
+	if err := b.Bucket.Iter(ctx, dir, collect, options...); err != nil {
+		return err
+	}
+	for _, archive := range b.archives {
+		if err := archive.Iter(ctx, dir, collect, options...); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := f(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *tieredBucket) Close() error {
+	err := b.Bucket.Close()
+	for _, archive := range b.archives {
+		if c, ok := archive.(io.Closer); ok {
+			if cerr := c.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+	}
+	return err
+}
+
+// read calls f with the primary bucket and then the archive buckets until it
+// doesn't return a not found error. If none has the object, the not found
+// error of the primary bucket is returned.
+func (b *tieredBucket) read(f func(objstore.BucketReader) error) error {
+	notFound := f(b.Bucket)
+	if notFound == nil || !b.Bucket.IsObjNotFoundErr(notFound) {
+		return notFound
+	}
+	for _, archive := range b.archives {
+		err := f(archive)
+		if err == nil || !archive.IsObjNotFoundErr(err) {
+			return err
+		}
+	}
+	return notFound
+}
@@ -0,0 +1,68 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debuginfo
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+)
+
+func TestStoreFetchFromArchiveBucket(t *testing.T) {
+	ctx := context.Background()
+
+	const buildID = "af2cabd35504fd7b26613123a1f5334b39e7d7ed"
+	original, err := os.ReadFile("testdata/validelf_withbuildid")
+	require.NoError(t, err)
+
+	archive := objstore.NewInMemBucket()
+	_, c := newTestStoreClientWithBucket(t, archive)
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(original))
+	require.NoError(t, err)
+
+	primary := objstore.NewInMemBucket()
+	s, c := newTestStoreClientWithBucket(t, NewTieredBucket(primary, archive))
+
+	exists, err := c.Exists(ctx, buildID, "abcd")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	objFile, source, err := s.FetchDebugInfo(ctx, buildID)
+	require.NoError(t, err)
+	require.Equal(t, debuginfopb.DownloadInfo_SOURCE_UPLOAD, source)
+	fetched, err := os.ReadFile(objFile)
+	require.NoError(t, err)
+	require.Equal(t, original, fetched)
+	require.Empty(t, primary.Objects())
+
+	// Debug info in an archive bucket counts as uploaded, and new uploads
+	// only go to the primary bucket.
+	_, err = c.Upload(ctx, buildID, "ef01", bytes.NewReader(original))
+	require.ErrorContains(t, err, "debug info already exists")
+
+	archived := len(archive.Objects())
+	f, err := os.Open("../symbolizer/testdata/e94c2ed1e1276255de44b79f0e74234cf7c70bb3/debuginfo")
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = c.Upload(ctx, "e94c2ed1e1276255de44b79f0e74234cf7c70bb3", "abcd", f)
+	require.NoError(t, err)
+	require.NotEmpty(t, primary.Objects())
+	require.Len(t, archive.Objects(), archived)
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jfr
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/parca-dev/parca/pkg/parcacol"
+)
+
+// testdata/cpu.jfr is a recording of two chunks with a 10ms sampling period.
+// The first chunk holds five execution samples of two stacks:
+//
+//	com.example.Worker.compute:42         com.example.Worker.hash:57
+//	com.example.Worker.run:20             com.example.Worker.compute:45
+//	java.lang.Thread.run:833              com.example.Worker.run:20
+//	                                      java.lang.Thread.run:833
+//
+// The second chunk holds one more sample of the first stack.
+func TestToPprof(t *testing.T) {
+	b, err := os.ReadFile("testdata/cpu.jfr")
+	require.NoError(t, err)
+	require.True(t, IsJFR(b))
+
+	p, err := ToPprof(b)
+	require.NoError(t, err)
+	require.NoError(t, parcacol.ValidatePprofProfile(p))
+
+	str := func(i int64) string { return p.StringTable[i] }
+	require.Equal(t, "samples", str(p.SampleType[0].Type))
+	require.Equal(t, "cpu", str(p.SampleType[1].Type))
+	require.Equal(t, "nanoseconds", str(p.SampleType[1].Unit))
+	require.Equal(t, int64(10_000_000), p.Period)
+	require.Equal(t, int64(1_660_000_000_000_000_000), p.TimeNanos)
+	require.Equal(t, int64(2_000_000_000), p.DurationNanos)
+
+	type frame struct {
+		function string
+		line     int64
+	}
+	stacks := map[int64][]frame{}
+	for _, s := range p.Sample {
+		var stack []frame
+		for _, id := range s.LocationId {
+			l := p.Location[id-1].Line[0]
+			stack = append(stack, frame{function: str(p.Function[l.FunctionId-1].Name), line: l.Line})
+		}
+		require.Equal(t, s.Value[0]*10_000_000, s.Value[1])
+		stacks[s.Value[0]] = stack
+	}
+
+	require.Equal(t, map[int64][]frame{
+		4: {
+			{"com.example.Worker.compute", 42},
+			{"com.example.Worker.run", 20},
+			{"java.lang.Thread.run", 833},
+		},
+		2: {
+			{"com.example.Worker.hash", 57},
+			{"com.example.Worker.compute", 45},
+			{"com.example.Worker.run", 20},
+			{"java.lang.Thread.run", 833},
+		},
+	}, stacks)
+	require.Len(t, p.Function, 4)
+	require.Len(t, p.Location, 5)
+}
+
+func TestToPprofInvalid(t *testing.T) {
+	b, err := os.ReadFile("testdata/cpu.jfr")
+	require.NoError(t, err)
+
+	// Truncating the recording anywhere must not panic.
+	for _, n := range []int{4, headerSize, 100, 1000, len(b) / 2, len(b) - 1} {
+		_, err := ToPprof(b[:n])
+		require.Error(t, err, "truncated to %d bytes", n)
+	}
+
+	_, err = ToPprof([]byte("FLR\x00not a recording"))
+	require.Error(t, err)
+}
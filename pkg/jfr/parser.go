@@ -0,0 +1,566 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jfr converts Java Flight Recorder recordings into pprof profiles.
+package jfr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf16"
+)
+
+const (
+	headerSize = 68
+
+	// Event types with a reserved ID.
+	metadataEventType     = 0
+	constantPoolEventType = 1
+
+	// Guards against cycles in malformed recordings.
+	maxNestingDepth = 64
+)
+
+var magic = []byte{'F', 'L', 'R', 0}
+
+// IsJFR returns whether the data starts like a JFR recording.
+func IsJFR(b []byte) bool {
+	return bytes.HasPrefix(b, magic)
+}
+
+// A JFR recording is a sequence of self-contained chunks. Every chunk starts
+// with a header, followed by events. The layout of the events is described
+// by the metadata event of the chunk, the values they reference by the
+// constant pool events of the chunk.
+type chunkHeader struct {
+	major              uint16
+	minor              uint16
+	size               int64
+	constantPoolOffset int64
+	metadataOffset     int64
+	startNanos         int64
+	durationNanos      int64
+	startTicks         int64
+	ticksPerSecond     int64
+	features           uint32
+}
+
+// compressedInts returns whether the integers of the chunk are encoded as
+// variable length integers.
+func (h chunkHeader) compressedInts() bool {
+	return h.features&1 != 0
+}
+
+type field struct {
+	name         string
+	typeID       int64
+	constantPool bool
+	array        bool
+}
+
+type class struct {
+	id     int64
+	name   string
+	fields []field
+}
+
+func (c *class) fieldIndex(name string) int {
+	for i, f := range c.fields {
+		if f.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// object is a decoded value of a class, its fields are in the order of the
+// fields of the class.
+type object struct {
+	class  *class
+	fields []interface{}
+}
+
+func (o *object) get(name string) interface{} {
+	if o == nil {
+		return nil
+	}
+	i := o.class.fieldIndex(name)
+	if i < 0 {
+		return nil
+	}
+	return o.fields[i]
+}
+
+// constantRef references a value in the constant pool of a type.
+type constantRef struct {
+	typeID int64
+	index  int64
+}
+
+type chunk struct {
+	header chunkHeader
+	data   []byte
+
+	classes      map[int64]*class
+	classByName  map[string]*class
+	stringTypeID int64
+	pools        map[int64]map[int64]interface{}
+}
+
+// parseChunk parses the chunk the data starts with, its metadata and its
+// constant pools.
+func parseChunk(b []byte) (*chunk, error) {
+	if len(b) < headerSize || !IsJFR(b) {
+		return nil, errors.New("invalid chunk header")
+	}
+
+	be := binary.BigEndian
+	h := chunkHeader{
+		major:              be.Uint16(b[4:]),
+		minor:              be.Uint16(b[6:]),
+		size:               int64(be.Uint64(b[8:])),
+		constantPoolOffset: int64(be.Uint64(b[16:])),
+		metadataOffset:     int64(be.Uint64(b[24:])),
+		startNanos:         int64(be.Uint64(b[32:])),
+		durationNanos:      int64(be.Uint64(b[40:])),
+		startTicks:         int64(be.Uint64(b[48:])),
+		ticksPerSecond:     int64(be.Uint64(b[56:])),
+		features:           be.Uint32(b[64:]),
+	}
+	if h.major != 2 {
+		return nil, fmt.Errorf("unsupported JFR version %d.%d", h.major, h.minor)
+	}
+	if h.size < headerSize || h.size > int64(len(b)) {
+		return nil, fmt.Errorf("invalid chunk size %d", h.size)
+	}
+
+	c := &chunk{
+		header:      h,
+		data:        b[:h.size],
+		classes:     map[int64]*class{},
+		classByName: map[string]*class{},
+		pools:       map[int64]map[int64]interface{}{},
+	}
+	if err := c.readMetadata(); err != nil {
+		return nil, fmt.Errorf("read metadata: %w", err)
+	}
+	if err := c.readConstantPools(); err != nil {
+		return nil, fmt.Errorf("read constant pools: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *chunk) reader(offset int64) *reader {
+	r := &reader{b: c.data, compressed: c.header.compressedInts()}
+	if offset < headerSize || offset >= int64(len(c.data)) {
+		r.fail(fmt.Errorf("invalid offset %d", offset))
+		return r
+	}
+	r.off = int(offset)
+	return r
+}
+
+// element is a node of the metadata tree.
+type element struct {
+	name     string
+	attrs    map[string]string
+	children []*element
+}
+
+func (c *chunk) readMetadata() error {
+	r := c.reader(c.header.metadataOffset)
+	r.int() // Size.
+	if typ := r.long(); r.err == nil && typ != metadataEventType {
+		return fmt.Errorf("unexpected event type %d", typ)
+	}
+	r.long() // Start time.
+	r.long() // Duration.
+	r.long() // Metadata ID.
+
+	strs := make([]string, r.count())
+	for i := range strs {
+		s, ok := r.string(0).(string)
+		if !ok {
+			r.fail(errors.New("invalid metadata string"))
+		}
+		strs[i] = s
+	}
+	root := readElement(r, strs, 0)
+	if r.err != nil {
+		return r.err
+	}
+
+	for _, e := range root.children {
+		if e.name != "metadata" {
+			continue
+		}
+		for _, e := range e.children {
+			if e.name != "class" {
+				continue
+			}
+			cls, err := newClass(e)
+			if err != nil {
+				return err
+			}
+			c.classes[cls.id] = cls
+			c.classByName[cls.name] = cls
+		}
+	}
+
+	str, ok := c.classByName["java.lang.String"]
+	if !ok {
+		return errors.New("missing java.lang.String type")
+	}
+	c.stringTypeID = str.id
+
+	return nil
+}
+
+func readElement(r *reader, strs []string, depth int) *element {
+	if depth > maxNestingDepth {
+		r.fail(errors.New("metadata nested too deeply"))
+	}
+	str := func() string {
+		i := r.int()
+		if i < 0 || int(i) >= len(strs) {
+			r.fail(fmt.Errorf("invalid metadata string index %d", i))
+			return ""
+		}
+		return strs[i]
+	}
+
+	e := &element{name: str(), attrs: map[string]string{}}
+	for i, n := 0, r.count(); i < n; i++ {
+		k := str()
+		e.attrs[k] = str()
+	}
+	for i, n := 0, r.count(); i < n && r.err == nil; i++ {
+		e.children = append(e.children, readElement(r, strs, depth+1))
+	}
+	return e
+}
+
+func newClass(e *element) (*class, error) {
+	id, err := strconv.ParseInt(e.attrs["id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid class id: %w", err)
+	}
+
+	cls := &class{id: id, name: e.attrs["name"]}
+	for _, e := range e.children {
+		if e.name != "field" {
+			continue
+		}
+		typeID, err := strconv.ParseInt(e.attrs["class"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type of field %s.%s: %w", cls.name, e.attrs["name"], err)
+		}
+		cls.fields = append(cls.fields, field{
+			name:         e.attrs["name"],
+			typeID:       typeID,
+			constantPool: e.attrs["constantPool"] == "true",
+			array:        e.attrs["dimension"] == "1",
+		})
+	}
+	return cls, nil
+}
+
+// readConstantPools reads the constant pool events of the chunk. The header
+// points to the last one, every event points to the previous one.
+func (c *chunk) readConstantPools() error {
+	for offset := c.header.constantPoolOffset; ; {
+		r := c.reader(offset)
+		r.int() // Size.
+		if typ := r.long(); r.err == nil && typ != constantPoolEventType {
+			return fmt.Errorf("unexpected event type %d", typ)
+		}
+		r.long() // Start time.
+		r.long() // Duration.
+		delta := r.long()
+		r.byte() // Checkpoint type.
+
+		for i, n := 0, r.count(); i < n && r.err == nil; i++ {
+			typeID := r.long()
+			pool, ok := c.pools[typeID]
+			if !ok {
+				pool = map[int64]interface{}{}
+				c.pools[typeID] = pool
+			}
+			for j, m := 0, r.count(); j < m && r.err == nil; j++ {
+				key := r.long()
+				pool[key] = c.readValue(r, typeID, 0)
+			}
+		}
+		if r.err != nil {
+			return r.err
+		}
+
+		// The previous event is always located before.
+		if delta >= 0 {
+			return nil
+		}
+		offset += delta
+	}
+}
+
+// events calls fn for every event of the chunk, except for the metadata and
+// the constant pools.
+func (c *chunk) events(fn func(*object) error) error {
+	for offset := int64(headerSize); offset < c.header.size; {
+		r := c.reader(offset)
+		size := int64(r.int())
+		if r.err != nil {
+			return r.err
+		}
+		if size <= 0 || offset+size > c.header.size {
+			return fmt.Errorf("invalid event size %d at offset %d", size, offset)
+		}
+		r.b = c.data[:offset+size]
+
+		typeID := r.long()
+		if typeID != metadataEventType && typeID != constantPoolEventType {
+			v := c.readValue(r, typeID, 0)
+			if r.err != nil {
+				return fmt.Errorf("read event at offset %d: %w", offset, r.err)
+			}
+			if o, ok := v.(*object); ok {
+				if err := fn(o); err != nil {
+					return err
+				}
+			}
+		}
+
+		offset += size
+	}
+	return nil
+}
+
+// readValue decodes a value of the given type. Primitive values are returned
+// as int64, float64, bool or string, other values as objects.
+func (c *chunk) readValue(r *reader, typeID int64, depth int) interface{} {
+	if r.err != nil {
+		return nil
+	}
+	cls, ok := c.classes[typeID]
+	if !ok {
+		r.fail(fmt.Errorf("unknown type %d", typeID))
+		return nil
+	}
+	if depth > maxNestingDepth {
+		r.fail(fmt.Errorf("values of type %s nested too deeply", cls.name))
+		return nil
+	}
+
+	switch cls.name {
+	case "boolean":
+		return r.byte() != 0
+	case "byte":
+		return int64(int8(r.byte()))
+	case "char":
+		return int64(r.char())
+	case "short":
+		return int64(r.short())
+	case "int":
+		return int64(r.int())
+	case "long":
+		return r.long()
+	case "float":
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(r.fixed(4))))
+	case "double":
+		return math.Float64frombits(binary.BigEndian.Uint64(r.fixed(8)))
+	case "java.lang.String":
+		return r.string(c.stringTypeID)
+	}
+
+	o := &object{class: cls, fields: make([]interface{}, len(cls.fields))}
+	for i, f := range cls.fields {
+		if !f.array {
+			o.fields[i] = c.readField(r, f, depth)
+			continue
+		}
+		vs := make([]interface{}, r.count())
+		for j := range vs {
+			vs[j] = c.readField(r, f, depth)
+		}
+		o.fields[i] = vs
+	}
+	return o
+}
+
+func (c *chunk) readField(r *reader, f field, depth int) interface{} {
+	if f.constantPool {
+		return constantRef{typeID: f.typeID, index: r.long()}
+	}
+	return c.readValue(r, f.typeID, depth+1)
+}
+
+// resolve returns the value referenced by constant pool references.
+func (c *chunk) resolve(v interface{}) interface{} {
+	for i := 0; i < maxNestingDepth; i++ {
+		ref, ok := v.(constantRef)
+		if !ok {
+			return v
+		}
+		v = c.pools[ref.typeID][ref.index]
+	}
+	return nil
+}
+
+func (c *chunk) object(v interface{}) *object {
+	o, _ := c.resolve(v).(*object)
+	return o
+}
+
+// string returns the string value of strings and symbols.
+func (c *chunk) string(v interface{}) string {
+	switch v := c.resolve(v).(type) {
+	case string:
+		return v
+	case *object:
+		if v.class.name == "jdk.types.Symbol" {
+			return c.string(v.get("string"))
+		}
+	}
+	return ""
+}
+
+func (c *chunk) int(v interface{}) int64 {
+	i, _ := c.resolve(v).(int64)
+	return i
+}
+
+// reader decodes the values of a chunk. Errors are sticky, once reading
+// failed all further reads return zero values.
+type reader struct {
+	b          []byte
+	off        int
+	compressed bool
+	err        error
+}
+
+func (r *reader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.off >= len(r.b) {
+		r.fail(io.ErrUnexpectedEOF)
+		return 0
+	}
+	b := r.b[r.off]
+	r.off++
+	return b
+}
+
+func (r *reader) fixed(n int) []byte {
+	if r.err == nil && (n < 0 || len(r.b)-r.off < n) {
+		r.fail(io.ErrUnexpectedEOF)
+	}
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	b := r.b[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+// varint reads a compressed integer: 7 bits per byte, least significant
+// first, except for the 9th byte which holds the 8 most significant bits.
+func (r *reader) varint() uint64 {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		b := r.byte()
+		v |= uint64(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+	return v | uint64(r.byte())<<56
+}
+
+func (r *reader) long() int64 {
+	if r.compressed {
+		return int64(r.varint())
+	}
+	return int64(binary.BigEndian.Uint64(r.fixed(8)))
+}
+
+func (r *reader) int() int32 {
+	if r.compressed {
+		return int32(r.varint())
+	}
+	return int32(binary.BigEndian.Uint32(r.fixed(4)))
+}
+
+func (r *reader) short() int16 {
+	if r.compressed {
+		return int16(r.varint())
+	}
+	return int16(binary.BigEndian.Uint16(r.fixed(2)))
+}
+
+func (r *reader) char() uint16 {
+	return uint16(r.short())
+}
+
+// count reads the length of an array, every element is at least a byte long.
+func (r *reader) count() int {
+	n := r.int()
+	if r.err == nil && (n < 0 || int(n) > len(r.b)-r.off) {
+		r.fail(fmt.Errorf("invalid length %d", n))
+	}
+	if r.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+// string reads a string, which is either returned as is or as a reference
+// to the constant pool of the given string type.
+func (r *reader) string(stringTypeID int64) interface{} {
+	switch enc := r.byte(); enc {
+	case 0, 1: // Null and empty string.
+		return ""
+	case 2:
+		return constantRef{typeID: stringTypeID, index: r.long()}
+	case 3: // UTF-8.
+		return string(r.fixed(r.count()))
+	case 4: // UTF-16 code units.
+		s := make([]uint16, r.count())
+		for i := range s {
+			s[i] = r.char()
+		}
+		return string(utf16.Decode(s))
+	case 5: // Latin-1.
+		b := r.fixed(r.count())
+		s := make([]rune, len(b))
+		for i, c := range b {
+			s[i] = rune(c)
+		}
+		return string(s)
+	default:
+		r.fail(fmt.Errorf("unknown string encoding %d", enc))
+		return ""
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jfr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
+)
+
+// The sampling period of the default JFR settings, used when the recording
+// doesn't contain the setting.
+const defaultPeriod = 20 * time.Millisecond
+
+// ToPprof converts the execution samples of a JFR recording into a CPU
+// profile. Every sample accounts for the CPU time of the sampling period.
+func ToPprof(b []byte) (*pprofpb.Profile, error) {
+	bld := newBuilder()
+	for len(b) > 0 {
+		c, err := parseChunk(b)
+		if err != nil {
+			return nil, err
+		}
+		if err := bld.addChunk(c); err != nil {
+			return nil, err
+		}
+		b = b[c.header.size:]
+	}
+	return bld.p, nil
+}
+
+type locationKey struct {
+	functionID uint64
+	line       int64
+}
+
+type builder struct {
+	p *pprofpb.Profile
+
+	strings   map[string]int64
+	functions map[string]uint64
+	locations map[locationKey]uint64
+	samples   map[string]*pprofpb.Sample
+
+	end int64
+}
+
+func newBuilder() *builder {
+	b := &builder{
+		p:         &pprofpb.Profile{StringTable: []string{""}},
+		strings:   map[string]int64{"": 0},
+		functions: map[string]uint64{},
+		locations: map[locationKey]uint64{},
+		samples:   map[string]*pprofpb.Sample{},
+	}
+	b.p.SampleType = []*pprofpb.ValueType{
+		{Type: b.string("samples"), Unit: b.string("count")},
+		{Type: b.string("cpu"), Unit: b.string("nanoseconds")},
+	}
+	b.p.PeriodType = &pprofpb.ValueType{Type: b.string("cpu"), Unit: b.string("nanoseconds")}
+	return b
+}
+
+func (b *builder) addChunk(c *chunk) error {
+	start, end := c.header.startNanos, c.header.startNanos+c.header.durationNanos
+	if b.p.TimeNanos == 0 || start < b.p.TimeNanos {
+		b.p.TimeNanos = start
+	}
+	if end > b.end {
+		b.end = end
+	}
+	b.p.DurationNanos = b.end - b.p.TimeNanos
+
+	executionSample, ok := c.classByName["jdk.ExecutionSample"]
+	if !ok {
+		return nil
+	}
+
+	period := defaultPeriod
+	counts := map[constantRef]int64{}
+	var stackTraces []constantRef
+	err := c.events(func(e *object) error {
+		switch e.class.name {
+		case "jdk.ExecutionSample":
+			ref, ok := e.get("stackTrace").(constantRef)
+			if !ok {
+				return nil
+			}
+			if _, ok := counts[ref]; !ok {
+				stackTraces = append(stackTraces, ref)
+			}
+			counts[ref]++
+		case "jdk.ActiveSetting":
+			if c.int(e.get("id")) != executionSample.id || c.string(e.get("name")) != "period" {
+				return nil
+			}
+			// Values are formatted like "10 ms".
+			d, err := time.ParseDuration(strings.ReplaceAll(c.string(e.get("value")), " ", ""))
+			if err == nil && d > 0 {
+				period = d
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	b.p.Period = period.Nanoseconds()
+
+	for _, ref := range stackTraces {
+		stackTrace := c.object(ref)
+		if stackTrace == nil {
+			return fmt.Errorf("missing stack trace %d", ref.index)
+		}
+		frames, _ := stackTrace.get("frames").([]interface{})
+
+		locationIDs := make([]uint64, 0, len(frames))
+		for _, frame := range frames {
+			frame := c.object(frame)
+			if frame == nil {
+				return fmt.Errorf("invalid frame of stack trace %d", ref.index)
+			}
+			locationIDs = append(locationIDs, b.location(
+				b.function(c, c.object(frame.get("method"))),
+				c.int(frame.get("lineNumber")),
+			))
+		}
+
+		n := counts[ref]
+		key := fmt.Sprint(locationIDs)
+		if s, ok := b.samples[key]; ok {
+			s.Value[0] += n
+			s.Value[1] += n * period.Nanoseconds()
+			continue
+		}
+		s := &pprofpb.Sample{
+			LocationId: locationIDs,
+			Value:      []int64{n, n * period.Nanoseconds()},
+		}
+		b.samples[key] = s
+		b.p.Sample = append(b.p.Sample, s)
+	}
+
+	return nil
+}
+
+// function returns the ID of the function of the method, which is named
+// after the method and its class, like "java.lang.Thread.run".
+func (b *builder) function(c *chunk, method *object) uint64 {
+	name := "unknown"
+	if method != nil {
+		className := strings.ReplaceAll(c.string(c.object(method.get("type")).get("name")), "/", ".")
+		name = className + "." + c.string(method.get("name"))
+	}
+
+	if id, ok := b.functions[name]; ok {
+		return id
+	}
+	id := uint64(len(b.p.Function) + 1)
+	b.p.Function = append(b.p.Function, &pprofpb.Function{
+		Id:         id,
+		Name:       b.string(name),
+		SystemName: b.string(name),
+	})
+	b.functions[name] = id
+	return id
+}
+
+func (b *builder) location(functionID uint64, line int64) uint64 {
+	key := locationKey{functionID: functionID, line: line}
+	if id, ok := b.locations[key]; ok {
+		return id
+	}
+	id := uint64(len(b.p.Location) + 1)
+	b.p.Location = append(b.p.Location, &pprofpb.Location{
+		Id:   id,
+		Line: []*pprofpb.Line{{FunctionId: functionID, Line: line}},
+	})
+	b.locations[key] = id
+	return id
+}
+
+func (b *builder) string(s string) int64 {
+	if i, ok := b.strings[s]; ok {
+		return i
+	}
+	i := int64(len(b.p.StringTable))
+	b.p.StringTable = append(b.p.StringTable, s)
+	b.strings[s] = i
+	return i
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logfields defines the structured fields components log with, so
+// that the log lines about the same object file or location can be
+// correlated across components, e.g. the symbolizer and the debuginfo store.
+package logfields
+
+import (
+	"fmt"
+
+	"github.com/go-kit/log"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+)
+
+// Keys of the standard fields.
+const (
+	Component  = "component"
+	BuildID    = "buildid"
+	LocationID = "location_id"
+	Address    = "address"
+)
+
+// WithComponent returns a logger that logs the name of the component.
+func WithComponent(logger log.Logger, component string) log.Logger {
+	return log.With(logger, Component, component)
+}
+
+// WithBuildID returns a logger that logs the build ID of an object file.
+func WithBuildID(logger log.Logger, buildID string) log.Logger {
+	return log.With(logger, BuildID, buildID)
+}
+
+// WithAddress returns a logger that logs the address in hex.
+func WithAddress(logger log.Logger, addr uint64) log.Logger {
+	return log.With(logger, Address, FormatAddress(addr))
+}
+
+// WithLocation returns a logger that logs the ID and address of a location.
+func WithLocation(logger log.Logger, location *pb.Location) log.Logger {
+	return log.With(logger, LocationID, location.Id, Address, FormatAddress(location.Address))
+}
+
+// FormatAddress formats an address the way it is logged.
+func FormatAddress(addr uint64) string {
+	return fmt.Sprintf("%#x", addr)
+}
@@ -15,13 +15,20 @@ package metastore
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/dgraph-io/badger/v3"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
 )
@@ -67,11 +74,77 @@ func NewBadgerMetastore(
 	tracer trace.Tracer,
 	db *badger.DB,
 ) *BadgerMetastore {
-	return &BadgerMetastore{
+	m := &BadgerMetastore{
 		db:     db,
 		tracer: tracer,
 		logger: logger,
 	}
+	if err := m.indexLocationAddresses(); err != nil {
+		level.Error(logger).Log("msg", "failed to index locations by address", "err", err)
+	}
+	return m
+}
+
+// locationsByAddressIndexedKey marks that the locations stored before they
+// were indexed by address were indexed.
+const locationsByAddressIndexedKey = "v1/migrations/locations-by-address"
+
+// indexLocationAddresses indexes the locations of a database written before
+// locations were indexed by address, once.
+func (m *BadgerMetastore) indexLocationAddresses() error {
+	indexed := false
+	if err := m.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte(locationsByAddressIndexedKey))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		indexed = err == nil
+		return err
+	}); err != nil || indexed {
+		return err
+	}
+
+	wb := m.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	err := m.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(locationsKeyPrefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			location := &pb.Location{}
+			if err := it.Item().Value(location.UnmarshalVT); err != nil {
+				return err
+			}
+			if location.MappingId == "" {
+				continue
+			}
+			if err := wb.Set([]byte(MakeLocationAddressKey(location)), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := wb.Set([]byte(locationsByAddressIndexedKey), []byte{}); err != nil {
+		return err
+	}
+	return wb.Flush()
+}
+
+// Ping returns an error if the database can't be read.
+func (m *BadgerMetastore) Ping(ctx context.Context) error {
+	return m.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte("ping"))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		return err
+	})
 }
 
 func (m *BadgerMetastore) Mappings(ctx context.Context, r *pb.MappingsRequest) (*pb.MappingsResponse, error) {
@@ -175,31 +248,37 @@ func (m *BadgerMetastore) Functions(ctx context.Context, r *pb.FunctionsRequest)
 	}
 
 	err := m.db.View(func(txn *badger.Txn) error {
-		for _, functionKey := range functionKeys {
-			item, err := txn.Get(functionKey)
-			if err != nil {
-				return err
-			}
+		var err error
+		res.Functions, err = m.functions(ctx, txn, res.Functions, functionKeys)
+		return err
+	})
 
-			err = item.Value(func(val []byte) error {
-				function := &pb.Function{}
-				err := function.UnmarshalVT(val)
-				if err != nil {
-					return err
-				}
+	return res, err
+}
 
-				res.Functions = append(res.Functions, function)
-				return nil
-			})
+func (m *BadgerMetastore) functions(ctx context.Context, txn *badger.Txn, functions []*pb.Function, functionKeys [][]byte) ([]*pb.Function, error) {
+	for _, functionKey := range functionKeys {
+		item, err := txn.Get(functionKey)
+		if err != nil {
+			return nil, err
+		}
+
+		err = item.Value(func(val []byte) error {
+			function := &pb.Function{}
+			err := function.UnmarshalVT(val)
 			if err != nil {
 				return err
 			}
-		}
 
-		return nil
-	})
+			functions = append(functions, function)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return res, err
+	return functions, nil
 }
 
 func (m *BadgerMetastore) GetOrCreateFunctions(ctx context.Context, r *pb.GetOrCreateFunctionsRequest) (*pb.GetOrCreateFunctionsResponse, error) {
@@ -213,45 +292,53 @@ func (m *BadgerMetastore) GetOrCreateFunctions(ctx context.Context, r *pb.GetOrC
 	}
 
 	err := m.db.Update(func(txn *badger.Txn) error {
-		for i, functionKey := range functionKeys {
-			item, err := txn.Get([]byte(functionKey))
-			if err != nil && err != badger.ErrKeyNotFound {
-				return err
-			}
+		var err error
+		res.Functions, err = getOrCreateFunctions(txn, r.Functions, functionKeys, res.Functions)
+		return err
+	})
 
-			if err == badger.ErrKeyNotFound {
-				function := r.Functions[i]
-				function.Id = FunctionIDFromKey(functionKey)
-				b, err := function.MarshalVT()
-				if err != nil {
-					return err
-				}
-				if err := txn.Set([]byte(functionKey), b); err != nil {
-					return err
-				}
-				res.Functions = append(res.Functions, function)
-				continue
-			}
+	return res, err
+}
 
-			err = item.Value(func(val []byte) error {
-				function := &pb.Function{}
-				err := function.UnmarshalVT(val)
-				if err != nil {
-					return err
-				}
+// getOrCreateFunctions appends the functions with the given keys to res,
+// creating the ones that don't exist yet.
+func getOrCreateFunctions(txn *badger.Txn, functions []*pb.Function, functionKeys []string, res []*pb.Function) ([]*pb.Function, error) {
+	for i, functionKey := range functionKeys {
+		item, err := txn.Get([]byte(functionKey))
+		if err != nil && err != badger.ErrKeyNotFound {
+			return nil, err
+		}
 
-				res.Functions = append(res.Functions, function)
-				return nil
-			})
+		if err == badger.ErrKeyNotFound {
+			function := functions[i]
+			function.Id = FunctionIDFromKey(functionKey)
+			b, err := function.MarshalVT()
 			if err != nil {
-				return err
+				return nil, err
 			}
+			if err := txn.Set([]byte(functionKey), b); err != nil {
+				return nil, err
+			}
+			res = append(res, function)
+			continue
 		}
 
-		return nil
-	})
+		err = item.Value(func(val []byte) error {
+			function := &pb.Function{}
+			err := function.UnmarshalVT(val)
+			if err != nil {
+				return err
+			}
 
-	return res, err
+			res = append(res, function)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
 }
 
 func (m *BadgerMetastore) Locations(ctx context.Context, r *pb.LocationsRequest) (*pb.LocationsResponse, error) {
@@ -309,6 +396,7 @@ func (m *BadgerMetastore) GetOrCreateLocations(ctx context.Context, r *pb.GetOrC
 	}
 
 	err := m.db.Update(func(txn *badger.Txn) error {
+		knownMappings := map[string]struct{}{}
 		for i, locationKey := range locationKeys {
 			item, err := txn.Get([]byte(locationKey))
 			if err != nil && err != badger.ErrKeyNotFound {
@@ -317,6 +405,9 @@ func (m *BadgerMetastore) GetOrCreateLocations(ctx context.Context, r *pb.GetOrC
 
 			if err == badger.ErrKeyNotFound {
 				location := r.Locations[i]
+				if err := validateLocationMapping(txn, location, knownMappings); err != nil {
+					return err
+				}
 				location.Id = LocationIDFromKey(locationKey)
 				b, err := location.MarshalVT()
 				if err != nil {
@@ -325,6 +416,11 @@ func (m *BadgerMetastore) GetOrCreateLocations(ctx context.Context, r *pb.GetOrC
 				if err := txn.Set([]byte(locationKey), b); err != nil {
 					return err
 				}
+				if location.MappingId != "" {
+					if err := txn.Set([]byte(MakeLocationAddressKey(location)), []byte{}); err != nil {
+						return err
+					}
+				}
 				res.Locations = append(res.Locations, location)
 
 				if location.MappingId != "" && location.Address != 0 && len(location.Lines) == 0 {
@@ -338,19 +434,32 @@ func (m *BadgerMetastore) GetOrCreateLocations(ctx context.Context, r *pb.GetOrC
 				continue
 			}
 
+			location := &pb.Location{}
 			err = item.Value(func(val []byte) error {
-				location := &pb.Location{}
-				err := location.UnmarshalVT(val)
-				if err != nil {
-					return err
-				}
-
-				res.Locations = append(res.Locations, location)
-				return nil
+				return location.UnmarshalVT(val)
 			})
 			if err != nil {
 				return err
 			}
+
+			// Locations that were already symbolized by the client are stored
+			// with their lines, unless the location was symbolized before, so
+			// that the symbolizer doesn't need to symbolize them anymore.
+			if len(location.Lines) == 0 && len(r.Locations[i].Lines) > 0 {
+				location.Lines = r.Locations[i].Lines
+				b, err := location.MarshalVT()
+				if err != nil {
+					return err
+				}
+				if err := txn.Set([]byte(locationKey), b); err != nil {
+					return err
+				}
+				if err := txn.Delete([]byte(MakeUnsymbolizedLocationKeyWithID(location.Id))); err != nil {
+					return err
+				}
+			}
+
+			res.Locations = append(res.Locations, location)
 		}
 
 		return nil
@@ -378,14 +487,39 @@ func (m *BadgerMetastore) UnsymbolizedLocations(ctx context.Context, r *pb.Unsym
 				return nil
 			}
 			// Need to skip the first one as the min is not supposed to be
-			// included.
-			it.Next()
+			// included. It may have been symbolized since, in which case
+			// the first one is already past it.
+			if string(it.Item().Key()) == r.MinKey {
+				it.Next()
+			}
 		} else {
 			it.Seek(prefix)
 		}
+		// The keys are prefixed by the mapping ID of their location, so the
+		// locations of mappings with other build IDs are skipped all at once.
+		buildIDMatches := map[string]bool{}
 		for it.ValidForPrefix(prefix) {
+			locationID := LocationIDFromUnsymbolizedKey(string(it.Item().Key()))
+			if r.BuildId != "" {
+				mappingID, _, _ := strings.Cut(locationID, "/")
+				matches, ok := buildIDMatches[mappingID]
+				if !ok {
+					var err error
+					matches, err = m.mappingHasBuildID(txn, mappingID, r.BuildId)
+					if err != nil {
+						return err
+					}
+					buildIDMatches[mappingID] = matches
+				}
+				if !matches {
+					// '0' is the character following '/'.
+					it.Seek([]byte(MakeUnsymbolizedLocationKeyWithID(mappingID + "0")))
+					continue
+				}
+			}
+
 			maxKey = string(it.Item().Key())
-			key := MakeLocationKeyWithID(LocationIDFromUnsymbolizedKey(maxKey))
+			key := MakeLocationKeyWithID(locationID)
 			locationKeys = append(locationKeys, []byte(key))
 			if uint32(len(locationKeys)) == r.Limit {
 				break
@@ -409,9 +543,199 @@ func (m *BadgerMetastore) UnsymbolizedLocations(ctx context.Context, r *pb.Unsym
 	}, nil
 }
 
+// mappingHasBuildID returns true if the mapping with the given ID has the
+// given build ID.
+func (m *BadgerMetastore) mappingHasBuildID(txn *badger.Txn, mappingID, buildID string) (bool, error) {
+	item, err := txn.Get([]byte(MakeMappingKeyWithID(mappingID)))
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	mapping := &pb.Mapping{}
+	if err := item.Value(mapping.UnmarshalVT); err != nil {
+		return false, err
+	}
+	return mapping.BuildId == buildID, nil
+}
+
+func (m *BadgerMetastore) ListLocations(ctx context.Context, r *pb.ListLocationsRequest) (*pb.ListLocationsResponse, error) {
+	res := &pb.ListLocationsResponse{}
+
+	err := m.db.View(func(txn *badger.Txn) error {
+		after := ""
+		if r.AfterId != "" {
+			after = MakeLocationKeyWithID(r.AfterId)
+		}
+		locationKeys, more := listKeys(txn, []byte(locationsKeyPrefix), after, r.Limit)
+
+		var err error
+		res.Locations, err = m.locations(ctx, txn, make([]*pb.Location, 0, len(locationKeys)), locationKeys)
+		if err != nil {
+			return err
+		}
+
+		if more {
+			res.NextAfterId = res.Locations[len(res.Locations)-1].Id
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (m *BadgerMetastore) LocationsByAddress(ctx context.Context, r *pb.LocationsByAddressRequest) (*pb.LocationsByAddressResponse, error) {
+	if r.MappingId == "" {
+		return nil, status.Error(codes.InvalidArgument, "mapping ID is required")
+	}
+	maxAddress := r.MaxAddress
+	if maxAddress == 0 {
+		maxAddress = math.MaxUint64
+	}
+	if r.MinAddress > maxAddress {
+		return nil, status.Error(codes.InvalidArgument, "min address is greater than max address")
+	}
+
+	res := &pb.LocationsByAddressResponse{}
+	err := m.db.View(func(txn *badger.Txn) error {
+		prefix := []byte(locationsByAddressKeyPrefix + r.MappingId + "/")
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		// The keys sort by address, so the range ends with the first key of
+		// an address greater than the max address.
+		locationKeys := [][]byte{}
+		for it.Seek([]byte(locationAddressKeyPrefix(r.MappingId, r.MinAddress))); it.ValidForPrefix(prefix); it.Next() {
+			address, locationID, err := parseLocationAddressKey(string(it.Item().Key()), r.MappingId)
+			if err != nil {
+				return err
+			}
+			if address > maxAddress {
+				break
+			}
+			locationKeys = append(locationKeys, []byte(MakeLocationKeyWithID(locationID)))
+		}
+
+		var err error
+		res.Locations, err = m.locations(ctx, txn, make([]*pb.Location, 0, len(locationKeys)), locationKeys)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (m *BadgerMetastore) ListFunctions(ctx context.Context, r *pb.ListFunctionsRequest) (*pb.ListFunctionsResponse, error) {
+	res := &pb.ListFunctionsResponse{}
+
+	err := m.db.View(func(txn *badger.Txn) error {
+		after := ""
+		if r.AfterId != "" {
+			after = MakeFunctionKeyWithID(r.AfterId)
+		}
+		functionKeys, more := listKeys(txn, []byte(functionKeyPrefix), after, r.Limit)
+
+		var err error
+		res.Functions, err = m.functions(ctx, txn, make([]*pb.Function, 0, len(functionKeys)), functionKeys)
+		if err != nil {
+			return err
+		}
+
+		if more {
+			res.NextAfterId = res.Functions[len(res.Functions)-1].Id
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// listKeys returns up to limit keys with the given prefix that sort after the
+// given key, and whether there are further keys to be listed. The keys are
+// returned in order, so a page of keys can be continued by passing its last
+// key. A limit of zero returns all keys.
+func listKeys(txn *badger.Txn, prefix []byte, after string, limit uint32) ([][]byte, bool) {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Prefix = prefix
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	if after != "" {
+		it.Seek([]byte(after))
+		// The key the page starts after is not supposed to be included,
+		// though it may have been deleted in the meantime.
+		if it.ValidForPrefix(prefix) && string(it.Item().Key()) == after {
+			it.Next()
+		}
+	} else {
+		it.Seek(prefix)
+	}
+
+	keys := [][]byte{}
+	for ; it.ValidForPrefix(prefix); it.Next() {
+		if limit > 0 && uint32(len(keys)) == limit {
+			return keys, true
+		}
+		keys = append(keys, it.Item().KeyCopy(nil))
+	}
+
+	return keys, false
+}
+
 func (m *BadgerMetastore) CreateLocationLines(ctx context.Context, r *pb.CreateLocationLinesRequest) (*pb.CreateLocationLinesResponse, error) {
+	functionKeys := make([]string, 0, len(r.Functions))
+	for _, function := range r.Functions {
+		functionKeys = append(functionKeys, MakeFunctionKey(function))
+	}
+
+	symbolizedAt := time.Now()
+	if r.SymbolizedAt != nil {
+		symbolizedAt = r.SymbolizedAt.AsTime()
+	}
+
+	// The functions and the lines referring to them are written in a single
+	// transaction, so that a failure leaves the locations unsymbolized
+	// instead of symbolized partially.
 	err := m.db.Update(func(txn *badger.Txn) error {
+		if len(r.Functions) > 0 {
+			if _, err := getOrCreateFunctions(txn, r.Functions, functionKeys, nil); err != nil {
+				return err
+			}
+			if err := validateLineFunctions(txn, r.Locations, functionKeys); err != nil {
+				return err
+			}
+		}
+
 		for _, location := range r.Locations {
+			// The lines of locations symbolized by the client while they were
+			// being symbolized asynchronously are kept. Only the lines of
+			// locations symbolized by an earlier call can be replaced.
+			symbolized, err := hasLines(txn, location.Id)
+			if err != nil {
+				return err
+			}
+			previous, err := symbolizedLocationTime(txn, location.Id)
+			if err != nil {
+				return err
+			}
+			if symbolized && (!r.Replace || previous == nil) {
+				continue
+			}
+
 			b, err := location.MarshalVT()
 			if err != nil {
 				return err
@@ -423,6 +747,10 @@ func (m *BadgerMetastore) CreateLocationLines(ctx context.Context, r *pb.CreateL
 			if err := txn.Delete([]byte(MakeUnsymbolizedLocationKeyWithID(location.Id))); err != nil {
 				return err
 			}
+
+			if err := setSymbolizedLocationTime(txn, location.Id, previous, symbolizedAt); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -433,6 +761,159 @@ func (m *BadgerMetastore) CreateLocationLines(ctx context.Context, r *pb.CreateL
 	return &pb.CreateLocationLinesResponse{}, nil
 }
 
+// symbolizedLocationTime returns the time in unix nanoseconds the location
+// was last symbolized at by CreateLocationLines, or nil if it never was.
+func symbolizedLocationTime(txn *badger.Txn, locationID string) (*int64, error) {
+	item, err := txn.Get([]byte(makeSymbolizedLocationIDKey(locationID)))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t int64
+	err = item.Value(func(val []byte) error {
+		if len(val) != 8 {
+			return fmt.Errorf("invalid symbolization time of location %q", locationID)
+		}
+		t = int64(binary.BigEndian.Uint64(val))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// setSymbolizedLocationTime records the time the location was symbolized at,
+// replacing the previous time if there is one.
+func setSymbolizedLocationTime(txn *badger.Txn, locationID string, previous *int64, symbolizedAt time.Time) error {
+	if previous != nil {
+		if err := txn.Delete([]byte(makeSymbolizedLocationTimeKey(locationID, *previous))); err != nil {
+			return err
+		}
+	}
+
+	t := symbolizedAt.UnixNano()
+	if err := txn.Set([]byte(makeSymbolizedLocationTimeKey(locationID, t)), []byte{}); err != nil {
+		return err
+	}
+	val := make([]byte, 8)
+	binary.BigEndian.PutUint64(val, uint64(t))
+	return txn.Set([]byte(makeSymbolizedLocationIDKey(locationID)), val)
+}
+
+// SymbolizedLocations returns the locations symbolized by CreateLocationLines
+// before the requested time, the ones symbolized the longest ago first.
+func (m *BadgerMetastore) SymbolizedLocations(ctx context.Context, r *pb.SymbolizedLocationsRequest) (*pb.SymbolizedLocationsResponse, error) {
+	if r.SymbolizedBefore == nil {
+		return nil, status.Error(codes.InvalidArgument, "symbolized before is required")
+	}
+	before := r.SymbolizedBefore.AsTime().UnixNano()
+
+	res := &pb.SymbolizedLocationsResponse{}
+	err := m.db.View(func(txn *badger.Txn) error {
+		prefix := []byte(symbolizedLocationsByTimeKeyPrefix)
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		locationKeys := [][]byte{}
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if r.Limit > 0 && uint32(len(locationKeys)) >= r.Limit {
+				break
+			}
+			t, locationID, err := parseSymbolizedLocationTimeKey(string(it.Item().Key()))
+			if err != nil {
+				return err
+			}
+			if t >= before {
+				break
+			}
+			locationKeys = append(locationKeys, []byte(MakeLocationKeyWithID(locationID)))
+		}
+
+		var err error
+		res.Locations, err = m.locations(ctx, txn, make([]*pb.Location, 0, len(locationKeys)), locationKeys)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// hasLines returns whether the stored location with the ID has lines.
+func hasLines(txn *badger.Txn, locationID string) (bool, error) {
+	item, err := txn.Get([]byte(MakeLocationKeyWithID(locationID)))
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	location := &pb.Location{}
+	if err := item.Value(func(val []byte) error {
+		return location.UnmarshalVT(val)
+	}); err != nil {
+		return false, err
+	}
+	return len(location.Lines) > 0, nil
+}
+
+// validateLocationMapping returns an InvalidArgument error if the location
+// refers to a mapping that doesn't exist. Mappings known to exist are added to
+// known.
+func validateLocationMapping(txn *badger.Txn, location *pb.Location, known map[string]struct{}) error {
+	if location.MappingId == "" {
+		return nil
+	}
+	if _, ok := known[location.MappingId]; ok {
+		return nil
+	}
+	_, err := txn.Get([]byte(MakeMappingKeyWithID(location.MappingId)))
+	if err == badger.ErrKeyNotFound {
+		return status.Errorf(codes.InvalidArgument, "location refers to unknown mapping %q", location.MappingId)
+	}
+	if err != nil {
+		return err
+	}
+	known[location.MappingId] = struct{}{}
+	return nil
+}
+
+// validateLineFunctions returns an error if a line of the
+// given locations refers to a function that is neither one of the ones with
+// the given keys nor an existing one.
+func validateLineFunctions(txn *badger.Txn, locations []*pb.Location, functionKeys []string) error {
+	known := make(map[string]struct{}, len(functionKeys))
+	for _, key := range functionKeys {
+		known[FunctionIDFromKey(key)] = struct{}{}
+	}
+
+	for _, location := range locations {
+		for _, line := range location.Lines {
+			if _, ok := known[line.FunctionId]; ok {
+				continue
+			}
+			_, err := txn.Get([]byte(MakeFunctionKeyWithID(line.FunctionId)))
+			if err == badger.ErrKeyNotFound {
+				return fmt.Errorf("line of location %q refers to unknown function %q", location.Id, line.FunctionId)
+			}
+			if err != nil {
+				return err
+			}
+			known[line.FunctionId] = struct{}{}
+		}
+	}
+	return nil
+}
+
 func (m *BadgerMetastore) GetOrCreateStacktraces(ctx context.Context, r *pb.GetOrCreateStacktracesRequest) (*pb.GetOrCreateStacktracesResponse, error) {
 	res := &pb.GetOrCreateStacktracesResponse{
 		Stacktraces: make([]*pb.Stacktrace, 0, len(r.Stacktraces)),
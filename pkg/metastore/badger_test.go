@@ -0,0 +1,742 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metastore_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/metastore"
+	"github.com/parca-dev/parca/pkg/metastoretest"
+)
+
+func newTestMetastore(t *testing.T) pb.MetastoreServiceServer {
+	return metastoretest.NewTestMetastore(
+		t,
+		log.NewNopLogger(),
+		prometheus.NewRegistry(),
+		trace.NewNoopTracerProvider().Tracer(""),
+	)
+}
+
+func TestGetOrCreateFunctionsBatch(t *testing.T) {
+	ctx := context.Background()
+	batch := newTestMetastore(t)
+	single := newTestMetastore(t)
+
+	const n = 1000
+	functions := func() []*pb.Function {
+		fs := make([]*pb.Function, 0, n)
+		for i := 0; i < n; i++ {
+			fs = append(fs, &pb.Function{
+				Name:       fmt.Sprintf("func%d", i),
+				SystemName: fmt.Sprintf("sys_func%d", i),
+				Filename:   "main.go",
+				StartLine:  int64(i),
+			})
+		}
+		return fs
+	}
+
+	bres, err := batch.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{Functions: functions()})
+	require.NoError(t, err)
+	require.Len(t, bres.Functions, n)
+
+	ids := make([]string, 0, n)
+	for _, f := range functions() {
+		sres, err := single.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{Functions: []*pb.Function{f}})
+		require.NoError(t, err)
+		require.Len(t, sres.Functions, 1)
+		ids = append(ids, sres.Functions[0].Id)
+	}
+
+	bfres, err := batch.Functions(ctx, &pb.FunctionsRequest{FunctionIds: ids})
+	require.NoError(t, err)
+	sfres, err := single.Functions(ctx, &pb.FunctionsRequest{FunctionIds: ids})
+	require.NoError(t, err)
+	require.Equal(t, sfres.Functions, bfres.Functions)
+
+	// Creating the same functions again must return the existing ones.
+	bres2, err := batch.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{Functions: functions()})
+	require.NoError(t, err)
+	require.Equal(t, bres.Functions, bres2.Functions)
+}
+
+func TestGetOrCreateLocationsBatch(t *testing.T) {
+	ctx := context.Background()
+	batch := newTestMetastore(t)
+	single := newTestMetastore(t)
+
+	mapping := &pb.Mapping{Start: 0x400000, Limit: 0x800000, BuildId: "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085"}
+	bmres, err := batch.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{Mappings: []*pb.Mapping{mapping}})
+	require.NoError(t, err)
+	smres, err := single.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{Mappings: []*pb.Mapping{mapping}})
+	require.NoError(t, err)
+	require.Equal(t, bmres.Mappings[0].Id, smres.Mappings[0].Id)
+	mappingID := bmres.Mappings[0].Id
+
+	const n = 1000
+	locations := func() []*pb.Location {
+		ls := make([]*pb.Location, 0, n)
+		for i := 0; i < n; i++ {
+			ls = append(ls, &pb.Location{
+				Address:   0x401000 + uint64(i),
+				MappingId: mappingID,
+			})
+		}
+		return ls
+	}
+
+	bres, err := batch.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: locations()})
+	require.NoError(t, err)
+	require.Len(t, bres.Locations, n)
+
+	ids := make([]string, 0, n)
+	for _, l := range locations() {
+		sres, err := single.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: []*pb.Location{l}})
+		require.NoError(t, err)
+		require.Len(t, sres.Locations, 1)
+		ids = append(ids, sres.Locations[0].Id)
+	}
+
+	blres, err := batch.Locations(ctx, &pb.LocationsRequest{LocationIds: ids})
+	require.NoError(t, err)
+	slres, err := single.Locations(ctx, &pb.LocationsRequest{LocationIds: ids})
+	require.NoError(t, err)
+	require.Equal(t, slres.Locations, blres.Locations)
+
+	bures, err := batch.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	sures, err := single.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, bures.Locations, n)
+	require.Equal(t, sures.Locations, bures.Locations)
+}
+
+func TestGetOrCreateLocationsUnknownMapping(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{Start: 1, Limit: 1 << 20, BuildId: "abc", File: "a.out"}},
+	})
+	require.NoError(t, err)
+	mappingID := mres.Mappings[0].Id
+
+	// The first location is valid, but the request fails as a whole.
+	_, err = m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{Address: 0x10, MappingId: mappingID},
+			{Address: 0x20, MappingId: "unknown"},
+		},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(t, err, `unknown mapping "unknown"`)
+
+	lres, err := m.ListLocations(ctx, &pb.ListLocationsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, lres.Locations)
+
+	// Locations without a mapping don't refer to one.
+	res, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{Address: 0x10, MappingId: mappingID},
+			{Address: 0x20, MappingId: mappingID},
+			{Address: 0x30},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Locations, 3)
+	for _, l := range res.Locations {
+		require.NotEmpty(t, l.Id)
+	}
+
+	lres, err = m.ListLocations(ctx, &pb.ListLocationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, lres.Locations, 3)
+}
+
+func TestGetOrCreateFunctionsDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	newFunction := func() *pb.Function {
+		return &pb.Function{
+			Name:       "main.main",
+			SystemName: "main.main",
+			Filename:   "/home/user/src/main.go",
+			StartLine:  12,
+		}
+	}
+
+	res1, err := m.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{Functions: []*pb.Function{newFunction()}})
+	require.NoError(t, err)
+	require.Len(t, res1.Functions, 1)
+	id := res1.Functions[0].Id
+	require.NotEmpty(t, id)
+
+	res2, err := m.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{Functions: []*pb.Function{newFunction()}})
+	require.NoError(t, err)
+	require.Len(t, res2.Functions, 1)
+	require.Equal(t, id, res2.Functions[0].Id)
+
+	// Duplicates within the same request resolve to the same function too.
+	res3, err := m.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{Functions: []*pb.Function{newFunction(), newFunction()}})
+	require.NoError(t, err)
+	require.Len(t, res3.Functions, 2)
+	require.Equal(t, id, res3.Functions[0].Id)
+	require.Equal(t, id, res3.Functions[1].Id)
+
+	// Any difference in the identifying fields creates a new function.
+	other := newFunction()
+	other.StartLine = 13
+	res4, err := m.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{Functions: []*pb.Function{other}})
+	require.NoError(t, err)
+	require.NotEqual(t, id, res4.Functions[0].Id)
+
+	fres, err := m.Functions(ctx, &pb.FunctionsRequest{FunctionIds: []string{id}})
+	require.NoError(t, err)
+	require.Len(t, fres.Functions, 1)
+	require.Equal(t, id, fres.Functions[0].Id)
+	require.Equal(t, "main.main", fres.Functions[0].Name)
+}
+
+func TestGetOrCreateLocationsDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{Start: 1, Limit: 1 << 20, BuildId: "abc", File: "a.out"}},
+	})
+	require.NoError(t, err)
+	mappingID := mres.Mappings[0].Id
+
+	newLocation := func() *pb.Location {
+		return &pb.Location{MappingId: mappingID, Address: 0x1234}
+	}
+
+	res1, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: []*pb.Location{newLocation()}})
+	require.NoError(t, err)
+	require.Len(t, res1.Locations, 1)
+	id := res1.Locations[0].Id
+	require.NotEmpty(t, id)
+
+	// Re-ingesting the location, also twice within a request, resolves to
+	// the same location.
+	res2, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: []*pb.Location{newLocation(), newLocation()}})
+	require.NoError(t, err)
+	require.Len(t, res2.Locations, 2)
+	require.Equal(t, id, res2.Locations[0].Id)
+	require.Equal(t, id, res2.Locations[1].Id)
+
+	// The key is made of the mapping, the address and whether the location
+	// is folded.
+	folded := newLocation()
+	folded.IsFolded = true
+	res3, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: []*pb.Location{folded}})
+	require.NoError(t, err)
+	require.NotEqual(t, id, res3.Locations[0].Id)
+
+	lres, err := m.Locations(ctx, &pb.LocationsRequest{LocationIds: []string{id}})
+	require.NoError(t, err)
+	require.Len(t, lres.Locations, 1)
+	require.Equal(t, id, lres.Locations[0].Id)
+
+	list, err := m.ListLocations(ctx, &pb.ListLocationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Locations, 2)
+}
+
+func TestListLocations(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{Start: 1, Limit: 1 << 20, BuildId: "abc", File: "a.out"}},
+	})
+	require.NoError(t, err)
+
+	const n = 250
+	locations := make([]*pb.Location, 0, n)
+	for i := 0; i < n; i++ {
+		locations = append(locations, &pb.Location{Address: uint64(i + 1), MappingId: mres.Mappings[0].Id})
+	}
+	lres, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: locations})
+	require.NoError(t, err)
+
+	want := make([]string, 0, n)
+	for _, l := range lres.Locations {
+		want = append(want, l.Id)
+	}
+	sort.Strings(want)
+
+	got := []string{}
+	pages := 0
+	afterID := ""
+	for {
+		res, err := m.ListLocations(ctx, &pb.ListLocationsRequest{Limit: 100, AfterId: afterID})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(res.Locations), 100)
+		pages++
+
+		for _, l := range res.Locations {
+			got = append(got, l.Id)
+		}
+		if res.NextAfterId == "" {
+			break
+		}
+		afterID = res.NextAfterId
+	}
+
+	require.Equal(t, 3, pages)
+	require.Equal(t, want, got)
+}
+
+func TestUnsymbolizedLocationsByBuildID(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{
+			{Start: 1, Limit: 1 << 20, BuildId: "abc", File: "a.out"},
+			{Start: 1, Limit: 1 << 20, BuildId: "def", File: "b.out"},
+			{Start: 1 << 20, Limit: 1 << 21, Offset: 1 << 20, BuildId: "abc", File: "a.out"},
+		},
+	})
+	require.NoError(t, err)
+
+	const n = 10
+	locations := make([]*pb.Location, 0, n*len(mres.Mappings))
+	for _, mapping := range mres.Mappings {
+		for i := 0; i < n; i++ {
+			locations = append(locations, &pb.Location{Address: mapping.Start + uint64(i), MappingId: mapping.Id})
+		}
+	}
+	lres, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: locations})
+	require.NoError(t, err)
+
+	want := []string{}
+	for _, l := range lres.Locations {
+		if l.MappingId != mres.Mappings[1].Id {
+			want = append(want, l.Id)
+		}
+	}
+	sort.Strings(want)
+
+	got := []string{}
+	minKey := ""
+	for {
+		res, err := m.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{BuildId: "abc", Limit: 7, MinKey: minKey})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(res.Locations), 7)
+		if len(res.Locations) == 0 {
+			break
+		}
+
+		for _, l := range res.Locations {
+			got = append(got, l.Id)
+		}
+		minKey = res.MaxKey
+	}
+	require.Equal(t, want, got)
+
+	res, err := m.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{BuildId: "unknown"})
+	require.NoError(t, err)
+	require.Empty(t, res.Locations)
+}
+
+func TestListFunctions(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	const n = 250
+	functions := make([]*pb.Function, 0, n)
+	for i := 0; i < n; i++ {
+		functions = append(functions, &pb.Function{Name: fmt.Sprintf("func%d", i), Filename: "main.go"})
+	}
+	fres, err := m.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{Functions: functions})
+	require.NoError(t, err)
+
+	want := make([]string, 0, n)
+	for _, f := range fres.Functions {
+		want = append(want, f.Id)
+	}
+	sort.Strings(want)
+
+	got := []string{}
+	pages := 0
+	afterID := ""
+	for {
+		res, err := m.ListFunctions(ctx, &pb.ListFunctionsRequest{Limit: 100, AfterId: afterID})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(res.Functions), 100)
+		pages++
+
+		for _, f := range res.Functions {
+			got = append(got, f.Id)
+		}
+		if res.NextAfterId == "" {
+			break
+		}
+		afterID = res.NextAfterId
+	}
+
+	require.Equal(t, 3, pages)
+	require.Equal(t, want, got)
+
+	// Without a limit everything is returned at once.
+	res, err := m.ListFunctions(ctx, &pb.ListFunctionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Functions, n)
+	require.Empty(t, res.NextAfterId)
+}
+
+func TestCreateLocationLinesAtomic(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{Start: 1, Limit: 1 << 20, BuildId: "abc", File: "a.out"}},
+	})
+	require.NoError(t, err)
+	lres, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{Address: 0x10, MappingId: mres.Mappings[0].Id},
+			{Address: 0x20, MappingId: mres.Mappings[0].Id},
+		},
+	})
+	require.NoError(t, err)
+
+	function := func() *pb.Function {
+		return &pb.Function{Name: "main", SystemName: "main", Filename: "main.go", StartLine: 3}
+	}
+	functionID := metastore.MakeFunctionID(function())
+	location := func(i int, functionID string) *pb.Location {
+		l := lres.Locations[i]
+		return &pb.Location{
+			Id:        l.Id,
+			Address:   l.Address,
+			MappingId: l.MappingId,
+			Lines:     []*pb.Line{{FunctionId: functionID, Line: 4}},
+		}
+	}
+
+	// The second location refers to a function that doesn't exist, which
+	// fails the request after the function of the first one was created.
+	_, err = m.CreateLocationLines(ctx, &pb.CreateLocationLinesRequest{
+		Locations: []*pb.Location{location(0, functionID), location(1, "unknown")},
+		Functions: []*pb.Function{function()},
+	})
+	require.Error(t, err)
+
+	// Neither the function nor the lines of the first location were stored.
+	fres, err := m.ListFunctions(ctx, &pb.ListFunctionsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, fres.Functions)
+	res, err := m.Locations(ctx, &pb.LocationsRequest{LocationIds: []string{lres.Locations[0].Id, lres.Locations[1].Id}})
+	require.NoError(t, err)
+	for _, l := range res.Locations {
+		require.Empty(t, l.Lines)
+	}
+	ures, err := m.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, ures.Locations, 2)
+
+	// Retrying stores the function along with the lines of both locations.
+	_, err = m.CreateLocationLines(ctx, &pb.CreateLocationLinesRequest{
+		Locations: []*pb.Location{location(0, functionID), location(1, functionID)},
+		Functions: []*pb.Function{function(), function()},
+	})
+	require.NoError(t, err)
+
+	fres, err = m.ListFunctions(ctx, &pb.ListFunctionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, fres.Functions, 1)
+	require.Equal(t, functionID, fres.Functions[0].Id)
+	res, err = m.Locations(ctx, &pb.LocationsRequest{LocationIds: []string{lres.Locations[0].Id, lres.Locations[1].Id}})
+	require.NoError(t, err)
+	for _, l := range res.Locations {
+		require.Equal(t, []*pb.Line{{FunctionId: functionID, Line: 4}}, l.Lines)
+	}
+	ures, err = m.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, ures.Locations)
+}
+
+func TestCreateLocationLinesKeepsClientLines(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{Start: 1, Limit: 1 << 20, BuildId: "abc", File: "a.out"}},
+	})
+	require.NoError(t, err)
+	fres, err := m.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{
+		Functions: []*pb.Function{
+			{Name: "main", SystemName: "main", Filename: "main.go"},
+			{Name: "other", SystemName: "other", Filename: "other.go"},
+		},
+	})
+	require.NoError(t, err)
+
+	lres, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{Address: 0x10, MappingId: mres.Mappings[0].Id}},
+	})
+	require.NoError(t, err)
+	location := lres.Locations[0]
+
+	// The location is symbolized by a client while it's being symbolized
+	// asynchronously.
+	_, err = m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			Address:   0x10,
+			MappingId: mres.Mappings[0].Id,
+			Lines:     []*pb.Line{{FunctionId: fres.Functions[0].Id, Line: 1}},
+		}},
+	})
+	require.NoError(t, err)
+	_, err = m.CreateLocationLines(ctx, &pb.CreateLocationLinesRequest{
+		Locations: []*pb.Location{{
+			Id:        location.Id,
+			Address:   location.Address,
+			MappingId: location.MappingId,
+			Lines:     []*pb.Line{{FunctionId: fres.Functions[1].Id, Line: 2}},
+		}},
+	})
+	require.NoError(t, err)
+
+	res, err := m.Locations(ctx, &pb.LocationsRequest{LocationIds: []string{location.Id}})
+	require.NoError(t, err)
+	require.Equal(t, []*pb.Line{{FunctionId: fres.Functions[0].Id, Line: 1}}, res.Locations[0].Lines)
+	ures, err := m.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, ures.Locations)
+}
+
+func TestSymbolizedLocations(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{Start: 1, Limit: 1 << 20, BuildId: "abc", File: "a.out"}},
+	})
+	require.NoError(t, err)
+	fres, err := m.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{
+		Functions: []*pb.Function{
+			{Name: "main", SystemName: "main", Filename: "main.go"},
+			{Name: "other", SystemName: "other", Filename: "other.go"},
+		},
+	})
+	require.NoError(t, err)
+	oldLines := []*pb.Line{{FunctionId: fres.Functions[0].Id, Line: 1}}
+	newLines := []*pb.Line{{FunctionId: fres.Functions[1].Id, Line: 2}}
+
+	lres, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{Address: 0x10, MappingId: mres.Mappings[0].Id},
+			{Address: 0x20, MappingId: mres.Mappings[0].Id},
+			{Address: 0x30, MappingId: mres.Mappings[0].Id, Lines: oldLines},
+		},
+	})
+	require.NoError(t, err)
+	a, b, client := lres.Locations[0], lres.Locations[1], lres.Locations[2]
+
+	createLines := func(location *pb.Location, lines []*pb.Line, replace bool, symbolizedAt int64) {
+		t.Helper()
+		_, err := m.CreateLocationLines(ctx, &pb.CreateLocationLinesRequest{
+			Locations: []*pb.Location{{
+				Id:        location.Id,
+				Address:   location.Address,
+				MappingId: location.MappingId,
+				Lines:     lines,
+			}},
+			Replace:      replace,
+			SymbolizedAt: timestamppb.New(time.Unix(symbolizedAt, 0)),
+		})
+		require.NoError(t, err)
+	}
+	symbolizedBefore := func(before int64, limit uint32) []string {
+		t.Helper()
+		res, err := m.SymbolizedLocations(ctx, &pb.SymbolizedLocationsRequest{
+			SymbolizedBefore: timestamppb.New(time.Unix(before, 0)),
+			Limit:            limit,
+		})
+		require.NoError(t, err)
+		ids := make([]string, 0, len(res.Locations))
+		for _, l := range res.Locations {
+			ids = append(ids, l.Id)
+		}
+		return ids
+	}
+	lines := func(location *pb.Location) []*pb.Line {
+		t.Helper()
+		res, err := m.Locations(ctx, &pb.LocationsRequest{LocationIds: []string{location.Id}})
+		require.NoError(t, err)
+		return res.Locations[0].Lines
+	}
+
+	createLines(a, oldLines, false, 200)
+	createLines(b, oldLines, false, 100)
+
+	// The locations symbolized the longest ago come first, locations
+	// symbolized by the client are not included.
+	require.Equal(t, []string{b.Id, a.Id}, symbolizedBefore(300, 0))
+	require.Equal(t, []string{b.Id}, symbolizedBefore(300, 1))
+	require.Equal(t, []string{b.Id}, symbolizedBefore(200, 0))
+
+	// Lines are only replaced if requested.
+	createLines(a, newLines, false, 400)
+	require.Equal(t, oldLines, lines(a))
+	require.Equal(t, []string{b.Id, a.Id}, symbolizedBefore(300, 0))
+
+	createLines(b, newLines, true, 400)
+	require.Equal(t, newLines, lines(b))
+	require.Equal(t, []string{a.Id}, symbolizedBefore(300, 0))
+	require.Equal(t, []string{a.Id, b.Id}, symbolizedBefore(500, 0))
+
+	// The lines of locations symbolized by the client are never replaced.
+	createLines(client, newLines, true, 400)
+	require.Equal(t, oldLines, lines(client))
+	require.Equal(t, []string{a.Id, b.Id}, symbolizedBefore(500, 0))
+}
+
+func TestLocationsByAddress(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{
+			{Start: 1, Limit: 1 << 20, BuildId: "abc", File: "a.out"},
+			{Start: 1, Limit: 1 << 20, BuildId: "def", File: "b.out"},
+		},
+	})
+	require.NoError(t, err)
+
+	// The locations are created out of address order.
+	addresses := []uint64{0x500, 0x100, 0x300, 0x1000, 0x200, 0x400}
+	locations := []*pb.Location{}
+	for _, mapping := range mres.Mappings {
+		for _, address := range addresses {
+			locations = append(locations, &pb.Location{Address: address, MappingId: mapping.Id})
+		}
+	}
+	_, err = m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: locations})
+	require.NoError(t, err)
+
+	query := func(mappingID string, min, max uint64) []uint64 {
+		res, err := m.LocationsByAddress(ctx, &pb.LocationsByAddressRequest{
+			MappingId:  mappingID,
+			MinAddress: min,
+			MaxAddress: max,
+		})
+		require.NoError(t, err)
+
+		got := []uint64{}
+		for _, l := range res.Locations {
+			require.Equal(t, mappingID, l.MappingId)
+			got = append(got, l.Address)
+		}
+		return got
+	}
+
+	require.Equal(t, []uint64{0x200, 0x300, 0x400}, query(mres.Mappings[0].Id, 0x200, 0x400))
+	require.Equal(t, []uint64{0x100, 0x200}, query(mres.Mappings[1].Id, 0x100, 0x2ff))
+	require.Equal(t, []uint64{0x400, 0x500, 0x1000}, query(mres.Mappings[1].Id, 0x400, 0))
+	require.Equal(t, []uint64{}, query(mres.Mappings[0].Id, 0x501, 0xfff))
+	require.Equal(t, []uint64{}, query("unknown", 0, 0))
+
+	_, err = m.LocationsByAddress(ctx, &pb.LocationsByAddressRequest{MappingId: mres.Mappings[0].Id, MinAddress: 2, MaxAddress: 1})
+	require.Error(t, err)
+}
+
+func TestLocationsByAddressIndexesExistingLocations(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m := metastore.NewBadgerMetastore(logger, prometheus.NewRegistry(), trace.NewNoopTracerProvider().Tracer(""), db)
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{Start: 1, Limit: 1 << 20, BuildId: "abc", File: "a.out"}},
+	})
+	require.NoError(t, err)
+	_, err = m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{Address: 0x20, MappingId: mres.Mappings[0].Id},
+			{Address: 0x10, MappingId: mres.Mappings[0].Id},
+		},
+	})
+	require.NoError(t, err)
+
+	// The locations were stored before they were indexed by address.
+	require.NoError(t, db.DropPrefix([]byte("v1/locations/by-address/"), []byte("v1/migrations/")))
+
+	m = metastore.NewBadgerMetastore(logger, prometheus.NewRegistry(), trace.NewNoopTracerProvider().Tracer(""), db)
+	res, err := m.LocationsByAddress(ctx, &pb.LocationsByAddressRequest{MappingId: mres.Mappings[0].Id})
+	require.NoError(t, err)
+	require.Len(t, res.Locations, 2)
+	require.Equal(t, uint64(0x10), res.Locations[0].Address)
+	require.Equal(t, uint64(0x20), res.Locations[1].Address)
+}
+
+func TestMappingsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMetastore(t)
+
+	mapping := func() *pb.Mapping {
+		return &pb.Mapping{
+			Start:           0x400000,
+			Limit:           0x800000,
+			Offset:          0x1000,
+			File:            "/usr/bin/app",
+			BuildId:         "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085",
+			HasFunctions:    true,
+			HasFilenames:    true,
+			HasLineNumbers:  true,
+			HasInlineFrames: true,
+		}
+	}
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{Mappings: []*pb.Mapping{mapping()}})
+	require.NoError(t, err)
+	id := mres.Mappings[0].Id
+	require.NotEmpty(t, id)
+
+	want := mapping()
+	want.Id = id
+
+	res, err := m.Mappings(ctx, &pb.MappingsRequest{MappingIds: []string{id}})
+	require.NoError(t, err)
+	require.Len(t, res.Mappings, 1)
+	require.True(t, proto.Equal(want, res.Mappings[0]), "got %v", res.Mappings[0])
+
+	// Getting the mapping again returns what was stored.
+	mres, err = m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{Mappings: []*pb.Mapping{mapping()}})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(want, mres.Mappings[0]), "got %v", mres.Mappings[0])
+}
@@ -0,0 +1,264 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metastore
+
+import (
+	"context"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+)
+
+type CachingOption func(*CachingMetastore)
+
+// WithLocationCacheSize sets the maximum number of cached locations.
+func WithLocationCacheSize(size int) CachingOption {
+	return func(m *CachingMetastore) {
+		m.locationCacheSize = size
+	}
+}
+
+// WithFunctionCacheSize sets the maximum number of cached functions.
+func WithFunctionCacheSize(size int) CachingOption {
+	return func(m *CachingMetastore) {
+		m.functionCacheSize = size
+	}
+}
+
+// WithMappingCacheSize sets the maximum number of cached mappings.
+func WithMappingCacheSize(size int) CachingOption {
+	return func(m *CachingMetastore) {
+		m.mappingCacheSize = size
+	}
+}
+
+// CachingMetastore is a metastore client that keeps the most recently read
+// locations, functions and mappings in memory, so that queries resolving the
+// same stacktraces repeatedly don't have to go to the underlying metastore.
+// Functions and mappings never change once created, locations however gain
+// lines when they are symbolized, so locations written through
+// CreateLocationLines are evicted. The cached messages are shared between
+// callers and must not be modified.
+type CachingMetastore struct {
+	pb.MetastoreServiceClient
+
+	locationCacheSize int
+	functionCacheSize int
+	mappingCacheSize  int
+
+	locations *lru.Cache
+	functions *lru.Cache
+	mappings  *lru.Cache
+
+	// generation is incremented whenever locations are invalidated. Lookups
+	// that raced with an invalidation don't populate the cache, as they may
+	// have read a location before its lines were written.
+	mtx        sync.Mutex
+	generation uint64
+
+	requests *prometheus.CounterVec
+}
+
+// NewCachingMetastore returns a metastore client that caches lookups of the
+// given client.
+func NewCachingMetastore(inner pb.MetastoreServiceClient, reg prometheus.Registerer, opts ...CachingOption) (*CachingMetastore, error) {
+	const (
+		defaultLocationCacheSize = 100_000
+		defaultFunctionCacheSize = 100_000
+		defaultMappingCacheSize  = 10_000
+	)
+
+	requests := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "parca_metastore_cache_requests_total",
+			Help: "Total number of metastore cache lookups by item type and result.",
+		},
+		[]string{"type", "result"},
+	)
+	reg.MustRegister(requests)
+
+	m := &CachingMetastore{
+		MetastoreServiceClient: inner,
+
+		locationCacheSize: defaultLocationCacheSize,
+		functionCacheSize: defaultFunctionCacheSize,
+		mappingCacheSize:  defaultMappingCacheSize,
+
+		requests: requests,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	var err error
+	if m.locations, err = lru.New(m.locationCacheSize); err != nil {
+		return nil, err
+	}
+	if m.functions, err = lru.New(m.functionCacheSize); err != nil {
+		return nil, err
+	}
+	if m.mappings, err = lru.New(m.mappingCacheSize); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// CreateLocationLines writes the lines of the locations and evicts the
+// now stale locations from the cache.
+func (m *CachingMetastore) CreateLocationLines(ctx context.Context, in *pb.CreateLocationLinesRequest, opts ...grpc.CallOption) (*pb.CreateLocationLinesResponse, error) {
+	res, err := m.MetastoreServiceClient.CreateLocationLines(ctx, in, opts...)
+
+	// Invalidate even if the request failed, the write may have been
+	// partially applied.
+	m.mtx.Lock()
+	m.generation++
+	for _, l := range in.Locations {
+		m.locations.Remove(l.Id)
+	}
+	m.mtx.Unlock()
+
+	return res, err
+}
+
+// GetOrCreateLocations gets or creates the locations, and evicts the ones
+// that were requested with lines from the cache, as they may have gained the
+// lines.
+func (m *CachingMetastore) GetOrCreateLocations(ctx context.Context, in *pb.GetOrCreateLocationsRequest, opts ...grpc.CallOption) (*pb.GetOrCreateLocationsResponse, error) {
+	res, err := m.MetastoreServiceClient.GetOrCreateLocations(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for i, l := range in.Locations {
+		if len(l.Lines) == 0 || i >= len(res.Locations) {
+			continue
+		}
+		m.generation++
+		m.locations.Remove(res.Locations[i].Id)
+	}
+
+	return res, nil
+}
+
+func (m *CachingMetastore) Locations(ctx context.Context, in *pb.LocationsRequest, opts ...grpc.CallOption) (*pb.LocationsResponse, error) {
+	m.mtx.Lock()
+	generation := m.generation
+	m.mtx.Unlock()
+
+	locations := make([]*pb.Location, len(in.LocationIds))
+	missing := m.lookup(m.locations, "location", in.LocationIds, func(i int, v interface{}) {
+		locations[i] = v.(*pb.Location)
+	})
+	if len(missing) == 0 {
+		return &pb.LocationsResponse{Locations: locations}, nil
+	}
+
+	ids := make([]string, 0, len(missing))
+	for _, i := range missing {
+		ids = append(ids, in.LocationIds[i])
+	}
+	res, err := m.MetastoreServiceClient.Locations(ctx, &pb.LocationsRequest{LocationIds: ids}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for j, i := range missing {
+		locations[i] = res.Locations[j]
+		if m.generation == generation {
+			m.locations.Add(ids[j], res.Locations[j])
+		}
+	}
+
+	return &pb.LocationsResponse{Locations: locations}, nil
+}
+
+func (m *CachingMetastore) Functions(ctx context.Context, in *pb.FunctionsRequest, opts ...grpc.CallOption) (*pb.FunctionsResponse, error) {
+	functions := make([]*pb.Function, len(in.FunctionIds))
+	missing := m.lookup(m.functions, "function", in.FunctionIds, func(i int, v interface{}) {
+		functions[i] = v.(*pb.Function)
+	})
+	if len(missing) == 0 {
+		return &pb.FunctionsResponse{Functions: functions}, nil
+	}
+
+	ids := make([]string, 0, len(missing))
+	for _, i := range missing {
+		ids = append(ids, in.FunctionIds[i])
+	}
+	res, err := m.MetastoreServiceClient.Functions(ctx, &pb.FunctionsRequest{FunctionIds: ids}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range missing {
+		functions[i] = res.Functions[j]
+		m.functions.Add(ids[j], res.Functions[j])
+	}
+
+	return &pb.FunctionsResponse{Functions: functions}, nil
+}
+
+func (m *CachingMetastore) Mappings(ctx context.Context, in *pb.MappingsRequest, opts ...grpc.CallOption) (*pb.MappingsResponse, error) {
+	mappings := make([]*pb.Mapping, len(in.MappingIds))
+	missing := m.lookup(m.mappings, "mapping", in.MappingIds, func(i int, v interface{}) {
+		mappings[i] = v.(*pb.Mapping)
+	})
+	if len(missing) == 0 {
+		return &pb.MappingsResponse{Mappings: mappings}, nil
+	}
+
+	ids := make([]string, 0, len(missing))
+	for _, i := range missing {
+		ids = append(ids, in.MappingIds[i])
+	}
+	res, err := m.MetastoreServiceClient.Mappings(ctx, &pb.MappingsRequest{MappingIds: ids}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range missing {
+		mappings[i] = res.Mappings[j]
+		m.mappings.Add(ids[j], res.Mappings[j])
+	}
+
+	return &pb.MappingsResponse{Mappings: mappings}, nil
+}
+
+// lookup calls found for every ID present in the cache and returns the
+// indexes of the IDs that are not.
+func (m *CachingMetastore) lookup(c *lru.Cache, typ string, ids []string, found func(int, interface{})) []int {
+	var missing []int
+	for i, id := range ids {
+		v, ok := c.Get(id)
+		if !ok {
+			missing = append(missing, i)
+			continue
+		}
+		found(i, v)
+	}
+
+	m.requests.WithLabelValues(typ, "hit").Add(float64(len(ids) - len(missing)))
+	m.requests.WithLabelValues(typ, "miss").Add(float64(len(missing)))
+	return missing
+}
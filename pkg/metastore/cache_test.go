@@ -0,0 +1,119 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metastore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/metastore"
+)
+
+type countingClient struct {
+	pb.MetastoreServiceClient
+
+	locationReads int
+	functionReads int
+}
+
+func (c *countingClient) Locations(ctx context.Context, in *pb.LocationsRequest, opts ...grpc.CallOption) (*pb.LocationsResponse, error) {
+	c.locationReads += len(in.LocationIds)
+	return c.MetastoreServiceClient.Locations(ctx, in, opts...)
+}
+
+func (c *countingClient) Functions(ctx context.Context, in *pb.FunctionsRequest, opts ...grpc.CallOption) (*pb.FunctionsResponse, error) {
+	c.functionReads += len(in.FunctionIds)
+	return c.MetastoreServiceClient.Functions(ctx, in, opts...)
+}
+
+func TestCachingMetastore(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingClient{MetastoreServiceClient: metastore.NewInProcessClient(newTestMetastore(t))}
+	m, err := metastore.NewCachingMetastore(inner, prometheus.NewRegistry(), metastore.WithLocationCacheSize(2))
+	require.NoError(t, err)
+
+	mres, err := m.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{Start: 0x1000, Limit: 0x2000, BuildId: "abc", File: "a.out"}},
+	})
+	require.NoError(t, err)
+	mappingID := mres.Mappings[0].Id
+
+	fres, err := m.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{
+		Functions: []*pb.Function{{Name: "main", SystemName: "main", Filename: "main.go"}},
+	})
+	require.NoError(t, err)
+	functionID := fres.Functions[0].Id
+
+	lres, err := m.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{Address: 0x1100, MappingId: mappingID},
+			{Address: 0x1200, MappingId: mappingID},
+			{Address: 0x1300, MappingId: mappingID},
+		},
+	})
+	require.NoError(t, err)
+	ids := []string{lres.Locations[0].Id, lres.Locations[1].Id}
+
+	res, err := m.Locations(ctx, &pb.LocationsRequest{LocationIds: ids})
+	require.NoError(t, err)
+	require.Len(t, res.Locations, 2)
+	require.Equal(t, 2, inner.locationReads)
+
+	// Repeated reads are served from the cache, in the requested order.
+	res, err = m.Locations(ctx, &pb.LocationsRequest{LocationIds: []string{ids[1], ids[0]}})
+	require.NoError(t, err)
+	require.Equal(t, ids[1], res.Locations[0].Id)
+	require.Equal(t, ids[0], res.Locations[1].Id)
+	require.Equal(t, 2, inner.locationReads)
+
+	for i := 0; i < 3; i++ {
+		_, err = m.Functions(ctx, &pb.FunctionsRequest{FunctionIds: []string{functionID}})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, inner.functionReads)
+
+	// Symbolizing a location evicts the stale entry.
+	symbolized := res.Locations[0]
+	_, err = m.CreateLocationLines(ctx, &pb.CreateLocationLinesRequest{
+		Locations: []*pb.Location{{
+			Id:        symbolized.Id,
+			Address:   symbolized.Address,
+			MappingId: symbolized.MappingId,
+			Lines:     []*pb.Line{{FunctionId: functionID, Line: 10}},
+		}},
+	})
+	require.NoError(t, err)
+
+	res, err = m.Locations(ctx, &pb.LocationsRequest{LocationIds: ids})
+	require.NoError(t, err)
+	require.Equal(t, 3, inner.locationReads)
+	require.Len(t, res.Locations[1].Lines, 1)
+	require.Equal(t, functionID, res.Locations[1].Lines[0].FunctionId)
+	require.Empty(t, res.Locations[0].Lines)
+
+	// The cache is bounded, reading a third location evicts the least
+	// recently used one.
+	_, err = m.Locations(ctx, &pb.LocationsRequest{LocationIds: []string{lres.Locations[2].Id}})
+	require.NoError(t, err)
+	require.Equal(t, 4, inner.locationReads)
+
+	_, err = m.Locations(ctx, &pb.LocationsRequest{LocationIds: ids})
+	require.NoError(t, err)
+	require.Equal(t, 5, inner.locationReads)
+}
@@ -68,3 +68,19 @@ func (c *InProcessClient) Mappings(ctx context.Context, in *pb.MappingsRequest,
 func (c *InProcessClient) Stacktraces(ctx context.Context, in *pb.StacktracesRequest, opts ...grpc.CallOption) (*pb.StacktracesResponse, error) {
 	return c.m.Stacktraces(ctx, in)
 }
+
+func (c *InProcessClient) ListLocations(ctx context.Context, in *pb.ListLocationsRequest, opts ...grpc.CallOption) (*pb.ListLocationsResponse, error) {
+	return c.m.ListLocations(ctx, in)
+}
+
+func (c *InProcessClient) ListFunctions(ctx context.Context, in *pb.ListFunctionsRequest, opts ...grpc.CallOption) (*pb.ListFunctionsResponse, error) {
+	return c.m.ListFunctions(ctx, in)
+}
+
+func (c *InProcessClient) LocationsByAddress(ctx context.Context, in *pb.LocationsByAddressRequest, opts ...grpc.CallOption) (*pb.LocationsByAddressResponse, error) {
+	return c.m.LocationsByAddress(ctx, in)
+}
+
+func (c *InProcessClient) SymbolizedLocations(ctx context.Context, in *pb.SymbolizedLocationsRequest, opts ...grpc.CallOption) (*pb.SymbolizedLocationsResponse, error) {
+	return c.m.SymbolizedLocations(ctx, in)
+}
@@ -17,6 +17,9 @@ import (
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
 
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
 )
@@ -37,6 +40,38 @@ func MakeLocationKeyWithID(locationID string) string {
 	return locationsKeyPrefix + locationID
 }
 
+// Locations are indexed by their mapping ID and address
+// `v1/locations/by-address/<mapping-id>/<hex-address>/<location-id>`. The
+// address is zero padded, so that the keys of a mapping sort by address.
+const locationsByAddressKeyPrefix = "v1/locations/by-address/"
+
+// MakeLocationAddressKey returns the key to be used to index the location by
+// its mapping ID and address.
+func MakeLocationAddressKey(l *pb.Location) string {
+	return locationAddressKeyPrefix(l.MappingId, l.Address) + l.Id
+}
+
+// locationAddressKeyPrefix returns the prefix of the address index keys of
+// the locations of the mapping at the address.
+func locationAddressKeyPrefix(mappingID string, address uint64) string {
+	return fmt.Sprintf("%s%s/%016x/", locationsByAddressKeyPrefix, mappingID, address)
+}
+
+// parseLocationAddressKey returns the address and the location ID of the
+// address index key of a location of the mapping.
+func parseLocationAddressKey(key, mappingID string) (uint64, string, error) {
+	rest := strings.TrimPrefix(key, locationsByAddressKeyPrefix+mappingID+"/")
+	hexAddress, locationID, ok := strings.Cut(rest, "/")
+	if !ok {
+		return 0, "", fmt.Errorf("invalid location address key %q", key)
+	}
+	address, err := strconv.ParseUint(hexAddress, 16, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid location address key %q: %w", key, err)
+	}
+	return address, locationID, nil
+}
+
 // Unsymbolized locations are namespaced by their mapping ID.
 // `v1/unsymbolized-locations/by-key/<hashed-mapping-key>/<hashed-location-key>`.
 const UnsymbolizedLocationLinesKeyPrefix = "v1/unsymbolized-locations/by-key/"
@@ -47,6 +82,43 @@ func MakeUnsymbolizedLocationKeyWithID(locationID string) string {
 	return UnsymbolizedLocationLinesKeyPrefix + locationID
 }
 
+// Locations symbolized by CreateLocationLines are indexed by the time they
+// were symbolized at
+// `v1/symbolized-locations/by-time/<hex-unix-nanos>/<location-id>`, and the
+// time of each of them is stored under
+// `v1/symbolized-locations/by-id/<location-id>`.
+const (
+	symbolizedLocationsByTimeKeyPrefix = "v1/symbolized-locations/by-time/"
+	symbolizedLocationsByIDKeyPrefix   = "v1/symbolized-locations/by-id/"
+)
+
+// makeSymbolizedLocationTimeKey returns the key indexing the location by the
+// time it was symbolized at.
+func makeSymbolizedLocationTimeKey(locationID string, symbolizedAt int64) string {
+	return fmt.Sprintf("%s%016x/%s", symbolizedLocationsByTimeKeyPrefix, uint64(symbolizedAt), locationID)
+}
+
+// makeSymbolizedLocationIDKey returns the key the time the location was
+// symbolized at is stored under.
+func makeSymbolizedLocationIDKey(locationID string) string {
+	return symbolizedLocationsByIDKeyPrefix + locationID
+}
+
+// parseSymbolizedLocationTimeKey returns the time in unix nanoseconds and the
+// location ID of the key indexing a location by its symbolization time.
+func parseSymbolizedLocationTimeKey(key string) (int64, string, error) {
+	rest := strings.TrimPrefix(key, symbolizedLocationsByTimeKeyPrefix)
+	hexTime, locationID, ok := strings.Cut(rest, "/")
+	if !ok {
+		return 0, "", fmt.Errorf("invalid symbolized location key %q", key)
+	}
+	t, err := strconv.ParseUint(hexTime, 16, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid symbolized location key %q: %w", key, err)
+	}
+	return int64(t), locationID, nil
+}
+
 // LocationIDFromUnsymbolizedKey returns the location ID portion of the provided key.
 func LocationIDFromUnsymbolizedKey(key string) string {
 	return key[len(UnsymbolizedLocationLinesKeyPrefix):]
@@ -59,10 +131,10 @@ func LocationIDFromKey(key string) string {
 
 // MakeLocationID returns a key for the location that uniquely identifies the
 // location. Locations are uniquely identified by their mapping ID and their
-// address and whether the address is folded. If a location address is 0, then
-// the lines are expected to be non empty and to be already resolved as they
-// cannot be asynchronously symbolized. The lines are then taken into the
-// location key.
+// address, whether the address is folded and whether it is a return address.
+// If a location address is 0, then the lines are expected to be non empty and
+// to be already resolved as they cannot be asynchronously symbolized. The
+// lines are then taken into the location key.
 func MakeLocationID(l *pb.Location) string {
 	hash := sha512.New512_256()
 
@@ -70,24 +142,13 @@ func MakeLocationID(l *pb.Location) string {
 
 	//nolint:errcheck // ignore error as writing to the hash will cannot error
 	binary.Write(hash, binary.BigEndian, l.Address)
+
+	// Folded locations used to have the same ID as unfolded ones, as writing
+	// an int to the hash fails without writing anything. The flag is only
+	// written for folded locations, so that existing IDs remain valid.
 	if l.IsFolded {
-		// If IsFolded is false this means automatically that these 8 bytes are
-		// 0. This works out well as the key is byte aligned to the nearest 8
-		// bytes that way.
-
-		//nolint:errcheck,staticcheck
-		// ignore the error as writing to the hash will not error
-		// https://staticcheck.io/docs/checks#SA1003
-		// The encoding/binary package can only serialize types with known sizes.
-		// This precludes the use of the int and uint types, as their sizes differ on different architectures.
-		// TODO: Fix this.
-		binary.Write(hash, binary.BigEndian, 1)
-	} else {
-		//nolint:errcheck,staticcheck
-		// ignore the error as writing to the hash will not error
-		// https://staticcheck.io/docs/checks#SA1003
-		// TODO: Fix this.
-		binary.Write(hash, binary.BigEndian, 0)
+		//nolint:errcheck // ignore error as writing to the hash will cannot error
+		binary.Write(hash, binary.BigEndian, uint64(1))
 	}
 
 	// If the address is 0, then the functions attached to the
@@ -104,6 +165,12 @@ func MakeLocationID(l *pb.Location) string {
 		}
 	}
 
+	// Only return addresses are hashed, so that the IDs of all other
+	// locations stay the same.
+	if l.ReturnAddress {
+		hash.Write([]byte("return-address"))
+	}
+
 	sum := hash.Sum(nil)
 	mappingId := l.MappingId
 	if mappingId == "" {
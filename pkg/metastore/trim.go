@@ -0,0 +1,215 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v3"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+)
+
+// DefaultTrimBatchSize is the number of entries deleted per transaction by
+// Trim if no batch size is given.
+const DefaultTrimBatchSize = 1000
+
+// TrimStats are the numbers of entries deleted by Trim.
+type TrimStats struct {
+	Stacktraces int
+	Locations   int
+	Functions   int
+}
+
+// Trim deletes the stacktraces that are not referenced anymore, along with
+// the locations and functions that none of the remaining stacktraces refer
+// to. Mappings are kept. The entries are deleted in transactions of up to
+// batchSize entries each, so a failure leaves the metastore trimmed
+// partially, but consistent.
+//
+// Stacktraces created while Trim runs may be deleted if they are not
+// referenced, so the caller has to make sure none are created.
+func (m *BadgerMetastore) Trim(ctx context.Context, referenced map[string]struct{}, batchSize int) (TrimStats, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultTrimBatchSize
+	}
+
+	var (
+		stats               TrimStats
+		stacktraceKeys      [][]byte
+		locations           []*pb.Location
+		functionKeys        [][]byte
+		referencedLocations = map[string]struct{}{}
+		referencedFunctions = map[string]struct{}{}
+	)
+	err := m.db.View(func(txn *badger.Txn) error {
+		err := iterate(txn, stacktraceKeyPrefix, true, func(item *badger.Item) error {
+			key := item.KeyCopy(nil)
+			if _, ok := referenced[StacktraceIDFromKey(string(key))]; !ok {
+				stacktraceKeys = append(stacktraceKeys, key)
+				return nil
+			}
+
+			return item.Value(func(val []byte) error {
+				s := &pb.Stacktrace{}
+				if err := s.UnmarshalVT(val); err != nil {
+					return err
+				}
+				for _, id := range s.LocationIds {
+					referencedLocations[id] = struct{}{}
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("stacktraces: %w", err)
+		}
+
+		err = iterate(txn, locationsKeyPrefix, true, func(item *badger.Item) error {
+			return item.Value(func(val []byte) error {
+				l := &pb.Location{}
+				if err := l.UnmarshalVT(val); err != nil {
+					return err
+				}
+				l.Id = LocationIDFromKey(string(item.Key()))
+				if _, ok := referencedLocations[l.Id]; !ok {
+					locations = append(locations, l)
+					return nil
+				}
+				for _, line := range l.Lines {
+					referencedFunctions[line.FunctionId] = struct{}{}
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("locations: %w", err)
+		}
+
+		err = iterate(txn, functionKeyPrefix, false, func(item *badger.Item) error {
+			key := item.KeyCopy(nil)
+			if _, ok := referencedFunctions[FunctionIDFromKey(string(key))]; !ok {
+				functionKeys = append(functionKeys, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("functions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	// Stacktraces are deleted before the locations they refer to, and
+	// locations before the functions their lines refer to, so that no
+	// remaining entry refers to a deleted one if trimming is interrupted.
+	err = m.deleteInBatches(ctx, len(stacktraceKeys), batchSize, func(txn *badger.Txn, i int) error {
+		return txn.Delete(stacktraceKeys[i])
+	})
+	if err != nil {
+		return stats, fmt.Errorf("delete stacktraces: %w", err)
+	}
+	stats.Stacktraces = len(stacktraceKeys)
+
+	err = m.deleteInBatches(ctx, len(locations), batchSize, func(txn *badger.Txn, i int) error {
+		return deleteLocation(txn, locations[i])
+	})
+	if err != nil {
+		return stats, fmt.Errorf("delete locations: %w", err)
+	}
+	stats.Locations = len(locations)
+
+	err = m.deleteInBatches(ctx, len(functionKeys), batchSize, func(txn *badger.Txn, i int) error {
+		return txn.Delete(functionKeys[i])
+	})
+	if err != nil {
+		return stats, fmt.Errorf("delete functions: %w", err)
+	}
+	stats.Functions = len(functionKeys)
+
+	return stats, nil
+}
+
+// iterate calls f with every item whose key has the prefix.
+func iterate(txn *badger.Txn, prefix string, values bool, f func(item *badger.Item) error) error {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = values
+	opts.Prefix = []byte(prefix)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		if err := f(it.Item()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteInBatches calls del for n entries, in transactions of up to
+// batchSize entries each.
+func (m *BadgerMetastore) deleteInBatches(ctx context.Context, n, batchSize int, del func(txn *badger.Txn, i int) error) error {
+	for start := 0; start < n; start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + batchSize
+		if end > n {
+			end = n
+		}
+		err := m.db.Update(func(txn *badger.Txn) error {
+			for i := start; i < end; i++ {
+				if err := del(txn, i); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteLocation deletes the location along with the keys indexing it.
+func deleteLocation(txn *badger.Txn, l *pb.Location) error {
+	symbolizedAt, err := symbolizedLocationTime(txn, l.Id)
+	if err != nil {
+		return err
+	}
+	if symbolizedAt != nil {
+		if err := txn.Delete([]byte(makeSymbolizedLocationTimeKey(l.Id, *symbolizedAt))); err != nil {
+			return err
+		}
+		if err := txn.Delete([]byte(makeSymbolizedLocationIDKey(l.Id))); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range []string{
+		MakeUnsymbolizedLocationKeyWithID(l.Id),
+		MakeLocationAddressKey(l),
+		MakeLocationKeyWithID(l.Id),
+	} {
+		if err := txn.Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ type Testing interface {
 	require.TestingT
 	Helper()
 	Name() string
+	Cleanup(func())
 }
 
 func NewTestMetastore(
@@ -45,6 +46,7 @@ func NewTestMetastore(
 			WithLogger(&metastore.BadgerLogger{Logger: logger}),
 	)
 	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
 
 	return metastore.NewBadgerMetastore(
 		logger,
@@ -0,0 +1,226 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objstoretest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/thanos-io/objstore"
+)
+
+// ErrInjected is returned by the operations a fault is injected into, unless
+// the fault has an error of its own.
+var ErrInjected = errors.New("injected bucket error")
+
+// Op is an operation of a bucket.
+type Op string
+
+const (
+	OpIter       Op = "iter"
+	OpGet        Op = "get"
+	OpGetRange   Op = "get_range"
+	OpExists     Op = "exists"
+	OpAttributes Op = "attributes"
+	OpUpload     Op = "upload"
+	OpDelete     Op = "delete"
+)
+
+// Fault is injected into the operations of a bucket.
+type Fault struct {
+	// Op is the operation the fault is injected into.
+	Op Op
+	// Match restricts the fault to the objects or directories whose name it
+	// returns true for. All of them match if it is nil.
+	Match func(name string) bool
+
+	// Latency delays the operation, unless its context is done first.
+	Latency time.Duration
+
+	// Err is returned instead of performing the operation, ErrInjected if
+	// nil. Faults with only a latency set don't fail, any other fault does.
+	Err error
+	// Rate is the fraction of the matching calls that fail, all of them if
+	// zero.
+	Rate float64
+	// Times is the number of calls that fail, after which the fault only
+	// delays the operation. The calls fail indefinitely if it is zero.
+	Times int
+}
+
+func (f *Fault) fails() bool {
+	return f.Err != nil || f.Rate > 0 || f.Times > 0 || f.Latency == 0
+}
+
+type fault struct {
+	Fault
+	failed int
+}
+
+// Bucket is an in-memory bucket that the faults of tests are injected into,
+// and that counts the calls of its operations.
+type Bucket struct {
+	objstore.Bucket
+
+	mtx    sync.Mutex
+	faults []*fault
+	calls  map[Op]map[string]int
+	rand   *rand.Rand
+}
+
+// NewBucket returns an empty bucket without faults. Faults with an error rate
+// fail the same calls for the same seed.
+func NewBucket(seed int64) *Bucket {
+	return &Bucket{
+		Bucket: objstore.NewInMemBucket(),
+		calls:  map[Op]map[string]int{},
+		rand:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Inject adds the fault to the bucket. The latencies of all faults matching a
+// call add up, and the first one failing it decides its error.
+func (b *Bucket) Inject(f Fault) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.faults = append(b.faults, &fault{Fault: f})
+}
+
+// Reset removes all faults from the bucket, and resets the call counts.
+func (b *Bucket) Reset() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.faults = nil
+	b.calls = map[Op]map[string]int{}
+}
+
+// Calls returns the number of calls of the operation, whatever object or
+// directory they were for.
+func (b *Bucket) Calls(op Op) int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	n := 0
+	for _, c := range b.calls[op] {
+		n += c
+	}
+	return n
+}
+
+// ObjectCalls returns the number of calls of the operation for the object or
+// directory with the given name.
+func (b *Bucket) ObjectCalls(op Op, name string) int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.calls[op][name]
+}
+
+// call counts the call of the operation, and applies the faults matching it.
+func (b *Bucket) call(ctx context.Context, op Op, name string) error {
+	b.mtx.Lock()
+	if b.calls[op] == nil {
+		b.calls[op] = map[string]int{}
+	}
+	b.calls[op][name]++
+
+	var (
+		latency time.Duration
+		err     error
+	)
+	for _, f := range b.faults {
+		if f.Op != op || (f.Match != nil && !f.Match(name)) {
+			continue
+		}
+		latency += f.Latency
+		if err != nil || !f.fails() || (f.Times > 0 && f.failed >= f.Times) {
+			continue
+		}
+		if f.Rate > 0 && b.rand.Float64() >= f.Rate {
+			continue
+		}
+		f.failed++
+		err = f.Err
+		if err == nil {
+			err = ErrInjected
+		}
+	}
+	b.mtx.Unlock()
+
+	if latency > 0 {
+		t := time.NewTimer(latency)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+func (b *Bucket) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	if err := b.call(ctx, OpIter, dir); err != nil {
+		return err
+	}
+	return b.Bucket.Iter(ctx, dir, f, options...)
+}
+
+func (b *Bucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	if err := b.call(ctx, OpGet, name); err != nil {
+		return nil, err
+	}
+	return b.Bucket.Get(ctx, name)
+}
+
+func (b *Bucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	if err := b.call(ctx, OpGetRange, name); err != nil {
+		return nil, err
+	}
+	return b.Bucket.GetRange(ctx, name, off, length)
+}
+
+func (b *Bucket) Exists(ctx context.Context, name string) (bool, error) {
+	if err := b.call(ctx, OpExists, name); err != nil {
+		return false, err
+	}
+	return b.Bucket.Exists(ctx, name)
+}
+
+func (b *Bucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	if err := b.call(ctx, OpAttributes, name); err != nil {
+		return objstore.ObjectAttributes{}, err
+	}
+	return b.Bucket.Attributes(ctx, name)
+}
+
+func (b *Bucket) Upload(ctx context.Context, name string, r io.Reader) error {
+	if err := b.call(ctx, OpUpload, name); err != nil {
+		return err
+	}
+	return b.Bucket.Upload(ctx, name, r)
+}
+
+func (b *Bucket) Delete(ctx context.Context, name string) error {
+	if err := b.call(ctx, OpDelete, name); err != nil {
+		return err
+	}
+	return b.Bucket.Delete(ctx, name)
+}
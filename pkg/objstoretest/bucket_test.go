@@ -0,0 +1,127 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objstoretest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketCountsCalls(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucket(0)
+
+	require.NoError(t, b.Upload(ctx, "a/obj", strings.NewReader("content")))
+	for i := 0; i < 2; i++ {
+		r, err := b.Get(ctx, "a/obj")
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "content", string(content))
+	}
+	_, err := b.Get(ctx, "b/obj")
+	require.True(t, b.IsObjNotFoundErr(err))
+
+	require.Equal(t, 1, b.Calls(OpUpload))
+	require.Equal(t, 3, b.Calls(OpGet))
+	require.Equal(t, 2, b.ObjectCalls(OpGet, "a/obj"))
+	require.Equal(t, 0, b.Calls(OpDelete))
+
+	b.Reset()
+	require.Equal(t, 0, b.Calls(OpGet))
+}
+
+func TestBucketInjectsErrors(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucket(0)
+	require.NoError(t, b.Upload(ctx, "a/obj", strings.NewReader("content")))
+	require.NoError(t, b.Upload(ctx, "b/obj", strings.NewReader("content")))
+
+	errUnavailable := errors.New("unavailable")
+	b.Inject(Fault{
+		Op:    OpGet,
+		Match: func(name string) bool { return strings.HasPrefix(name, "a/") },
+		Err:   errUnavailable,
+		Times: 2,
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := b.Get(ctx, "a/obj")
+		require.ErrorIs(t, err, errUnavailable)
+	}
+	_, err := b.Get(ctx, "a/obj")
+	require.NoError(t, err)
+	_, err = b.Get(ctx, "b/obj")
+	require.NoError(t, err)
+
+	// Other operations aren't affected.
+	exists, err := b.Exists(ctx, "a/obj")
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func TestBucketInjectsErrorRate(t *testing.T) {
+	ctx := context.Background()
+
+	failures := func(seed int64) []bool {
+		b := NewBucket(seed)
+		b.Inject(Fault{Op: OpExists, Rate: 0.5})
+
+		var failed []bool
+		for i := 0; i < 100; i++ {
+			_, err := b.Exists(ctx, "obj")
+			if err != nil {
+				require.ErrorIs(t, err, ErrInjected)
+			}
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	failed := failures(1)
+	n := 0
+	for _, f := range failed {
+		if f {
+			n++
+		}
+	}
+	require.Greater(t, n, 25)
+	require.Less(t, n, 75)
+
+	// The same calls fail for the same seed.
+	require.Equal(t, failed, failures(1))
+}
+
+func TestBucketInjectsLatency(t *testing.T) {
+	b := NewBucket(0)
+	b.Inject(Fault{Op: OpExists, Latency: 50 * time.Millisecond})
+
+	start := time.Now()
+	_, err := b.Exists(context.Background(), "obj")
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// The latency is cut short by the context.
+	b.Inject(Fault{Op: OpExists, Latency: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = b.Exists(ctx, "obj")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
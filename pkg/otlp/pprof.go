@@ -0,0 +1,297 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+
+	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
+	profilespb "github.com/parca-dev/parca/gen/proto/go/opentelemetry/proto/profiles/v1development"
+)
+
+// The mapping attributes holding the build ID of the mapped file, in order
+// of preference.
+var buildIDAttributes = []string{
+	"process.executable.build_id.gnu",
+	"process.executable.build_id.go",
+	"process.executable.build_id.htlhash",
+}
+
+// ToPprof converts an OTLP profile into a pprof profile. The tables of the
+// OTLP profile are indexed from zero, so the IDs of the pprof mappings,
+// locations and functions are their index plus one. String and integer
+// sample attributes become pprof labels.
+func ToPprof(p *profilespb.Profile) (*pprofpb.Profile, error) {
+	if len(p.StringTable) == 0 || p.StringTable[0] != "" {
+		return nil, errors.New("string table must start with the empty string")
+	}
+
+	c := &converter{
+		in: p,
+		out: &pprofpb.Profile{
+			StringTable:   append([]string(nil), p.StringTable...),
+			TimeNanos:     p.TimeNanos,
+			DurationNanos: p.DurationNanos,
+			Period:        p.Period,
+		},
+		strings: make(map[string]int64, len(p.StringTable)),
+	}
+	for i, s := range p.StringTable {
+		if _, ok := c.strings[s]; !ok {
+			c.strings[s] = int64(i)
+		}
+	}
+
+	if err := c.convert(); err != nil {
+		return nil, err
+	}
+	return c.out, nil
+}
+
+type converter struct {
+	in  *profilespb.Profile
+	out *pprofpb.Profile
+
+	// strings indexes the pprof string table by string, to add the keys of
+	// attributes to it.
+	strings map[string]int64
+}
+
+func (c *converter) convert() error {
+	var err error
+
+	for _, t := range c.in.SampleType {
+		vt, err := c.valueType(t)
+		if err != nil {
+			return fmt.Errorf("sample type: %w", err)
+		}
+		c.out.SampleType = append(c.out.SampleType, vt)
+	}
+	if c.in.PeriodType != nil {
+		if c.out.PeriodType, err = c.valueType(c.in.PeriodType); err != nil {
+			return fmt.Errorf("period type: %w", err)
+		}
+	}
+	if c.out.DefaultSampleType, err = c.stringIndex(c.in.DefaultSampleTypeStrindex); err != nil {
+		return fmt.Errorf("default sample type: %w", err)
+	}
+	for _, i := range c.in.CommentStrindices {
+		s, err := c.stringIndex(i)
+		if err != nil {
+			return fmt.Errorf("comment: %w", err)
+		}
+		c.out.Comment = append(c.out.Comment, s)
+	}
+
+	for i, m := range c.in.MappingTable {
+		mapping, err := c.mapping(m)
+		if err != nil {
+			return fmt.Errorf("mapping %d: %w", i, err)
+		}
+		mapping.Id = uint64(i + 1)
+		c.out.Mapping = append(c.out.Mapping, mapping)
+	}
+	for i, f := range c.in.FunctionTable {
+		function, err := c.function(f)
+		if err != nil {
+			return fmt.Errorf("function %d: %w", i, err)
+		}
+		function.Id = uint64(i + 1)
+		c.out.Function = append(c.out.Function, function)
+	}
+	for i, l := range c.in.LocationTable {
+		location, err := c.location(l)
+		if err != nil {
+			return fmt.Errorf("location %d: %w", i, err)
+		}
+		location.Id = uint64(i + 1)
+		c.out.Location = append(c.out.Location, location)
+	}
+	for i, s := range c.in.Sample {
+		sample, err := c.sample(s)
+		if err != nil {
+			return fmt.Errorf("sample %d: %w", i, err)
+		}
+		c.out.Sample = append(c.out.Sample, sample)
+	}
+
+	return nil
+}
+
+func (c *converter) valueType(t *profilespb.ValueType) (*pprofpb.ValueType, error) {
+	typ, err := c.stringIndex(t.TypeStrindex)
+	if err != nil {
+		return nil, err
+	}
+	unit, err := c.stringIndex(t.UnitStrindex)
+	if err != nil {
+		return nil, err
+	}
+	return &pprofpb.ValueType{Type: typ, Unit: unit}, nil
+}
+
+func (c *converter) mapping(m *profilespb.Mapping) (*pprofpb.Mapping, error) {
+	filename, err := c.stringIndex(m.FilenameStrindex)
+	if err != nil {
+		return nil, err
+	}
+
+	var buildID int64
+	for _, key := range buildIDAttributes {
+		for _, i := range m.AttributeIndices {
+			attr, err := c.attribute(i)
+			if err != nil {
+				return nil, err
+			}
+			if attr.Key == key && attr.Value.GetStringValue() != "" {
+				buildID = c.string(attr.Value.GetStringValue())
+				break
+			}
+		}
+		if buildID != 0 {
+			break
+		}
+	}
+
+	return &pprofpb.Mapping{
+		MemoryStart:     m.MemoryStart,
+		MemoryLimit:     m.MemoryLimit,
+		FileOffset:      m.FileOffset,
+		Filename:        filename,
+		BuildId:         buildID,
+		HasFunctions:    m.HasFunctions,
+		HasFilenames:    m.HasFilenames,
+		HasLineNumbers:  m.HasLineNumbers,
+		HasInlineFrames: m.HasInlineFrames,
+	}, nil
+}
+
+func (c *converter) function(f *profilespb.Function) (*pprofpb.Function, error) {
+	name, err := c.stringIndex(f.NameStrindex)
+	if err != nil {
+		return nil, err
+	}
+	systemName, err := c.stringIndex(f.SystemNameStrindex)
+	if err != nil {
+		return nil, err
+	}
+	filename, err := c.stringIndex(f.FilenameStrindex)
+	if err != nil {
+		return nil, err
+	}
+	return &pprofpb.Function{
+		Name:       name,
+		SystemName: systemName,
+		Filename:   filename,
+		StartLine:  f.StartLine,
+	}, nil
+}
+
+func (c *converter) location(l *profilespb.Location) (*pprofpb.Location, error) {
+	location := &pprofpb.Location{
+		Address:  l.Address,
+		IsFolded: l.IsFolded,
+	}
+	if l.MappingIndex != nil {
+		i := *l.MappingIndex
+		if i < 0 || int(i) >= len(c.in.MappingTable) {
+			return nil, fmt.Errorf("mapping index %d out of range", i)
+		}
+		location.MappingId = uint64(i + 1)
+	}
+	for _, line := range l.Line {
+		i := line.FunctionIndex
+		if i < 0 || int(i) >= len(c.in.FunctionTable) {
+			return nil, fmt.Errorf("function index %d out of range", i)
+		}
+		location.Line = append(location.Line, &pprofpb.Line{
+			FunctionId: uint64(i + 1),
+			Line:       line.Line,
+		})
+	}
+	return location, nil
+}
+
+func (c *converter) sample(s *profilespb.Sample) (*pprofpb.Sample, error) {
+	start, length := s.LocationsStartIndex, s.LocationsLength
+	if start < 0 || length < 0 || int(start)+int(length) > len(c.in.LocationIndices) {
+		return nil, fmt.Errorf("locations [%d, %d) out of range", start, int(start)+int(length))
+	}
+
+	sample := &pprofpb.Sample{
+		LocationId: make([]uint64, 0, length),
+		Value:      s.Value,
+	}
+	for _, i := range c.in.LocationIndices[start : start+length] {
+		if i < 0 || int(i) >= len(c.in.LocationTable) {
+			return nil, fmt.Errorf("location index %d out of range", i)
+		}
+		sample.LocationId = append(sample.LocationId, uint64(i+1))
+	}
+
+	for _, i := range s.AttributeIndices {
+		attr, err := c.attribute(i)
+		if err != nil {
+			return nil, err
+		}
+
+		label := &pprofpb.Label{Key: c.string(attr.Key)}
+		switch v := attr.Value.GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			label.Str = c.string(v.StringValue)
+		case *commonpb.AnyValue_BoolValue:
+			label.Str = c.string(strconv.FormatBool(v.BoolValue))
+		case *commonpb.AnyValue_IntValue:
+			label.Num = v.IntValue
+		case *commonpb.AnyValue_DoubleValue:
+			label.Str = c.string(strconv.FormatFloat(v.DoubleValue, 'g', -1, 64))
+		default:
+			// Arrays, key-value lists and bytes have no pprof equivalent.
+			continue
+		}
+		sample.Label = append(sample.Label, label)
+	}
+
+	return sample, nil
+}
+
+func (c *converter) attribute(i int32) (*commonpb.KeyValue, error) {
+	if i < 0 || int(i) >= len(c.in.AttributeTable) {
+		return nil, fmt.Errorf("attribute index %d out of range", i)
+	}
+	return c.in.AttributeTable[i], nil
+}
+
+func (c *converter) stringIndex(i int32) (int64, error) {
+	if i < 0 || int(i) >= len(c.in.StringTable) {
+		return 0, fmt.Errorf("string index %d out of range", i)
+	}
+	return int64(i), nil
+}
+
+// string returns the index of the string in the pprof string table, adding
+// it if it isn't in there yet.
+func (c *converter) string(s string) int64 {
+	if i, ok := c.strings[s]; ok {
+		return i
+	}
+	i := int64(len(c.out.StringTable))
+	c.out.StringTable = append(c.out.StringTable, s)
+	c.strings[s] = i
+	return i
+}
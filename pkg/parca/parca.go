@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	goruntime "runtime"
 	"strings"
@@ -51,34 +52,35 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"gopkg.in/yaml.v2"
 
-	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
 	metastorepb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
-	profilestorepb "github.com/parca-dev/parca/gen/proto/go/parca/profilestore/v1alpha1"
-	querypb "github.com/parca-dev/parca/gen/proto/go/parca/query/v1alpha1"
-	scrapepb "github.com/parca-dev/parca/gen/proto/go/parca/scrape/v1alpha1"
 	sharepb "github.com/parca-dev/parca/gen/proto/go/share"
 	"github.com/parca-dev/parca/pkg/config"
 	"github.com/parca-dev/parca/pkg/debuginfo"
 	"github.com/parca-dev/parca/pkg/metastore"
 	"github.com/parca-dev/parca/pkg/parcacol"
+	"github.com/parca-dev/parca/pkg/prober"
 	"github.com/parca-dev/parca/pkg/profilestore"
 	queryservice "github.com/parca-dev/parca/pkg/query"
 	"github.com/parca-dev/parca/pkg/scrape"
 	"github.com/parca-dev/parca/pkg/server"
+	"github.com/parca-dev/parca/pkg/signedupload"
 	"github.com/parca-dev/parca/pkg/symbol"
+	"github.com/parca-dev/parca/pkg/symbol/demangle"
 	"github.com/parca-dev/parca/pkg/symbolizer"
 )
 
 const (
-	symbolizationInterval = 10 * time.Second
-	flagModeScraperOnly   = "scraper-only"
-	metaStoreBadger       = "badger"
+	healthCheckInterval = 10 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+	flagModeScraperOnly = "scraper-only"
+	metaStoreBadger     = "badger"
 )
 
 type Flags struct {
 	ConfigPath         string   `default:"parca.yaml" help:"Path to config file."`
 	Mode               string   `default:"all" enum:"all,scraper-only" help:"Scraper only runs a scraper that sends to a remote gRPC endpoint. All runs all components."`
 	LogLevel           string   `default:"info" enum:"error,warn,info,debug" help:"log level."`
+	LogFormat          string   `default:"logfmt" enum:"logfmt,json" help:"Format of the log lines, logfmt or json."`
 	Port               string   `default:":7070" help:"Port string for server"`
 	CORSAllowedOrigins []string `help:"Allowed CORS origins."`
 	OTLPAddress        string   `help:"OpenTelemetry collector address to send traces to."`
@@ -90,14 +92,43 @@ type Flags struct {
 
 	EnablePersistence bool `default:"false" help:"Turn on persistent storage for the metastore and profile storage."`
 
-	StorageDebugValueLog bool   `default:"false" help:"Log every value written to the database into a separate file. This is only for debugging purposes to produce data to replay situations in tests."`
-	StorageGranuleSize   int    `default:"8196" help:"Granule size for storage."`
-	StorageActiveMemory  int64  `default:"536870912" help:"Amount of memory to use for active storage. Defaults to 512MB."`
-	StoragePath          string `default:"data" help:"Path to storage directory."`
-	StorageEnableWAL     bool   `default:"false" help:"Enables write ahead log for profile storage."`
-
-	SymbolizerDemangleMode  string `default:"simple" help:"Mode to demangle C++ symbols. Default mode is simplified: no parameters, no templates, no return type" enum:"simple,full,none,templates"`
-	SymbolizerNumberOfTries int    `default:"3" help:"Number of tries to attempt to symbolize an unsybolized location"`
+	StorageDebugValueLog      bool          `default:"false" help:"Log every value written to the database into a separate file. This is only for debugging purposes to produce data to replay situations in tests."`
+	StorageGranuleSize        int           `default:"8196" help:"Granule size for storage."`
+	StorageActiveMemory       int64         `default:"536870912" help:"Amount of memory to use for active storage. Defaults to 512MB."`
+	StoragePath               string        `default:"data" help:"Path to storage directory."`
+	StorageEnableWAL          bool          `default:"false" help:"Enables write ahead log for profile storage."`
+	StorageMaxSampleSize      int64         `default:"67108864" help:"Maximum size in bytes of a single written profile, compressed and decompressed. Defaults to 64MB. 0 means unlimited."`
+	StorageMaxRequestSize     int64         `default:"268435456" help:"Maximum total size in bytes of the profiles of a single write request, compressed and decompressed. Defaults to 256MB. 0 means unlimited."`
+	StorageMaxPendingWrites   int           `default:"256" help:"Maximum number of write requests that are ingested at the same time. Further write requests are rejected as unavailable until one of them completes. 0 means unlimited."`
+	StorageDownsampleInterval time.Duration `default:"0" help:"Interval in which at most one profile per series is stored, the first one written with a timestamp in it. Further profiles of the series within the interval are dropped. 0 stores all profiles."`
+	StorageDeduplicateMaxAge  time.Duration `default:"0" help:"Maximum age of the previous profile of a series that identical profiles are stored as a reference to instead of in full. Has to be shorter than the retention. 0 stores all profiles in full."`
+
+	StorageArchiveRawProfiles bool   `default:"false" help:"Archive every ingested profile as it was written in the object storage, so that it can be backfilled later."`
+	StorageBackfillFrom       string `default:"" help:"Start of the time range in RFC 3339 format, e.g. 2022-10-01T00:00:00Z, of the archived profiles to ingest again at startup, e.g. after a change of the storage representation. Requires --storage-backfill-to."`
+	StorageBackfillTo         string `default:"" help:"End of the time range in RFC 3339 format of the archived profiles to ingest again at startup, excluding it."`
+
+	StorageRetention              time.Duration `default:"0" help:"Duration after which persisted profile data is deleted. Only applies when persistence is enabled. 0 disables retention."`
+	StorageRetentionInterval      time.Duration `default:"5m" help:"Interval in which the storage retention is enforced."`
+	StorageRetentionTrimBatchSize int           `default:"1000" help:"Maximum number of metastore entries deleted per transaction when trimming the metastore after the storage retention deleted profile data."`
+
+	SymbolizerDemangleMode      string   `default:"simple" help:"Mode to demangle C++ and Rust symbols. Default mode is simplified: no parameters, no templates, no return type. Use none to keep the raw symbol names." enum:"simple,full,none,templates"`
+	SymbolizerDemanglers        []string `default:"rust,cpp,d" help:"Demanglers to try in order on symbol names, the first one recognizing a name is used. Names that none recognizes are kept as they are. Available demanglers: rust, cpp, d."`
+	SymbolizerPathRewrites      []string `help:"Rewrites of the source file path prefixes of symbolized functions in the form from=to, e.g. /home/user/src=/repo. The first one matching a path is applied."`
+	SymbolizerNumberOfTries     int      `default:"3" help:"Number of tries to attempt to symbolize an unsybolized location"`
+	SymbolizerCacheSize         int      `default:"1000" help:"Maximum number of opened debug information files to keep cached for symbolization."`
+	SymbolizerCacheMaxBytes     int64    `default:"0" help:"Maximum total size in bytes of the debug information files kept cached for symbolization. 0 means unlimited."`
+	SymbolizerLinesCacheSize    int      `default:"100000" help:"Maximum number of addresses whose symbolized lines are kept cached, so that they are not symbolized again. 0 disables the cache."`
+	SymbolizerNoDWARFBufferPool bool     `default:"false" help:"Disable reusing the buffers that DWARF debug information is read with across object files. Reusing them reduces the allocations of symbolization."`
+
+	SymbolizerInterval            time.Duration `default:"10s" help:"Interval in which unsymbolized locations are symbolized."`
+	SymbolizerBatchSize           uint32        `default:"0" help:"Maximum number of unsymbolized locations to symbolize per interval. 0 means unlimited."`
+	SymbolizerMissingDebuginfoTTL time.Duration `default:"10m" help:"Duration to wait before looking for debug information again that was found to be missing, unless it is uploaded."`
+	SymbolizerConcurrency         int           `default:"4" help:"Number of object files to symbolize in parallel."`
+	SymbolizerFetchConcurrency    int           `default:"2" help:"Maximum number of debug information files to fetch in parallel for symbolization."`
+	SymbolizerParseTimeout        time.Duration `default:"1m" help:"Maximum duration of reading the debug information of an object file for symbolization, after which it is skipped. 0 means no timeout."`
+	SymbolizerReverifyAge         time.Duration `default:"0" help:"Age after which symbolized locations are symbolized again, and their lines replaced if they changed. 0 disables re-verification."`
+	SymbolizerReverifyBatchSize   uint32        `default:"100" help:"Maximum number of symbolized locations to re-verify per interval, only in intervals without unsymbolized locations left. 0 means unlimited."`
+	SymbolizerMaxLinesPerLocation int           `default:"0" help:"Maximum number of inlined lines to store per location, the innermost ones beyond it are dropped. 0 means unlimited."`
 
 	Metastore string `default:"badger" help:"Which metastore implementation to use" enum:"badger"`
 
@@ -106,6 +137,24 @@ type Flags struct {
 	DebugInfodUpstreamServers    []string      `default:"https://debuginfod.elfutils.org" help:"Upstream debuginfod servers. Defaults to https://debuginfod.elfutils.org. It is an ordered list of servers to try. Learn more at https://sourceware.org/elfutils/Debuginfod.html"`
 	DebugInfodHTTPRequestTimeout time.Duration `default:"5m" help:"Timeout duration for HTTP request to upstream debuginfod server. Defaults to 5m"`
 	DebuginfoCacheDir            string        `default:"/tmp" help:"Path to directory where debuginfo is cached."`
+	DebuginfoFetchMaxRetries     int           `default:"3" help:"Number of times fetching debuginfo from object storage is retried on transient errors."`
+	DebuginfoFetchRetryBaseDelay time.Duration `default:"100ms" help:"Initial delay between retries of fetching debuginfo from object storage. Grows exponentially."`
+	DebuginfoFetchRetryMaxDelay  time.Duration `default:"5s" help:"Maximum delay between retries of fetching debuginfo from object storage."`
+	DebuginfoBucketPrefix        string        `default:"" help:"Prefix of the keys of all debuginfo objects in object storage, e.g. to share the bucket with other data. Defaults to the root of the bucket."`
+
+	DebuginfoDownloadTempDir     string `default:"" help:"Path to directory where debuginfo is downloaded to before it is moved to the cache directory. Must be on the same filesystem as the cache directory. Defaults to the cache directory."`
+	DebuginfoDownloadTempMaxSize int64  `default:"0" help:"Maximum total size in bytes of the debuginfo being downloaded. Downloads are refused while it is exceeded. 0 means unlimited."`
+
+	DebuginfoExistsCacheTTL         time.Duration `default:"1m" help:"How long it is remembered that debuginfo exists in object storage for a build ID. 0 disables caching."`
+	DebuginfoExistsCacheNegativeTTL time.Duration `default:"30s" help:"How long it is remembered that no debuginfo exists in object storage for a build ID. 0 disables caching."`
+
+	DebuginfoUploadAllowMissingBuildID bool          `default:"false" help:"Accept uploaded debuginfo that has no GNU build ID note to verify the claimed build ID against."`
+	DebuginfoUploadCompression         string        `default:"none" enum:"none,gzip,zstd" help:"Compression of uploaded debuginfo in object storage. Previously stored debuginfo is read regardless of its compression."`
+	DebuginfoUploadMaxSize             int64         `default:"0" help:"Maximum size in bytes of an uploaded debuginfo file. 0 means unlimited."`
+	DebuginfoUploadInterval            time.Duration `default:"0" help:"Minimum time between two uploads of debuginfo for the same build ID. 0 means unlimited."`
+
+	DebuginfoUploadsSignedURL       bool          `default:"false" help:"Whether to let clients upload debuginfo directly to the object storage with signed URLs. Only supported by GCS and S3 object storage, others fall back to uploads with gRPC."`
+	DebuginfoUploadsSignedURLExpiry time.Duration `default:"15m" help:"How long the signed URLs to upload debuginfo with are valid for."`
 
 	StoreAddress       string            `kong:"help='gRPC address to send profiles and symbols to.'"`
 	BearerToken        string            `kong:"help='Bearer token to authenticate with store.'"`
@@ -159,7 +208,12 @@ func Run(ctx context.Context, logger log.Logger, reg *prometheus.Registry, flags
 		return err
 	}
 
-	var mStr metastorepb.MetastoreServiceServer
+	health := prober.NewHealth(logger, healthCheckTimeout)
+
+	var (
+		mStr        metastorepb.MetastoreServiceServer
+		badgerStore *metastore.BadgerMetastore
+	)
 	switch flags.Metastore {
 	case metaStoreBadger:
 		var badgerOptions badger.Options
@@ -176,20 +230,32 @@ func Run(ctx context.Context, logger log.Logger, reg *prometheus.Registry, flags
 			level.Error(logger).Log("msg", "failed to open badger database for metastore", "err", err)
 			return err
 		}
+		// Closed once all components using the metastore returned.
+		defer func() {
+			if err := db.Close(); err != nil {
+				level.Error(logger).Log("msg", "error closing badger database for metastore", "err", err)
+			}
+		}()
 
-		mStr = metastore.NewBadgerMetastore(
+		badgerStore = metastore.NewBadgerMetastore(
 			logger,
 			reg,
 			tracerProvider.Tracer(metaStoreBadger),
 			db,
 		)
+		health.AddCheck("metastore", badgerStore.Ping)
+		mStr = badgerStore
 	default:
 		err := fmt.Errorf("unknown metastore implementation: %s", flags.Metastore)
 		level.Error(logger).Log("msg", "failed to initialize metastore", "err", err)
 		return err
 	}
 
-	metastore := metastore.NewInProcessClient(mStr)
+	metastore, err := metastore.NewCachingMetastore(metastore.NewInProcessClient(mStr), reg)
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to initialize metastore cache", "err", err)
+		return err
+	}
 
 	frostdbOptions := []frostdb.Option{
 		frostdb.WithGranuleSize(flags.StorageGranuleSize),
@@ -237,14 +303,58 @@ func Run(ctx context.Context, logger log.Logger, reg *prometheus.Registry, flags
 		return err
 	}
 
+	// The metastore entries only referenced by samples deleted by retention
+	// are trimmed after it.
+	var trimmer *parcacol.Trimmer
+	if flags.EnablePersistence && flags.StorageRetention > 0 {
+		trimmer = parcacol.NewTrimmer(
+			logger,
+			reg,
+			query.NewEngine(
+				memory.DefaultAllocator,
+				colDB.TableProvider(),
+			),
+			"stacktraces",
+			badgerStore,
+			flags.StorageRetentionTrimBatchSize,
+		)
+	}
+
+	profileStoreOpts := []profilestore.Option{
+		profilestore.WithMaxSampleSize(flags.StorageMaxSampleSize),
+		profilestore.WithMaxRequestSize(flags.StorageMaxRequestSize),
+		profilestore.WithMaxPendingWrites(flags.StorageMaxPendingWrites),
+		profilestore.WithDownsampleInterval(flags.StorageDownsampleInterval),
+		profilestore.WithDeduplication(flags.StorageDeduplicateMaxAge),
+		profilestore.WithTrimmer(trimmer),
+	}
+	if flags.StorageArchiveRawProfiles || flags.StorageBackfillFrom != "" || flags.StorageBackfillTo != "" {
+		profileStoreOpts = append(profileStoreOpts, profilestore.WithRawProfileArchive(objstore.NewPrefixedBucket(bucket, "raw-profiles")))
+	}
 	s := profilestore.NewProfileColumnStore(
 		logger,
+		reg,
 		tracerProvider.Tracer("profilestore"),
 		metastore,
 		table,
 		schema,
 		flags.StorageDebugValueLog,
+		profileStoreOpts...,
 	)
+	backfill := flags.StorageBackfillFrom != "" || flags.StorageBackfillTo != ""
+	var backfillFrom, backfillTo time.Time
+	if backfill {
+		backfillFrom, err = time.Parse(time.RFC3339, flags.StorageBackfillFrom)
+		if err != nil {
+			level.Error(logger).Log("msg", "invalid start of the backfill time range", "err", err)
+			return err
+		}
+		backfillTo, err = time.Parse(time.RFC3339, flags.StorageBackfillTo)
+		if err != nil {
+			level.Error(logger).Log("msg", "invalid end of the backfill time range", "err", err)
+			return err
+		}
+	}
 	conn, err := grpc.Dial(flags.ProfileShareServer, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
 	if err != nil {
 		return fmt.Errorf("failed to create gRPC connection to ProfileShareServer: %s, %w", flags.ProfileShareServer, err)
@@ -278,16 +388,46 @@ func Run(ctx context.Context, logger log.Logger, reg *prometheus.Registry, flags
 		return err
 	}
 
-	sym, err := symbol.NewSymbolizer(logger,
+	demanglers, err := demangle.SchemesByName(flags.SymbolizerDemanglers)
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to configure demanglers", "err", err)
+		return err
+	}
+
+	pathRewrites, err := symbol.ParsePathRewrites(flags.SymbolizerPathRewrites)
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to configure path rewrites", "err", err)
+		return err
+	}
+
+	sym, err := symbol.NewSymbolizer(logger, reg,
 		symbol.WithDemangleMode(flags.SymbolizerDemangleMode),
+		symbol.WithDemangleSchemes(demanglers...),
+		symbol.WithPathRewrites(pathRewrites...),
 		symbol.WithAttemptThreshold(flags.SymbolizerNumberOfTries),
-		symbol.WithCacheItemTTL(symbolizationInterval*3),
+		symbol.WithCacheSize(flags.SymbolizerCacheSize),
+		symbol.WithCacheMaxBytes(flags.SymbolizerCacheMaxBytes),
+		symbol.WithLinesCacheSize(flags.SymbolizerLinesCacheSize),
+		symbol.WithDWARFBufferPool(!flags.SymbolizerNoDWARFBufferPool),
+		symbol.WithCacheItemTTL(flags.SymbolizerInterval*3),
+		symbol.WithParseTimeout(flags.SymbolizerParseTimeout),
 	)
 	if err != nil {
 		level.Error(logger).Log("msg", "failed to initialize symbolizer", "err", err)
 		return err
 	}
 
+	dbgInfoCfg := debuginfo.Config{
+		Prefix:         flags.DebuginfoBucketPrefix,
+		ArchiveBuckets: cfg.ObjectStorage.DebuginfoArchiveBuckets,
+	}
+	tieredBucket, err := dbgInfoCfg.TieredBucket(logger, reg, bucket)
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to initialize debuginfo archive buckets", "err", err)
+		return err
+	}
+	dbgInfoRoot := dbgInfoCfg.PrefixedBucket(tieredBucket)
+
 	var debugInfodClient debuginfo.DebugInfodClient = debuginfo.NopDebugInfodClient{}
 	if len(flags.DebugInfodUpstreamServers) > 0 {
 		httpDebugInfoClient, err := debuginfo.NewHTTPDebugInfodClient(logger, flags.DebugInfodUpstreamServers, flags.DebugInfodHTTPRequestTimeout)
@@ -298,7 +438,7 @@ func Run(ctx context.Context, logger log.Logger, reg *prometheus.Registry, flags
 
 		debugInfodClient, err = debuginfo.NewDebugInfodClientWithObjectStorageCache(
 			logger,
-			objstore.NewPrefixedBucket(bucket, "debuginfod-cache"),
+			objstore.NewPrefixedBucket(dbgInfoRoot, "debuginfod-cache"),
 			httpDebugInfoClient,
 		)
 		if err != nil {
@@ -307,13 +447,64 @@ func Run(ctx context.Context, logger log.Logger, reg *prometheus.Registry, flags
 		}
 	}
 
-	dbgInfoMetadata := debuginfo.NewObjectStoreMetadata(logger, bucket)
+	dbgInfoBucket := objstore.NewPrefixedBucket(dbgInfoRoot, "debuginfo")
+	health.AddCheck("debuginfo bucket", func(ctx context.Context) error {
+		_, err := dbgInfoBucket.Exists(ctx, "health")
+		return err
+	})
+
+	var reSymbolize func(buildID string)
+	dbgInfoOpts := []debuginfo.Option{
+		debuginfo.WithRetry(debuginfo.RetryConfig{
+			MaxRetries: flags.DebuginfoFetchMaxRetries,
+			BaseDelay:  flags.DebuginfoFetchRetryBaseDelay,
+			MaxDelay:   flags.DebuginfoFetchRetryMaxDelay,
+		}),
+		debuginfo.WithExistsCache(debuginfo.ExistsCacheConfig{
+			TTL:         flags.DebuginfoExistsCacheTTL,
+			NegativeTTL: flags.DebuginfoExistsCacheNegativeTTL,
+		}),
+		debuginfo.WithAllowMissingBuildID(flags.DebuginfoUploadAllowMissingBuildID),
+		debuginfo.WithCompression(debuginfo.Compression(flags.DebuginfoUploadCompression)),
+		debuginfo.WithUploadLimits(debuginfo.UploadLimitsConfig{
+			MaxSize:  flags.DebuginfoUploadMaxSize,
+			Interval: flags.DebuginfoUploadInterval,
+		}),
+		debuginfo.WithDownloadTempDir(debuginfo.DownloadTempDirConfig{
+			Directory: flags.DebuginfoDownloadTempDir,
+			MaxSize:   flags.DebuginfoDownloadTempMaxSize,
+		}),
+		// The symbolizer is created after the store, so it is only hooked up
+		// to the uploads once it exists, before anything is served.
+		debuginfo.WithOnUploaded(func(buildID string) {
+			reSymbolize(buildID)
+		}),
+	}
+	if flags.DebuginfoUploadsSignedURL {
+		signedUploadClient, err := signedupload.NewClient(ctx, bucketCfg)
+		switch {
+		case errors.Is(err, signedupload.ErrUnsupportedProvider):
+			level.Warn(logger).Log("msg", "signed URL debuginfo uploads are not supported by the object storage, falling back to uploads with gRPC")
+		case err != nil:
+			level.Error(logger).Log("msg", "failed to initialize signed upload client", "err", err)
+			return err
+		default:
+			defer signedUploadClient.Close()
+			dbgInfoOpts = append(dbgInfoOpts, debuginfo.WithSignedUploadClient(signedupload.NewPrefixedClient(signedUploadClient, path.Join(flags.DebuginfoBucketPrefix, "debuginfo")), flags.DebuginfoUploadsSignedURLExpiry))
+		}
+	}
+
+	dbgInfoMetadata := debuginfo.NewObjectStoreMetadata(logger, dbgInfoRoot)
 	dbgInfo, err := debuginfo.NewStore(
 		logger,
+		reg,
 		flags.DebuginfoCacheDir,
 		dbgInfoMetadata,
-		objstore.NewPrefixedBucket(bucket, "debuginfo"),
+		dbgInfoBucket,
 		debugInfodClient,
+		sym,
+		metastore,
+		dbgInfoOpts...,
 	)
 	if err != nil {
 		level.Error(logger).Log("msg", "failed to initialize debug info store", "err", err)
@@ -346,24 +537,50 @@ func Run(ctx context.Context, logger log.Logger, reg *prometheus.Registry, flags
 	{
 		s := symbolizer.New(
 			logger,
+			reg,
 			metastore,
 			dbgInfo,
 			sym,
 			flags.DebuginfoCacheDir,
 			flags.DebuginfoCacheDir,
-			0,
+			symbolizer.WithMissingDebugInfoTTL(flags.SymbolizerMissingDebuginfoTTL),
+			symbolizer.WithBatchSize(flags.SymbolizerBatchSize),
+			symbolizer.WithConcurrency(flags.SymbolizerConcurrency),
+			symbolizer.WithFetchConcurrency(flags.SymbolizerFetchConcurrency),
+			symbolizer.WithReverification(flags.SymbolizerReverifyAge, flags.SymbolizerReverifyBatchSize),
+			symbolizer.WithMaxLinesPerLocation(flags.SymbolizerMaxLinesPerLocation),
 		)
-		ctx, cancel := context.WithCancel(ctx)
+		reSymbolize = s.ReSymbolizeInBackground
 		gr.Add(
 			func() error {
-				return s.Run(ctx, symbolizationInterval)
+				return s.Run(ctx, flags.SymbolizerInterval)
 			},
 			func(_ error) {
 				level.Debug(logger).Log("msg", "symbolizer server shutting down")
-				cancel()
+				s.Stop()
 				sym.Close()
 			})
 	}
+	if flags.EnablePersistence && flags.StorageRetention > 0 {
+		r := parcacol.NewRetention(
+			logger,
+			reg,
+			objstore.NewPrefixedBucket(bucket, "blocks"),
+			"parca",
+			"stacktraces",
+			flags.StorageRetention,
+		)
+		r.SetTrimmer(trimmer)
+		ctx, cancel := context.WithCancel(ctx)
+		gr.Add(
+			func() error {
+				return r.Run(ctx, flags.StorageRetentionInterval)
+			},
+			func(_ error) {
+				level.Debug(logger).Log("msg", "storage retention exiting")
+				cancel()
+			})
+	}
 	gr.Add(
 		func() error {
 			return discoveryManager.Run()
@@ -391,7 +608,39 @@ func Run(ctx context.Context, logger log.Logger, reg *prometheus.Registry, flags
 			cancel()
 		},
 	)
-	parcaserver := server.NewServer(reg, version)
+	{
+		ctx, cancel := context.WithCancel(ctx)
+		gr.Add(
+			func() error {
+				return health.Run(ctx, healthCheckInterval)
+			},
+			func(_ error) {
+				level.Debug(logger).Log("msg", "health checks exiting")
+				cancel()
+			})
+	}
+	if backfill {
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		gr.Add(
+			func() error {
+				defer close(done)
+				if _, err := s.Backfill(ctx, backfillFrom, backfillTo); err != nil && !errors.Is(err, context.Canceled) {
+					level.Error(logger).Log("msg", "failed to backfill archived profiles", "err", err)
+				}
+				// Parca keeps running once the backfill is done.
+				<-ctx.Done()
+				return nil
+			},
+			func(_ error) {
+				level.Debug(logger).Log("msg", "backfill exiting")
+				cancel()
+				// The backfill writes to the columnstore and the metastore,
+				// it has to be stopped before they are closed.
+				<-done
+			})
+	}
+	parcaserver := server.NewServer(reg, version, health)
 	gr.Add(
 		func() error {
 			return parcaserver.ListenAndServe(
@@ -401,28 +650,16 @@ func Run(ctx context.Context, logger log.Logger, reg *prometheus.Registry, flags
 				flags.CORSAllowedOrigins,
 				flags.PathPrefix,
 				server.RegisterableFunc(func(ctx context.Context, srv *grpc.Server, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error {
-					debuginfopb.RegisterDebugInfoServiceServer(srv, dbgInfo)
-					profilestorepb.RegisterProfileStoreServiceServer(srv, s)
-					querypb.RegisterQueryServiceServer(srv, q)
-					scrapepb.RegisterScrapeServiceServer(srv, m)
-
-					if err := debuginfopb.RegisterDebugInfoServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
-						return err
-					}
-
-					if err := profilestorepb.RegisterProfileStoreServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
-						return err
+					services := server.Services{
+						DebugInfo:    dbgInfo,
+						ProfileStore: s,
+						Query:        q,
+						Scrape:       m,
+						OTLPProfiles: profilestore.NewOTLPServer(s),
+						Health:       parcaserver.HealthServer(),
 					}
-
-					if err := querypb.RegisterQueryServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
-						return err
-					}
-
-					if err := scrapepb.RegisterScrapeServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
-						return err
-					}
-
-					return nil
+					server.RegisterServices(srv, services)
+					return server.RegisterServiceHandlers(ctx, mux, endpoint, opts, services)
 				}),
 			)
 		},
@@ -577,7 +814,7 @@ func runScraper(
 		},
 	)
 
-	parcaserver := server.NewServer(reg, version)
+	parcaserver := server.NewServer(reg, version, nil)
 	gr.Add(
 		func() error {
 			return parcaserver.ListenAndServe(
@@ -587,11 +824,12 @@ func runScraper(
 				flags.CORSAllowedOrigins,
 				flags.PathPrefix,
 				server.RegisterableFunc(func(ctx context.Context, srv *grpc.Server, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error {
-					scrapepb.RegisterScrapeServiceServer(srv, m)
-					if err := scrapepb.RegisterScrapeServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
-						return err
+					services := server.Services{
+						Scrape: m,
+						Health: parcaserver.HealthServer(),
 					}
-					return nil
+					server.RegisterServices(srv, services)
+					return server.RegisterServiceHandlers(ctx, mux, endpoint, opts, services)
 				}),
 			)
 		},
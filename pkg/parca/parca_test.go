@@ -154,6 +154,7 @@ type Testing interface {
 	require.TestingT
 	Helper()
 	Name() string
+	Cleanup(func())
 }
 
 func replayDebugLog(ctx context.Context, t Testing) (querypb.QueryServiceServer, *frostdb.Table, *semgroup.Group, func()) {
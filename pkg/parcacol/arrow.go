@@ -16,6 +16,7 @@ package parcacol
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/apache/arrow/go/v8/arrow"
 	"github.com/apache/arrow/go/v8/arrow/array"
@@ -71,10 +72,25 @@ func (c *ArrowToProfileConverter) Convert(
 	}
 	valueColumn := ar.Column(indices[0]).(*array.Int64)
 
-	rows := int(ar.NumRows())
-	stacktraceIDs := make([]string, rows)
-	for i := 0; i < rows; i++ {
-		stacktraceIDs[i] = string(stacktraceColumn.Value(i))
+	// The pprof labels of the samples are stored in a dynamic column per
+	// label name.
+	labelColumns := map[string]*array.Binary{}
+	numLabelColumns := map[string]*array.Int64{}
+	for i, field := range schema.Fields() {
+		switch {
+		case strings.HasPrefix(field.Name, ColumnPprofLabels+"."):
+			labelColumns[strings.TrimPrefix(field.Name, ColumnPprofLabels+".")] = ar.Column(i).(*array.Binary)
+		case strings.HasPrefix(field.Name, ColumnPprofNumLabels+"."):
+			numLabelColumns[strings.TrimPrefix(field.Name, ColumnPprofNumLabels+".")] = ar.Column(i).(*array.Int64)
+		}
+	}
+
+	// The samples of duplicate profiles are skipped, the querier resolves
+	// them to the samples of the profiles they duplicate.
+	rows := sampleRows(stacktraceColumn)
+	stacktraceIDs := make([]string, len(rows))
+	for j, i := range rows {
+		stacktraceIDs[j] = string(stacktraceColumn.Value(i))
 	}
 
 	stacktraceLocations, err := c.resolveStacktraces(ctx, stacktraceIDs)
@@ -82,11 +98,13 @@ func (c *ArrowToProfileConverter) Convert(
 		return nil, fmt.Errorf("read stacktrace metadata: %w", err)
 	}
 
-	samples := make([]*profile.SymbolizedSample, 0, rows)
-	for i := 0; i < rows; i++ {
+	samples := make([]*profile.SymbolizedSample, 0, len(rows))
+	for j, i := range rows {
 		samples = append(samples, &profile.SymbolizedSample{
 			Value:     valueColumn.Value(i),
-			Locations: stacktraceLocations[i],
+			Locations: stacktraceLocations[j],
+			Label:     sampleLabels(labelColumns, i),
+			NumLabel:  sampleNumLabels(numLabelColumns, i),
 		})
 	}
 
@@ -96,6 +114,50 @@ func (c *ArrowToProfileConverter) Convert(
 	}, nil
 }
 
+// sampleRows returns the rows of the record that are samples of a profile, all
+// but the ones of duplicate profiles.
+func sampleRows(stacktraces *array.Binary) []int {
+	rows := make([]int, 0, stacktraces.Len())
+	for i := 0; i < stacktraces.Len(); i++ {
+		if _, ok := duplicateOf(string(stacktraces.Value(i))); !ok {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// sampleLabels returns the pprof labels of the sample in the row, or nil if
+// it has none.
+func sampleLabels(columns map[string]*array.Binary, row int) map[string]string {
+	var labels map[string]string
+	for name, col := range columns {
+		if col.IsNull(row) {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[name] = string(col.Value(row))
+	}
+	return labels
+}
+
+// sampleNumLabels returns the numeric pprof labels of the sample in the row,
+// or nil if it has none.
+func sampleNumLabels(columns map[string]*array.Int64, row int) map[string]int64 {
+	var labels map[string]int64
+	for name, col := range columns {
+		if col.IsNull(row) {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]int64{}
+		}
+		labels[name] = col.Value(row)
+	}
+	return labels
+}
+
 func (c *ArrowToProfileConverter) SymbolizeNormalizedProfile(ctx context.Context, p *profile.NormalizedProfile) (*profile.Profile, error) {
 	stacktraceIDs := make([]string, len(p.Samples))
 	for i, sample := range p.Samples {
@@ -113,6 +175,8 @@ func (c *ArrowToProfileConverter) SymbolizeNormalizedProfile(ctx context.Context
 			Value:     sample.Value,
 			DiffValue: sample.DiffValue,
 			Locations: stacktraceLocations[i],
+			Label:     sample.Label,
+			NumLabel:  sample.NumLabel,
 		}
 	}
 
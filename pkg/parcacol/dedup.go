@@ -0,0 +1,374 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parcacol
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow/go/v8/arrow"
+	"github.com/apache/arrow/go/v8/arrow/array"
+	"github.com/polarsignals/frostdb/query/logicalplan"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/parca-dev/parca/pkg/profile"
+)
+
+// duplicateStacktracePrefix is the prefix of the stacktrace of the sample
+// that is stored in place of a profile identical to the previous one of its
+// series. It is followed by the timestamp in milliseconds of the profile it
+// duplicates, and its value is the total of that profile, so that the
+// metrics of the series don't need to resolve it.
+const duplicateStacktracePrefix = "duplicate-of/"
+
+// minDeduplicatorSweepSize is the number of remembered series from which on
+// the ones that weren't written to recently are forgotten.
+const minDeduplicatorSweepSize = 1024
+
+// duplicateStacktraceID returns the stacktrace of the sample standing in for
+// a profile identical to the one stored at the timestamp.
+func duplicateStacktraceID(timestamp int64) string {
+	return duplicateStacktracePrefix + strconv.FormatInt(timestamp, 10)
+}
+
+// duplicateOf returns the timestamp of the profile the stacktrace of a sample
+// stands in for, if it is one of a duplicate profile.
+func duplicateOf(stacktraceID string) (int64, bool) {
+	if !strings.HasPrefix(stacktraceID, duplicateStacktracePrefix) {
+		return 0, false
+	}
+	ts, err := strconv.ParseInt(strings.TrimPrefix(stacktraceID, duplicateStacktracePrefix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return ts, true
+}
+
+// Deduplicator replaces profiles identical to the previous profile of their
+// series by a single sample referring to the stored profile, which the
+// querier resolves when reading them.
+type Deduplicator struct {
+	maxAge       time.Duration
+	deduplicated prometheus.Counter
+	now          func() time.Time
+
+	mtx sync.Mutex
+	// last is the last profile written per series.
+	last      map[string]deduplicatedProfile
+	sweepSize int
+}
+
+type deduplicatedProfile struct {
+	hash [sha256.Size]byte
+	// timestamp and total are the ones of the stored profile, which is the
+	// one the duplicates refer to.
+	timestamp int64
+	total     int64
+	written   time.Time
+}
+
+// NewDeduplicator returns a deduplicator that only refers to profiles stored
+// at most maxAge before, after that an identical profile is stored in full
+// again. This keeps duplicates from referring to profiles deleted by the
+// retention, as long as it is longer. The counter is incremented for every
+// deduplicated profile.
+func NewDeduplicator(maxAge time.Duration, deduplicated prometheus.Counter) *Deduplicator {
+	return &Deduplicator{
+		maxAge:       maxAge,
+		deduplicated: deduplicated,
+		now:          time.Now,
+		last:         map[string]deduplicatedProfile{},
+		sweepSize:    minDeduplicatorSweepSize,
+	}
+}
+
+// deduplicate returns the profile to store for the profile of the series.
+// It is either the profile itself, or a profile of a single sample referring
+// to the previous one of the series if they have the same content.
+//
+// Profiles with pprof labels are always stored in full, as the sample
+// referring to them has none and so would not be selected by queries
+// matching the pprof labels of the samples it stands in for.
+func (d *Deduplicator) deduplicate(tenant string, ls labels.Labels, p *profile.NormalizedProfile) *profile.NormalizedProfile {
+	series := seriesKey(tenant, ls, p.Meta)
+	if hasPprofLabels(p) {
+		d.mtx.Lock()
+		delete(d.last, series)
+		d.mtx.Unlock()
+		return p
+	}
+	hash := profileHash(p)
+	now := d.now()
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if last, ok := d.last[series]; ok && last.hash == hash && now.Sub(last.written) <= d.maxAge {
+		d.deduplicated.Inc()
+		return &profile.NormalizedProfile{
+			Meta: p.Meta,
+			Samples: []*profile.NormalizedSample{{
+				StacktraceID: duplicateStacktraceID(last.timestamp),
+				Value:        last.total,
+			}},
+		}
+	}
+
+	if len(d.last) >= d.sweepSize {
+		for s, last := range d.last {
+			if now.Sub(last.written) > d.maxAge {
+				delete(d.last, s)
+			}
+		}
+		d.sweepSize = 2 * len(d.last)
+		if d.sweepSize < minDeduplicatorSweepSize {
+			d.sweepSize = minDeduplicatorSweepSize
+		}
+	}
+
+	var total int64
+	for _, s := range p.Samples {
+		total += s.Value
+	}
+	d.last[series] = deduplicatedProfile{
+		hash:      hash,
+		timestamp: p.Meta.Timestamp,
+		total:     total,
+		written:   now,
+	}
+	return p
+}
+
+// hasPprofLabels returns whether any sample of the profile has pprof labels.
+func hasPprofLabels(p *profile.NormalizedProfile) bool {
+	for _, s := range p.Samples {
+		if len(s.Label) > 0 || len(s.NumLabel) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// seriesKey identifies the series a normalized profile is stored in.
+func seriesKey(tenant string, ls labels.Labels, meta profile.Meta) string {
+	return strings.Join([]string{
+		tenant,
+		meta.Name,
+		meta.SampleType.Type,
+		meta.SampleType.Unit,
+		meta.PeriodType.Type,
+		meta.PeriodType.Unit,
+		ls.String(),
+	}, "\xff")
+}
+
+// profileHash returns the hash of the content of the profile, everything but
+// its timestamp. It doesn't depend on the order of the samples.
+func profileHash(p *profile.NormalizedProfile) [sha256.Size]byte {
+	samples := make([]string, 0, len(p.Samples))
+	for _, s := range p.Samples {
+		fields := make([]string, 0, 2+len(s.Label)+len(s.NumLabel))
+		for name, value := range s.Label {
+			fields = append(fields, name+"="+value)
+		}
+		for name, value := range s.NumLabel {
+			fields = append(fields, name+"#"+strconv.FormatInt(value, 10))
+		}
+		sort.Strings(fields)
+		fields = append(fields, s.StacktraceID, strconv.FormatInt(s.Value, 10))
+		samples = append(samples, strings.Join(fields, "\xff"))
+	}
+	sort.Strings(samples)
+
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatInt(p.Meta.Duration, 10)))
+	h.Write([]byte{0xff})
+	h.Write([]byte(strconv.FormatInt(p.Meta.Period, 10)))
+	for _, s := range samples {
+		h.Write([]byte{0xfe})
+		h.Write([]byte(s))
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// resolveDuplicates returns the samples of the profiles that the duplicate
+// profiles selected by the filter refer to, with their values multiplied by
+// the number of duplicates of each. The selector expressions select the
+// profile type the filter does. If keep is not nil, only the duplicates it
+// returns true for by their series and timestamp are resolved.
+func (q *Querier) resolveDuplicates(ctx context.Context, filterExpr logicalplan.Expr, selectorExprs []logicalplan.Expr, keep func(series string, timestamp int64) bool) ([]*profile.SymbolizedSample, error) {
+	ctx, span := q.tracer.Start(ctx, "resolveDuplicates")
+	defer span.End()
+
+	duplicateExpr := logicalplan.Col(ColumnStacktrace).RegexMatch("^" + regexp.QuoteMeta(duplicateStacktracePrefix))
+
+	// The summed values of the duplicates of every series per timestamp of
+	// the profile they refer to.
+	refs := map[int64]map[string]int64{}
+	err := q.scan(ctx, logicalplan.And(filterExpr, duplicateExpr), func(ar arrow.Record) error {
+		stacktraces, err := BinaryFieldFromRecord(ar, ColumnStacktrace)
+		if err != nil {
+			return err
+		}
+		values, err := int64FieldFromRecord(ar, "sum(value)")
+		if err != nil {
+			return err
+		}
+		timestamps, err := int64FieldFromRecord(ar, ColumnTimestamp)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < int(ar.NumRows()); i++ {
+			ts, ok := duplicateOf(string(stacktraces.Value(i)))
+			if !ok {
+				continue
+			}
+			series := seriesOfRow(ar, i)
+			if keep != nil && !keep(series, timestamps.Value(i)) {
+				continue
+			}
+			if refs[ts] == nil {
+				refs[ts] = map[string]int64{}
+			}
+			refs[ts][series] += values.Value(i)
+		}
+		return nil
+	}, logicalplan.Col(ColumnStacktrace), logicalplan.DynCol(ColumnLabels), logicalplan.Col(ColumnTimestamp))
+	if err != nil {
+		return nil, fmt.Errorf("select duplicate profiles: %w", err)
+	}
+
+	var samples []*profile.SymbolizedSample
+	for ts, series := range refs {
+		exprs := append(
+			selectorExprs[:len(selectorExprs):len(selectorExprs)],
+			tenantFilter(ctx),
+			logicalplan.Col(ColumnTimestamp).Eq(logicalplan.Literal(ts)),
+			logicalplan.Col(ColumnStacktrace).RegexNotMatch("^"+regexp.QuoteMeta(duplicateStacktracePrefix)),
+		)
+		err := q.scan(ctx, logicalplan.And(exprs...), func(ar arrow.Record) error {
+			p, err := q.converter.Convert(ctx, ar, "sum(value)", profile.Meta{})
+			if err != nil {
+				return err
+			}
+
+			// The samples are the rows of the record in order, as it has no
+			// duplicates.
+			rowSeries := make([]string, len(p.Samples))
+			totals := map[string]int64{}
+			for i, s := range p.Samples {
+				rowSeries[i] = seriesOfRow(ar, i)
+				totals[rowSeries[i]] += s.Value
+			}
+			for i, s := range p.Samples {
+				sum, ok := series[rowSeries[i]]
+				if !ok || totals[rowSeries[i]] == 0 {
+					continue
+				}
+				s.Value = scaleValue(s.Value, sum, totals[rowSeries[i]])
+				samples = append(samples, s)
+			}
+			return nil
+		}, logicalplan.Col(ColumnStacktrace), logicalplan.DynCol(ColumnPprofLabels), logicalplan.DynCol(ColumnPprofNumLabels), logicalplan.DynCol(ColumnLabels))
+		if err != nil {
+			return nil, fmt.Errorf("select duplicated profile: %w", err)
+		}
+	}
+	return samples, nil
+}
+
+// scaleValue returns v*num/den, computed without overflowing in between, and
+// clamped to the range of int64.
+func scaleValue(v, num, den int64) int64 {
+	scaled := new(big.Int).Mul(big.NewInt(v), big.NewInt(num))
+	scaled.Quo(scaled, big.NewInt(den))
+	switch {
+	case scaled.IsInt64():
+		return scaled.Int64()
+	case scaled.Sign() < 0:
+		return math.MinInt64
+	default:
+		return math.MaxInt64
+	}
+}
+
+// scan sums up the values of the rows selected by the filter grouped by the
+// given columns, and calls f with the result if there is any.
+func (q *Querier) scan(ctx context.Context, filterExpr logicalplan.Expr, f func(arrow.Record) error, groupBy ...logicalplan.Expr) error {
+	var ar arrow.Record
+	err := q.engine.ScanTable(q.tableName).
+		Filter(filterExpr).
+		Aggregate(logicalplan.Sum(logicalplan.Col(ColumnValue)), groupBy...).
+		Execute(ctx, func(r arrow.Record) error {
+			r.Retain()
+			ar = r
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	if ar == nil {
+		return nil
+	}
+	defer ar.Release()
+	if ar.NumRows() == 0 {
+		return nil
+	}
+	return f(ar)
+}
+
+// seriesOfRow returns the labels of the series of the row of a record grouped
+// by them.
+func seriesOfRow(ar arrow.Record, row int) string {
+	ls := labels.Labels{}
+	for i, field := range ar.Schema().Fields() {
+		if !strings.HasPrefix(field.Name, ColumnLabels+".") {
+			continue
+		}
+		col, ok := ar.Column(i).(*array.Binary)
+		if !ok || col.IsNull(row) || col.ValueLen(row) == 0 {
+			continue
+		}
+		ls = append(ls, labels.Label{Name: strings.TrimPrefix(field.Name, ColumnLabels+"."), Value: string(col.Value(row))})
+	}
+	sort.Sort(ls)
+	return ls.String()
+}
+
+func int64FieldFromRecord(ar arrow.Record, name string) (*array.Int64, error) {
+	indices := ar.Schema().FieldIndices(name)
+	if len(indices) != 1 {
+		return nil, fmt.Errorf("expected 1 column named %q, got %d", name, len(indices))
+	}
+
+	col, ok := ar.Column(indices[0]).(*array.Int64)
+	if !ok {
+		return nil, fmt.Errorf("expected column %q to be an int64 column, got %T", name, ar.Column(indices[0]))
+	}
+
+	return col, nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parcacol
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaleValue(t *testing.T) {
+	// Three duplicates of a profile with a total of 7.
+	require.Equal(t, int64(6), scaleValue(2, 21, 7))
+	// Only part of the profile is selected, the value is scaled by the
+	// fraction, not truncated to a whole multiple.
+	require.Equal(t, int64(15), scaleValue(10, 3, 2))
+	require.Equal(t, int64(-15), scaleValue(-10, 3, 2))
+	// The product doesn't overflow in between.
+	require.Equal(t, int64(math.MaxInt64/2), scaleValue(math.MaxInt64, 1<<40, 1<<41))
+	require.Equal(t, int64(math.MaxInt64), scaleValue(math.MaxInt64, 2, 1))
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parcacol
+
+import (
+	"sync"
+
+	"github.com/polarsignals/frostdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/parca-dev/parca/pkg/profile"
+)
+
+// HeadStats tracks the series and samples written to the active block of a
+// table, the block that is held in memory until it is rotated and persisted.
+type HeadStats struct {
+	table *frostdb.Table
+
+	mtx sync.Mutex
+	// block is the active block the series and samples were written to.
+	block   *frostdb.TableBlock
+	series  map[string]struct{}
+	samples int64
+
+	blocks prometheus.Counter
+}
+
+// NewHeadStats returns the stats of the active block of the table, and
+// registers gauges of its number of series, samples and size in bytes.
+func NewHeadStats(reg prometheus.Registerer, table *frostdb.Table) *HeadStats {
+	h := &HeadStats{
+		table:  table,
+		series: map[string]struct{}{},
+		blocks: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "parca_storage_blocks_total",
+			Help: "Total number of active table blocks, a new one is started once the active one is rotated.",
+		}),
+	}
+	reg.MustRegister(
+		h.blocks,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "parca_storage_head_series",
+			Help: "Number of series written to the active table block.",
+		}, func() float64 {
+			series, _ := h.Stats()
+			return float64(series)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "parca_storage_head_samples",
+			Help: "Number of samples written to the active table block.",
+		}, func() float64 {
+			_, samples := h.Stats()
+			return float64(samples)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "parca_storage_head_bytes",
+			Help: "Estimated size in bytes of the active table block.",
+		}, func() float64 {
+			return float64(table.ActiveBlock().Size())
+		}),
+	)
+	return h
+}
+
+// Stats returns the number of series and samples written to the active
+// block.
+func (h *HeadStats) Stats() (series int, samples int64) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	h.sync()
+	return len(h.series), h.samples
+}
+
+// observe records that the profile was written as a series of the tenant.
+func (h *HeadStats) observe(tenant string, ls labels.Labels, p *profile.NormalizedProfile) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	h.sync()
+	h.series[seriesKey(tenant, ls, p.Meta)] = struct{}{}
+	h.samples += int64(len(p.Samples))
+}
+
+// sync starts over counting once the active block was rotated. It must be
+// called with the mutex held.
+func (h *HeadStats) sync() {
+	block := h.table.ActiveBlock()
+	if block == h.block {
+		return
+	}
+	h.blocks.Inc()
+	h.block = block
+	h.series = map[string]struct{}{}
+	h.samples = 0
+}
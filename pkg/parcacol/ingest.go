@@ -26,6 +26,7 @@ import (
 
 	pprofproto "github.com/parca-dev/parca/gen/proto/go/google/pprof"
 	"github.com/parca-dev/parca/pkg/profile"
+	"github.com/parca-dev/parca/pkg/tenant"
 )
 
 type Table interface {
@@ -38,6 +39,18 @@ type Ingester struct {
 	table      Table
 	normalizer *Normalizer
 	schema     *dynparquet.Schema
+
+	// trimmer, if set, is kept from deleting the metastore entries of the
+	// profiles being ingested.
+	trimmer *Trimmer
+
+	// deduplicator, if set, replaces profiles identical to the previous one
+	// of their series.
+	deduplicator *Deduplicator
+
+	// headStats, if set, tracks the series and samples written to the
+	// active block of the table.
+	headStats *HeadStats
 }
 
 func NewIngester(logger log.Logger, normalizer *Normalizer, table Table, schema *dynparquet.Schema) *Ingester {
@@ -49,6 +62,24 @@ func NewIngester(logger log.Logger, normalizer *Normalizer, table Table, schema
 	}
 }
 
+// SetTrimmer makes the ingester keep the trimmer from deleting the metastore
+// entries of the profiles it ingests.
+func (ing *Ingester) SetTrimmer(t *Trimmer) {
+	ing.trimmer = t
+}
+
+// SetDeduplicator makes the ingester store profiles identical to the previous
+// one of their series as a reference to it.
+func (ing *Ingester) SetDeduplicator(d *Deduplicator) {
+	ing.deduplicator = d
+}
+
+// SetHeadStats makes the ingester record the profiles it writes in the stats
+// of the active block of the table.
+func (ing *Ingester) SetHeadStats(h *HeadStats) {
+	ing.headStats = h
+}
+
 var ErrMissingNameLabel = errors.New("missing __name__ label")
 
 func separateNameFromLabels(ls labels.Labels) (string, map[string]struct{}, labels.Labels, error) {
@@ -79,14 +110,21 @@ func (ing Ingester) Ingest(ctx context.Context, ls labels.Labels, p *pprofproto.
 		return fmt.Errorf("prepare labels: %w", err)
 	}
 
-	if err := validatePprofProfile(p); err != nil {
+	if err := ValidatePprofProfile(p); err != nil {
 		return err
 	}
 
+	if ing.trimmer != nil {
+		defer ing.trimmer.startIngest()()
+	}
+
 	normalizedProfiles, err := ing.normalizer.NormalizePprof(ctx, name, names, p, normalized)
 	if err != nil {
 		return fmt.Errorf("normalize profile: %w", err)
 	}
+	if ing.trimmer != nil {
+		ing.trimmer.track(normalizedProfiles)
+	}
 
 	for _, p := range normalizedProfiles {
 		if len(p.Samples) == 0 {
@@ -94,6 +132,10 @@ func (ing Ingester) Ingest(ctx context.Context, ls labels.Labels, p *pprofproto.
 			continue
 		}
 
+		if ing.deduplicator != nil {
+			p = ing.deduplicator.deduplicate(tenant.FromContext(ctx), ls, p)
+		}
+
 		if err := ing.IngestProfile(ctx, ls, p); err != nil {
 			return fmt.Errorf("ingest profile: %w", err)
 		}
@@ -102,8 +144,9 @@ func (ing Ingester) Ingest(ctx context.Context, ls labels.Labels, p *pprofproto.
 	return nil
 }
 
+// IngestProfile writes the profile as a series of the tenant of the context.
 func (ing Ingester) IngestProfile(ctx context.Context, ls labels.Labels, p *profile.NormalizedProfile) error {
-	buffer, err := NormalizedProfileToParquetBuffer(ing.schema, ls, p)
+	buffer, err := NormalizedProfileToParquetBuffer(ing.schema, tenant.FromContext(ctx), ls, p)
 	if err != nil {
 		return fmt.Errorf("failed to convert samples to buffer: %w", err)
 	}
@@ -113,10 +156,15 @@ func (ing Ingester) IngestProfile(ctx context.Context, ls labels.Labels, p *prof
 		return fmt.Errorf("insert buffer: %w", err)
 	}
 
+	if ing.headStats != nil {
+		ing.headStats.observe(tenant.FromContext(ctx), ls, p)
+	}
 	return nil
 }
 
-func validatePprofProfile(p *pprofproto.Profile) error {
+// ValidatePprofProfile checks that all references within the profile are
+// within bounds, so that it can be normalized and ingested.
+func ValidatePprofProfile(p *pprofproto.Profile) error {
 	stringTableLen := int64(len(p.StringTable))
 
 	if stringTableLen > 0 && p.StringTable[0] != "" {
@@ -133,10 +181,10 @@ func validatePprofProfile(p *pprofproto.Profile) error {
 		if m.Id != uint64(i+1) {
 			return fmt.Errorf("mapping id is not sequential")
 		}
-		if m.Filename != 0 && m.Filename > stringTableLen {
+		if m.Filename != 0 && m.Filename >= stringTableLen {
 			return fmt.Errorf("mapping (id: %d) has invalid filename index %d", m.Id, m.Filename)
 		}
-		if m.BuildId != 0 && m.BuildId > stringTableLen {
+		if m.BuildId != 0 && m.BuildId >= stringTableLen {
 			return fmt.Errorf("mapping (id: %d) has invalid buildid index %d", m.Id, m.Filename)
 		}
 	}
@@ -149,13 +197,13 @@ func validatePprofProfile(p *pprofproto.Profile) error {
 		if f.Id != uint64(i+1) {
 			return fmt.Errorf("function id is not sequential")
 		}
-		if f.Name != 0 && f.Name > stringTableLen {
+		if f.Name != 0 && f.Name >= stringTableLen {
 			return fmt.Errorf("function (id: %d) has invalid name index %d", f.Id, f.Name)
 		}
-		if f.SystemName != 0 && f.SystemName > stringTableLen {
+		if f.SystemName != 0 && f.SystemName >= stringTableLen {
 			return fmt.Errorf("function (id: %d) has invalid name index %d", f.Id, f.SystemName)
 		}
-		if f.Filename != 0 && f.Filename > stringTableLen {
+		if f.Filename != 0 && f.Filename >= stringTableLen {
 			return fmt.Errorf("function (id: %d) has invalid filename index %d", f.Id, f.Filename)
 		}
 	}
@@ -203,10 +251,10 @@ func validatePprofProfile(p *pprofproto.Profile) error {
 			if label.Key == 0 {
 				return fmt.Errorf("sample %d label %d has no key", i, j)
 			}
-			if label.Key != 0 && label.Key > stringTableLen {
+			if label.Key != 0 && label.Key >= stringTableLen {
 				return fmt.Errorf("sample %d label %d has invalid key index %d", i, j, label.Key)
 			}
-			if label.Str != 0 && label.Str > stringTableLen {
+			if label.Str != 0 && label.Str >= stringTableLen {
 				return fmt.Errorf("sample %d label %d has invalid str index %d", i, j, label.Key)
 			}
 		}
@@ -30,8 +30,10 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
+	metastorepb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
 	"github.com/parca-dev/parca/pkg/metastore"
 	"github.com/parca-dev/parca/pkg/metastoretest"
+	"github.com/parca-dev/parca/pkg/tenant"
 )
 
 func MustReadAllGzip(t require.TestingT, filename string) []byte {
@@ -70,7 +72,7 @@ func TestPprofToParquet(t *testing.T) {
 	require.NoError(t, err)
 
 	for i, np := range nps {
-		buf, err := NormalizedProfileToParquetBuffer(schema, labels.Labels{}, np)
+		buf, err := NormalizedProfileToParquetBuffer(schema, tenant.Default, labels.Labels{}, np)
 		require.NoError(t, err)
 
 		b, err := schema.SerializeBuffer(buf)
@@ -95,3 +97,70 @@ func TestPprofToParquet(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeSymbolizedPprof(t *testing.T) {
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	ctx := context.Background()
+
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := NewNormalizer(metastore)
+
+	newProfile := func(symbolized bool) *pprofpb.Profile {
+		p := &pprofpb.Profile{
+			StringTable: []string{"", "samples", "count", "main", "main.go", "a.out", "abcd"},
+			SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+			Mapping:     []*pprofpb.Mapping{{Id: 1, MemoryStart: 0x1000, MemoryLimit: 0x2000, Filename: 5, BuildId: 6}},
+			Location: []*pprofpb.Location{
+				{Id: 1, MappingId: 1, Address: 0x1010},
+				{Id: 2, MappingId: 1, Address: 0x1020},
+			},
+			Sample: []*pprofpb.Sample{{LocationId: []uint64{1, 2}, Value: []int64{1}}},
+		}
+		if symbolized {
+			p.Function = []*pprofpb.Function{{Id: 1, Name: 3, SystemName: 3, Filename: 4}}
+			for i, l := range p.Location {
+				l.Line = []*pprofpb.Line{{FunctionId: 1, Line: int64(i + 1)}}
+			}
+		}
+		return p
+	}
+	unsymbolized := func() int {
+		res, err := metastore.UnsymbolizedLocations(ctx, &metastorepb.UnsymbolizedLocationsRequest{})
+		require.NoError(t, err)
+		return len(res.Locations)
+	}
+
+	// The locations of a profile symbolized by the client aren't symbolized
+	// again.
+	_, err := normalizer.NormalizePprof(ctx, "process_cpu", map[string]struct{}{}, newProfile(true), false)
+	require.NoError(t, err)
+	require.Equal(t, 0, unsymbolized())
+
+	// Neither are the ones that were only symbolized by a later profile.
+	p := newProfile(false)
+	p.Location[0].Address, p.Location[1].Address = 0x1030, 0x1040
+	_, err = normalizer.NormalizePprof(ctx, "process_cpu", map[string]struct{}{}, p, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, unsymbolized())
+
+	p = newProfile(true)
+	p.Location[0].Address, p.Location[1].Address = 0x1030, 0x1040
+	_, err = normalizer.NormalizePprof(ctx, "process_cpu", map[string]struct{}{}, p, false)
+	require.NoError(t, err)
+	require.Equal(t, 0, unsymbolized())
+
+	res, err := metastore.ListLocations(ctx, &metastorepb.ListLocationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Locations, 4)
+	for _, l := range res.Locations {
+		require.Len(t, l.Lines, 1)
+	}
+}
@@ -17,6 +17,8 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
@@ -48,9 +50,10 @@ func (n *Normalizer) NormalizePprof(ctx context.Context, name string, takenLabel
 		return nil, fmt.Errorf("normalize functions: %w", err)
 	}
 
-	locations, err := n.NormalizeLocations(
+	leaves, callers, err := n.NormalizeLocations(
 		ctx,
 		p.Location,
+		p.Sample,
 		mappings,
 		functions,
 		normalizedAddress,
@@ -60,12 +63,13 @@ func (n *Normalizer) NormalizePprof(ctx context.Context, name string, takenLabel
 		return nil, fmt.Errorf("normalize locations: %w", err)
 	}
 
-	stacktraces, err := n.NormalizeStacktraces(ctx, p.Sample, locations)
+	stacktraces, err := n.NormalizeStacktraces(ctx, p.Sample, leaves, callers)
 	if err != nil {
 		return nil, fmt.Errorf("normalize stacktraces: %w", err)
 	}
 
 	sampleIndex := map[int]map[string]int{}
+	sampleKeys := make([][]string, 0, len(p.SampleType))
 	profiles := make([]*profile.NormalizedProfile, 0, len(p.SampleType))
 	for i := 0; i < len(p.SampleType); i++ {
 		normalizedProfile := &profile.NormalizedProfile{
@@ -74,6 +78,7 @@ func (n *Normalizer) NormalizePprof(ctx context.Context, name string, takenLabel
 		}
 		profiles = append(profiles, normalizedProfile)
 		sampleIndex[i] = map[string]int{}
+		sampleKeys = append(sampleKeys, make([]string, 0, len(p.Sample)))
 	}
 
 	for i, sample := range p.Sample {
@@ -94,6 +99,7 @@ func (n *Normalizer) NormalizePprof(ctx context.Context, name string, takenLabel
 			index, ok := sampleIndex[j][key]
 			if !ok {
 				profiles[j].Samples = append(profiles[j].Samples, ns)
+				sampleKeys[j] = append(sampleKeys[j], key)
 				sampleIndex[j][key] = len(profiles[j].Samples) - 1
 			} else {
 				profiles[j].Samples[index].Value += ns.Value
@@ -101,19 +107,59 @@ func (n *Normalizer) NormalizePprof(ctx context.Context, name string, takenLabel
 		}
 	}
 
+	for j, np := range profiles {
+		np.Samples = canonicalSamples(np.Samples, sampleKeys[j])
+	}
+
 	return profiles, nil
 }
 
+// canonicalSamples drops the samples whose values add up to zero and sorts
+// the others by their keys, so that profiles of the same content are stored
+// the same regardless of the order of their samples.
+func canonicalSamples(samples []*profile.NormalizedSample, keys []string) []*profile.NormalizedSample {
+	kept := &keyedSamples{
+		samples: samples[:0],
+		keys:    keys[:0],
+	}
+	for i, s := range samples {
+		if s.Value == 0 {
+			continue
+		}
+		kept.samples = append(kept.samples, s)
+		kept.keys = append(kept.keys, keys[i])
+	}
+	sort.Sort(kept)
+	return kept.samples
+}
+
+type keyedSamples struct {
+	samples []*profile.NormalizedSample
+	keys    []string
+}
+
+func (s *keyedSamples) Len() int           { return len(s.samples) }
+func (s *keyedSamples) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s *keyedSamples) Swap(i, j int) {
+	s.samples[i], s.samples[j] = s.samples[j], s.samples[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// sampleKey identifies the samples of a stacktrace with the same labels. The
+// labels are ordered by name, so that the same labels have the same key.
 func sampleKey(stacktraceID string, labels map[string]string, numLabels map[string]int64) string {
-	key := stacktraceID + ";"
+	fields := make([]string, 0, len(labels))
 	for k, v := range labels {
-		key += fmt.Sprintf("%s=%s;", k, v)
+		fields = append(fields, k+"="+v)
 	}
-	key += ";"
+	sort.Strings(fields)
+	numFields := make([]string, 0, len(numLabels))
 	for k, v := range numLabels {
-		key += fmt.Sprintf("%s=%d;", k, v)
+		numFields = append(numFields, k+"="+strconv.FormatInt(v, 10))
 	}
-	return key
+	sort.Strings(numFields)
+
+	return stacktraceID + "\xff" + strings.Join(fields, "\xff") + "\xfe" + strings.Join(numFields, "\xff")
 }
 
 // TODO: support num label units.
@@ -221,19 +267,46 @@ func (n *Normalizer) NormalizeFunctions(ctx context.Context, functions []*pprofp
 	return res.Functions, nil
 }
 
+// NormalizeLocations returns the locations of the pprof locations as the leaf
+// of the stacks of the samples and as callers, at the index of the pprof
+// location. The addresses of callers are return addresses, so locations with
+// a mapping and an address are distinct locations as callers, which are
+// symbolized by the call instruction. The location of a pprof location is nil
+// if no sample has it in the position.
 func (n *Normalizer) NormalizeLocations(
 	ctx context.Context,
 	locations []*pprofpb.Location,
+	samples []*pprofpb.Sample,
 	mappings []mappingNormalizationInfo,
 	functions []*pb.Function,
 	normalizedAddress bool,
 	stringTable []string,
-) ([]*pb.Location, error) {
+) ([]*pb.Location, []*pb.Location, error) {
+	isLeaf := make([]bool, len(locations))
+	isCaller := make([]bool, len(locations))
+	for _, sample := range samples {
+		for i, locationId := range sample.LocationId {
+			if i == 0 {
+				isLeaf[locationId-1] = true
+			} else {
+				isCaller[locationId-1] = true
+			}
+		}
+	}
+
 	req := &pb.GetOrCreateLocationsRequest{
 		Locations: make([]*pb.Location, 0, len(locations)),
 	}
+	// leafIndex and callerIndex are the indices of the requested locations
+	// of the pprof locations, -1 if not requested.
+	leafIndex := make([]int, len(locations))
+	callerIndex := make([]int, len(locations))
+	for i, location := range locations {
+		leafIndex[i], callerIndex[i] = -1, -1
+		if !isLeaf[i] && !isCaller[i] {
+			continue
+		}
 
-	for _, location := range locations {
 		if location.MappingId == 0 && len(location.Line) == 0 {
 			req.Locations = append(req.Locations, &pb.Location{
 				// Locations that have no lines and no mapping are never going
@@ -241,6 +314,8 @@ func (n *Normalizer) NormalizeLocations(
 				// make them the same and therefore deduplicate them.
 				Address: UnsymolizableLocationAddress,
 			})
+			leafIndex[i] = len(req.Locations) - 1
+			callerIndex[i] = leafIndex[i]
 			continue
 		}
 
@@ -269,23 +344,57 @@ func (n *Normalizer) NormalizeLocations(
 			})
 		}
 
-		req.Locations = append(req.Locations, &pb.Location{
+		l := &pb.Location{
 			Address:   addr,
 			IsFolded:  location.IsFolded,
 			MappingId: mappingId,
 			Lines:     lines,
-		})
+		}
+		if mappingId == "" || addr == 0 {
+			// The location can't be symbolized by its address.
+			req.Locations = append(req.Locations, l)
+			leafIndex[i] = len(req.Locations) - 1
+			callerIndex[i] = leafIndex[i]
+			continue
+		}
+
+		if isLeaf[i] {
+			req.Locations = append(req.Locations, l)
+			leafIndex[i] = len(req.Locations) - 1
+		}
+		if isCaller[i] {
+			req.Locations = append(req.Locations, &pb.Location{
+				Address:       addr,
+				IsFolded:      location.IsFolded,
+				MappingId:     mappingId,
+				Lines:         lines,
+				ReturnAddress: true,
+			})
+			callerIndex[i] = len(req.Locations) - 1
+		}
 	}
 
 	res, err := n.metastore.GetOrCreateLocations(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return res.Locations, nil
+	leaves := make([]*pb.Location, len(locations))
+	callers := make([]*pb.Location, len(locations))
+	for i := range locations {
+		if leafIndex[i] >= 0 {
+			leaves[i] = res.Locations[leafIndex[i]]
+		}
+		if callerIndex[i] >= 0 {
+			callers[i] = res.Locations[callerIndex[i]]
+		}
+	}
+	return leaves, callers, nil
 }
 
-func (n *Normalizer) NormalizeStacktraces(ctx context.Context, samples []*pprofpb.Sample, locations []*pb.Location) ([]*pb.Stacktrace, error) {
+// NormalizeStacktraces returns the stacktraces of the samples, of the
+// locations of their leaves and callers as returned by NormalizeLocations.
+func (n *Normalizer) NormalizeStacktraces(ctx context.Context, samples []*pprofpb.Sample, leaves, callers []*pb.Location) ([]*pb.Stacktrace, error) {
 	req := &pb.GetOrCreateStacktracesRequest{
 		Stacktraces: make([]*pb.Stacktrace, 0, len(samples)),
 	}
@@ -293,8 +402,12 @@ func (n *Normalizer) NormalizeStacktraces(ctx context.Context, samples []*pprofp
 	for _, sample := range samples {
 		locationIds := make([]string, 0, len(sample.LocationId))
 
-		for _, locationId := range sample.LocationId {
-			locationIds = append(locationIds, locations[locationId-1].Id)
+		for i, locationId := range sample.LocationId {
+			if i == 0 {
+				locationIds = append(locationIds, leaves[locationId-1].Id)
+			} else {
+				locationIds = append(locationIds, callers[locationId-1].Id)
+			}
 		}
 
 		req.Stacktraces = append(req.Stacktraces, &pb.Stacktrace{
@@ -14,11 +14,20 @@
 package parcacol
 
 import (
+	"context"
 	"testing"
 
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 
 	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
+	"github.com/parca-dev/parca/pkg/metastore"
+	"github.com/parca-dev/parca/pkg/metastoretest"
+	"github.com/parca-dev/parca/pkg/profile"
+	"github.com/parca-dev/parca/pkg/tenant"
 )
 
 func TestLabelsFromSample(t *testing.T) {
@@ -75,3 +84,70 @@ func TestLabelsFromSample(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizePprofCanonicalSamples(t *testing.T) {
+	ctx := context.Background()
+	m := metastoretest.NewTestMetastore(
+		t,
+		log.NewNopLogger(),
+		prometheus.NewRegistry(),
+		trace.NewNoopTracerProvider().Tracer(""),
+	)
+	normalizer := NewNormalizer(metastore.NewInProcessClient(m))
+	schema, err := Schema()
+	require.NoError(t, err)
+
+	newProfile := func(samples ...*pprofpb.Sample) *pprofpb.Profile {
+		return &pprofpb.Profile{
+			StringTable: []string{"", "samples", "count", "a.out", "abcd", "thread", "main", "worker"},
+			SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+			Mapping:     []*pprofpb.Mapping{{Id: 1, MemoryStart: 0x1000, MemoryLimit: 0x2000, Filename: 3, BuildId: 4}},
+			Location: []*pprofpb.Location{
+				{Id: 1, MappingId: 1, Address: 0x1010},
+				{Id: 2, MappingId: 1, Address: 0x1020},
+				{Id: 3, MappingId: 1, Address: 0x1030},
+			},
+			Sample:    samples,
+			TimeNanos: 1,
+		}
+	}
+	mainLabels := []*pprofpb.Label{{Key: 5, Str: 6}}
+	workerLabels := []*pprofpb.Label{{Key: 5, Str: 7}}
+
+	p := newProfile(
+		&pprofpb.Sample{LocationId: []uint64{1, 2}, Value: []int64{3}, Label: mainLabels},
+		&pprofpb.Sample{LocationId: []uint64{1, 2}, Value: []int64{1}, Label: workerLabels},
+		&pprofpb.Sample{LocationId: []uint64{2}, Value: []int64{2}},
+	)
+	// The same profile with its samples shuffled and split up, a zero
+	// sample and samples adding up to zero.
+	shuffled := newProfile(
+		&pprofpb.Sample{LocationId: []uint64{2}, Value: []int64{2}},
+		&pprofpb.Sample{LocationId: []uint64{3}, Value: []int64{0}},
+		&pprofpb.Sample{LocationId: []uint64{1, 2}, Value: []int64{1}, Label: workerLabels},
+		&pprofpb.Sample{LocationId: []uint64{1, 2}, Value: []int64{2}, Label: mainLabels},
+		&pprofpb.Sample{LocationId: []uint64{3, 2}, Value: []int64{4}},
+		&pprofpb.Sample{LocationId: []uint64{1, 2}, Value: []int64{1}, Label: mainLabels},
+		&pprofpb.Sample{LocationId: []uint64{3, 2}, Value: []int64{-4}},
+	)
+
+	nps, err := normalizer.NormalizePprof(ctx, "memory", map[string]struct{}{}, p, false)
+	require.NoError(t, err)
+	require.Len(t, nps, 1)
+	require.Len(t, nps[0].Samples, 3)
+	shuffledNps, err := normalizer.NormalizePprof(ctx, "memory", map[string]struct{}{}, shuffled, false)
+	require.NoError(t, err)
+	require.Len(t, shuffledNps, 1)
+
+	require.Equal(t, nps[0], shuffledNps[0])
+	require.Equal(t, profileHash(nps[0]), profileHash(shuffledNps[0]))
+
+	serialize := func(np *profile.NormalizedProfile) []byte {
+		buf, err := NormalizedProfileToParquetBuffer(schema, tenant.Default, labels.Labels{{Name: "job", Value: "test"}}, np)
+		require.NoError(t, err)
+		b, err := schema.SerializeBuffer(buf)
+		require.NoError(t, err)
+		return b
+	}
+	require.Equal(t, serialize(nps[0]), serialize(shuffledNps[0]))
+}
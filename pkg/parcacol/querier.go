@@ -17,6 +17,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -38,6 +39,7 @@ import (
 	profilestorepb "github.com/parca-dev/parca/gen/proto/go/parca/profilestore/v1alpha1"
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/query/v1alpha1"
 	"github.com/parca-dev/parca/pkg/profile"
+	"github.com/parca-dev/parca/pkg/tenant"
 )
 
 var (
@@ -81,23 +83,28 @@ func (q *Querier) Labels(
 ) ([]string, error) {
 	seen := map[string]struct{}{}
 
-	err := q.engine.ScanSchema(q.tableName).
-		Distinct(logicalplan.Col("name")).
-		Filter(logicalplan.Col("name").RegexMatch("^labels\\..+$")).
+	// The label columns of the schema include the labels of all tenants, so
+	// only the columns with values of the tenant's series are returned.
+	err := q.engine.ScanTable(q.tableName).
+		Filter(tenantFilter(ctx)).
+		Distinct(logicalplan.DynCol(ColumnLabels)).
 		Execute(ctx, func(ar arrow.Record) error {
-			if ar.NumCols() != 1 {
-				return fmt.Errorf("expected 1 column, got %d", ar.NumCols())
-			}
+			for i, field := range ar.Schema().Fields() {
+				if !strings.HasPrefix(field.Name, ColumnLabels+".") {
+					continue
+				}
 
-			col := ar.Column(0)
-			stringCol, ok := col.(*array.String)
-			if !ok {
-				return fmt.Errorf("expected string column, got %T", col)
-			}
+				col, ok := ar.Column(i).(*array.Binary)
+				if !ok {
+					return fmt.Errorf("expected binary column, got %T", ar.Column(i))
+				}
 
-			for i := 0; i < stringCol.Len(); i++ {
-				val := stringCol.Value(i)
-				seen[strings.TrimPrefix(val, "labels.")] = struct{}{}
+				for j := 0; j < col.Len(); j++ {
+					if col.IsValid(j) && len(col.Value(j)) > 0 {
+						seen[strings.TrimPrefix(field.Name, ColumnLabels+".")] = struct{}{}
+						break
+					}
+				}
 			}
 
 			return nil
@@ -125,8 +132,13 @@ func (q *Querier) Values(
 	vals := []string{}
 
 	err := q.engine.ScanTable(q.tableName).
+		Filter(tenantFilter(ctx)).
 		Distinct(logicalplan.Col("labels."+labelName)).
 		Execute(ctx, func(ar arrow.Record) error {
+			if ar.NumCols() == 0 {
+				// None of the tenant's series has the label.
+				return nil
+			}
 			if ar.NumCols() != 1 {
 				return fmt.Errorf("expected 1 column, got %d", ar.NumCols())
 			}
@@ -152,8 +164,21 @@ func (q *Querier) Values(
 	return vals, nil
 }
 
+// tenantFilter returns the expression selecting the rows of the tenant of the
+// context. Every query must filter by it, so that tenants can't read each
+// other's data.
+func tenantFilter(ctx context.Context) logicalplan.Expr {
+	return logicalplan.Col(ColumnTenant).Eq(logicalplan.Literal(tenant.FromContext(ctx)))
+}
+
 func MatcherToBooleanExpression(matcher *labels.Matcher) (logicalplan.Expr, error) {
-	ref := logicalplan.Col("labels." + matcher.Name)
+	return matcherToBooleanExpression(ColumnLabels, matcher)
+}
+
+// matcherToBooleanExpression returns the expression of the matcher on the
+// label column of the given dynamic column.
+func matcherToBooleanExpression(column string, matcher *labels.Matcher) (logicalplan.Expr, error) {
+	ref := logicalplan.Col(column + "." + matcher.Name)
 	switch matcher.Type {
 	case labels.MatchEqual:
 		return ref.Eq(logicalplan.Literal(matcher.Value)), nil
@@ -183,7 +208,10 @@ func MatchersToBooleanExpressions(matchers []*labels.Matcher) ([]logicalplan.Exp
 	return exprs, nil
 }
 
-func QueryToFilterExprs(query string) (profile.Meta, []logicalplan.Expr, error) {
+// QueryToFilterExprs returns the expressions selecting the samples of the
+// profiles matching the query. Matchers on the names of pprofLabels select
+// samples by their pprof labels, all others by the labels of their series.
+func QueryToFilterExprs(query string, pprofLabels map[string]struct{}) (profile.Meta, []logicalplan.Expr, error) {
 	parsedSelector, err := parser.ParseMetricSelector(query)
 	if err != nil {
 		return profile.Meta{}, nil, status.Error(codes.InvalidArgument, "failed to parse query")
@@ -211,9 +239,17 @@ func QueryToFilterExprs(query string) (profile.Meta, []logicalplan.Expr, error)
 		delta = true
 	}
 
-	labelFilterExpressions, err := MatchersToBooleanExpressions(sel)
-	if err != nil {
-		return profile.Meta{}, nil, status.Error(codes.InvalidArgument, "failed to build query")
+	labelFilterExpressions := make([]logicalplan.Expr, 0, len(sel))
+	for _, matcher := range sel {
+		column := ColumnLabels
+		if _, ok := pprofLabels[matcher.Name]; ok {
+			column = ColumnPprofLabels
+		}
+		expr, err := matcherToBooleanExpression(column, matcher)
+		if err != nil {
+			return profile.Meta{}, nil, status.Error(codes.InvalidArgument, "failed to build query")
+		}
+		labelFilterExpressions = append(labelFilterExpressions, expr)
 	}
 
 	exprs := append([]logicalplan.Expr{
@@ -238,13 +274,62 @@ func QueryToFilterExprs(query string) (profile.Meta, []logicalplan.Expr, error)
 	}, exprs, nil
 }
 
+// queryToFilterExprs returns the expressions selecting the samples of the
+// profiles matching the query, see QueryToFilterExprs.
+func (q *Querier) queryToFilterExprs(ctx context.Context, query string) (profile.Meta, []logicalplan.Expr, error) {
+	pprofLabels, err := q.pprofLabelNames(ctx)
+	if err != nil {
+		return profile.Meta{}, nil, fmt.Errorf("get pprof label names: %w", err)
+	}
+	return QueryToFilterExprs(query, pprofLabels)
+}
+
+// pprofLabelNames returns the names of the pprof labels of the samples of the
+// tenant. Names that are also used by series labels are left out, these
+// select series. The normalizer renames pprof labels that collide with the
+// labels of their series, so both can be selected.
+func (q *Querier) pprofLabelNames(ctx context.Context) (map[string]struct{}, error) {
+	pprofLabels := map[string]struct{}{}
+	seriesLabels := map[string]struct{}{}
+	err := q.engine.ScanSchema(q.tableName).
+		Filter(tenantFilter(ctx)).
+		Execute(ctx, func(ar arrow.Record) error {
+			col, ok := ar.Column(0).(*array.String)
+			if !ok {
+				return fmt.Errorf("expected string column, got %T", ar.Column(0))
+			}
+			for i := 0; i < col.Len(); i++ {
+				name := col.Value(i)
+				switch {
+				case strings.HasPrefix(name, ColumnPprofLabels+"."):
+					pprofLabels[strings.TrimPrefix(name, ColumnPprofLabels+".")] = struct{}{}
+				case strings.HasPrefix(name, ColumnLabels+"."):
+					seriesLabels[strings.TrimPrefix(name, ColumnLabels+".")] = struct{}{}
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range seriesLabels {
+		delete(pprofLabels, name)
+	}
+	return pprofLabels, nil
+}
+
+// QueryRange returns the series of the profiles matching the query within the
+// time range. If step is greater than zero, the values of each series are
+// summed up per step, otherwise every profile is a sample of its series.
 func (q *Querier) QueryRange(
 	ctx context.Context,
 	query string,
 	startTime, endTime time.Time,
+	step time.Duration,
 	limit uint32,
 ) ([]*pb.MetricsSeries, error) {
-	_, selectorExprs, err := QueryToFilterExprs(query)
+	_, selectorExprs, err := q.queryToFilterExprs(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -254,6 +339,7 @@ func (q *Querier) QueryRange(
 
 	exprs := append(
 		selectorExprs,
+		tenantFilter(ctx),
 		logicalplan.Col("timestamp").Gt(logicalplan.Literal(start)),
 		logicalplan.Col("timestamp").Lt(logicalplan.Literal(end)),
 	)
@@ -362,11 +448,40 @@ func (q *Querier) QueryRange(
 		sort.Slice(series.Samples, func(i, j int) bool {
 			return series.Samples[i].Timestamp.AsTime().Before(series.Samples[j].Timestamp.AsTime())
 		})
+		// Steps shorter than the millisecond precision of the timestamps
+		// are treated as no step.
+		if step.Milliseconds() > 0 {
+			series.Samples = sumPerStep(series.Samples, start, step.Milliseconds())
+		}
 	}
 
 	return resSeries, nil
 }
 
+// sumPerStep sums up the values of the sorted samples within each step into a
+// sample at the start of the step. Steps are aligned to the start of the query.
+func sumPerStep(samples []*pb.MetricsSample, start, step int64) []*pb.MetricsSample {
+	res := samples[:0]
+	var cur *pb.MetricsSample
+	curStep := int64(0)
+	for _, s := range samples {
+		ts := timestamp.FromTime(s.Timestamp.AsTime())
+		stepStart := start + (ts-start)/step*step
+		if cur != nil && stepStart == curStep {
+			cur.Value += s.Value
+			continue
+		}
+
+		cur = &pb.MetricsSample{
+			Timestamp: timestamppb.New(timestamp.Time(stepStart)),
+			Value:     s.Value,
+		}
+		curStep = stepStart
+		res = append(res, cur)
+	}
+	return res
+}
+
 func (q *Querier) ProfileTypes(
 	ctx context.Context,
 ) ([]*pb.ProfileType, error) {
@@ -374,6 +489,10 @@ func (q *Querier) ProfileTypes(
 	res := []*pb.ProfileType{}
 
 	err := q.engine.ScanTable(q.tableName).
+		Filter(tenantFilter(ctx)).
+		// The projection of the filtered column is needed for the projection
+		// of "duration > 0" to be pushed down below the filter.
+		Project(logicalplan.Col(ColumnTenant)).
 		Distinct(
 			logicalplan.Col(ColumnName),
 			logicalplan.Col(ColumnSampleType),
@@ -506,7 +625,7 @@ func (q *Querier) QuerySingle(
 	ctx, span := q.tracer.Start(ctx, "QuerySingle")
 	defer span.End()
 
-	ar, valueColumn, meta, err := q.findSingle(ctx, query, time)
+	ar, valueColumn, meta, duplicates, err := q.findSingle(ctx, query, time)
 	if err != nil {
 		return nil, err
 	}
@@ -529,11 +648,15 @@ func (q *Querier) QuerySingle(
 	if p == nil {
 		return nil, status.Error(codes.NotFound, "could not find profile at requested time and selectors")
 	}
+	p.Samples = append(p.Samples, duplicates...)
 
 	return p, nil
 }
 
-func (q *Querier) findSingle(ctx context.Context, query string, t time.Time) (arrow.Record, string, profile.Meta, error) {
+// findSingle returns the samples of the profiles matching the query at the
+// time, along with the samples of the profiles that the duplicate ones among
+// them refer to.
+func (q *Querier) findSingle(ctx context.Context, query string, t time.Time) (arrow.Record, string, profile.Meta, []*profile.SymbolizedSample, error) {
 	requestedTime := timestamp.FromTime(t)
 
 	ctx, span := q.tracer.Start(ctx, "findSingle")
@@ -541,14 +664,15 @@ func (q *Querier) findSingle(ctx context.Context, query string, t time.Time) (ar
 	span.SetAttributes(attribute.Int64("time", t.Unix()))
 	defer span.End()
 
-	meta, selectorExprs, err := QueryToFilterExprs(query)
+	meta, selectorExprs, err := q.queryToFilterExprs(ctx, query)
 	if err != nil {
-		return nil, "", profile.Meta{}, err
+		return nil, "", profile.Meta{}, nil, err
 	}
 
 	filterExpr := logicalplan.And(
 		append(
 			selectorExprs,
+			tenantFilter(ctx),
 			logicalplan.Col("timestamp").Eq(logicalplan.Literal(requestedTime)),
 		)...,
 	)
@@ -568,7 +692,15 @@ func (q *Querier) findSingle(ctx context.Context, query string, t time.Time) (ar
 			return nil
 		})
 	if err != nil {
-		return nil, "", profile.Meta{}, fmt.Errorf("execute query: %w", err)
+		return nil, "", profile.Meta{}, nil, fmt.Errorf("execute query: %w", err)
+	}
+
+	duplicates, err := q.resolveDuplicates(ctx, filterExpr, selectorExprs, nil)
+	if err != nil {
+		if ar != nil {
+			ar.Release()
+		}
+		return nil, "", profile.Meta{}, nil, err
 	}
 
 	return ar,
@@ -579,19 +711,29 @@ func (q *Querier) findSingle(ctx context.Context, query string, t time.Time) (ar
 			PeriodType: meta.PeriodType,
 			Timestamp:  requestedTime,
 		},
+		duplicates,
 		nil
 }
 
+// QueryMerge merges the profiles matching the query within the time range into
+// a single profile. Samples with the same stacktrace are summed up.
 func (q *Querier) QueryMerge(ctx context.Context, query string, start, end time.Time) (*profile.Profile, error) {
 	ctx, span := q.tracer.Start(ctx, "QueryMerge")
 	defer span.End()
 
-	r, valueColumn, meta, err := q.selectMerge(ctx, query, start, end)
+	r, valueColumn, meta, duplicates, err := q.selectMerge(ctx, query, start, end)
 	if err != nil {
 		return nil, err
 	}
+	if r == nil {
+		return nil, status.Error(codes.NotFound, "could not find profiles within the requested time range and selectors")
+	}
 	defer r.Release()
 
+	if r.NumRows() == 0 {
+		return nil, status.Error(codes.NotFound, "could not find profiles within the requested time range and selectors")
+	}
+
 	p, err := q.arrowRecordToProfile(
 		ctx,
 		r,
@@ -601,17 +743,21 @@ func (q *Querier) QueryMerge(ctx context.Context, query string, start, end time.
 	if err != nil {
 		return nil, err
 	}
+	p.Samples = append(p.Samples, duplicates...)
 
 	return p, nil
 }
 
-func (q *Querier) selectMerge(ctx context.Context, query string, startTime, endTime time.Time) (arrow.Record, string, profile.Meta, error) {
+// selectMerge returns the samples of the profiles matching the query within
+// the time range summed up by stacktrace, along with the samples of the
+// profiles that the duplicate ones among them refer to.
+func (q *Querier) selectMerge(ctx context.Context, query string, startTime, endTime time.Time) (arrow.Record, string, profile.Meta, []*profile.SymbolizedSample, error) {
 	ctx, span := q.tracer.Start(ctx, "selectMerge")
 	defer span.End()
 
-	meta, selectorExprs, err := QueryToFilterExprs(query)
+	meta, selectorExprs, err := q.queryToFilterExprs(ctx, query)
 	if err != nil {
-		return nil, "", profile.Meta{}, err
+		return nil, "", profile.Meta{}, nil, err
 	}
 
 	start := timestamp.FromTime(startTime)
@@ -620,6 +766,7 @@ func (q *Querier) selectMerge(ctx context.Context, query string, startTime, endT
 	filterExpr := logicalplan.And(
 		append(
 			selectorExprs,
+			tenantFilter(ctx),
 			logicalplan.Col("timestamp").Gt(logicalplan.Literal(start)),
 			logicalplan.Col("timestamp").Lt(logicalplan.Literal(end)),
 		)...,
@@ -638,7 +785,15 @@ func (q *Querier) selectMerge(ctx context.Context, query string, startTime, endT
 			return nil
 		})
 	if err != nil {
-		return nil, "", profile.Meta{}, err
+		return nil, "", profile.Meta{}, nil, err
+	}
+
+	duplicates, err := q.resolveDuplicates(ctx, filterExpr, selectorExprs, nil)
+	if err != nil {
+		if ar != nil {
+			ar.Release()
+		}
+		return nil, "", profile.Meta{}, nil, err
 	}
 
 	return ar,
@@ -649,5 +804,108 @@ func (q *Querier) selectMerge(ctx context.Context, query string, startTime, endT
 			PeriodType: meta.PeriodType,
 			Timestamp:  start,
 		},
+		duplicates,
 		nil
 }
+
+// QueryInstant merges the newest profile at or before the time of every series
+// matching the query into a single profile.
+func (q *Querier) QueryInstant(ctx context.Context, query string, t time.Time) (*profile.Profile, error) {
+	ctx, span := q.tracer.Start(ctx, "QueryInstant")
+	span.SetAttributes(attribute.String("query", query))
+	span.SetAttributes(attribute.Int64("time", t.Unix()))
+	defer span.End()
+
+	meta, selectorExprs, err := q.queryToFilterExprs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	requestedTime := timestamp.FromTime(t)
+	exprs := append(
+		selectorExprs[:len(selectorExprs):len(selectorExprs)],
+		tenantFilter(ctx),
+		logicalplan.Col(ColumnTimestamp).LtEq(logicalplan.Literal(requestedTime)),
+	)
+
+	// The timestamp of the newest profile of every series.
+	newest := map[string]int64{}
+	err = q.scan(ctx, logicalplan.And(exprs...), func(ar arrow.Record) error {
+		timestamps, err := int64FieldFromRecord(ar, ColumnTimestamp)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < int(ar.NumRows()); i++ {
+			series := seriesOfRow(ar, i)
+			if ts, ok := newest[series]; !ok || timestamps.Value(i) > ts {
+				newest[series] = timestamps.Value(i)
+			}
+		}
+		return nil
+	}, logicalplan.DynCol(ColumnLabels), logicalplan.Col(ColumnTimestamp))
+	if err != nil {
+		return nil, fmt.Errorf("select newest profiles: %w", err)
+	}
+	if len(newest) == 0 {
+		return nil, status.Error(codes.NotFound, "could not find profile at or before requested time and selectors")
+	}
+
+	oldest := requestedTime
+	for _, ts := range newest {
+		if ts < oldest {
+			oldest = ts
+		}
+	}
+	isNewest := func(series string, ts int64) bool {
+		newestTS, ok := newest[series]
+		return ok && newestTS == ts
+	}
+	filterExpr := logicalplan.And(append(
+		exprs,
+		logicalplan.Col(ColumnTimestamp).GtEq(logicalplan.Literal(oldest)),
+	)...)
+
+	meta = profile.Meta{
+		Name:       meta.Name,
+		SampleType: meta.SampleType,
+		PeriodType: meta.PeriodType,
+		Timestamp:  requestedTime,
+	}
+
+	var samples []*profile.SymbolizedSample
+	err = q.scan(ctx, logicalplan.And(
+		filterExpr,
+		logicalplan.Col(ColumnStacktrace).RegexNotMatch("^"+regexp.QuoteMeta(duplicateStacktracePrefix)),
+	), func(ar arrow.Record) error {
+		p, err := q.arrowRecordToProfile(ctx, ar, "sum(value)", meta)
+		if err != nil {
+			return err
+		}
+		timestamps, err := int64FieldFromRecord(ar, ColumnTimestamp)
+		if err != nil {
+			return err
+		}
+
+		// The samples are the rows of the record in order, as it has no
+		// duplicates.
+		for i, s := range p.Samples {
+			if isNewest(seriesOfRow(ar, i), timestamps.Value(i)) {
+				samples = append(samples, s)
+			}
+		}
+		return nil
+	}, logicalplan.Col(ColumnStacktrace), logicalplan.DynCol(ColumnPprofLabels), logicalplan.DynCol(ColumnPprofNumLabels), logicalplan.DynCol(ColumnLabels), logicalplan.Col(ColumnTimestamp))
+	if err != nil {
+		return nil, fmt.Errorf("select newest profile samples: %w", err)
+	}
+
+	duplicates, err := q.resolveDuplicates(ctx, filterExpr, selectorExprs, isNewest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile.Profile{
+		Samples: append(samples, duplicates...),
+		Meta:    meta,
+	}, nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parcacol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/segmentio/parquet-go"
+	"github.com/thanos-io/objstore"
+)
+
+// Retention deletes the blocks of a table that were persisted to the object
+// storage once all of their samples are older than the retention window.
+// Samples that are still held in memory are not affected, they are only
+// dropped from memory when the block they belong to is persisted.
+type Retention struct {
+	logger    log.Logger
+	bucket    objstore.Bucket
+	dir       string
+	retention time.Duration
+	// trimmer, if set, trims the metastore once blocks were deleted.
+	trimmer *Trimmer
+
+	deletedBlocks prometheus.Counter
+}
+
+// NewRetention returns a Retention enforcing the retention window on the
+// blocks of the table of the database in the bucket the columnstore persists
+// blocks to.
+func NewRetention(logger log.Logger, reg prometheus.Registerer, bucket objstore.Bucket, db, table string, retention time.Duration) *Retention {
+	deletedBlocks := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parca_storage_retention_deleted_blocks_total",
+		Help: "Total number of persisted blocks deleted because all of their samples were older than the retention window.",
+	})
+	reg.MustRegister(deletedBlocks)
+
+	return &Retention{
+		logger:        logger,
+		bucket:        bucket,
+		dir:           path.Join(db, table),
+		retention:     retention,
+		deletedBlocks: deletedBlocks,
+	}
+}
+
+// SetTrimmer makes the retention trim the metastore whenever it deleted
+// blocks, so that the entries only the deleted samples referred to are
+// deleted as well.
+func (r *Retention) SetTrimmer(t *Trimmer) {
+	r.trimmer = t
+}
+
+// Run enforces the retention window in the given interval until the context
+// is canceled.
+func (r *Retention) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			deleted, err := r.Enforce(ctx)
+			if err != nil {
+				level.Warn(r.logger).Log("msg", "failed to enforce retention", "err", err)
+			}
+			if deleted > 0 && r.trimmer != nil {
+				if _, err := r.trimmer.Trim(ctx); err != nil {
+					level.Warn(r.logger).Log("msg", "failed to trim metastore", "err", err)
+				}
+			}
+		}
+	}
+}
+
+// Enforce deletes the blocks whose samples are all older than the retention
+// window. It returns the number of deleted blocks.
+func (r *Retention) Enforce(ctx context.Context) (int, error) {
+	before := timestamp.FromTime(time.Now().Add(-r.retention))
+
+	var blocks []string
+	err := r.bucket.Iter(ctx, r.dir, func(name string) error {
+		if _, err := ulid.Parse(path.Base(name)); err != nil {
+			// Not a block.
+			return nil
+		}
+		blocks = append(blocks, strings.TrimSuffix(name, objstore.DirDelim))
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list blocks: %w", err)
+	}
+
+	deleted := 0
+	for _, block := range blocks {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		logger := log.With(r.logger, "block", block)
+		maxTimestamp, err := r.maxTimestamp(ctx, path.Join(block, "data.parquet"))
+		if err != nil {
+			level.Warn(logger).Log("msg", "failed to read block, skipping it", "err", err)
+			continue
+		}
+		if maxTimestamp >= before {
+			continue
+		}
+
+		if err := r.bucket.Delete(ctx, path.Join(block, "data.parquet")); err != nil && !r.bucket.IsObjNotFoundErr(err) {
+			return deleted, fmt.Errorf("delete block %q: %w", block, err)
+		}
+		deleted++
+		r.deletedBlocks.Inc()
+		level.Debug(logger).Log("msg", "deleted block older than retention", "max_timestamp", maxTimestamp)
+	}
+
+	level.Info(r.logger).Log("msg", "enforced retention", "blocks", len(blocks), "deleted", deleted)
+	return deleted, nil
+}
+
+// maxTimestamp returns the timestamp of the most recent sample of the block.
+// Only the pages of the timestamp column are read.
+func (r *Retention) maxTimestamp(ctx context.Context, name string) (int64, error) {
+	attrs, err := r.bucket.Attributes(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := parquet.OpenFile(&bucketReaderAt{ctx: ctx, bucket: r.bucket, name: name}, attrs.Size)
+	if err != nil {
+		return 0, err
+	}
+
+	leaf, ok := f.Schema().Lookup(ColumnTimestamp)
+	if !ok {
+		return 0, ErrTimestampColumnNotFound
+	}
+
+	max := int64(math.MinInt64)
+	for _, rg := range f.RowGroups() {
+		pages := rg.ColumnChunks()[leaf.ColumnIndex].Pages()
+		for {
+			p, err := pages.ReadPage()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				pages.Close()
+				return 0, err
+			}
+			if _, pageMax, ok := p.Bounds(); ok && pageMax.Int64() > max {
+				max = pageMax.Int64()
+			}
+		}
+		pages.Close()
+	}
+
+	return max, nil
+}
+
+// bucketReaderAt reads an object of a bucket with range requests.
+type bucketReaderAt struct {
+	ctx    context.Context
+	bucket objstore.Bucket
+	name   string
+}
+
+func (b *bucketReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	rc, err := b.bucket.GetRange(b.ctx, b.name, off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	return io.ReadFull(rc, p)
+}
@@ -0,0 +1,242 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parcacol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v8/arrow"
+	"github.com/apache/arrow/go/v8/arrow/array"
+	"github.com/apache/arrow/go/v8/arrow/memory"
+	"github.com/go-kit/log"
+	"github.com/polarsignals/frostdb"
+	"github.com/polarsignals/frostdb/query"
+	"github.com/polarsignals/frostdb/query/logicalplan"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"go.opentelemetry.io/otel/trace"
+
+	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
+	metastorepb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/metastore"
+	"github.com/parca-dev/parca/pkg/metastoretest"
+	"github.com/parca-dev/parca/pkg/profile"
+	"github.com/parca-dev/parca/pkg/tenant"
+)
+
+func TestRetention(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	bucket := objstore.NewInMemBucket()
+
+	col, err := frostdb.New(
+		logger,
+		prometheus.NewRegistry(),
+		frostdb.WithBucketStorage(objstore.NewPrefixedBucket(bucket, "blocks")),
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(ctx, "parca")
+	require.NoError(t, err)
+
+	schema, err := Schema()
+	require.NoError(t, err)
+	table, err := colDB.Table("stacktraces", frostdb.NewTableConfig(schema))
+	require.NoError(t, err)
+
+	insert := func(ts time.Time) {
+		buf, err := NormalizedProfileToParquetBuffer(schema, tenant.Default, labels.Labels{{Name: "job", Value: "test"}}, &profile.NormalizedProfile{
+			Meta: profile.Meta{
+				Name:       "memory",
+				Timestamp:  timestamp.FromTime(ts),
+				SampleType: profile.ValueType{Type: "alloc_objects", Unit: "count"},
+			},
+			Samples: []*profile.NormalizedSample{{StacktraceID: "stacktrace", Value: 1}},
+		})
+		require.NoError(t, err)
+		_, err = table.InsertBuffer(ctx, buf)
+		require.NoError(t, err)
+	}
+
+	persisted := func() int {
+		n := 0
+		require.NoError(t, bucket.Iter(ctx, "blocks/parca/stacktraces", func(string) error {
+			n++
+			return nil
+		}))
+		return n
+	}
+
+	// Persist a block of old samples and one of recent samples.
+	now := time.Now()
+	insert(now.Add(-3 * time.Hour))
+	insert(now.Add(-2 * time.Hour))
+	require.NoError(t, table.RotateBlock(table.ActiveBlock()))
+	require.Eventually(t, func() bool { return persisted() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	insert(now.Add(-2 * time.Hour))
+	insert(now.Add(-time.Minute))
+	require.NoError(t, table.RotateBlock(table.ActiveBlock()))
+	require.Eventually(t, func() bool { return persisted() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	insert(now)
+
+	engine := query.NewEngine(memory.DefaultAllocator, colDB.TableProvider())
+	timestamps := func() []int64 {
+		var ts []int64
+		require.NoError(t, engine.ScanTable("stacktraces").
+			Project(logicalplan.Col(ColumnTimestamp)).
+			Execute(ctx, func(ar arrow.Record) error {
+				col := ar.Column(0).(*array.Int64)
+				for i := 0; i < col.Len(); i++ {
+					ts = append(ts, col.Value(i))
+				}
+				return nil
+			}))
+		return ts
+	}
+	require.Eventually(t, func() bool { return len(timestamps()) == 5 }, 5*time.Second, 10*time.Millisecond)
+
+	r := NewRetention(logger, prometheus.NewRegistry(), objstore.NewPrefixedBucket(bucket, "blocks"), "parca", "stacktraces", time.Hour)
+	deleted, err := r.Enforce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+	require.Equal(t, 1, persisted())
+
+	// The block with a recent sample is kept entirely.
+	require.ElementsMatch(t, []int64{
+		timestamp.FromTime(now.Add(-2 * time.Hour)),
+		timestamp.FromTime(now.Add(-time.Minute)),
+		timestamp.FromTime(now),
+	}, timestamps())
+
+	deleted, err = r.Enforce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, deleted)
+}
+
+func TestRetentionTrimsMetastore(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	bucket := objstore.NewInMemBucket()
+
+	col, err := frostdb.New(
+		logger,
+		prometheus.NewRegistry(),
+		frostdb.WithBucketStorage(objstore.NewPrefixedBucket(bucket, "blocks")),
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(ctx, "parca")
+	require.NoError(t, err)
+
+	schema, err := Schema()
+	require.NoError(t, err)
+	table, err := colDB.Table("stacktraces", frostdb.NewTableConfig(schema))
+	require.NoError(t, err)
+
+	m := metastoretest.NewTestMetastore(t, logger, prometheus.NewRegistry(), trace.NewNoopTracerProvider().Tracer(""))
+	mc := metastore.NewInProcessClient(m)
+	engine := query.NewEngine(memory.DefaultAllocator, colDB.TableProvider())
+	trimmer := NewTrimmer(logger, prometheus.NewRegistry(), engine, "stacktraces", m.(*metastore.BadgerMetastore), 2)
+	ingester := NewIngester(logger, NewNormalizer(mc), table, schema)
+	ingester.SetTrimmer(trimmer)
+
+	// The locations 1 to 4 are in the functions "main", "a", "b" and "c",
+	// location 5 is not symbolized.
+	ingest := func(ts time.Time, stacks ...[]uint64) {
+		p := &pprofpb.Profile{
+			StringTable: []string{"", "alloc_objects", "count", "main", "a", "b", "c", "a.out", "abc"},
+			SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+			TimeNanos:   ts.UnixNano(),
+			Mapping:     []*pprofpb.Mapping{{Id: 1, MemoryStart: 0x1000, MemoryLimit: 0x2000, Filename: 7, BuildId: 8}},
+			Function:    []*pprofpb.Function{{Id: 1, Name: 3}, {Id: 2, Name: 4}, {Id: 3, Name: 5}, {Id: 4, Name: 6}},
+			Location: []*pprofpb.Location{
+				{Id: 1, MappingId: 1, Address: 0x1001, Line: []*pprofpb.Line{{FunctionId: 1, Line: 1}}},
+				{Id: 2, MappingId: 1, Address: 0x1002, Line: []*pprofpb.Line{{FunctionId: 2, Line: 2}}},
+				{Id: 3, MappingId: 1, Address: 0x1003, Line: []*pprofpb.Line{{FunctionId: 3, Line: 3}}},
+				{Id: 4, MappingId: 1, Address: 0x1004, Line: []*pprofpb.Line{{FunctionId: 4, Line: 4}}},
+				{Id: 5, MappingId: 1, Address: 0x1005},
+			},
+		}
+		for _, stack := range stacks {
+			p.Sample = append(p.Sample, &pprofpb.Sample{LocationId: stack, Value: []int64{1}})
+		}
+		require.NoError(t, ingester.Ingest(ctx, labels.Labels{{Name: "__name__", Value: "memory"}, {Name: "job", Value: "test"}}, p, false))
+	}
+
+	persisted := func() int {
+		n := 0
+		require.NoError(t, bucket.Iter(ctx, "blocks/parca/stacktraces", func(string) error {
+			n++
+			return nil
+		}))
+		return n
+	}
+
+	// The stacks of "b" and of the unsymbolized location are only in a
+	// persisted block older than the retention, the ones of "a" and "c" are
+	// in memory.
+	now := time.Now()
+	ingest(now.Add(-3*time.Hour), []uint64{2, 1}, []uint64{3, 1}, []uint64{5, 1})
+	require.NoError(t, table.RotateBlock(table.ActiveBlock()))
+	require.Eventually(t, func() bool { return persisted() == 1 }, 5*time.Second, 10*time.Millisecond)
+	ingest(now, []uint64{2, 1}, []uint64{4, 1})
+
+	r := NewRetention(logger, prometheus.NewRegistry(), objstore.NewPrefixedBucket(bucket, "blocks"), "parca", "stacktraces", time.Hour)
+	r.SetTrimmer(trimmer)
+	deleted, err := r.Enforce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	stats, err := trimmer.Trim(ctx)
+	require.NoError(t, err)
+	require.Equal(t, metastore.TrimStats{Stacktraces: 2, Locations: 2, Functions: 1}, stats)
+
+	fres, err := mc.ListFunctions(ctx, &metastorepb.ListFunctionsRequest{})
+	require.NoError(t, err)
+	functions := []string{}
+	for _, f := range fres.Functions {
+		functions = append(functions, f.Name)
+	}
+	require.ElementsMatch(t, []string{"main", "a", "c"}, functions)
+
+	lres, err := mc.ListLocations(ctx, &metastorepb.ListLocationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, lres.Locations, 3)
+	ares, err := mc.LocationsByAddress(ctx, &metastorepb.LocationsByAddressRequest{MappingId: lres.Locations[0].MappingId})
+	require.NoError(t, err)
+	addresses := []uint64{}
+	for _, l := range ares.Locations {
+		addresses = append(addresses, l.Address)
+	}
+	require.Equal(t, []uint64{0x1001, 0x1002, 0x1004}, addresses)
+	ures, err := mc.UnsymbolizedLocations(ctx, &metastorepb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, ures.Locations)
+
+	// The samples in memory can still be resolved.
+	p, err := NewQuerier(trace.NewNoopTracerProvider().Tracer(""), engine, "stacktraces", mc).
+		QuerySingle(ctx, `memory:alloc_objects:count::{job="test"}`, now)
+	require.NoError(t, err)
+	require.Len(t, p.Samples, 2)
+
+	// Nothing is left to trim.
+	stats, err = trimmer.Trim(ctx)
+	require.NoError(t, err)
+	require.Equal(t, metastore.TrimStats{}, stats)
+}
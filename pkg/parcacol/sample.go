@@ -24,9 +24,9 @@ import (
 	"github.com/parca-dev/parca/pkg/profile"
 )
 
-// NormalizedProfileToParquetBuffer converts a normalized profile to a Parquet
-// buffer. The passed labels must be sorted.
-func NormalizedProfileToParquetBuffer(schema *dynparquet.Schema, ls labels.Labels, p *profile.NormalizedProfile) (*dynparquet.Buffer, error) {
+// NormalizedProfileToParquetBuffer converts a normalized profile of the tenant
+// to a Parquet buffer. The passed labels must be sorted.
+func NormalizedProfileToParquetBuffer(schema *dynparquet.Schema, tenant string, ls labels.Labels, p *profile.NormalizedProfile) (*dynparquet.Buffer, error) {
 	names := labelNames(ls)
 	pprofLabels := profileLabelNames(p)
 	pprofNumLabels := profileNumLabelNames(p)
@@ -47,6 +47,7 @@ func NormalizedProfileToParquetBuffer(schema *dynparquet.Schema, ls labels.Label
 			r[:0],
 			pprofLabels,
 			pprofNumLabels,
+			tenant,
 			ls,
 			p.Meta,
 			sample,
@@ -105,12 +106,13 @@ func profileNumLabelNames(p *profile.NormalizedProfile) []string {
 	return names
 }
 
-// SampleToParquetRow converts a sample to a Parquet row. The passed labels
-// must be sorted.
+// SampleToParquetRow converts a sample of the tenant to a Parquet row. The
+// passed labels must be sorted.
 func SampleToParquetRow(
 	schema *dynparquet.Schema,
 	row parquet.Row,
 	profileLabelNames, profileNumLabelNames []string,
+	tenant string,
 	ls labels.Labels,
 	meta profile.Meta,
 	s *profile.NormalizedSample,
@@ -145,6 +147,9 @@ func SampleToParquetRow(
 		case ColumnStacktrace:
 			row = append(row, parquet.ValueOf(s.StacktraceID).Level(0, 0, columnIndex))
 			columnIndex++
+		case ColumnTenant:
+			row = append(row, parquet.ValueOf(tenant).Level(0, 0, columnIndex))
+			columnIndex++
 		case ColumnTimestamp:
 			row = append(row, parquet.ValueOf(meta.Timestamp).Level(0, 0, columnIndex))
 			columnIndex++
@@ -32,6 +32,7 @@ const (
 	ColumnSampleType     = "sample_type"
 	ColumnSampleUnit     = "sample_unit"
 	ColumnStacktrace     = "stacktrace"
+	ColumnTenant         = "tenant"
 	ColumnTimestamp      = "timestamp"
 	ColumnValue          = "value"
 )
@@ -121,6 +122,13 @@ func Schema() (*dynparquet.Schema, error) {
 					Compression: schemapb.StorageLayout_COMPRESSION_ZSTD,
 				},
 				Dynamic: false,
+			}, {
+				Name: ColumnTenant,
+				StorageLayout: &schemapb.StorageLayout{
+					Type:     schemapb.StorageLayout_TYPE_STRING,
+					Encoding: schemapb.StorageLayout_ENCODING_RLE_DICTIONARY,
+				},
+				Dynamic: false,
 			}, {
 				Name: ColumnTimestamp,
 				StorageLayout: &schemapb.StorageLayout{
@@ -141,6 +149,9 @@ func Schema() (*dynparquet.Schema, error) {
 		},
 		SortingColumns: []*schemapb.SortingColumn{
 			{
+				Name:      ColumnTenant,
+				Direction: schemapb.SortingColumn_DIRECTION_ASCENDING,
+			}, {
 				Name:      ColumnName,
 				Direction: schemapb.SortingColumn_DIRECTION_ASCENDING,
 			}, {
@@ -0,0 +1,168 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parcacol
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/apache/arrow/go/v8/arrow"
+	"github.com/apache/arrow/go/v8/arrow/array"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/polarsignals/frostdb/query/logicalplan"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/parca-dev/parca/pkg/metastore"
+	"github.com/parca-dev/parca/pkg/profile"
+)
+
+// MetastoreTrimmer deletes the metastore entries that are not referenced by
+// any of the given stacktraces.
+type MetastoreTrimmer interface {
+	Trim(ctx context.Context, referenced map[string]struct{}, batchSize int) (metastore.TrimStats, error)
+}
+
+// Trimmer deletes the stacktraces, locations and functions from the metastore
+// that no sample of a table refers to anymore, e.g. once retention deleted
+// the samples referring to them.
+type Trimmer struct {
+	logger    log.Logger
+	engine    Engine
+	tableName string
+	metastore MetastoreTrimmer
+	batchSize int
+
+	// ingestion is held for reading by ingesters while they create the
+	// metastore entries of a profile and insert its samples, and for writing
+	// while entries are deleted, so that the entries of profiles being
+	// ingested are never deleted.
+	ingestion sync.RWMutex
+	// tracked are the stacktraces of the profiles ingested while trimming
+	// looks for the stacktraces referenced by the table, nil if it doesn't.
+	trackedMtx sync.Mutex
+	tracked    map[string]struct{}
+
+	trimmed *prometheus.CounterVec
+}
+
+// NewTrimmer returns a Trimmer deleting the metastore entries not referenced
+// by the samples of the table, in transactions of up to batchSize entries.
+func NewTrimmer(logger log.Logger, reg prometheus.Registerer, engine Engine, tableName string, metastore MetastoreTrimmer, batchSize int) *Trimmer {
+	trimmed := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "parca_storage_trimmed_metastore_entries_total",
+		Help: "Total number of metastore entries deleted because no sample referred to them anymore.",
+	}, []string{"type"})
+	reg.MustRegister(trimmed)
+
+	return &Trimmer{
+		logger:    logger,
+		engine:    engine,
+		tableName: tableName,
+		metastore: metastore,
+		batchSize: batchSize,
+		trimmed:   trimmed,
+	}
+}
+
+// Trim deletes the metastore entries that no sample of the table refers to.
+// Ingestion is blocked only while the entries are deleted.
+func (t *Trimmer) Trim(ctx context.Context) (metastore.TrimStats, error) {
+	// Profiles that are being ingested already may have created their
+	// entries without their samples being inserted yet, so the stacktraces
+	// of profiles are tracked before the table is read.
+	t.ingestion.Lock()
+	t.trackedMtx.Lock()
+	t.tracked = map[string]struct{}{}
+	t.trackedMtx.Unlock()
+	t.ingestion.Unlock()
+
+	referenced, err := t.referencedStacktraces(ctx)
+
+	t.ingestion.Lock()
+	defer t.ingestion.Unlock()
+
+	t.trackedMtx.Lock()
+	tracked := t.tracked
+	t.tracked = nil
+	t.trackedMtx.Unlock()
+
+	if err != nil {
+		return metastore.TrimStats{}, fmt.Errorf("read referenced stacktraces: %w", err)
+	}
+	for id := range tracked {
+		referenced[id] = struct{}{}
+	}
+
+	stats, err := t.metastore.Trim(ctx, referenced, t.batchSize)
+	t.trimmed.WithLabelValues("stacktrace").Add(float64(stats.Stacktraces))
+	t.trimmed.WithLabelValues("location").Add(float64(stats.Locations))
+	t.trimmed.WithLabelValues("function").Add(float64(stats.Functions))
+	if err != nil {
+		return stats, fmt.Errorf("trim metastore: %w", err)
+	}
+
+	level.Info(t.logger).Log("msg", "trimmed metastore", "referenced_stacktraces", len(referenced), "stacktraces", stats.Stacktraces, "locations", stats.Locations, "functions", stats.Functions)
+	return stats, nil
+}
+
+// referencedStacktraces returns the IDs of the stacktraces of all samples of
+// the table, of all tenants.
+func (t *Trimmer) referencedStacktraces(ctx context.Context) (map[string]struct{}, error) {
+	referenced := map[string]struct{}{}
+	err := t.engine.ScanTable(t.tableName).
+		Distinct(logicalplan.Col(ColumnStacktrace)).
+		Execute(ctx, func(ar arrow.Record) error {
+			if ar.NumCols() != 1 {
+				return fmt.Errorf("expected 1 column, got %d", ar.NumCols())
+			}
+			col, ok := ar.Column(0).(*array.Binary)
+			if !ok {
+				return fmt.Errorf("expected binary column, got %T", ar.Column(0))
+			}
+			for i := 0; i < col.Len(); i++ {
+				referenced[string(col.Value(i))] = struct{}{}
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return referenced, nil
+}
+
+// startIngest is called by ingesters before they create the metastore
+// entries of a profile, the returned function once its samples were
+// inserted.
+func (t *Trimmer) startIngest() func() {
+	t.ingestion.RLock()
+	return t.ingestion.RUnlock
+}
+
+// track records the stacktraces of ingested profiles if trimming is looking
+// for the referenced stacktraces.
+func (t *Trimmer) track(profiles []*profile.NormalizedProfile) {
+	t.trackedMtx.Lock()
+	defer t.trackedMtx.Unlock()
+
+	if t.tracked == nil {
+		return
+	}
+	for _, p := range profiles {
+		for _, s := range p.Samples {
+			t.tracked[s.StacktraceID] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+var errNotChecked = errors.New("dependencies have not been checked yet")
+
+// Check returns an error if a dependency is not reachable.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Health checks that the dependencies of the server are reachable, and
+// reports the server as ready only once all of them are.
+type Health struct {
+	logger  log.Logger
+	timeout time.Duration
+
+	mtx    sync.RWMutex
+	checks []namedCheck
+	probes []Probe
+	err    error
+}
+
+// NewHealth returns a Health that fails a check if it doesn't succeed within
+// the timeout.
+func NewHealth(logger log.Logger, timeout time.Duration) *Health {
+	return &Health{
+		logger:  logger,
+		timeout: timeout,
+		err:     errNotChecked,
+	}
+}
+
+// AddCheck adds the check of a dependency.
+func (h *Health) AddCheck(name string, check Check) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// AddProbe adds a probe to set ready or not ready with the result of every
+// check.
+func (h *Health) AddProbe(p Probe) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.probes = append(h.probes, p)
+	setProbe(p, h.err)
+}
+
+// Err returns the result of the last check.
+func (h *Health) Err() error {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	return h.err
+}
+
+// Check runs all checks concurrently and returns an error naming the failed
+// dependencies. Checks that don't return within the timeout fail, even if
+// they don't respect the cancellation of their context.
+func (h *Health) Check(ctx context.Context) error {
+	h.mtx.RLock()
+	checks := h.checks
+	h.mtx.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			errs[i] = runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	var msgs []string
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+func runCheck(ctx context.Context, c namedCheck) error {
+	// Buffered, so that the check can return after it timed out.
+	done := make(chan error, 1)
+	go func() {
+		done <- c.check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", c.name, ctx.Err())
+	}
+}
+
+// Run checks the dependencies in the given interval until the context is
+// canceled, and sets the probes ready or not ready with the result.
+func (h *Health) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.update(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Health) update(ctx context.Context) {
+	err := h.Check(ctx)
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if err != nil && h.err == nil {
+		level.Warn(h.logger).Log("msg", "dependencies are not reachable, server is not ready", "err", err)
+	}
+	if err == nil && h.err != nil {
+		level.Info(h.logger).Log("msg", "dependencies are reachable, server is ready")
+	}
+
+	h.err = err
+	for _, p := range h.probes {
+		setProbe(p, err)
+	}
+}
+
+func setProbe(p Probe, err error) {
+	if err != nil {
+		p.NotReady(err)
+		return
+	}
+	p.Ready()
+}
+
+// ServeHTTP responds with the result of the last check, with status 200 if
+// the server is ready and 503 otherwise.
+func (h *Health) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	if err := h.Err(); err != nil {
+		http.Error(w, fmt.Sprintf("not ready: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ready")
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prober
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"go.opentelemetry.io/otel/trace"
+	grpc_health "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/parca-dev/parca/pkg/metastore"
+)
+
+// hungBucket blocks on every call, ignoring the cancellation of the context.
+type hungBucket struct {
+	objstore.Bucket
+	release chan struct{}
+}
+
+func (b *hungBucket) Exists(ctx context.Context, name string) (bool, error) {
+	<-b.release
+	return false, nil
+}
+
+func newTestMetastore(t *testing.T) (*metastore.BadgerMetastore, *badger.DB) {
+	t.Helper()
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	return metastore.NewBadgerMetastore(
+		log.NewNopLogger(),
+		prometheus.NewRegistry(),
+		trace.NewNoopTracerProvider().Tracer(""),
+		db,
+	), db
+}
+
+func bucketCheck(bucket objstore.Bucket) Check {
+	return func(ctx context.Context) error {
+		_, err := bucket.Exists(ctx, "health")
+		return err
+	}
+}
+
+func TestHealth(t *testing.T) {
+	m, db := newTestMetastore(t)
+	t.Cleanup(func() { db.Close() })
+
+	h := NewHealth(log.NewNopLogger(), time.Second)
+	h.AddCheck("metastore", m.Ping)
+	h.AddCheck("debuginfo bucket", bucketCheck(objstore.NewInMemBucket()))
+
+	p := NewGRPC()
+	h.AddProbe(p)
+	require.Error(t, h.Err(), "must not be ready before the first check")
+
+	h.update(context.Background())
+	require.NoError(t, h.Err())
+
+	res, err := p.HealthServer().Check(context.Background(), &grpc_health.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, grpc_health.HealthCheckResponse_SERVING, res.Status)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHealthFailingMetastore(t *testing.T) {
+	m, db := newTestMetastore(t)
+	require.NoError(t, db.Close())
+
+	h := NewHealth(log.NewNopLogger(), time.Second)
+	h.AddCheck("metastore", m.Ping)
+	h.AddCheck("debuginfo bucket", bucketCheck(objstore.NewInMemBucket()))
+
+	p := NewGRPC()
+	h.AddProbe(p)
+	h.update(context.Background())
+
+	err := h.Err()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "metastore")
+	require.NotContains(t, err.Error(), "debuginfo bucket")
+
+	res, err := p.HealthServer().Check(context.Background(), &grpc_health.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, grpc_health.HealthCheckResponse_NOT_SERVING, res.Status)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestHealthHungBucket(t *testing.T) {
+	m, db := newTestMetastore(t)
+	t.Cleanup(func() { db.Close() })
+
+	bucket := &hungBucket{Bucket: objstore.NewInMemBucket(), release: make(chan struct{})}
+	t.Cleanup(func() { close(bucket.release) })
+
+	h := NewHealth(log.NewNopLogger(), 100*time.Millisecond)
+	h.AddCheck("metastore", m.Ping)
+	h.AddCheck("debuginfo bucket", bucketCheck(bucket))
+
+	start := time.Now()
+	err := h.Check(context.Background())
+	require.Less(t, time.Since(start), time.Second, "check must not block on a hung bucket")
+	require.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+	require.Contains(t, err.Error(), "debuginfo bucket")
+	require.NotContains(t, err.Error(), "metastore")
+}
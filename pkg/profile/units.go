@@ -0,0 +1,110 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrIncompatibleUnits is returned when values of one unit can't be converted
+// to another unit, e.g. bytes to nanoseconds.
+var ErrIncompatibleUnits = errors.New("incompatible units")
+
+type dimension int
+
+const (
+	dimensionCount dimension = iota
+	dimensionTime
+	dimensionBytes
+)
+
+type unit struct {
+	dimension dimension
+	// factor is the number of base units (count, nanoseconds, bytes) in one
+	// unit.
+	factor float64
+}
+
+var units = map[string]unit{
+	"count": {dimensionCount, 1},
+
+	"nanoseconds":  {dimensionTime, 1},
+	"ns":           {dimensionTime, 1},
+	"microseconds": {dimensionTime, 1e3},
+	"us":           {dimensionTime, 1e3},
+	"milliseconds": {dimensionTime, 1e6},
+	"ms":           {dimensionTime, 1e6},
+	"seconds":      {dimensionTime, 1e9},
+	"s":            {dimensionTime, 1e9},
+	"minutes":      {dimensionTime, 60e9},
+	"hours":        {dimensionTime, 3600e9},
+
+	"bytes":     {dimensionBytes, 1},
+	"b":         {dimensionBytes, 1},
+	"kilobytes": {dimensionBytes, 1e3},
+	"kb":        {dimensionBytes, 1e3},
+	"megabytes": {dimensionBytes, 1e6},
+	"mb":        {dimensionBytes, 1e6},
+	"gigabytes": {dimensionBytes, 1e9},
+	"gb":        {dimensionBytes, 1e9},
+	"kib":       {dimensionBytes, 1 << 10},
+	"mib":       {dimensionBytes, 1 << 20},
+	"gib":       {dimensionBytes, 1 << 30},
+	"tib":       {dimensionBytes, 1 << 40},
+}
+
+// Scaler converts values from one unit to another.
+type Scaler struct {
+	factor float64
+}
+
+// NewScaler returns a scaler converting values of the from unit to the to
+// unit. Units are matched case-insensitively, and a unit can always be
+// converted to itself, even if it isn't known.
+func NewScaler(from, to string) (Scaler, error) {
+	if strings.EqualFold(from, to) {
+		return Scaler{factor: 1}, nil
+	}
+
+	f, ok := units[strings.ToLower(from)]
+	if !ok {
+		return Scaler{}, fmt.Errorf("%w: unknown unit %q", ErrIncompatibleUnits, from)
+	}
+	t, ok := units[strings.ToLower(to)]
+	if !ok {
+		return Scaler{}, fmt.Errorf("%w: unknown unit %q", ErrIncompatibleUnits, to)
+	}
+	if f.dimension != t.dimension {
+		return Scaler{}, fmt.Errorf("%w: %s and %s", ErrIncompatibleUnits, from, to)
+	}
+
+	return Scaler{factor: f.factor / t.factor}, nil
+}
+
+// Identity returns whether the scaler leaves values unchanged.
+func (s Scaler) Identity() bool {
+	return s.factor == 1
+}
+
+// Scale returns the value converted to the unit of the scaler, rounded to the
+// nearest integer.
+func (s Scaler) Scale(v int64) int64 {
+	if s.factor == 1 {
+		return v
+	}
+	return int64(math.Round(float64(v) * s.factor))
+}
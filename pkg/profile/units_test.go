@@ -0,0 +1,49 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaler(t *testing.T) {
+	for _, tc := range []struct {
+		from, to string
+		value    int64
+		expected int64
+	}{
+		{from: "nanoseconds", to: "seconds", value: 4_000_000_000, expected: 4},
+		{from: "nanoseconds", to: "ms", value: 1_500_000, expected: 2},
+		{from: "seconds", to: "nanoseconds", value: 3, expected: 3_000_000_000},
+		{from: "bytes", to: "MiB", value: 3 << 20, expected: 3},
+		{from: "bytes", to: "kB", value: 2_000, expected: 2},
+		{from: "count", to: "count", value: 7, expected: 7},
+		{from: "unknown", to: "unknown", value: 7, expected: 7},
+	} {
+		s, err := NewScaler(tc.from, tc.to)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, s.Scale(tc.value), "%s to %s", tc.from, tc.to)
+	}
+
+	for _, tc := range []struct{ from, to string }{
+		{from: "bytes", to: "nanoseconds"},
+		{from: "count", to: "seconds"},
+		{from: "unknown", to: "seconds"},
+	} {
+		_, err := NewScaler(tc.from, tc.to)
+		require.ErrorIs(t, err, ErrIncompatibleUnits, "%s to %s", tc.from, tc.to)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profilestore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/thanos-io/objstore"
+
+	profilestorepb "github.com/parca-dev/parca/gen/proto/go/parca/profilestore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/tenant"
+)
+
+// archiveProfile stores the raw profile of the series, with the timestamp in
+// nanoseconds, in the archive. The object is named by the timestamp and the
+// hash of its content, so archiving the same profile again overwrites it. The
+// profiles of tenants other than the default one are stored in a directory
+// named by the tenant.
+func (s *ProfileColumnStore) archiveProfile(ctx context.Context, normalized bool, labels *profilestorepb.LabelSet, sample *profilestorepb.RawSample, timeNanos int64) error {
+	b, err := (&profilestorepb.WriteRawRequest{
+		Normalized: normalized,
+		Series: []*profilestorepb.RawProfileSeries{{
+			Labels:  labels,
+			Samples: []*profilestorepb.RawSample{sample},
+		}},
+	}).MarshalVT()
+	if err != nil {
+		return fmt.Errorf("marshal raw profile: %w", err)
+	}
+
+	h := sha256.Sum256(b)
+	name := fmt.Sprintf("%020d-%s.pb", timeNanos, hex.EncodeToString(h[:16]))
+	if t := tenant.FromContext(ctx); t != tenant.Default {
+		name = path.Join(t, name)
+	}
+	if err := s.archive.Upload(ctx, name, bytes.NewReader(b)); err != nil {
+		return fmt.Errorf("upload raw profile: %w", err)
+	}
+	return nil
+}
+
+// archivedProfileTenant returns the tenant of the archived profile with the
+// given object name.
+func archivedProfileTenant(name string) string {
+	dir, _ := path.Split(name)
+	if dir == "" {
+		return tenant.Default
+	}
+	return strings.TrimSuffix(dir, objstore.DirDelim)
+}
+
+// archivedProfileTime returns the timestamp of the archived profile with the
+// given object name.
+func archivedProfileTime(name string) (time.Time, bool) {
+	name = path.Base(name)
+	i := strings.IndexByte(name, '-')
+	if i < 0 || !strings.HasSuffix(name, ".pb") {
+		return time.Time{}, false
+	}
+	ns, err := strconv.ParseInt(name[:i], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, ns), true
+}
+
+// Backfill ingests the archived profiles with timestamps in [from, to) again,
+// in the order of their timestamps, e.g. to store them in a changed storage
+// representation after the previously stored data was deleted. The profiles
+// are ingested for the tenants they were written by, in full, as they were
+// already downsampled when they were archived. They are not archived again.
+// It returns the number of profiles that were ingested.
+func (s *ProfileColumnStore) Backfill(ctx context.Context, from, to time.Time) (int, error) {
+	if s.archive == nil {
+		return 0, errors.New("raw profiles are not archived")
+	}
+
+	var names []string
+	err := s.archive.Iter(ctx, "", func(name string) error {
+		ts, ok := archivedProfileTime(name)
+		if ok && !ts.Before(from) && ts.Before(to) {
+			names = append(names, name)
+		}
+		return nil
+	}, objstore.WithRecursiveIter)
+	if err != nil {
+		return 0, fmt.Errorf("list archived profiles: %w", err)
+	}
+	// The base names start with the zero padded timestamps.
+	sort.Slice(names, func(i, j int) bool {
+		return path.Base(names[i]) < path.Base(names[j])
+	})
+
+	for i, name := range names {
+		req, err := s.readArchivedProfile(ctx, name)
+		if err != nil {
+			return i, err
+		}
+		ctx := tenant.NewContext(ctx, archivedProfileTenant(name))
+		if _, err := s.write(ctx, req, true); err != nil {
+			return i, fmt.Errorf("ingest archived profile %s: %w", name, err)
+		}
+	}
+
+	level.Info(s.logger).Log("msg", "backfilled archived profiles", "count", len(names), "from", from, "to", to)
+	return len(names), nil
+}
+
+// readArchivedProfile reads the write request of the archived profile with the
+// given object name.
+func (s *ProfileColumnStore) readArchivedProfile(ctx context.Context, name string) (*profilestorepb.WriteRawRequest, error) {
+	r, err := s.archive.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("get archived profile %s: %w", name, err)
+	}
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read archived profile %s: %w", name, err)
+	}
+	req := &profilestorepb.WriteRawRequest{}
+	if err := req.UnmarshalVT(b); err != nil {
+		return nil, fmt.Errorf("unmarshal archived profile %s: %w", name, err)
+	}
+	return req, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profilestore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// minDownsamplerSweepSize is the number of remembered series from which on
+// the ones that weren't written to recently are forgotten.
+const minDownsamplerSweepSize = 1024
+
+// downsampler keeps at most one profile per series per interval. The
+// intervals are aligned to the unix epoch and a profile belongs to the one its
+// timestamp is in, so which profiles are kept only depends on the order they
+// are written in, not on when they are written.
+type downsampler struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mtx sync.Mutex
+	// kept is the interval of the last kept profile per series.
+	kept      map[uint64]int64
+	sweepSize int
+}
+
+func newDownsampler(interval time.Duration) *downsampler {
+	return &downsampler{
+		interval:  interval,
+		now:       time.Now,
+		kept:      map[uint64]int64{},
+		sweepSize: minDownsamplerSweepSize,
+	}
+}
+
+// keep returns whether the profile of the series with the timestamp in
+// nanoseconds is to be kept. It is kept if no profile of the series of the
+// same interval or a later one was kept before.
+func (d *downsampler) keep(ls labels.Labels, timeNanos int64) bool {
+	interval := timeNanos / d.interval.Nanoseconds()
+	series := ls.Hash()
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if last, ok := d.kept[series]; ok && interval <= last {
+		return false
+	}
+
+	if len(d.kept) >= d.sweepSize {
+		current := d.now().UnixNano() / d.interval.Nanoseconds()
+		for s, last := range d.kept {
+			if last < current-1 {
+				delete(d.kept, s)
+			}
+		}
+		d.sweepSize = 2 * len(d.kept)
+		if d.sweepSize < minDownsamplerSweepSize {
+			d.sweepSize = minDownsamplerSweepSize
+		}
+	}
+	d.kept[series] = interval
+	return true
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profilestore
+
+import (
+	"time"
+
+	"github.com/thanos-io/objstore"
+
+	"github.com/parca-dev/parca/pkg/parcacol"
+)
+
+type Option func(*ProfileColumnStore)
+
+// WithMaxSampleSize limits the size in bytes of a single profile, both
+// compressed and decompressed. Zero means unlimited.
+func WithMaxSampleSize(size int64) Option {
+	return func(s *ProfileColumnStore) {
+		s.maxSampleSize = size
+	}
+}
+
+// WithMaxRequestSize limits the size in bytes of all profiles of a write
+// request, both compressed and decompressed. Zero means unlimited.
+func WithMaxRequestSize(size int64) Option {
+	return func(s *ProfileColumnStore) {
+		s.maxRequestSize = size
+	}
+}
+
+// WithMaxPendingWrites makes the store reject write requests while the given
+// number of them is being ingested, so that a slow metastore doesn't pile up
+// requests. Zero means unlimited.
+func WithMaxPendingWrites(n int) Option {
+	return func(s *ProfileColumnStore) {
+		s.pendingWrites = nil
+		if n > 0 {
+			s.pendingWrites = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithDownsampleInterval makes the store keep at most one profile per series
+// per interval, the first one written with a timestamp in it. The other
+// profiles are dropped. An interval of 0 keeps all profiles.
+func WithDownsampleInterval(interval time.Duration) Option {
+	return func(s *ProfileColumnStore) {
+		s.downsampler = nil
+		if interval > 0 {
+			s.downsampler = newDownsampler(interval)
+		}
+	}
+}
+
+// WithDeduplication makes the store keep only a reference to the previous
+// profile of a series for profiles identical to it, as long as the previous
+// one was stored at most maxAge before. The references are resolved when
+// querying the profiles. maxAge has to be shorter than the retention. A maxAge
+// of 0 stores all profiles in full.
+func WithDeduplication(maxAge time.Duration) Option {
+	return func(s *ProfileColumnStore) {
+		s.deduplicator = nil
+		if maxAge > 0 {
+			s.deduplicator = parcacol.NewDeduplicator(maxAge, s.deduplicated)
+		}
+	}
+}
+
+// WithTrimmer makes the store keep the trimmer from deleting the metastore
+// entries of the profiles it ingests.
+func WithTrimmer(t *parcacol.Trimmer) Option {
+	return func(s *ProfileColumnStore) {
+		s.trimmer = t
+	}
+}
+
+// WithRawProfileArchive makes the store archive every profile it ingests, as
+// it was written, in the bucket, so that it can be ingested again with
+// Backfill. A nil bucket disables archiving.
+func WithRawProfileArchive(bucket objstore.Bucket) Option {
+	return func(s *ProfileColumnStore) {
+		s.archive = bucket
+	}
+}
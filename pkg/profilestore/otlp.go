@@ -0,0 +1,159 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profilestore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
+	otlpcollectorpb "github.com/parca-dev/parca/gen/proto/go/opentelemetry/proto/collector/profiles/v1development"
+	profilestorepb "github.com/parca-dev/parca/gen/proto/go/parca/profilestore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/otlp"
+)
+
+// OTLPServer accepts profiles exported with the OpenTelemetry protocol. The
+// profiles are converted to pprof and written to the store like the ones of
+// WriteRaw requests, with the resource attributes as series labels.
+type OTLPServer struct {
+	store profilestorepb.ProfileStoreServiceServer
+
+	otlpcollectorpb.UnimplementedProfilesServiceServer
+}
+
+func NewOTLPServer(store profilestorepb.ProfileStoreServiceServer) *OTLPServer {
+	return &OTLPServer{
+		store: store,
+	}
+}
+
+func (s *OTLPServer) Export(ctx context.Context, req *otlpcollectorpb.ExportProfilesServiceRequest) (*otlpcollectorpb.ExportProfilesServiceResponse, error) {
+	wreq := &profilestorepb.WriteRawRequest{}
+	for i, rp := range req.ResourceProfiles {
+		for j, sp := range rp.ScopeProfiles {
+			for k, op := range sp.Profiles {
+				p, err := otlp.ToPprof(op)
+				if err != nil {
+					return nil, status.Errorf(codes.InvalidArgument, "invalid profile %d of scope %d of resource %d: %v", k, j, i, err)
+				}
+
+				raw, err := compressProfile(p)
+				if err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to encode profile: %v", err)
+				}
+
+				wreq.Series = append(wreq.Series, &profilestorepb.RawProfileSeries{
+					Labels:  &profilestorepb.LabelSet{Labels: resourceLabels(rp.Resource, profileName(p))},
+					Samples: []*profilestorepb.RawSample{{RawProfile: raw, Encoding: profilestorepb.RawSample_ENCODING_GZIP}},
+				})
+			}
+		}
+	}
+
+	if _, err := s.store.WriteRaw(ctx, wreq); err != nil {
+		return nil, err
+	}
+	return &otlpcollectorpb.ExportProfilesServiceResponse{}, nil
+}
+
+// profileName returns the name of the series of a profile, which is the type
+// of its period, or of its first sample type if it has no period type.
+func profileName(p *pprofpb.Profile) string {
+	if t := p.PeriodType; t != nil && t.Type != 0 {
+		return p.StringTable[t.Type]
+	}
+	if len(p.SampleType) > 0 {
+		return p.StringTable[p.SampleType[0].Type]
+	}
+	return ""
+}
+
+// resourceLabels returns the labels of the series of a resource. Characters
+// of attribute keys that aren't valid in label names, like the dots of
+// semantic conventions, are replaced by underscores, so that service.name
+// becomes the service_name label.
+func resourceLabels(r *resourcepb.Resource, name string) []*profilestorepb.Label {
+	ls := []*profilestorepb.Label{{Name: labels.MetricName, Value: name}}
+	for _, attr := range r.GetAttributes() {
+		value, ok := attributeValue(attr.Value)
+		if !ok {
+			continue
+		}
+		ls = append(ls, &profilestorepb.Label{
+			Name:  sanitizeLabelName(attr.Key),
+			Value: value,
+		})
+	}
+	return ls
+}
+
+func attributeValue(v *commonpb.AnyValue) (string, bool) {
+	switch v := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue, true
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue), true
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10), true
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+func sanitizeLabelName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func compressProfile(p *pprofpb.Profile) ([]byte, error) {
+	content, err := p.MarshalVT()
+	if err != nil {
+		return nil, fmt.Errorf("marshal profile: %w", err)
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(content); err != nil {
+		return nil, fmt.Errorf("compress profile: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("compress profile: %w", err)
+	}
+	return buf.Bytes(), nil
+}
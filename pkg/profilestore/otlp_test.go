@@ -0,0 +1,139 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profilestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v8/arrow"
+	"github.com/apache/arrow/go/v8/arrow/array"
+	"github.com/apache/arrow/go/v8/arrow/memory"
+	"github.com/polarsignals/frostdb/query"
+	"github.com/polarsignals/frostdb/query/logicalplan"
+	"github.com/stretchr/testify/require"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	otlpcollectorpb "github.com/parca-dev/parca/gen/proto/go/opentelemetry/proto/collector/profiles/v1development"
+	otlpprofilespb "github.com/parca-dev/parca/gen/proto/go/opentelemetry/proto/profiles/v1development"
+	metastorepb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/parcacol"
+)
+
+func Test_OTLPServer_Export(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	api, colDB := newTestProfileColumnStoreWithDB(t)
+	srv := NewOTLPServer(api)
+
+	str := func(s string) *commonpb.AnyValue {
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+	}
+	ts := time.Unix(600, 0)
+
+	// A CPU profile of two stacks, main -> work and main.
+	profile := &otlpprofilespb.Profile{
+		StringTable: []string{"", "cpu", "nanoseconds", "main", "work"},
+		SampleType:  []*otlpprofilespb.ValueType{{TypeStrindex: 1, UnitStrindex: 2}},
+		PeriodType:  &otlpprofilespb.ValueType{TypeStrindex: 1, UnitStrindex: 2},
+		Period:      10_000_000,
+		TimeNanos:   ts.UnixNano(),
+		FunctionTable: []*otlpprofilespb.Function{
+			{NameStrindex: 3},
+			{NameStrindex: 4},
+		},
+		LocationTable: []*otlpprofilespb.Location{
+			{Line: []*otlpprofilespb.Line{{FunctionIndex: 0, Line: 10}}},
+			{Line: []*otlpprofilespb.Line{{FunctionIndex: 1, Line: 20}}},
+		},
+		LocationIndices: []int32{1, 0},
+		Sample: []*otlpprofilespb.Sample{
+			{LocationsStartIndex: 0, LocationsLength: 2, Value: []int64{30_000_000}},
+			{LocationsStartIndex: 1, LocationsLength: 1, Value: []int64{10_000_000}},
+		},
+	}
+	req := &otlpcollectorpb.ExportProfilesServiceRequest{
+		ResourceProfiles: []*otlpprofilespb.ResourceProfiles{{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{
+					{Key: "service.name", Value: str("api")},
+					{Key: "host.name", Value: str("node-1")},
+				},
+			},
+			ScopeProfiles: []*otlpprofilespb.ScopeProfiles{{
+				Profiles: []*otlpprofilespb.Profile{profile},
+			}},
+		}},
+	}
+	_, err := srv.Export(ctx, req)
+	require.NoError(t, err)
+
+	type stored struct {
+		name      string
+		service   string
+		host      string
+		timestamp int64
+		value     int64
+	}
+	var got []stored
+	engine := query.NewEngine(memory.DefaultAllocator, colDB.TableProvider())
+	err = engine.ScanTable("stacktraces").
+		Project(
+			logicalplan.Col(parcacol.ColumnName),
+			logicalplan.Col(parcacol.ColumnLabels+".service_name"),
+			logicalplan.Col(parcacol.ColumnLabels+".host_name"),
+			logicalplan.Col(parcacol.ColumnTimestamp),
+			logicalplan.Col(parcacol.ColumnValue),
+		).
+		Execute(ctx, func(ar arrow.Record) error {
+			names := ar.Column(0).(*array.Binary)
+			services := ar.Column(1).(*array.Binary)
+			hosts := ar.Column(2).(*array.Binary)
+			timestamps := ar.Column(3).(*array.Int64)
+			values := ar.Column(4).(*array.Int64)
+			for i := 0; i < int(ar.NumRows()); i++ {
+				got = append(got, stored{
+					name:      string(names.Value(i)),
+					service:   string(services.Value(i)),
+					host:      string(hosts.Value(i)),
+					timestamp: timestamps.Value(i),
+					value:     values.Value(i),
+				})
+			}
+			return nil
+		})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []stored{
+		{name: "cpu", service: "api", host: "node-1", timestamp: 600000, value: 30_000_000},
+		{name: "cpu", service: "api", host: "node-1", timestamp: 600000, value: 10_000_000},
+	}, got)
+
+	fres, err := api.metastore.ListFunctions(ctx, &metastorepb.ListFunctionsRequest{})
+	require.NoError(t, err)
+	var functions []string
+	for _, f := range fres.Functions {
+		functions = append(functions, f.Name)
+	}
+	require.ElementsMatch(t, []string{"main", "work"}, functions)
+
+	// Indices outside of the tables of the profile are rejected.
+	profile.LocationIndices = []int32{2, 0}
+	_, err = srv.Export(ctx, req)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -18,18 +18,22 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/polarsignals/frostdb"
 	"github.com/polarsignals/frostdb/dynparquet"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/thanos-io/objstore"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -37,6 +41,7 @@ import (
 	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
 	metastorepb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
 	profilestorepb "github.com/parca-dev/parca/gen/proto/go/parca/profilestore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/jfr"
 	"github.com/parca-dev/parca/pkg/parcacol"
 )
 
@@ -56,86 +61,341 @@ type ProfileColumnStore struct {
 	// reproducing situations in tests. This has huge overhead, do not enable
 	// unless you know what you're doing.
 	debugValueLog bool
+
+	// Maximum size in bytes of a single profile and of all profiles of a
+	// request, both compressed and decompressed. Zero means unlimited.
+	maxSampleSize  int64
+	maxRequestSize int64
+
+	// pendingWrites holds a token for every write request being ingested.
+	// Once it is full, further requests are rejected so that a slow
+	// metastore doesn't pile up requests. It is nil if unlimited.
+	pendingWrites chan struct{}
+
+	// downsampler, if set, drops the profiles of series that were written
+	// more often than its interval.
+	downsampler *downsampler
+
+	// trimmer, if set, is kept from deleting the metastore entries of the
+	// profiles being ingested.
+	trimmer *parcacol.Trimmer
+
+	// deduplicator, if set, stores profiles identical to the previous one of
+	// their series as a reference to it.
+	deduplicator *parcacol.Deduplicator
+
+	// headStats tracks the series and samples written to the active block
+	// of the table.
+	headStats *parcacol.HeadStats
+
+	// archive, if set, holds every ingested profile as it was written.
+	archive objstore.Bucket
+
+	droppedEmpty       prometheus.Counter
+	droppedDownsampled prometheus.Counter
+	rejectedWrites     prometheus.Counter
+	deduplicated       prometheus.Counter
 }
 
 var _ profilestorepb.ProfileStoreServiceServer = &ProfileColumnStore{}
 
 func NewProfileColumnStore(
 	logger log.Logger,
+	reg prometheus.Registerer,
 	tracer trace.Tracer,
 	metastore metastorepb.MetastoreServiceClient,
 	table *frostdb.Table,
 	schema *dynparquet.Schema,
 	debugValueLog bool,
+	opts ...Option,
 ) *ProfileColumnStore {
-	return &ProfileColumnStore{
-		logger:        logger,
-		tracer:        tracer,
-		metastore:     metastore,
-		table:         table,
-		debugValueLog: debugValueLog,
-		schema:        schema,
+	droppedEmpty := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parca_profilestore_profiles_dropped_empty_total",
+		Help: "Total number of written profiles that were dropped because they contain no samples.",
+	})
+	droppedDownsampled := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parca_profilestore_profiles_dropped_downsampled_total",
+		Help: "Total number of written profiles that were dropped because a profile of the same series was kept for the downsampling interval.",
+	})
+	rejectedWrites := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parca_profilestore_writes_rejected_total",
+		Help: "Total number of write requests that were rejected because too many write requests were pending.",
+	})
+	deduplicated := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "parca_profilestore_profiles_deduplicated_total",
+		Help: "Total number of written profiles that were stored as a reference to the previous profile of their series, as they were identical.",
+	})
+	reg.MustRegister(droppedEmpty, droppedDownsampled, rejectedWrites, deduplicated)
+
+	s := &ProfileColumnStore{
+		logger:             logger,
+		tracer:             tracer,
+		metastore:          metastore,
+		table:              table,
+		debugValueLog:      debugValueLog,
+		schema:             schema,
+		droppedEmpty:       droppedEmpty,
+		droppedDownsampled: droppedDownsampled,
+		rejectedWrites:     rejectedWrites,
+		deduplicated:       deduplicated,
+		headStats:          parcacol.NewHeadStats(reg, table),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *ProfileColumnStore) WriteRaw(ctx context.Context, req *profilestorepb.WriteRawRequest) (*profilestorepb.WriteRawResponse, error) {
 	ctx, span := s.tracer.Start(ctx, "write-raw")
 	defer span.End()
 
+	if s.pendingWrites != nil {
+		select {
+		case s.pendingWrites <- struct{}{}:
+			defer func() { <-s.pendingWrites }()
+		default:
+			s.rejectedWrites.Inc()
+			return nil, status.Errorf(codes.Unavailable, "too many pending writes, retry later")
+		}
+	}
+
+	return s.write(ctx, req, false)
+}
+
+// parsedProfile is a validated profile of a request along with the series it
+// was written to.
+type parsedProfile struct {
+	series  int
+	labels  labels.Labels
+	sample  *profilestorepb.RawSample
+	profile *pprofpb.Profile
+}
+
+// write ingests the profiles of the request, and archives them if archiving is
+// enabled. Backfilled profiles were already downsampled when they were
+// archived, they are ingested in full and not archived again.
+func (s *ProfileColumnStore) write(ctx context.Context, req *profilestorepb.WriteRawRequest, backfill bool) (*profilestorepb.WriteRawResponse, error) {
+	if err := s.checkCompressedSize(req); err != nil {
+		return nil, err
+	}
+	// Remaining budget for the decompressed profiles of the request.
+	remaining := s.maxRequestSize
+
 	ingester := parcacol.NewIngester(
 		s.logger,
 		parcacol.NewNormalizer(s.metastore),
 		s.table,
 		s.schema,
 	)
+	ingester.SetTrimmer(s.trimmer)
+	if !backfill {
+		ingester.SetDeduplicator(s.deduplicator)
+	}
+	ingester.SetHeadStats(s.headStats)
 
-	for _, series := range req.Series {
-		ls := make(labels.Labels, 0, len(series.Labels.Labels))
-		for _, l := range series.Labels.Labels {
-			if valid := model.LabelName(l.Name).IsValid(); !valid {
-				return nil, status.Errorf(codes.InvalidArgument, "invalid label name: %v", l.Name)
-			}
-
-			ls = append(ls, labels.Label{
-				Name:  l.Name,
-				Value: l.Value,
-			})
+	// All profiles of the request are validated before any of them is
+	// ingested, so that a request rejected for an invalid profile is not
+	// partially ingested, and ingested again once the client retries it.
+	var profiles []parsedProfile
+	for i, series := range req.Series {
+		ls, err := normalizeLabels(series.GetLabels().GetLabels())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid labels of series %d: %v", i, err)
 		}
 
 		for _, sample := range series.Samples {
-			r, err := gzip.NewReader(bytes.NewBuffer(sample.RawProfile))
+			limit, perRequest := s.decompressLimit(remaining)
+			content, err := decompress(sample.RawProfile, sample.Encoding, limit)
+			if errors.Is(err, errLimitExceeded) {
+				if perRequest {
+					return nil, status.Errorf(codes.ResourceExhausted, "decompressed profiles exceed the limit of %d bytes per request", s.maxRequestSize)
+				}
+				return nil, status.Errorf(codes.ResourceExhausted, "decompressed profile of series %d %s exceeds the limit of %d bytes per profile", i, ls, s.maxSampleSize)
+			}
 			if err != nil {
-				return nil, status.Errorf(codes.Internal, "failed to create gzip reader: %v", err)
+				return nil, status.Errorf(codes.InvalidArgument, "failed to decompress profile of series %d %s: %v", i, ls, err)
 			}
+			remaining -= int64(len(content))
 
-			content, err := io.ReadAll(r)
+			p, err := parseProfile(content)
 			if err != nil {
-				return nil, status.Errorf(codes.InvalidArgument, "failed to decompress profile: %v", err)
+				return nil, status.Errorf(codes.InvalidArgument, "failed to parse profile of series %d %s: %v", i, ls, err)
 			}
-
-			p := &pprofpb.Profile{}
-			if err := p.UnmarshalVT(content); err != nil {
-				return nil, status.Errorf(codes.InvalidArgument, "failed to parse profile: %v", err)
+			if err := parcacol.ValidatePprofProfile(p); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid profile of series %d %s: %v", i, ls, err)
 			}
 
-			if s.debugValueLog {
-				dir := fmt.Sprintf("tmp/%s", base64.URLEncoding.EncodeToString([]byte(ls.String())))
-				err := os.MkdirAll(dir, os.ModePerm)
+			profiles = append(profiles, parsedProfile{
+				series:  i,
+				labels:  ls,
+				sample:  sample,
+				profile: p,
+			})
+		}
+	}
+
+	for _, pp := range profiles {
+		i, ls, p := pp.series, pp.labels, pp.profile
+
+		if len(p.Sample) == 0 {
+			level.Debug(s.logger).Log("msg", "no samples found in profile, dropping it", "series", i, "labels", ls)
+			s.droppedEmpty.Inc()
+			continue
+		}
+
+		if s.downsampler != nil && !backfill && !s.downsampler.keep(ls, p.TimeNanos) {
+			level.Debug(s.logger).Log("msg", "profile of series written within the downsampling interval, dropping it", "series", i, "labels", ls)
+			s.droppedDownsampled.Inc()
+			continue
+		}
+
+		if s.debugValueLog {
+			dir := fmt.Sprintf("tmp/%s", base64.URLEncoding.EncodeToString([]byte(ls.String())))
+			err := os.MkdirAll(dir, os.ModePerm)
+			if err != nil {
+				level.Error(s.logger).Log("msg", "failed to create debug-value-log directory", "err", err)
+			} else {
+				err := os.WriteFile(fmt.Sprintf("%s/%d.pb.gz", dir, timestamp.FromTime(time.Now())), pp.sample.RawProfile, 0o644)
 				if err != nil {
-					level.Error(s.logger).Log("msg", "failed to create debug-value-log directory", "err", err)
-				} else {
-					err := os.WriteFile(fmt.Sprintf("%s/%d.pb.gz", dir, timestamp.FromTime(time.Now())), sample.RawProfile, 0o644)
-					if err != nil {
-						level.Error(s.logger).Log("msg", "failed to write debug-value-log", "err", err)
-					}
+					level.Error(s.logger).Log("msg", "failed to write debug-value-log", "err", err)
 				}
 			}
+		}
 
-			if err := ingester.Ingest(ctx, ls, p, req.Normalized); err != nil {
-				return nil, status.Errorf(codes.Internal, "failed to ingest profile: %v", err)
+		if s.archive != nil && !backfill {
+			if err := s.archiveProfile(ctx, req.Normalized, req.Series[i].Labels, pp.sample, p.TimeNanos); err != nil {
+				level.Error(s.logger).Log("msg", "failed to archive raw profile", "labels", ls, "err", err)
 			}
 		}
+
+		if err := ingester.Ingest(ctx, ls, p, req.Normalized); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to ingest profile: %v", err)
+		}
 	}
 
 	return &profilestorepb.WriteRawResponse{}, nil
 }
+
+// normalizeLabels validates the label set of a series and returns it sorted
+// by label name, so that the identity of a series doesn't depend on the order
+// the client sent the labels in. As in Prometheus, labels with an empty value
+// are equivalent to unset labels and are dropped.
+func normalizeLabels(in []*profilestorepb.Label) (labels.Labels, error) {
+	ls := make(labels.Labels, 0, len(in))
+	for _, l := range in {
+		if valid := model.LabelName(l.Name).IsValid(); !valid {
+			return nil, fmt.Errorf("invalid label name: %q", l.Name)
+		}
+
+		ls = append(ls, labels.Label{
+			Name:  l.Name,
+			Value: l.Value,
+		})
+	}
+
+	sort.Sort(ls)
+	out := make(labels.Labels, 0, len(ls))
+	for i, l := range ls {
+		if i > 0 && l.Name == ls[i-1].Name {
+			return nil, fmt.Errorf("duplicate label name: %q", l.Name)
+		}
+		if l.Value != "" {
+			out = append(out, l)
+		}
+	}
+
+	return out, nil
+}
+
+// checkCompressedSize rejects requests whose raw profiles exceed the limits
+// before anything is decompressed.
+func (s *ProfileColumnStore) checkCompressedSize(req *profilestorepb.WriteRawRequest) error {
+	var size int64
+	for _, series := range req.Series {
+		for _, sample := range series.Samples {
+			n := int64(len(sample.RawProfile))
+			if s.maxSampleSize > 0 && n > s.maxSampleSize {
+				return status.Errorf(codes.ResourceExhausted, "profile of %d bytes exceeds the limit of %d bytes per profile", n, s.maxSampleSize)
+			}
+			size += n
+		}
+	}
+	if s.maxRequestSize > 0 && size > s.maxRequestSize {
+		return status.Errorf(codes.ResourceExhausted, "profiles of %d bytes exceed the limit of %d bytes per request", size, s.maxRequestSize)
+	}
+	return nil
+}
+
+// decompressLimit returns the maximum decompressed size of the next profile
+// given the remaining budget of the request, and whether it is bounded by the
+// request rather than the per profile limit. A negative limit means
+// unlimited.
+func (s *ProfileColumnStore) decompressLimit(remaining int64) (int64, bool) {
+	if s.maxRequestSize > 0 && (s.maxSampleSize <= 0 || remaining < s.maxSampleSize) {
+		return remaining, true
+	}
+	if s.maxSampleSize <= 0 {
+		return -1, false
+	}
+	return s.maxSampleSize, false
+}
+
+var errLimitExceeded = errors.New("limit exceeded")
+
+// decompress decompresses a raw profile of the given encoding, stopping as
+// soon as it exceeds the limit, so that a small but highly compressed profile
+// can't exhaust memory. A negative limit means unlimited. Raw profiles of
+// unspecified encoding are decompressed if they are gzip compressed. JFR
+// recordings are always accepted uncompressed.
+func decompress(raw []byte, encoding profilestorepb.RawSample_Encoding, limit int64) ([]byte, error) {
+	if encoding == profilestorepb.RawSample_ENCODING_UNSPECIFIED {
+		encoding = profilestorepb.RawSample_ENCODING_NONE
+		if isGzip(raw) {
+			encoding = profilestorepb.RawSample_ENCODING_GZIP
+		}
+	}
+	if encoding != profilestorepb.RawSample_ENCODING_GZIP || jfr.IsJFR(raw) {
+		if limit >= 0 && int64(len(raw)) > limit {
+			return nil, errLimitExceeded
+		}
+		return raw, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewBuffer(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 0 {
+		return io.ReadAll(r)
+	}
+
+	// Read one byte past the limit to tell whether it was exceeded.
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		return nil, errLimitExceeded
+	}
+	return content, nil
+}
+
+// isGzip returns whether the data starts with the gzip magic bytes.
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// parseProfile parses a pprof profile or converts a JFR recording into one.
+func parseProfile(content []byte) (*pprofpb.Profile, error) {
+	if jfr.IsJFR(content) {
+		return jfr.ToPprof(content)
+	}
+
+	p := &pprofpb.Profile{}
+	if err := p.UnmarshalVT(content); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -14,27 +14,53 @@
 package profilestore
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"fmt"
+	"io"
+	"os"
 	"testing"
+	"time"
 
+	"github.com/apache/arrow/go/v8/arrow"
+	"github.com/apache/arrow/go/v8/arrow/array"
+	"github.com/apache/arrow/go/v8/arrow/memory"
 	"github.com/go-kit/log"
 	"github.com/polarsignals/frostdb"
+	"github.com/polarsignals/frostdb/query"
+	"github.com/polarsignals/frostdb/query/logicalplan"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
+	metastorepb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
 	profilestorepb "github.com/parca-dev/parca/gen/proto/go/parca/profilestore/v1alpha1"
 	"github.com/parca-dev/parca/pkg/metastore"
 	"github.com/parca-dev/parca/pkg/metastoretest"
 	"github.com/parca-dev/parca/pkg/parcacol"
+	prof "github.com/parca-dev/parca/pkg/profile"
+	"github.com/parca-dev/parca/pkg/tenant"
 )
 
-func Test_LabelName_Invalid(t *testing.T) {
-	t.Parallel()
+func newTestProfileColumnStore(t *testing.T, opts ...Option) *ProfileColumnStore {
+	t.Helper()
+
+	s, _ := newTestProfileColumnStoreWithDB(t, opts...)
+	return s
+}
+
+// newTestProfileColumnStoreWithDB returns a ProfileColumnStore along with the
+// database it stores the profiles in.
+func newTestProfileColumnStoreWithDB(t *testing.T, opts ...Option) (*ProfileColumnStore, *frostdb.DB) {
+	t.Helper()
 
-	ctx := context.Background()
 	logger := log.NewNopLogger()
 	reg := prometheus.NewRegistry()
 	tracer := trace.NewNoopTracerProvider().Tracer("")
@@ -61,14 +87,23 @@ func Test_LabelName_Invalid(t *testing.T) {
 		tracer,
 	)
 
-	api := NewProfileColumnStore(
+	return NewProfileColumnStore(
 		logger,
+		reg,
 		tracer,
 		metastore.NewInProcessClient(m),
 		table,
 		schema,
 		false,
-	)
+		opts...,
+	), colDB
+}
+
+func Test_LabelName_Invalid(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	api := newTestProfileColumnStore(t)
 
 	req := &profilestorepb.WriteRawRequest{
 		Series: []*profilestorepb.RawProfileSeries{{
@@ -81,8 +116,908 @@ func Test_LabelName_Invalid(t *testing.T) {
 		}},
 	}
 
-	_, err = api.WriteRaw(ctx, req)
+	_, err := api.WriteRaw(ctx, req)
 	st, _ := status.FromError(err)
 
 	require.Equal(t, st.Code(), codes.InvalidArgument)
 }
+
+func Test_WriteRaw_SizeLimits(t *testing.T) {
+	t.Parallel()
+
+	raw, err := os.ReadFile("../query/testdata/alloc_objects.pb.gz")
+	require.NoError(t, err)
+	r, err := gzip.NewReader(bytes.NewReader(raw))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	compressedSize, decompressedSize := int64(len(raw)), int64(len(decompressed))
+
+	req := func(samples int) *profilestorepb.WriteRawRequest {
+		series := &profilestorepb.RawProfileSeries{
+			Labels: &profilestorepb.LabelSet{
+				Labels: []*profilestorepb.Label{{
+					Name:  "__name__",
+					Value: "memory",
+				}, {
+					Name:  "job",
+					Value: "default",
+				}},
+			},
+		}
+		for i := 0; i < samples; i++ {
+			series.Samples = append(series.Samples, &profilestorepb.RawSample{RawProfile: raw})
+		}
+		return &profilestorepb.WriteRawRequest{Series: []*profilestorepb.RawProfileSeries{series}}
+	}
+
+	for _, tc := range []struct {
+		name           string
+		maxSampleSize  int64
+		maxRequestSize int64
+		samples        int
+		code           codes.Code
+	}{
+		{name: "unlimited", samples: 2, code: codes.OK},
+		{name: "sample under limit", maxSampleSize: decompressedSize, samples: 1, code: codes.OK},
+		{name: "sample compressed over limit", maxSampleSize: compressedSize - 1, samples: 1, code: codes.ResourceExhausted},
+		{name: "sample decompressed over limit", maxSampleSize: decompressedSize - 1, samples: 1, code: codes.ResourceExhausted},
+		{name: "request under limit", maxRequestSize: 2 * decompressedSize, samples: 2, code: codes.OK},
+		{name: "request compressed over limit", maxRequestSize: 2*compressedSize - 1, samples: 2, code: codes.ResourceExhausted},
+		{name: "request decompressed over limit", maxRequestSize: 2*decompressedSize - 1, samples: 2, code: codes.ResourceExhausted},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			api := newTestProfileColumnStore(t,
+				WithMaxSampleSize(tc.maxSampleSize),
+				WithMaxRequestSize(tc.maxRequestSize),
+			)
+			_, err := api.WriteRaw(context.Background(), req(tc.samples))
+			require.Equal(t, tc.code, status.Code(err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func Test_WriteRaw_InvalidProfiles(t *testing.T) {
+	t.Parallel()
+
+	raw, err := os.ReadFile("../query/testdata/alloc_objects.pb.gz")
+	require.NoError(t, err)
+
+	gzipped := func(p *pprofpb.Profile) []byte {
+		content, err := p.MarshalVT()
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	req := func(rawProfile []byte) *profilestorepb.WriteRawRequest {
+		return &profilestorepb.WriteRawRequest{
+			Series: []*profilestorepb.RawProfileSeries{{
+				Labels: &profilestorepb.LabelSet{
+					Labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}},
+				},
+				Samples: []*profilestorepb.RawSample{{RawProfile: raw}},
+			}, {
+				Labels: &profilestorepb.LabelSet{
+					Labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "job", Value: "broken"}},
+				},
+				Samples: []*profilestorepb.RawSample{{RawProfile: rawProfile}},
+			}},
+		}
+	}
+
+	for _, tc := range []struct {
+		name       string
+		rawProfile []byte
+		err        string
+	}{
+		{
+			name:       "garbage",
+			rawProfile: []byte("not a profile"),
+			err:        `failed to parse profile of series 1 {__name__="memory", job="broken"}`,
+		},
+		{
+			name:       "truncated gzip",
+			rawProfile: raw[:len(raw)/2],
+			err:        `failed to decompress profile of series 1 {__name__="memory", job="broken"}`,
+		},
+		{
+			name: "out of range string",
+			rawProfile: gzipped(&pprofpb.Profile{
+				StringTable: []string{""},
+				Function:    []*pprofpb.Function{{Id: 1, Name: 1}},
+			}),
+			err: `invalid profile of series 1 {__name__="memory", job="broken"}`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			api := newTestProfileColumnStore(t)
+			_, err := api.WriteRaw(context.Background(), req(tc.rawProfile))
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+			require.Contains(t, err.Error(), tc.err)
+
+			// The valid profile of the first series isn't ingested either,
+			// otherwise it would be ingested twice once the request is
+			// retried.
+			series, samples := api.headStats.Stats()
+			require.Equal(t, 0, series)
+			require.Equal(t, int64(0), samples)
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		api := newTestProfileColumnStore(t)
+		_, err := api.WriteRaw(context.Background(), req(gzipped(&pprofpb.Profile{
+			StringTable: []string{"", "alloc_objects", "count"},
+			SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+		})))
+		require.NoError(t, err)
+		require.Equal(t, float64(1), testutil.ToFloat64(api.droppedEmpty))
+	})
+}
+
+func Test_WriteRaw_Encoding(t *testing.T) {
+	t.Parallel()
+
+	gzipped, err := os.ReadFile("../query/testdata/alloc_objects.pb.gz")
+	require.NoError(t, err)
+	r, err := gzip.NewReader(bytes.NewReader(gzipped))
+	require.NoError(t, err)
+	plain, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		rawProfile []byte
+		encoding   profilestorepb.RawSample_Encoding
+		err        string
+	}{
+		{name: "gzip", rawProfile: gzipped, encoding: profilestorepb.RawSample_ENCODING_GZIP},
+		{name: "none", rawProfile: plain, encoding: profilestorepb.RawSample_ENCODING_NONE},
+		{name: "detected gzip", rawProfile: gzipped},
+		{name: "detected none", rawProfile: plain},
+		{
+			name:       "plain declared gzip",
+			rawProfile: plain,
+			encoding:   profilestorepb.RawSample_ENCODING_GZIP,
+			err:        "failed to decompress profile of series 0",
+		},
+		{
+			name:       "gzip declared none",
+			rawProfile: gzipped,
+			encoding:   profilestorepb.RawSample_ENCODING_NONE,
+			err:        "failed to parse profile of series 0",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			api := newTestProfileColumnStore(t)
+			_, err := api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{
+				Series: []*profilestorepb.RawProfileSeries{{
+					Labels: &profilestorepb.LabelSet{
+						Labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}},
+					},
+					Samples: []*profilestorepb.RawSample{{RawProfile: tc.rawProfile, Encoding: tc.encoding}},
+				}},
+			})
+			if tc.err != "" {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			lres, err := api.metastore.ListLocations(ctx, &metastorepb.ListLocationsRequest{})
+			require.NoError(t, err)
+			require.NotEmpty(t, lres.Locations)
+		})
+	}
+}
+
+func Test_WriteRaw_HeadStats(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	raw, err := (&pprofpb.Profile{
+		StringTable: []string{"", "samples", "count"},
+		SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+		Location:    []*pprofpb.Location{{Id: 1, Address: 0x1000}, {Id: 2, Address: 0x2000}},
+		Sample: []*pprofpb.Sample{
+			{LocationId: []uint64{1}, Value: []int64{1}},
+			{LocationId: []uint64{2, 1}, Value: []int64{2}},
+		},
+		TimeNanos: time.Now().UnixNano(),
+	}).MarshalVT()
+	require.NoError(t, err)
+
+	api := newTestProfileColumnStore(t)
+	write := func(job string) {
+		_, err := api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{
+			Series: []*profilestorepb.RawProfileSeries{{
+				Labels: &profilestorepb.LabelSet{
+					Labels: []*profilestorepb.Label{{Name: "__name__", Value: "cpu"}, {Name: "job", Value: job}},
+				},
+				Samples: []*profilestorepb.RawSample{{RawProfile: raw, Encoding: profilestorepb.RawSample_ENCODING_NONE}},
+			}},
+		})
+		require.NoError(t, err)
+	}
+
+	const n = 5
+	for i := 0; i < n; i++ {
+		write(fmt.Sprintf("job-%d", i))
+	}
+	series, samples := api.headStats.Stats()
+	require.Equal(t, n, series)
+	require.Equal(t, int64(2*n), samples)
+
+	// Only new series increase the number of series.
+	write("job-0")
+	series, samples = api.headStats.Stats()
+	require.Equal(t, n, series)
+	require.Equal(t, int64(2*(n+1)), samples)
+
+	write("new")
+	series, samples = api.headStats.Stats()
+	require.Equal(t, n+1, series)
+	require.Equal(t, int64(2*(n+2)), samples)
+
+	// A rotated block starts over.
+	require.NoError(t, api.table.RotateBlock(api.table.ActiveBlock()))
+	series, samples = api.headStats.Stats()
+	require.Equal(t, 0, series)
+	require.Equal(t, int64(0), samples)
+}
+
+// blockingMetastore blocks creating mappings until it is released.
+type blockingMetastore struct {
+	metastorepb.MetastoreServiceClient
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (m *blockingMetastore) GetOrCreateMappings(ctx context.Context, in *metastorepb.GetOrCreateMappingsRequest, opts ...grpc.CallOption) (*metastorepb.GetOrCreateMappingsResponse, error) {
+	m.entered <- struct{}{}
+	<-m.release
+	return m.MetastoreServiceClient.GetOrCreateMappings(ctx, in, opts...)
+}
+
+func Test_WriteRaw_PendingWrites(t *testing.T) {
+	t.Parallel()
+
+	raw, err := os.ReadFile("../query/testdata/alloc_objects.pb.gz")
+	require.NoError(t, err)
+	req := &profilestorepb.WriteRawRequest{
+		Series: []*profilestorepb.RawProfileSeries{{
+			Labels: &profilestorepb.LabelSet{
+				Labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}},
+			},
+			Samples: []*profilestorepb.RawSample{{RawProfile: raw}},
+		}},
+	}
+
+	const maxPendingWrites = 2
+	api := newTestProfileColumnStore(t, WithMaxPendingWrites(maxPendingWrites))
+	m := &blockingMetastore{
+		MetastoreServiceClient: api.metastore,
+		entered:                make(chan struct{}),
+		release:                make(chan struct{}),
+	}
+	api.metastore = m
+
+	ctx := context.Background()
+	errs := make(chan error, maxPendingWrites)
+	for i := 0; i < maxPendingWrites; i++ {
+		go func() {
+			_, err := api.WriteRaw(ctx, req)
+			errs <- err
+		}()
+		<-m.entered
+	}
+
+	// The metastore is blocked with as many writes as may be pending.
+	_, err = api.WriteRaw(ctx, req)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, float64(1), testutil.ToFloat64(api.rejectedWrites))
+
+	// The pending writes complete one after the other, as concurrent
+	// writes of the same profile conflict in the metastore.
+	for i := 0; i < maxPendingWrites; i++ {
+		m.release <- struct{}{}
+		require.NoError(t, <-errs)
+	}
+
+	// Once the pending writes completed, new ones are accepted again.
+	api.metastore = m.MetastoreServiceClient
+	_, err = api.WriteRaw(ctx, req)
+	require.NoError(t, err)
+}
+
+func Test_WriteRaw_Labels(t *testing.T) {
+	t.Parallel()
+
+	raw, err := os.ReadFile("../query/testdata/alloc_objects.pb.gz")
+	require.NoError(t, err)
+
+	req := func(ls ...*profilestorepb.Label) *profilestorepb.WriteRawRequest {
+		return &profilestorepb.WriteRawRequest{
+			Series: []*profilestorepb.RawProfileSeries{{
+				Labels:  &profilestorepb.LabelSet{Labels: ls},
+				Samples: []*profilestorepb.RawSample{{RawProfile: raw}},
+			}},
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		labels []*profilestorepb.Label
+		code   codes.Code
+	}{
+		{
+			name:   "duplicate name",
+			labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "job", Value: "a"}, {Name: "job", Value: "b"}},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "duplicate __name__",
+			labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "__name__", Value: "cpu"}},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "empty name",
+			labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "", Value: "a"}},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "invalid name",
+			labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "n0:n", Value: "a"}},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "out of order",
+			labels: []*profilestorepb.Label{{Name: "job", Value: "a"}, {Name: "instance", Value: "b"}, {Name: "__name__", Value: "memory"}},
+			code:   codes.OK,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			api := newTestProfileColumnStore(t)
+			_, err := api.WriteRaw(context.Background(), req(tc.labels...))
+			require.Equal(t, tc.code, status.Code(err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func Test_normalizeLabels(t *testing.T) {
+	sorted, err := normalizeLabels([]*profilestorepb.Label{
+		{Name: "__name__", Value: "memory"},
+		{Name: "instance", Value: "b"},
+		{Name: "job", Value: "a"},
+	})
+	require.NoError(t, err)
+
+	unsorted, err := normalizeLabels([]*profilestorepb.Label{
+		{Name: "job", Value: "a"},
+		{Name: "pod", Value: ""},
+		{Name: "instance", Value: "b"},
+		{Name: "__name__", Value: "memory"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, sorted, unsorted)
+	require.Equal(t, `{__name__="memory", instance="b", job="a"}`, unsorted.String())
+
+	_, err = normalizeLabels([]*profilestorepb.Label{{Name: "job", Value: ""}, {Name: "job", Value: "a"}})
+	require.Error(t, err)
+}
+
+func Test_WriteRaw_JFR(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	raw, err := os.ReadFile("../jfr/testdata/cpu.jfr")
+	require.NoError(t, err)
+
+	api := newTestProfileColumnStore(t)
+	_, err = api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{
+		Series: []*profilestorepb.RawProfileSeries{{
+			Labels: &profilestorepb.LabelSet{
+				Labels: []*profilestorepb.Label{{Name: "__name__", Value: "jvm_cpu"}},
+			},
+			Samples: []*profilestorepb.RawSample{{RawProfile: raw}},
+		}},
+	})
+	require.NoError(t, err)
+
+	fres, err := api.metastore.ListFunctions(ctx, &metastorepb.ListFunctionsRequest{})
+	require.NoError(t, err)
+	functions := map[string]string{}
+	for _, f := range fres.Functions {
+		functions[f.Id] = f.Name
+	}
+	require.ElementsMatch(t, []string{
+		"com.example.Worker.compute",
+		"com.example.Worker.hash",
+		"com.example.Worker.run",
+		"java.lang.Thread.run",
+	}, values(functions))
+
+	lres, err := api.metastore.ListLocations(ctx, &metastorepb.ListLocationsRequest{})
+	require.NoError(t, err)
+	var frames []string
+	for _, l := range lres.Locations {
+		require.Len(t, l.Lines, 1)
+		frames = append(frames, fmt.Sprintf("%s:%d", functions[l.Lines[0].FunctionId], l.Lines[0].Line))
+	}
+	require.ElementsMatch(t, []string{
+		"com.example.Worker.compute:42",
+		"com.example.Worker.compute:45",
+		"com.example.Worker.hash:57",
+		"com.example.Worker.run:20",
+		"java.lang.Thread.run:833",
+	}, frames)
+}
+
+func values(m map[string]string) []string {
+	vs := make([]string, 0, len(m))
+	for _, v := range m {
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+func Test_WriteRaw_Downsample(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	api, colDB := newTestProfileColumnStoreWithDB(t, WithDownsampleInterval(time.Minute))
+
+	// The profiles have a single sample with the value, in the minute
+	// starting at the unix timestamp 600.
+	profile := func(offset time.Duration, value int64) *profilestorepb.RawSample {
+		p := &pprofpb.Profile{
+			StringTable: []string{"", "alloc_objects", "count", "main"},
+			SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+			TimeNanos:   time.Unix(600, 0).Add(offset).UnixNano(),
+			Function:    []*pprofpb.Function{{Id: 1, Name: 3}},
+			Location:    []*pprofpb.Location{{Id: 1, Line: []*pprofpb.Line{{FunctionId: 1}}}},
+			Sample:      []*pprofpb.Sample{{LocationId: []uint64{1}, Value: []int64{value}}},
+		}
+		content, err := p.MarshalVT()
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return &profilestorepb.RawSample{RawProfile: buf.Bytes()}
+	}
+	series := func(job string, samples ...*profilestorepb.RawSample) *profilestorepb.RawProfileSeries {
+		return &profilestorepb.RawProfileSeries{
+			Labels: &profilestorepb.LabelSet{
+				Labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "job", Value: job}},
+			},
+			Samples: samples,
+		}
+	}
+
+	// Only the first profile of a series within the interval is kept, also
+	// across requests.
+	_, err := api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{Series: []*profilestorepb.RawProfileSeries{
+		series("a", profile(10*time.Second, 1), profile(20*time.Second, 2), profile(30*time.Second, 4)),
+		series("b", profile(40*time.Second, 8)),
+	}})
+	require.NoError(t, err)
+	_, err = api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{Series: []*profilestorepb.RawProfileSeries{
+		series("a", profile(50*time.Second, 16), profile(70*time.Second, 32)),
+	}})
+	require.NoError(t, err)
+	require.Equal(t, float64(3), testutil.ToFloat64(api.droppedDownsampled))
+
+	type stored struct {
+		job       string
+		timestamp int64
+		value     int64
+	}
+	var got []stored
+	engine := query.NewEngine(memory.DefaultAllocator, colDB.TableProvider())
+	err = engine.ScanTable("stacktraces").
+		Project(
+			logicalplan.Col(parcacol.ColumnLabels+".job"),
+			logicalplan.Col(parcacol.ColumnTimestamp),
+			logicalplan.Col(parcacol.ColumnValue),
+		).
+		Execute(ctx, func(ar arrow.Record) error {
+			jobs := ar.Column(0).(*array.Binary)
+			timestamps := ar.Column(1).(*array.Int64)
+			values := ar.Column(2).(*array.Int64)
+			for i := 0; i < int(ar.NumRows()); i++ {
+				got = append(got, stored{job: string(jobs.Value(i)), timestamp: timestamps.Value(i), value: values.Value(i)})
+			}
+			return nil
+		})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []stored{
+		{job: "a", timestamp: 610000, value: 1},
+		{job: "b", timestamp: 640000, value: 8},
+		{job: "a", timestamp: 670000, value: 32},
+	}, got)
+}
+
+func Test_WriteRaw_Deduplicate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	api, colDB := newTestProfileColumnStoreWithDB(t, WithDeduplication(time.Hour))
+
+	// The profiles have a sample of main and one of work called by main.
+	profile := func(ts int64, mainValue, workValue int64) *profilestorepb.RawSample {
+		p := &pprofpb.Profile{
+			StringTable: []string{"", "alloc_objects", "count", "space", "bytes", "main", "work"},
+			SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+			PeriodType:  &pprofpb.ValueType{Type: 3, Unit: 4},
+			TimeNanos:   time.Unix(ts, 0).UnixNano(),
+			Function:    []*pprofpb.Function{{Id: 1, Name: 5}, {Id: 2, Name: 6}},
+			Location: []*pprofpb.Location{
+				{Id: 1, Line: []*pprofpb.Line{{FunctionId: 1}}},
+				{Id: 2, Line: []*pprofpb.Line{{FunctionId: 2}}},
+			},
+			Sample: []*pprofpb.Sample{
+				{LocationId: []uint64{1}, Value: []int64{mainValue}},
+				{LocationId: []uint64{2, 1}, Value: []int64{workValue}},
+			},
+		}
+		content, err := p.MarshalVT()
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return &profilestorepb.RawSample{RawProfile: buf.Bytes()}
+	}
+	series := func(job string, samples ...*profilestorepb.RawSample) *profilestorepb.RawProfileSeries {
+		return &profilestorepb.RawProfileSeries{
+			Labels: &profilestorepb.LabelSet{
+				Labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "job", Value: job}},
+			},
+			Samples: samples,
+		}
+	}
+
+	// The profiles of a at 610 and 620 are identical to the one at 600, the
+	// one of b at 610 is the first one of its series.
+	_, err := api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{Series: []*profilestorepb.RawProfileSeries{
+		series("a", profile(600, 3, 5), profile(610, 3, 5)),
+		series("b", profile(610, 3, 5)),
+	}})
+	require.NoError(t, err)
+	_, err = api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{Series: []*profilestorepb.RawProfileSeries{
+		series("a", profile(620, 3, 5), profile(630, 1, 2)),
+	}})
+	require.NoError(t, err)
+	require.Equal(t, float64(2), testutil.ToFloat64(api.deduplicated))
+
+	// The duplicates are stored as a single row each.
+	rows := map[string]int{}
+	engine := query.NewEngine(memory.DefaultAllocator, colDB.TableProvider())
+	err = engine.ScanTable("stacktraces").
+		Project(
+			logicalplan.Col(parcacol.ColumnLabels+".job"),
+			logicalplan.Col(parcacol.ColumnTimestamp),
+		).
+		Execute(ctx, func(ar arrow.Record) error {
+			jobs := ar.Column(0).(*array.Binary)
+			timestamps := ar.Column(1).(*array.Int64)
+			for i := 0; i < int(ar.NumRows()); i++ {
+				rows[fmt.Sprintf("%s@%d", jobs.Value(i), timestamps.Value(i))]++
+			}
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{
+		"a@600000": 2,
+		"a@610000": 1,
+		"a@620000": 1,
+		"b@610000": 2,
+		"a@630000": 2,
+	}, rows)
+
+	// The duplicates are resolved to the profile they refer to.
+	leafValues := func(p *prof.Profile) map[string]int64 {
+		values := map[string]int64{}
+		for _, s := range p.Samples {
+			values[s.Locations[0].Lines[0].Function.Name] += s.Value
+		}
+		return values
+	}
+	querier := parcacol.NewQuerier(
+		trace.NewNoopTracerProvider().Tracer(""),
+		engine,
+		"stacktraces",
+		api.metastore,
+	)
+	p, err := querier.QuerySingle(ctx, `memory:alloc_objects:count:space:bytes{job="a"}`, time.Unix(610, 0))
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"main": 3, "work": 5}, leafValues(p))
+
+	p, err = querier.QuerySingle(ctx, `memory:alloc_objects:count:space:bytes`, time.Unix(610, 0))
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"main": 6, "work": 10}, leafValues(p))
+
+	p, err = querier.QueryMerge(ctx, `memory:alloc_objects:count:space:bytes{job="a"}`, time.Unix(599, 0), time.Unix(631, 0))
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"main": 10, "work": 17}, leafValues(p))
+
+	// The newest profile of a at 625 is a duplicate, of b the one at 610.
+	p, err = querier.QueryInstant(ctx, `memory:alloc_objects:count:space:bytes`, time.Unix(625, 0))
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"main": 6, "work": 10}, leafValues(p))
+
+	res, err := querier.QueryRange(ctx, `memory:alloc_objects:count:space:bytes{job="a"}`, time.Unix(599, 0), time.Unix(631, 0), 0, 0)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	values := make([]int64, 0, len(res[0].Samples))
+	for _, s := range res[0].Samples {
+		values = append(values, s.Value)
+	}
+	require.Equal(t, []int64{8, 8, 8, 3}, values)
+}
+
+func Test_WriteRaw_DeduplicatePprofLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	api, colDB := newTestProfileColumnStoreWithDB(t, WithDeduplication(time.Hour))
+
+	// The samples of the profiles of a have the pprof label handler=x, the
+	// ones of b have none.
+	profile := func(ts int64, withLabels bool) *profilestorepb.RawSample {
+		sample := &pprofpb.Sample{LocationId: []uint64{1}, Value: []int64{3}}
+		if withLabels {
+			sample.Label = []*pprofpb.Label{{Key: 6, Str: 7}}
+		}
+		p := &pprofpb.Profile{
+			StringTable: []string{"", "alloc_objects", "count", "space", "bytes", "main", "handler", "x"},
+			SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+			PeriodType:  &pprofpb.ValueType{Type: 3, Unit: 4},
+			TimeNanos:   time.Unix(ts, 0).UnixNano(),
+			Function:    []*pprofpb.Function{{Id: 1, Name: 5}},
+			Location:    []*pprofpb.Location{{Id: 1, Line: []*pprofpb.Line{{FunctionId: 1}}}},
+			Sample:      []*pprofpb.Sample{sample},
+		}
+		content, err := p.MarshalVT()
+		require.NoError(t, err)
+		return &profilestorepb.RawSample{RawProfile: content, Encoding: profilestorepb.RawSample_ENCODING_NONE}
+	}
+	series := func(job string, samples ...*profilestorepb.RawSample) *profilestorepb.RawProfileSeries {
+		return &profilestorepb.RawProfileSeries{
+			Labels: &profilestorepb.LabelSet{
+				Labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "job", Value: job}},
+			},
+			Samples: samples,
+		}
+	}
+
+	_, err := api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{Series: []*profilestorepb.RawProfileSeries{
+		series("a", profile(600, true), profile(610, true)),
+		series("b", profile(600, false), profile(610, false)),
+	}})
+	require.NoError(t, err)
+	// Only the profile of b is deduplicated.
+	require.Equal(t, float64(1), testutil.ToFloat64(api.deduplicated))
+
+	querier := parcacol.NewQuerier(
+		trace.NewNoopTracerProvider().Tracer(""),
+		query.NewEngine(memory.DefaultAllocator, colDB.TableProvider()),
+		"stacktraces",
+		api.metastore,
+	)
+
+	// Both profiles of a are selected by their pprof labels.
+	res, err := querier.QueryRange(ctx, `memory:alloc_objects:count:space:bytes{handler="x"}`, time.Unix(599, 0), time.Unix(611, 0), 0, 0)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	values := make([]int64, 0, len(res[0].Samples))
+	for _, s := range res[0].Samples {
+		values = append(values, s.Value)
+	}
+	require.Equal(t, []int64{3, 3}, values)
+
+	p, err := querier.QueryMerge(ctx, `memory:alloc_objects:count:space:bytes{handler="x"}`, time.Unix(599, 0), time.Unix(611, 0))
+	require.NoError(t, err)
+	var total int64
+	for _, s := range p.Samples {
+		total += s.Value
+	}
+	require.Equal(t, int64(6), total)
+
+	// The duplicate of b is selected like the profile it refers to.
+	p, err = querier.QueryMerge(ctx, `memory:alloc_objects:count:space:bytes{handler!="x"}`, time.Unix(599, 0), time.Unix(611, 0))
+	require.NoError(t, err)
+	total = 0
+	for _, s := range p.Samples {
+		total += s.Value
+	}
+	require.Equal(t, int64(6), total)
+}
+
+func Test_WriteRaw_ArchiveBackfill(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	archive := objstore.NewInMemBucket()
+
+	profile := func(ts int64, value int64) *profilestorepb.RawSample {
+		p := &pprofpb.Profile{
+			StringTable: []string{"", "alloc_objects", "count", "main"},
+			SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+			TimeNanos:   time.Unix(ts, 0).UnixNano(),
+			Function:    []*pprofpb.Function{{Id: 1, Name: 3}},
+			Location:    []*pprofpb.Location{{Id: 1, Line: []*pprofpb.Line{{FunctionId: 1}}}},
+			Sample:      []*pprofpb.Sample{{LocationId: []uint64{1}, Value: []int64{value}}},
+		}
+		content, err := p.MarshalVT()
+		require.NoError(t, err)
+		return &profilestorepb.RawSample{RawProfile: content, Encoding: profilestorepb.RawSample_ENCODING_NONE}
+	}
+	stored := func(colDB *frostdb.DB) map[int64]int64 {
+		values := map[int64]int64{}
+		engine := query.NewEngine(memory.DefaultAllocator, colDB.TableProvider())
+		err := engine.ScanTable("stacktraces").
+			Project(
+				logicalplan.Col(parcacol.ColumnTimestamp),
+				logicalplan.Col(parcacol.ColumnValue),
+			).
+			Execute(ctx, func(ar arrow.Record) error {
+				timestamps := ar.Column(0).(*array.Int64)
+				vs := ar.Column(1).(*array.Int64)
+				for i := 0; i < int(ar.NumRows()); i++ {
+					values[timestamps.Value(i)] += vs.Value(i)
+				}
+				return nil
+			})
+		require.NoError(t, err)
+		return values
+	}
+
+	api, colDB := newTestProfileColumnStoreWithDB(t, WithRawProfileArchive(archive))
+	_, err := api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{Series: []*profilestorepb.RawProfileSeries{{
+		Labels: &profilestorepb.LabelSet{
+			Labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "job", Value: "a"}},
+		},
+		Samples: []*profilestorepb.RawSample{profile(600, 1), profile(610, 2), profile(620, 4)},
+	}}})
+	require.NoError(t, err)
+	require.Equal(t, map[int64]int64{600000: 1, 610000: 2, 620000: 4}, stored(colDB))
+	require.Len(t, archive.Objects(), 3)
+
+	// A store with an empty database and metastore ingests the archived
+	// profiles of the time range again.
+	backfilled, backfilledDB := newTestProfileColumnStoreWithDB(t, WithRawProfileArchive(archive))
+	n, err := backfilled.Backfill(ctx, time.Unix(600, 0), time.Unix(620, 0))
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, map[int64]int64{600000: 1, 610000: 2}, stored(backfilledDB))
+
+	// The backfilled profiles aren't archived again.
+	require.Len(t, archive.Objects(), 3)
+
+	p, err := parcacol.NewQuerier(
+		trace.NewNoopTracerProvider().Tracer(""),
+		query.NewEngine(memory.DefaultAllocator, backfilledDB.TableProvider()),
+		"stacktraces",
+		backfilled.metastore,
+	).QuerySingle(ctx, `memory:alloc_objects:count::{job="a"}`, time.Unix(610, 0))
+	require.NoError(t, err)
+	require.Len(t, p.Samples, 1)
+	require.Equal(t, int64(2), p.Samples[0].Value)
+	require.Equal(t, "main", p.Samples[0].Locations[0].Lines[0].Function.Name)
+
+	// Without an archive there is nothing to backfill.
+	_, err = newTestProfileColumnStore(t).Backfill(ctx, time.Unix(600, 0), time.Unix(620, 0))
+	require.Error(t, err)
+}
+
+func Test_WriteRaw_ArchiveBackfillTenants(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	archive := objstore.NewInMemBucket()
+
+	profile := func(ts int64, value int64) *profilestorepb.RawSample {
+		p := &pprofpb.Profile{
+			StringTable: []string{"", "alloc_objects", "count", "main"},
+			SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+			TimeNanos:   time.Unix(ts, 0).UnixNano(),
+			Function:    []*pprofpb.Function{{Id: 1, Name: 3}},
+			Location:    []*pprofpb.Location{{Id: 1, Line: []*pprofpb.Line{{FunctionId: 1}}}},
+			Sample:      []*pprofpb.Sample{{LocationId: []uint64{1}, Value: []int64{value}}},
+		}
+		content, err := p.MarshalVT()
+		require.NoError(t, err)
+		return &profilestorepb.RawSample{RawProfile: content, Encoding: profilestorepb.RawSample_ENCODING_NONE}
+	}
+	write := func(ctx context.Context, api *ProfileColumnStore, job string, samples ...*profilestorepb.RawSample) {
+		_, err := api.WriteRaw(ctx, &profilestorepb.WriteRawRequest{Series: []*profilestorepb.RawProfileSeries{{
+			Labels: &profilestorepb.LabelSet{
+				Labels: []*profilestorepb.Label{{Name: "__name__", Value: "memory"}, {Name: "job", Value: job}},
+			},
+			Samples: samples,
+		}}})
+		require.NoError(t, err)
+	}
+
+	tenantA := tenant.NewContext(ctx, "a")
+	tenantB := tenant.NewContext(ctx, "b")
+
+	api := newTestProfileColumnStore(t,
+		WithRawProfileArchive(archive),
+		WithDownsampleInterval(time.Minute),
+	)
+	write(tenantA, api, "a", profile(600, 1), profile(610, 2))
+	write(tenantB, api, "b", profile(605, 4))
+	// The downsampled profile isn't archived.
+	require.Len(t, archive.Objects(), 2)
+
+	// The series of tenant a was already written again after the archived
+	// profile, which doesn't keep it from being backfilled.
+	backfilled, backfilledDB := newTestProfileColumnStoreWithDB(t,
+		WithRawProfileArchive(archive),
+		WithDownsampleInterval(time.Minute),
+		WithDeduplication(time.Hour),
+	)
+	write(tenantA, backfilled, "a", profile(700, 8))
+
+	n, err := backfilled.Backfill(ctx, time.Unix(0, 0), time.Unix(650, 0))
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	querier := parcacol.NewQuerier(
+		trace.NewNoopTracerProvider().Tracer(""),
+		query.NewEngine(memory.DefaultAllocator, backfilledDB.TableProvider()),
+		"stacktraces",
+		backfilled.metastore,
+	)
+	for _, tc := range []struct {
+		ctx   context.Context
+		job   string
+		ts    int64
+		value int64
+	}{
+		{ctx: tenantA, job: "a", ts: 600, value: 1},
+		{ctx: tenantA, job: "a", ts: 700, value: 8},
+		{ctx: tenantB, job: "b", ts: 605, value: 4},
+	} {
+		p, err := querier.QuerySingle(tc.ctx, fmt.Sprintf(`memory:alloc_objects:count::{job=%q}`, tc.job), time.Unix(tc.ts, 0))
+		require.NoError(t, err)
+		require.Len(t, p.Samples, 1)
+		require.Equal(t, tc.value, p.Samples[0].Value)
+	}
+
+	// The profiles are backfilled for their tenants only.
+	_, err = querier.QuerySingle(ctx, `memory:alloc_objects:count::{job="b"}`, time.Unix(605, 0))
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = querier.QuerySingle(tenantA, `memory:alloc_objects:count::{job="b"}`, time.Unix(605, 0))
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log"
@@ -33,7 +34,7 @@ import (
 type Querier interface {
 	Labels(ctx context.Context, match []string, start, end time.Time) ([]string, error)
 	Values(ctx context.Context, labelName string, match []string, start, end time.Time) ([]string, error)
-	QueryRange(ctx context.Context, query string, startTime, endTime time.Time, limit uint32) ([]*pb.MetricsSeries, error)
+	QueryRange(ctx context.Context, query string, startTime, endTime time.Time, step time.Duration, limit uint32) ([]*pb.MetricsSeries, error)
 	ProfileTypes(ctx context.Context) ([]*pb.ProfileType, error)
 	QuerySingle(ctx context.Context, query string, time time.Time) (*profile.Profile, error)
 	QueryMerge(ctx context.Context, query string, start, end time.Time) (*profile.Profile, error)
@@ -94,7 +95,7 @@ func (q *ColumnQueryAPI) QueryRange(ctx context.Context, req *pb.QueryRangeReque
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	res, err := q.querier.QueryRange(ctx, req.Query, req.Start.AsTime(), req.End.AsTime(), req.Limit)
+	res, err := q.querier.QueryRange(ctx, req.Query, req.Start.AsTime(), req.End.AsTime(), req.Step.AsDuration(), req.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -142,44 +143,102 @@ func (q *ColumnQueryAPI) Query(ctx context.Context, req *pb.QueryRequest) (*pb.Q
 		return nil, err
 	}
 
-	return q.renderReport(ctx, p, req.GetReportType())
+	return q.renderReport(ctx, p, req.GetReportType(), int(req.GetTopLimit()), req.GetUnit())
 }
 
-func (q *ColumnQueryAPI) renderReport(ctx context.Context, p *profile.Profile, typ pb.QueryRequest_ReportType) (*pb.QueryResponse, error) {
+// Pprof returns the profile of the series selected by the query as gzipped
+// pprof. If start and end are equal, it is the profile stored at that time,
+// otherwise the profiles stored within the range are merged.
+func (q *ColumnQueryAPI) Pprof(ctx context.Context, query string, start, end time.Time) ([]byte, error) {
+	var (
+		p   *profile.Profile
+		err error
+	)
+	if start.Equal(end) {
+		p, err = q.querier.QuerySingle(ctx, query, start)
+	} else {
+		p, err = q.querier.QueryMerge(ctx, query, start, end)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pprof, err := encodePprof(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate pprof: %w", err)
+	}
+	return pprof, nil
+}
+
+// encodePprof returns the profile as gzipped pprof.
+func encodePprof(ctx context.Context, p *profile.Profile) ([]byte, error) {
+	pp, err := GenerateFlatPprof(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if err := pp.CheckValid(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pp.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderReport renders the report of the given type for the profile. Values
+// are scaled to the unit after they are aggregated, so that rounding doesn't
+// add up, unless the unit is empty.
+func (q *ColumnQueryAPI) renderReport(ctx context.Context, p *profile.Profile, typ pb.QueryRequest_ReportType, topLimit int, unit string) (*pb.QueryResponse, error) {
 	ctx, span := q.tracer.Start(ctx, "renderReport")
 	span.SetAttributes(attribute.String("reportType", typ.String()))
 	defer span.End()
 
+	if unit == "" {
+		unit = p.Meta.SampleType.Unit
+	}
+	scaler, err := profile.NewScaler(p.Meta.SampleType.Unit, unit)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to scale to unit %q: %v", unit, err.Error())
+	}
+
 	switch typ {
 	case pb.QueryRequest_REPORT_TYPE_FLAMEGRAPH_UNSPECIFIED:
 		fg, err := GenerateFlamegraphFlat(ctx, q.tracer, p)
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to generate flamegraph: %v", err.Error())
 		}
+		if !scaler.Identity() {
+			scaleFlamegraph(fg, scaler, unit)
+		}
 		return &pb.QueryResponse{
 			Report: &pb.QueryResponse_Flamegraph{
 				Flamegraph: fg,
 			},
 		}, nil
 	case pb.QueryRequest_REPORT_TYPE_PPROF:
-		pp, err := GenerateFlatPprof(ctx, p)
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to generate pprof: %v", err.Error())
+		// pprof profiles carry their unit, and are scaled by the pprof tools.
+		if !scaler.Identity() {
+			return nil, status.Error(codes.InvalidArgument, "pprof reports can't be scaled to another unit")
 		}
 
-		var buf bytes.Buffer
-		if err := pp.Write(&buf); err != nil {
+		pprof, err := encodePprof(ctx, p)
+		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to generate pprof: %v", err.Error())
 		}
 
 		return &pb.QueryResponse{
-			Report: &pb.QueryResponse_Pprof{Pprof: buf.Bytes()},
+			Report: &pb.QueryResponse_Pprof{Pprof: pprof},
 		}, nil
 	case pb.QueryRequest_REPORT_TYPE_TOP:
-		top, err := GenerateTopTable(ctx, p)
+		top, err := GenerateTopTable(ctx, p, topLimit)
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to generate pprof: %v", err.Error())
 		}
+		if !scaler.Identity() {
+			scaleTop(top, scaler, unit)
+		}
 
 		return &pb.QueryResponse{
 			Report: &pb.QueryResponse_Top{Top: top},
@@ -234,32 +293,65 @@ func (q *ColumnQueryAPI) selectDiff(ctx context.Context, d *pb.DiffProfile) (*pr
 		return nil, fmt.Errorf("reading compared profile: %w", err)
 	}
 
+	diff, err := diffProfiles(base, compare)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return diff, nil
+}
+
+// diffProfiles returns a profile with a sample per stack of either profile.
+// The value of a sample is its value in the compared profile, the diff value
+// is the value in the compared profile minus the value in the base profile.
+// Values of the base profile are converted to the unit of the compared
+// profile, profiles of incompatible units can't be diffed.
+func diffProfiles(base, compare *profile.Profile) (*profile.Profile, error) {
+	scaler, err := profile.NewScaler(base.Meta.SampleType.Unit, compare.Meta.SampleType.Unit)
+	if err != nil {
+		return nil, fmt.Errorf("diff profiles: %w", err)
+	}
+
 	// TODO: This is cheating a bit. This should be done with a sub-query in the columnstore.
-	diff := &profile.Profile{}
+	diff := &profile.Profile{
+		Samples: make([]*profile.SymbolizedSample, 0, len(compare.Samples)),
+		Meta:    compare.Meta,
+	}
 
-	// TODO: Use parcacol.Sample for comparing these
-	for i := range compare.Samples {
-		diff.Samples = append(diff.Samples, &profile.SymbolizedSample{
-			Locations: compare.Samples[i].Locations,
-			Value:     compare.Samples[i].Value,
-			DiffValue: compare.Samples[i].Value,
-			Label:     compare.Samples[i].Label,
-			NumLabel:  compare.Samples[i].NumLabel,
-		})
+	stacks := make(map[string]*profile.SymbolizedSample, len(compare.Samples))
+	sample := func(s *profile.SymbolizedSample) *profile.SymbolizedSample {
+		key := stackKey(s.Locations)
+		if d, ok := stacks[key]; ok {
+			return d
+		}
+		d := &profile.SymbolizedSample{Locations: s.Locations}
+		stacks[key] = d
+		diff.Samples = append(diff.Samples, d)
+		return d
 	}
 
-	for i := range base.Samples {
-		diff.Samples = append(diff.Samples, &profile.SymbolizedSample{
-			Locations: base.Samples[i].Locations,
-			DiffValue: -base.Samples[i].Value,
-			Label:     base.Samples[i].Label,
-			NumLabel:  base.Samples[i].NumLabel,
-		})
+	for _, s := range compare.Samples {
+		d := sample(s)
+		d.Value += s.Value
+		d.DiffValue += s.Value
+	}
+	for _, s := range base.Samples {
+		d := sample(s)
+		d.DiffValue -= scaler.Scale(s.Value)
 	}
 
 	return diff, nil
 }
 
+func stackKey(locations []*profile.Location) string {
+	var b strings.Builder
+	for _, l := range locations {
+		b.WriteString(l.ID)
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
 func (q *ColumnQueryAPI) selectProfileForDiff(ctx context.Context, s *pb.ProfileDiffSelection) (*profile.Profile, error) {
 	switch s.Mode {
 	case pb.ProfileDiffSelection_MODE_SINGLE_UNSPECIFIED:
@@ -18,13 +18,16 @@ import (
 	"compress/gzip"
 	"context"
 	"crypto/tls"
+	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/apache/arrow/go/v8/arrow/memory"
 	"github.com/go-kit/log"
+	pprofprofile "github.com/google/pprof/profile"
 	columnstore "github.com/polarsignals/frostdb"
 	"github.com/polarsignals/frostdb/query"
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,6 +40,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
@@ -48,6 +52,7 @@ import (
 	"github.com/parca-dev/parca/pkg/metastoretest"
 	"github.com/parca-dev/parca/pkg/parcacol"
 	"github.com/parca-dev/parca/pkg/profile"
+	"github.com/parca-dev/parca/pkg/tenant"
 )
 
 func getShareServerConn(t Testing) share.ShareClient {
@@ -216,6 +221,173 @@ func TestColumnQueryAPIQueryRange(t *testing.T) {
 	require.Equal(t, 10, len(res.Series[0].Samples))
 }
 
+func TestColumnQueryAPIQueryRangeStep(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+
+	fres, err := m.GetOrCreateFunctions(ctx, &metastorepb.GetOrCreateFunctionsRequest{
+		Functions: []*metastorepb.Function{{Name: "testFunc"}},
+	})
+	require.NoError(t, err)
+
+	lres, err := m.GetOrCreateLocations(ctx, &metastorepb.GetOrCreateLocationsRequest{
+		Locations: []*metastorepb.Location{{
+			Address: 0x1,
+			Lines: []*metastorepb.Line{{
+				Line:       1,
+				FunctionId: fres.Functions[0].Id,
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	sres, err := m.GetOrCreateStacktraces(ctx, &metastorepb.GetOrCreateStacktracesRequest{
+		Stacktraces: []*metastorepb.Stacktrace{{
+			LocationIds: []string{lres.Locations[0].Id},
+		}},
+	})
+	require.NoError(t, err)
+	st := sres.Stacktraces[0]
+
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	for i, ts := range []int64{1000, 1500, 2000, 2500, 3500} {
+		for job, value := range map[string]int64{"a": int64(i + 1), "b": 100} {
+			err = ingester.IngestProfile(
+				ctx,
+				labels.Labels{{Name: "job", Value: job}},
+				&profile.NormalizedProfile{
+					Meta: profile.Meta{
+						Name:       "memory",
+						PeriodType: profile.ValueType{Type: "space", Unit: "bytes"},
+						SampleType: profile.ValueType{Type: "alloc_objects", Unit: "count"},
+						Timestamp:  ts,
+					},
+					Samples: []*profile.NormalizedSample{{
+						StacktraceID: st.Id,
+						Value:        value,
+					}},
+				},
+			)
+			require.NoError(t, err)
+		}
+	}
+
+	api := NewColumnQueryAPI(
+		logger,
+		tracer,
+		getShareServerConn(t),
+		parcacol.NewQuerier(
+			tracer,
+			query.NewEngine(
+				memory.DefaultAllocator,
+				colDB.TableProvider(),
+			),
+			"stacktraces",
+			metastore,
+		),
+	)
+
+	type sample struct {
+		ts    int64
+		value int64
+	}
+	for _, tc := range []struct {
+		name    string
+		query   string
+		step    time.Duration
+		samples []sample
+	}{{
+		name:    "equal",
+		query:   `memory:alloc_objects:count:space:bytes{job="a"}`,
+		step:    time.Second,
+		samples: []sample{{1000, 3}, {2000, 7}, {3000, 5}},
+	}, {
+		name:    "not_equal",
+		query:   `memory:alloc_objects:count:space:bytes{job!="b"}`,
+		step:    time.Second,
+		samples: []sample{{1000, 3}, {2000, 7}, {3000, 5}},
+	}, {
+		name:    "regexp",
+		query:   `memory:alloc_objects:count:space:bytes{job=~"a|c"}`,
+		step:    2 * time.Second,
+		samples: []sample{{0, 3}, {2000, 12}},
+	}, {
+		name:    "not_regexp",
+		query:   `memory:alloc_objects:count:space:bytes{job!~"b.*"}`,
+		samples: []sample{{1000, 1}, {1500, 2}, {2000, 3}, {2500, 4}, {3500, 5}},
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := api.QueryRange(ctx, &pb.QueryRangeRequest{
+				Query: tc.query,
+				Start: timestamppb.New(timestamp.Time(0)),
+				End:   timestamppb.New(timestamp.Time(10000)),
+				Step:  durationpb.New(tc.step),
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(res.Series))
+			require.Equal(t, "a", res.Series[0].Labelset.Labels[0].Value)
+
+			samples := make([]sample, 0, len(res.Series[0].Samples))
+			for _, s := range res.Series[0].Samples {
+				samples = append(samples, sample{timestamp.FromTime(s.Timestamp.AsTime()), s.Value})
+			}
+			require.Equal(t, tc.samples, samples)
+		})
+	}
+
+	_, err = api.QueryRange(ctx, &pb.QueryRangeRequest{
+		Query: `memory:alloc_objects:count:space:bytes{job="a"}`,
+		Start: timestamppb.New(timestamp.Time(0)),
+		End:   timestamppb.New(timestamp.Time(10000)),
+		Step:  durationpb.New(-time.Second),
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = api.QueryRange(ctx, &pb.QueryRangeRequest{
+		Query: `memory:alloc_objects:count:space:bytes{job="a"}`,
+		Start: timestamppb.New(timestamp.Time(0)),
+		End:   timestamppb.New(timestamp.Time(10000)),
+		Step:  durationpb.New(500 * time.Microsecond),
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The querier treats steps shorter than a millisecond as no step.
+	series, err := api.querier.QueryRange(ctx, `memory:alloc_objects:count:space:bytes{job="a"}`, timestamp.Time(0), timestamp.Time(10000), 500*time.Microsecond, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(series))
+	require.Equal(t, 5, len(series[0].Samples))
+}
+
 func TestColumnQueryAPIQuerySingle(t *testing.T) {
 	t.Parallel()
 
@@ -245,16 +417,927 @@ func TestColumnQueryAPIQuerySingle(t *testing.T) {
 		reg,
 		tracer,
 	)
-
-	fileContent := MustReadAllGzip(t, "testdata/alloc_objects.pb.gz")
-	p := &pprofpb.Profile{}
-	err = p.UnmarshalVT(fileContent)
-	require.NoError(t, err)
-
+
+	fileContent := MustReadAllGzip(t, "testdata/alloc_objects.pb.gz")
+	p := &pprofpb.Profile{}
+	err = p.UnmarshalVT(fileContent)
+	require.NoError(t, err)
+
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	err = ingester.Ingest(ctx, labels.Labels{{
+		Name:  "__name__",
+		Value: "memory",
+	}, {
+		Name:  "job",
+		Value: "default",
+	}}, p, false)
+	require.NoError(t, err)
+
+	api := NewColumnQueryAPI(
+		logger,
+		tracer,
+		getShareServerConn(t),
+		parcacol.NewQuerier(
+			tracer,
+			query.NewEngine(
+				memory.DefaultAllocator,
+				colDB.TableProvider(),
+			),
+			"stacktraces",
+			metastore,
+		),
+	)
+	ts := timestamppb.New(timestamp.Time(p.TimeNanos / time.Millisecond.Nanoseconds()))
+	res, err := api.Query(ctx, &pb.QueryRequest{
+		Options: &pb.QueryRequest_Single{
+			Single: &pb.SingleProfile{
+				Query: `memory:alloc_objects:count:space:bytes{job="default"}`,
+				Time:  ts,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(33), res.Report.(*pb.QueryResponse_Flamegraph).Flamegraph.Height)
+
+	res, err = api.Query(ctx, &pb.QueryRequest{
+		ReportType: pb.QueryRequest_REPORT_TYPE_PPROF,
+		Options: &pb.QueryRequest_Single{
+			Single: &pb.SingleProfile{
+				Query: `memory:alloc_objects:count:space:bytes{job="default"}`,
+				Time:  ts,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	testProf := &pprofpb.Profile{}
+	err = testProf.UnmarshalVT(MustDecompressGzip(t, res.Report.(*pb.QueryResponse_Pprof).Pprof))
+	require.NoError(t, err)
+}
+
+func TestColumnQueryAPIPprofRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+
+	fileContent := MustReadAllGzip(t, "testdata/alloc_objects.pb.gz")
+	p := &pprofpb.Profile{}
+	err = p.UnmarshalVT(fileContent)
+	require.NoError(t, err)
+
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	err = ingester.Ingest(ctx, labels.Labels{{
+		Name:  "__name__",
+		Value: "memory",
+	}, {
+		Name:  "job",
+		Value: "default",
+	}}, p, false)
+	require.NoError(t, err)
+
+	api := NewColumnQueryAPI(
+		logger,
+		tracer,
+		getShareServerConn(t),
+		parcacol.NewQuerier(
+			tracer,
+			query.NewEngine(
+				memory.DefaultAllocator,
+				colDB.TableProvider(),
+			),
+			"stacktraces",
+			metastore,
+		),
+	)
+
+	original, err := pprofprofile.ParseData(fileContent)
+	require.NoError(t, err)
+
+	// Samples of the same stack and labels are stored as one, and merging
+	// profiles merges the samples of the same stack regardless of their
+	// labels.
+	samples := map[string]struct{}{}
+	stacks := map[string]struct{}{}
+	for _, s := range original.Sample {
+		stack := ""
+		for _, l := range s.Location {
+			stack += fmt.Sprintf(";%d", l.ID)
+		}
+		stacks[stack] = struct{}{}
+		samples[fmt.Sprint(s.Label, s.NumLabel, stack)] = struct{}{}
+	}
+
+	ts := timestamp.Time(p.TimeNanos / time.Millisecond.Nanoseconds())
+	for _, tc := range []struct {
+		name       string
+		start, end time.Time
+		samples    int
+	}{{
+		name:    "single",
+		start:   ts,
+		end:     ts,
+		samples: len(samples),
+	}, {
+		name:    "range",
+		start:   ts.Add(-time.Minute),
+		end:     ts.Add(time.Minute),
+		samples: len(stacks),
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := api.Pprof(ctx, `memory:alloc_objects:count:space:bytes{job="default"}`, tc.start, tc.end)
+			require.NoError(t, err)
+
+			got, err := pprofprofile.Parse(bytes.NewReader(res))
+			require.NoError(t, err)
+			require.NoError(t, got.CheckValid())
+
+			require.Equal(t, tc.samples, len(got.Sample))
+			require.Equal(t, topFunction(original, "alloc_objects"), topFunction(got, "alloc_objects"))
+		})
+	}
+}
+
+// sampleTypeIndex returns the index of the values of the sample type.
+func sampleTypeIndex(p *pprofprofile.Profile, sampleType string) int {
+	for i, st := range p.SampleType {
+		if st.Type == sampleType {
+			return i
+		}
+	}
+	return -1
+}
+
+// topFunction returns the name of the function with the largest flat value
+// of the sample type.
+func topFunction(p *pprofprofile.Profile, sampleType string) string {
+	index := sampleTypeIndex(p, sampleType)
+	flat := map[string]int64{}
+	for _, s := range p.Sample {
+		flat[s.Location[0].Line[0].Function.Name] += s.Value[index]
+	}
+
+	var (
+		top   string
+		value int64
+	)
+	for name, v := range flat {
+		if v > value || (v == value && name < top) {
+			top, value = name, v
+		}
+	}
+	return top
+}
+
+// newTestStackProfile returns a profile with a sample of the given value per
+// leaf location ID. The stack of every sample is the leaf called by "main".
+// The locations 2, 3 and 4 are in the functions "a", "b" and "c".
+func newTestStackProfile(samples map[uint64]int64) *pprofpb.Profile {
+	p := &pprofpb.Profile{
+		StringTable: []string{"", "alloc_objects", "count", "space", "bytes", "main", "a", "b", "c"},
+		SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+		PeriodType:  &pprofpb.ValueType{Type: 3, Unit: 4},
+		Function:    []*pprofpb.Function{{Id: 1, Name: 5}, {Id: 2, Name: 6}, {Id: 3, Name: 7}, {Id: 4, Name: 8}},
+		Location: []*pprofpb.Location{
+			{Id: 1, Line: []*pprofpb.Line{{FunctionId: 1, Line: 1}}},
+			{Id: 2, Line: []*pprofpb.Line{{FunctionId: 2, Line: 2}}},
+			{Id: 3, Line: []*pprofpb.Line{{FunctionId: 3, Line: 3}}},
+			{Id: 4, Line: []*pprofpb.Line{{FunctionId: 4, Line: 4}}},
+		},
+	}
+	for leaf, value := range samples {
+		p.Sample = append(p.Sample, &pprofpb.Sample{
+			LocationId: []uint64{leaf, 1},
+			Value:      []int64{value},
+		})
+	}
+	return p
+}
+
+// stackName returns the function names of the stack of the sample joined by ";".
+func stackName(s *profile.SymbolizedSample) string {
+	names := make([]string, 0, len(s.Locations))
+	for _, l := range s.Locations {
+		names = append(names, l.Lines[0].Function.Name)
+	}
+	return strings.Join(names, ";")
+}
+
+func TestColumnQueryAPIQueryMerge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	// All profiles are normalized separately, as if written by separate
+	// requests, and share the stack main -> a.
+	for i, tc := range []struct {
+		job     string
+		samples map[uint64]int64
+	}{
+		{job: "default", samples: map[uint64]int64{2: 1, 3: 2}},
+		{job: "default", samples: map[uint64]int64{2: 3}},
+		{job: "other", samples: map[uint64]int64{2: 100, 3: 100}},
+	} {
+		p := newTestStackProfile(tc.samples)
+		p.TimeNanos = int64(i+1) * time.Millisecond.Nanoseconds()
+		err = ingester.Ingest(ctx, labels.Labels{{
+			Name:  "__name__",
+			Value: "memory",
+		}, {
+			Name:  "job",
+			Value: tc.job,
+		}}, p, false)
+		require.NoError(t, err)
+	}
+
+	querier := parcacol.NewQuerier(
+		tracer,
+		query.NewEngine(
+			memory.DefaultAllocator,
+			colDB.TableProvider(),
+		),
+		"stacktraces",
+		metastore,
+	)
+
+	p, err := querier.QueryMerge(
+		ctx,
+		`memory:alloc_objects:count:space:bytes{job="default"}`,
+		timestamp.Time(0),
+		timestamp.Time(10),
+	)
+	require.NoError(t, err)
+
+	stacks := map[string]int64{}
+	for _, s := range p.Samples {
+		stacks[stackName(s)] += s.Value
+	}
+	require.Equal(t, 2, len(p.Samples))
+	require.Equal(t, map[string]int64{"a;main": 4, "b;main": 2}, stacks)
+
+	_, err = querier.QueryMerge(
+		ctx,
+		`memory:alloc_objects:count:space:bytes{job="unknown"}`,
+		timestamp.Time(0),
+		timestamp.Time(10),
+	)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestColumnQueryAPIQueryInstant(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	// The default series has a profile at 10ms and one at 20ms, the other
+	// series only one at 10ms.
+	for _, tc := range []struct {
+		job     string
+		ts      int64
+		samples map[uint64]int64
+	}{
+		{job: "default", ts: 10, samples: map[uint64]int64{2: 1, 3: 2}},
+		{job: "default", ts: 20, samples: map[uint64]int64{2: 3}},
+		{job: "other", ts: 10, samples: map[uint64]int64{2: 100, 3: 100}},
+	} {
+		p := newTestStackProfile(tc.samples)
+		p.TimeNanos = tc.ts * time.Millisecond.Nanoseconds()
+		err = ingester.Ingest(ctx, labels.Labels{{
+			Name:  "__name__",
+			Value: "memory",
+		}, {
+			Name:  "job",
+			Value: tc.job,
+		}}, p, false)
+		require.NoError(t, err)
+	}
+
+	querier := parcacol.NewQuerier(
+		tracer,
+		query.NewEngine(
+			memory.DefaultAllocator,
+			colDB.TableProvider(),
+		),
+		"stacktraces",
+		metastore,
+	)
+
+	stacks := func(p *profile.Profile) map[string]int64 {
+		stacks := map[string]int64{}
+		for _, s := range p.Samples {
+			stacks[stackName(s)] += s.Value
+		}
+		return stacks
+	}
+
+	for _, tc := range []struct {
+		name   string
+		query  string
+		time   int64
+		stacks map[string]int64
+	}{{
+		name:   "after the newest profile",
+		query:  `memory:alloc_objects:count:space:bytes{job="default"}`,
+		time:   25,
+		stacks: map[string]int64{"a;main": 3},
+	}, {
+		name:   "at the newest profile",
+		query:  `memory:alloc_objects:count:space:bytes{job="default"}`,
+		time:   20,
+		stacks: map[string]int64{"a;main": 3},
+	}, {
+		name:   "between the profiles",
+		query:  `memory:alloc_objects:count:space:bytes{job="default"}`,
+		time:   15,
+		stacks: map[string]int64{"a;main": 1, "b;main": 2},
+	}, {
+		name:   "newest profiles of all series merged",
+		query:  `memory:alloc_objects:count:space:bytes`,
+		time:   25,
+		stacks: map[string]int64{"a;main": 103, "b;main": 100},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := querier.QueryInstant(ctx, tc.query, timestamp.Time(tc.time))
+			require.NoError(t, err)
+			require.Equal(t, tc.time, p.Meta.Timestamp)
+			require.Equal(t, tc.stacks, stacks(p))
+		})
+	}
+
+	_, err = querier.QueryInstant(
+		ctx,
+		`memory:alloc_objects:count:space:bytes{job="default"}`,
+		timestamp.Time(5),
+	)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestColumnQueryAPIQueryMergeFlamegraph(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	// The function a is recursive, main -> a -> a -> b is a different stack
+	// than main -> a -> b.
+	for i, stacks := range []map[int64][]uint64{
+		{5: {3, 2, 2, 1}, 3: {3, 2, 1}, 2: {4, 1}},
+		{1: {3, 2, 2, 1}},
+	} {
+		p := newTestStackProfile(nil)
+		p.TimeNanos = int64(i+1) * time.Millisecond.Nanoseconds()
+		for value, stack := range stacks {
+			p.Sample = append(p.Sample, &pprofpb.Sample{LocationId: stack, Value: []int64{value}})
+		}
+		err = ingester.Ingest(ctx, labels.Labels{{
+			Name:  "__name__",
+			Value: "memory",
+		}, {
+			Name:  "job",
+			Value: "default",
+		}}, p, false)
+		require.NoError(t, err)
+	}
+
+	api := NewColumnQueryAPI(
+		logger,
+		tracer,
+		getShareServerConn(t),
+		parcacol.NewQuerier(
+			tracer,
+			query.NewEngine(
+				memory.DefaultAllocator,
+				colDB.TableProvider(),
+			),
+			"stacktraces",
+			metastore,
+		),
+	)
+	res, err := api.Query(ctx, &pb.QueryRequest{
+		Mode: pb.QueryRequest_MODE_MERGE,
+		Options: &pb.QueryRequest_Merge{
+			Merge: &pb.MergeProfile{
+				Query: `memory:alloc_objects:count:space:bytes{job="default"}`,
+				Start: timestamppb.New(timestamp.Time(0)),
+				End:   timestamppb.New(timestamp.Time(10)),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	fg := res.Report.(*pb.QueryResponse_Flamegraph).Flamegraph
+	require.Equal(t, int64(11), fg.Root.Cumulative)
+	require.Equal(t, int64(11), fg.Total)
+
+	child := func(children []*pb.FlamegraphNode, name string) *pb.FlamegraphNode {
+		t.Helper()
+		for _, c := range children {
+			if c.Meta.Function.Name == name {
+				return c
+			}
+		}
+		require.FailNowf(t, "child not found", "no child %q", name)
+		return nil
+	}
+	require.Len(t, fg.Root.Children, 1)
+	main := child(fg.Root.Children, "main")
+	require.Equal(t, int64(11), main.Cumulative)
+	require.Len(t, main.Children, 2)
+	require.Equal(t, int64(2), child(main.Children, "c").Cumulative)
+
+	a := child(main.Children, "a")
+	require.Equal(t, int64(9), a.Cumulative)
+	require.Len(t, a.Children, 2)
+	require.Equal(t, int64(3), child(a.Children, "b").Cumulative)
+
+	recursive := child(a.Children, "a")
+	require.Equal(t, int64(6), recursive.Cumulative)
+	require.Len(t, recursive.Children, 1)
+	require.Equal(t, int64(6), child(recursive.Children, "b").Cumulative)
+}
+
+func TestColumnQueryAPIQueryBySampleType(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	// A CPU profile has a value per sample type in every sample.
+	p := &pprofpb.Profile{
+		StringTable: []string{"", "samples", "count", "cpu", "nanoseconds", "main", "a", "b"},
+		SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}, {Type: 3, Unit: 4}},
+		PeriodType:  &pprofpb.ValueType{Type: 3, Unit: 4},
+		Period:      10000000,
+		TimeNanos:   time.Millisecond.Nanoseconds(),
+		Function:    []*pprofpb.Function{{Id: 1, Name: 5}, {Id: 2, Name: 6}, {Id: 3, Name: 7}},
+		Location: []*pprofpb.Location{
+			{Id: 1, Line: []*pprofpb.Line{{FunctionId: 1, Line: 1}}},
+			{Id: 2, Line: []*pprofpb.Line{{FunctionId: 2, Line: 2}}},
+			{Id: 3, Line: []*pprofpb.Line{{FunctionId: 3, Line: 3}}},
+		},
+		Sample: []*pprofpb.Sample{
+			{LocationId: []uint64{2, 1}, Value: []int64{1, 10000000}},
+			{LocationId: []uint64{3, 1}, Value: []int64{3, 30000000}},
+		},
+	}
+	err = ingester.Ingest(ctx, labels.Labels{{
+		Name:  "__name__",
+		Value: "process_cpu",
+	}, {
+		Name:  "job",
+		Value: "default",
+	}}, p, false)
+	require.NoError(t, err)
+
+	table.Sync()
+
+	querier := parcacol.NewQuerier(
+		tracer,
+		query.NewEngine(
+			memory.DefaultAllocator,
+			colDB.TableProvider(),
+		),
+		"stacktraces",
+		metastore,
+	)
+
+	types, err := querier.ProfileTypes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(types))
+
+	for _, tc := range []struct {
+		query string
+		want  map[string]int64
+	}{
+		{
+			query: `process_cpu:samples:count:cpu:nanoseconds{job="default"}`,
+			want:  map[string]int64{"a;main": 1, "b;main": 3},
+		},
+		{
+			query: `process_cpu:cpu:nanoseconds:cpu:nanoseconds{job="default"}`,
+			want:  map[string]int64{"a;main": 10000000, "b;main": 30000000},
+		},
+	} {
+		p, err := querier.QueryMerge(ctx, tc.query, timestamp.Time(0), timestamp.Time(10))
+		require.NoError(t, err)
+
+		stacks := map[string]int64{}
+		for _, s := range p.Samples {
+			stacks[stackName(s)] += s.Value
+		}
+		require.Equal(t, tc.want, stacks, tc.query)
+	}
+}
+
+func TestColumnQueryAPIQueryByPprofLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	// The samples of the tenants are labeled like by pprof.Do, the last
+	// sample has no labels at all.
+	p := newTestStackProfile(nil)
+	p.StringTable = append(p.StringTable, "tenant", "foo", "bar")
+	p.TimeNanos = time.Millisecond.Nanoseconds()
+	p.Sample = []*pprofpb.Sample{
+		{LocationId: []uint64{2, 1}, Value: []int64{1}, Label: []*pprofpb.Label{{Key: 9, Str: 10}}},
+		{LocationId: []uint64{3, 1}, Value: []int64{2}, Label: []*pprofpb.Label{{Key: 9, Str: 10}}},
+		{LocationId: []uint64{3, 1}, Value: []int64{4}, Label: []*pprofpb.Label{{Key: 9, Str: 11}}},
+		{LocationId: []uint64{4, 1}, Value: []int64{8}},
+	}
+	err = ingester.Ingest(ctx, labels.Labels{{
+		Name:  "__name__",
+		Value: "memory",
+	}, {
+		Name:  "job",
+		Value: "default",
+	}}, p, false)
+	require.NoError(t, err)
+
+	table.Sync()
+
+	querier := parcacol.NewQuerier(
+		tracer,
+		query.NewEngine(
+			memory.DefaultAllocator,
+			colDB.TableProvider(),
+		),
+		"stacktraces",
+		metastore,
+	)
+
+	for _, tc := range []struct {
+		query string
+		want  map[string]int64
+	}{
+		{
+			query: `memory:alloc_objects:count:space:bytes{job="default"}`,
+			want:  map[string]int64{"a;main": 1, "b;main": 6, "c;main": 8},
+		},
+		{
+			query: `memory:alloc_objects:count:space:bytes{job="default", tenant="foo"}`,
+			want:  map[string]int64{"a;main": 1, "b;main": 2},
+		},
+		{
+			query: `memory:alloc_objects:count:space:bytes{tenant=~"b.*"}`,
+			want:  map[string]int64{"b;main": 4},
+		},
+	} {
+		p, err := querier.QueryMerge(ctx, tc.query, timestamp.Time(0), timestamp.Time(10))
+		require.NoError(t, err)
+
+		stacks := map[string]int64{}
+		for _, s := range p.Samples {
+			stacks[stackName(s)] += s.Value
+		}
+		require.Equal(t, tc.want, stacks, tc.query)
+	}
+
+	_, err = querier.QueryMerge(ctx, `memory:alloc_objects:count:space:bytes{tenant="unknown"}`, timestamp.Time(0), timestamp.Time(10))
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestColumnQueryAPIQuerySinglePprofLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	// The samples are labeled like by pprof.Do, the ones of "b" also with
+	// the size of the allocations, the ones of "c" not at all.
+	p := newTestStackProfile(nil)
+	p.StringTable = append(p.StringTable, "tenant", "foo", "bar", "size")
+	p.TimeNanos = time.Millisecond.Nanoseconds()
+	p.Sample = []*pprofpb.Sample{
+		{LocationId: []uint64{2, 1}, Value: []int64{1}, Label: []*pprofpb.Label{{Key: 9, Str: 10}}},
+		{LocationId: []uint64{3, 1}, Value: []int64{2}, Label: []*pprofpb.Label{{Key: 9, Str: 11}, {Key: 12, Num: 512}}},
+		{LocationId: []uint64{4, 1}, Value: []int64{4}},
+	}
+	err = ingester.Ingest(ctx, labels.Labels{{
+		Name:  "__name__",
+		Value: "memory",
+	}, {
+		Name:  "job",
+		Value: "default",
+	}}, p, false)
+	require.NoError(t, err)
+
+	table.Sync()
+
+	querier := parcacol.NewQuerier(
+		tracer,
+		query.NewEngine(
+			memory.DefaultAllocator,
+			colDB.TableProvider(),
+		),
+		"stacktraces",
+		metastore,
+	)
+
+	const q = `memory:alloc_objects:count:space:bytes{job="default"}`
+	sp, err := querier.QuerySingle(ctx, q, timestamp.Time(1))
+	require.NoError(t, err)
+
+	type sampleLabels struct {
+		label    map[string]string
+		numLabel map[string]int64
+	}
+	got := map[string]sampleLabels{}
+	for _, s := range sp.Samples {
+		got[stackName(s)] = sampleLabels{label: s.Label, numLabel: s.NumLabel}
+	}
+	require.Equal(t, map[string]sampleLabels{
+		"a;main": {label: map[string]string{"tenant": "foo"}},
+		"b;main": {label: map[string]string{"tenant": "bar"}, numLabel: map[string]int64{"size": 512}},
+		"c;main": {},
+	}, got)
+
+	// The labels are kept in pprof reports.
+	api := NewColumnQueryAPI(
+		logger,
+		tracer,
+		getShareServerConn(t),
+		querier,
+	)
+	res, err := api.Query(ctx, &pb.QueryRequest{
+		ReportType: pb.QueryRequest_REPORT_TYPE_PPROF,
+		Options: &pb.QueryRequest_Single{
+			Single: &pb.SingleProfile{
+				Query: q,
+				Time:  timestamppb.New(timestamp.Time(1)),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	pp, err := pprofprofile.ParseData(res.Report.(*pb.QueryResponse_Pprof).Pprof)
+	require.NoError(t, err)
+	require.Len(t, pp.Sample, 3)
+	for _, s := range pp.Sample {
+		switch s.Value[0] {
+		case 1:
+			require.Equal(t, map[string][]string{"tenant": {"foo"}}, s.Label)
+			require.Empty(t, s.NumLabel)
+		case 2:
+			require.Equal(t, map[string][]string{"tenant": {"bar"}}, s.Label)
+			require.Equal(t, map[string][]int64{"size": {512}}, s.NumLabel)
+		default:
+			require.Empty(t, s.Label)
+			require.Empty(t, s.NumLabel)
+		}
+	}
+}
+
+func TestColumnQueryAPIQueryTop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
 	metastore := metastore.NewInProcessClient(m)
 	normalizer := parcacol.NewNormalizer(metastore)
 	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
 
+	// The function "a" is recursive, it calls itself from a different line.
+	p := &pprofpb.Profile{
+		StringTable: []string{"", "alloc_objects", "count", "space", "bytes", "main", "a", "b", "c"},
+		SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+		PeriodType:  &pprofpb.ValueType{Type: 3, Unit: 4},
+		TimeNanos:   time.Millisecond.Nanoseconds(),
+		Function:    []*pprofpb.Function{{Id: 1, Name: 5}, {Id: 2, Name: 6}, {Id: 3, Name: 7}, {Id: 4, Name: 8}},
+		Location: []*pprofpb.Location{
+			{Id: 1, Address: 0x1, Line: []*pprofpb.Line{{FunctionId: 1, Line: 1}}},
+			{Id: 2, Address: 0x2, Line: []*pprofpb.Line{{FunctionId: 2, Line: 2}}},
+			{Id: 3, Address: 0x3, Line: []*pprofpb.Line{{FunctionId: 3, Line: 3}}},
+			{Id: 4, Address: 0x4, Line: []*pprofpb.Line{{FunctionId: 4, Line: 4}}},
+			{Id: 5, Address: 0x5, Line: []*pprofpb.Line{{FunctionId: 2, Line: 5}}},
+		},
+		Sample: []*pprofpb.Sample{
+			{LocationId: []uint64{3, 2, 5, 1}, Value: []int64{5}},
+			{LocationId: []uint64{2, 5, 1}, Value: []int64{3}},
+			{LocationId: []uint64{4, 3, 1}, Value: []int64{2}},
+			{LocationId: []uint64{2, 1}, Value: []int64{1}},
+		},
+	}
 	err = ingester.Ingest(ctx, labels.Labels{{
 		Name:  "__name__",
 		Value: "memory",
@@ -264,6 +1347,8 @@ func TestColumnQueryAPIQuerySingle(t *testing.T) {
 	}}, p, false)
 	require.NoError(t, err)
 
+	table.Sync()
+
 	api := NewColumnQueryAPI(
 		logger,
 		tracer,
@@ -278,32 +1363,49 @@ func TestColumnQueryAPIQuerySingle(t *testing.T) {
 			metastore,
 		),
 	)
-	ts := timestamppb.New(timestamp.Time(p.TimeNanos / time.Millisecond.Nanoseconds()))
-	res, err := api.Query(ctx, &pb.QueryRequest{
-		Options: &pb.QueryRequest_Single{
-			Single: &pb.SingleProfile{
-				Query: `memory:alloc_objects:count:space:bytes{job="default"}`,
-				Time:  ts,
-			},
-		},
-	})
-	require.NoError(t, err)
-	require.Equal(t, int32(33), res.Report.(*pb.QueryResponse_Flamegraph).Flamegraph.Height)
 
-	res, err = api.Query(ctx, &pb.QueryRequest{
-		ReportType: pb.QueryRequest_REPORT_TYPE_PPROF,
-		Options: &pb.QueryRequest_Single{
-			Single: &pb.SingleProfile{
-				Query: `memory:alloc_objects:count:space:bytes{job="default"}`,
-				Time:  ts,
+	queryTop := func(limit uint32) *pb.Top {
+		res, err := api.Query(ctx, &pb.QueryRequest{
+			Mode:       pb.QueryRequest_MODE_MERGE,
+			ReportType: pb.QueryRequest_REPORT_TYPE_TOP,
+			TopLimit:   limit,
+			Options: &pb.QueryRequest_Merge{
+				Merge: &pb.MergeProfile{
+					Query: `memory:alloc_objects:count:space:bytes{job="default"}`,
+					Start: timestamppb.New(timestamp.Time(0)),
+					End:   timestamppb.New(timestamp.Time(10)),
+				},
 			},
-		},
-	})
-	require.NoError(t, err)
+		})
+		require.NoError(t, err)
+		return res.Report.(*pb.QueryResponse_Top).Top
+	}
 
-	testProf := &pprofpb.Profile{}
-	err = testProf.UnmarshalVT(MustDecompressGzip(t, res.Report.(*pb.QueryResponse_Pprof).Pprof))
-	require.NoError(t, err)
+	type value struct{ flat, cum int64 }
+	values := func(top *pb.Top) ([]string, map[string]value) {
+		names := make([]string, 0, len(top.List))
+		values := map[string]value{}
+		for _, n := range top.List {
+			name := n.Meta.Function.Name
+			names = append(names, name)
+			values[name] = value{flat: n.Flat, cum: n.Cumulative}
+		}
+		return names, values
+	}
+
+	names, vals := values(queryTop(0))
+	require.Equal(t, []string{"b", "a", "c", "main"}, names)
+	require.Equal(t, map[string]value{
+		"b":    {flat: 5, cum: 7},
+		"a":    {flat: 4, cum: 9},
+		"c":    {flat: 2, cum: 2},
+		"main": {flat: 0, cum: 11},
+	}, vals)
+
+	top := queryTop(2)
+	require.Equal(t, int32(2), top.Reported)
+	names, _ = values(top)
+	require.Equal(t, []string{"b", "a"}, names)
 }
 
 func TestColumnQueryAPIQueryFgprof(t *testing.T) {
@@ -635,6 +1737,372 @@ func TestColumnQueryAPIQueryDiff(t *testing.T) {
 	require.Equal(t, []int64{-1}, testProf.Sample[1].Value)
 }
 
+func TestColumnQueryAPIQueryDiffMerge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	// The first two profiles are the base, the last two the comparison. The
+	// stack main -> a is in both, main -> b only in the base and main -> c
+	// only in the comparison.
+	for i, samples := range []map[uint64]int64{
+		{2: 1, 3: 2},
+		{2: 3},
+		{2: 5, 4: 1},
+		{4: 3},
+	} {
+		p := newTestStackProfile(samples)
+		p.TimeNanos = int64(i+1) * time.Millisecond.Nanoseconds()
+		err = ingester.Ingest(ctx, labels.Labels{{
+			Name:  "__name__",
+			Value: "memory",
+		}, {
+			Name:  "job",
+			Value: "default",
+		}}, p, false)
+		require.NoError(t, err)
+	}
+
+	api := NewColumnQueryAPI(
+		logger,
+		tracer,
+		getShareServerConn(t),
+		parcacol.NewQuerier(
+			tracer,
+			query.NewEngine(
+				memory.DefaultAllocator,
+				colDB.TableProvider(),
+			),
+			"stacktraces",
+			metastore,
+		),
+	)
+
+	merge := func(start, end int64) *pb.ProfileDiffSelection {
+		return &pb.ProfileDiffSelection{
+			Mode: pb.ProfileDiffSelection_MODE_MERGE,
+			Options: &pb.ProfileDiffSelection_Merge{
+				Merge: &pb.MergeProfile{
+					Query: `memory:alloc_objects:count:space:bytes{job="default"}`,
+					Start: timestamppb.New(timestamp.Time(start)),
+					End:   timestamppb.New(timestamp.Time(end)),
+				},
+			},
+		}
+	}
+
+	p, err := api.selectDiff(ctx, &pb.DiffProfile{
+		A: merge(0, 3),
+		B: merge(2, 5),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "alloc_objects", p.Meta.SampleType.Type)
+
+	type values struct {
+		value, diff int64
+	}
+	stacks := map[string]values{}
+	for _, s := range p.Samples {
+		_, ok := stacks[stackName(s)]
+		require.False(t, ok, "duplicate stack %s", stackName(s))
+		stacks[stackName(s)] = values{s.Value, s.DiffValue}
+	}
+	require.Equal(t, map[string]values{
+		"a;main": {5, 1},
+		"b;main": {0, -2},
+		"c;main": {4, 4},
+	}, stacks)
+}
+
+func TestColumnQueryAPIQueryUnit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	// Two CPU profiles in nanoseconds, adding up to 4s, and a memory profile
+	// in bytes.
+	for i, samples := range []map[uint64]int64{
+		{2: 1_500_000_000},
+		{3: 2_500_000_000},
+	} {
+		p := newTestStackProfile(samples)
+		p.StringTable[1], p.StringTable[2] = "samples", "nanoseconds"
+		p.StringTable[3], p.StringTable[4] = "cpu", "nanoseconds"
+		p.TimeNanos = int64(i+1) * time.Millisecond.Nanoseconds()
+		err = ingester.Ingest(ctx, labels.Labels{{
+			Name:  "__name__",
+			Value: "process_cpu",
+		}, {
+			Name:  "job",
+			Value: "default",
+		}}, p, false)
+		require.NoError(t, err)
+	}
+	p := newTestStackProfile(map[uint64]int64{2: 1024})
+	p.StringTable[2] = "bytes"
+	p.TimeNanos = time.Millisecond.Nanoseconds()
+	err = ingester.Ingest(ctx, labels.Labels{{
+		Name:  "__name__",
+		Value: "memory",
+	}, {
+		Name:  "job",
+		Value: "default",
+	}}, p, false)
+	require.NoError(t, err)
+
+	api := NewColumnQueryAPI(
+		logger,
+		tracer,
+		getShareServerConn(t),
+		parcacol.NewQuerier(
+			tracer,
+			query.NewEngine(
+				memory.DefaultAllocator,
+				colDB.TableProvider(),
+			),
+			"stacktraces",
+			metastore,
+		),
+	)
+
+	const (
+		cpuQuery    = `process_cpu:samples:nanoseconds:cpu:nanoseconds{job="default"}`
+		memoryQuery = `memory:alloc_objects:bytes:space:bytes{job="default"}`
+	)
+	merge := func(query string) *pb.MergeProfile {
+		return &pb.MergeProfile{
+			Query: query,
+			Start: timestamppb.New(timestamp.Time(0)),
+			End:   timestamppb.New(timestamp.Time(3)),
+		}
+	}
+
+	res, err := api.Query(ctx, &pb.QueryRequest{
+		Mode:    pb.QueryRequest_MODE_MERGE,
+		Options: &pb.QueryRequest_Merge{Merge: merge(cpuQuery)},
+		Unit:    "seconds",
+	})
+	require.NoError(t, err)
+	fg := res.GetFlamegraph()
+	require.Equal(t, int64(4), fg.Total)
+	require.Equal(t, int64(4), fg.Root.Cumulative)
+	require.Equal(t, "seconds", fg.Unit)
+
+	res, err = api.Query(ctx, &pb.QueryRequest{
+		Mode:       pb.QueryRequest_MODE_MERGE,
+		Options:    &pb.QueryRequest_Merge{Merge: merge(cpuQuery)},
+		ReportType: pb.QueryRequest_REPORT_TYPE_TOP,
+		Unit:       "ms",
+	})
+	require.NoError(t, err)
+	top := res.GetTop()
+	require.Equal(t, "ms", top.Unit)
+	cumulative := map[string]int64{}
+	for _, n := range top.List {
+		cumulative[n.Meta.Function.Name] = n.Cumulative
+	}
+	require.Equal(t, map[string]int64{"main": 4000, "a": 1500, "b": 2500}, cumulative)
+
+	// Nanoseconds can't be reported in bytes.
+	_, err = api.Query(ctx, &pb.QueryRequest{
+		Mode:    pb.QueryRequest_MODE_MERGE,
+		Options: &pb.QueryRequest_Merge{Merge: merge(cpuQuery)},
+		Unit:    "bytes",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Bytes and nanoseconds can't be diffed.
+	_, err = api.Query(ctx, &pb.QueryRequest{
+		Mode: pb.QueryRequest_MODE_DIFF,
+		Options: &pb.QueryRequest_Diff{Diff: &pb.DiffProfile{
+			A: &pb.ProfileDiffSelection{
+				Mode:    pb.ProfileDiffSelection_MODE_MERGE,
+				Options: &pb.ProfileDiffSelection_Merge{Merge: merge(memoryQuery)},
+			},
+			B: &pb.ProfileDiffSelection{
+				Mode:    pb.ProfileDiffSelection_MODE_MERGE,
+				Options: &pb.ProfileDiffSelection_Merge{Merge: merge(cpuQuery)},
+			},
+		}},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(t, err, profile.ErrIncompatibleUnits.Error())
+}
+
+func TestColumnQueryAPITenantIsolation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	reg := prometheus.NewRegistry()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	col, err := columnstore.New(
+		logger,
+		reg,
+	)
+	require.NoError(t, err)
+	colDB, err := col.DB(context.Background(), "parca")
+	require.NoError(t, err)
+
+	schema, err := parcacol.Schema()
+	require.NoError(t, err)
+
+	table, err := colDB.Table(
+		"stacktraces",
+		columnstore.NewTableConfig(schema),
+	)
+	require.NoError(t, err)
+	m := metastoretest.NewTestMetastore(
+		t,
+		logger,
+		reg,
+		tracer,
+	)
+	metastore := metastore.NewInProcessClient(m)
+	normalizer := parcacol.NewNormalizer(metastore)
+	ingester := parcacol.NewIngester(logger, normalizer, table, schema)
+
+	tenantA := tenant.NewContext(ctx, "a")
+	tenantB := tenant.NewContext(ctx, "b")
+
+	// Both tenants write the same series, tenant b writes another series
+	// with a label tenant a doesn't have.
+	for _, w := range []struct {
+		ctx     context.Context
+		ls      labels.Labels
+		samples map[uint64]int64
+	}{
+		{ctx: tenantA, ls: labels.Labels{{Name: "job", Value: "default"}}, samples: map[uint64]int64{2: 1}},
+		{ctx: tenantB, ls: labels.Labels{{Name: "job", Value: "default"}}, samples: map[uint64]int64{3: 10}},
+		{ctx: tenantB, ls: labels.Labels{{Name: "job", Value: "default"}, {Name: "secret", Value: "b"}}, samples: map[uint64]int64{4: 100}},
+	} {
+		p := newTestStackProfile(w.samples)
+		p.TimeNanos = time.Millisecond.Nanoseconds()
+		err = ingester.Ingest(w.ctx, append(labels.Labels{{Name: "__name__", Value: "memory"}}, w.ls...), p, false)
+		require.NoError(t, err)
+	}
+
+	querier := parcacol.NewQuerier(
+		tracer,
+		query.NewEngine(
+			memory.DefaultAllocator,
+			colDB.TableProvider(),
+		),
+		"stacktraces",
+		metastore,
+	)
+
+	series, err := querier.QueryRange(tenantA, `memory:alloc_objects:count:space:bytes{job="default"}`, timestamp.Time(0), timestamp.Time(10), 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(series))
+	require.Equal(t, 1, len(series[0].Samples))
+	require.Equal(t, int64(1), series[0].Samples[0].Value)
+
+	single, err := querier.QuerySingle(tenantA, `memory:alloc_objects:count:space:bytes{job="default"}`, timestamp.Time(1))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(single.Samples))
+	require.Equal(t, "a;main", stackName(single.Samples[0]))
+	require.Equal(t, int64(1), single.Samples[0].Value)
+
+	merged, err := querier.QueryMerge(tenantA, `memory:alloc_objects:count:space:bytes{}`, timestamp.Time(0), timestamp.Time(10))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(merged.Samples))
+	require.Equal(t, "a;main", stackName(merged.Samples[0]))
+	require.Equal(t, int64(1), merged.Samples[0].Value)
+
+	names, err := querier.Labels(tenantA, nil, timestamp.Time(0), timestamp.Time(10))
+	require.NoError(t, err)
+	require.Equal(t, []string{"job"}, names)
+
+	values, err := querier.Values(tenantA, "secret", nil, timestamp.Time(0), timestamp.Time(10))
+	require.NoError(t, err)
+	require.NotContains(t, values, "b")
+
+	types, err := querier.ProfileTypes(tenantA)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(types))
+
+	merged, err = querier.QueryMerge(tenantB, `memory:alloc_objects:count:space:bytes{}`, timestamp.Time(0), timestamp.Time(10))
+	require.NoError(t, err)
+	stacks := map[string]int64{}
+	for _, s := range merged.Samples {
+		stacks[stackName(s)] += s.Value
+	}
+	require.Equal(t, map[string]int64{"b;main": 10, "c;main": 100}, stacks)
+
+	names, err = querier.Labels(tenantB, nil, timestamp.Time(0), timestamp.Time(10))
+	require.NoError(t, err)
+	require.Equal(t, []string{"job", "secret"}, names)
+
+	values, err = querier.Values(tenantB, "secret", nil, timestamp.Time(0), timestamp.Time(10))
+	require.NoError(t, err)
+	require.Contains(t, values, "b")
+
+	// Requests without a tenant only see the data of the default tenant.
+	_, err = querier.QueryRange(ctx, `memory:alloc_objects:count:space:bytes{job="default"}`, timestamp.Time(0), timestamp.Time(10), 0, 0)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	types, err = querier.ProfileTypes(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(types))
+}
+
 func TestColumnQueryAPITypes(t *testing.T) {
 	t.Parallel()
 
@@ -91,7 +91,7 @@ func GenerateFlatPprof(ctx context.Context, ip *parcaprofile.Profile) (*profile.
 			if l.Mapping != nil {
 				if pm, ok = mappingByID[string(l.Mapping.Id)]; !ok {
 					lm := l.Mapping
-					pm := &profile.Mapping{
+					pm = &profile.Mapping{
 						ID:              0, // set later
 						Start:           lm.Start,
 						Limit:           lm.Limit,
@@ -152,10 +152,23 @@ func GenerateFlatPprof(ctx context.Context, ip *parcaprofile.Profile) (*profile.
 			s.Value = s.DiffValue
 		}
 
-		p.Sample = append(p.Sample, &profile.Sample{
+		sample := &profile.Sample{
 			Value:    []int64{s.Value},
 			Location: locations,
-		})
+		}
+		for name, value := range s.Label {
+			if sample.Label == nil {
+				sample.Label = map[string][]string{}
+			}
+			sample.Label[name] = []string{value}
+		}
+		for name, value := range s.NumLabel {
+			if sample.NumLabel == nil {
+				sample.NumLabel = map[string][]int64{}
+			}
+			sample.NumLabel[name] = []int64{value}
+		}
+		p.Sample = append(p.Sample, sample)
 	}
 
 	mappings := make([]*profile.Mapping, 0, len(mappingByID))
@@ -188,3 +188,78 @@ func TestGeneratePprofNilMapping(t *testing.T) {
 	require.NoError(t, f.Close())
 	require.NoError(t, resProf.CheckValid())
 }
+
+func TestGeneratePprofMapping(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	l := metastoretest.NewTestMetastore(
+		t,
+		log.NewNopLogger(),
+		prometheus.NewRegistry(),
+		trace.NewNoopTracerProvider().Tracer(""),
+	)
+	metastore := metastore.NewInProcessClient(l)
+
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:           0x400000,
+			Limit:           0x800000,
+			Offset:          0x1000,
+			File:            "/usr/bin/app",
+			BuildId:         "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085",
+			HasFunctions:    true,
+			HasFilenames:    true,
+			HasLineNumbers:  true,
+			HasInlineFrames: true,
+		}},
+	})
+	require.NoError(t, err)
+	m := mres.Mappings[0]
+
+	lres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{MappingId: m.Id, Address: 0x10},
+			{MappingId: m.Id, Address: 0x20},
+		},
+	})
+	require.NoError(t, err)
+
+	sres, err := metastore.GetOrCreateStacktraces(ctx, &pb.GetOrCreateStacktracesRequest{
+		Stacktraces: []*pb.Stacktrace{{
+			LocationIds: []string{lres.Locations[0].Id, lres.Locations[1].Id},
+		}},
+	})
+	require.NoError(t, err)
+
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	symbolizedProfile, err := parcacol.NewArrowToProfileConverter(tracer, metastore).SymbolizeNormalizedProfile(ctx, &parcaprofile.NormalizedProfile{
+		Samples: []*parcaprofile.NormalizedSample{{
+			StacktraceID: sres.Stacktraces[0].Id,
+			Value:        1,
+		}},
+	})
+	require.NoError(t, err)
+
+	res, err := GenerateFlatPprof(ctx, symbolizedProfile)
+	require.NoError(t, err)
+	require.NoError(t, res.CheckValid())
+
+	// Every location refers to the mapping, with all of its metadata.
+	require.Len(t, res.Mapping, 1)
+	require.Equal(t, &profile.Mapping{
+		ID:              1,
+		Start:           0x400000,
+		Limit:           0x800000,
+		Offset:          0x1000,
+		File:            "/usr/bin/app",
+		BuildID:         "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085",
+		HasFunctions:    true,
+		HasFilenames:    true,
+		HasLineNumbers:  true,
+		HasInlineFrames: true,
+	}, res.Mapping[0])
+	for _, loc := range res.Location {
+		require.Same(t, res.Mapping[0], loc.Mapping)
+	}
+}
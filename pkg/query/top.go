@@ -15,6 +15,7 @@ package query
 
 import (
 	"context"
+	"fmt"
 	"sort"
 
 	metastorev1alpha1 "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
@@ -22,24 +23,31 @@ import (
 	parcaprofile "github.com/parca-dev/parca/pkg/profile"
 )
 
-func GenerateTopTable(ctx context.Context, p *parcaprofile.Profile) (*pb.Top, error) {
+// GenerateTopTable returns the functions of the profile ordered by their flat
+// value. Locations without a function are listed by their address. Functions
+// that appear multiple times in the same stack, like recursive ones, count
+// towards the cumulative value only once per sample. If limit is greater than
+// 0, only the top limit entries are reported.
+func GenerateTopTable(ctx context.Context, p *parcaprofile.Profile, limit int) (*pb.Top, error) {
 	// Iterate over all samples and their locations.
 	// Calculate the cumulative value of all locations of all samples.
 	// In the end return a *pb.TopNode for each location including all the metadata we have.
 	locationsTopNodes := map[string]*pb.TopNode{}
+	seen := map[string]struct{}{}
 	for _, sample := range p.Samples {
+		for k := range seen {
+			delete(seen, k)
+		}
 		for i, location := range sample.Locations {
-			if node, found := locationsTopNodes[location.ID]; found {
-				node.Cumulative += sample.Value
-				node.Diff += sample.DiffValue
-
-				if i == 0 {
-					node.Flat += sample.Value
-				}
-			} else {
-				node := &pb.TopNode{
-					Cumulative: sample.Value,
-					Diff:       sample.DiffValue,
+			// The cumulative value is only counted for the first location of
+			// what the locations are aggregated by later on.
+			key := topAggregationKey(location)
+			_, counted := seen[key]
+			seen[key] = struct{}{}
+
+			node, found := locationsTopNodes[location.ID]
+			if !found {
+				node = &pb.TopNode{
 					Meta: &pb.TopNodeMeta{
 						Mapping: location.Mapping,
 						Location: &metastorev1alpha1.Location{
@@ -58,12 +66,16 @@ func GenerateTopTable(ctx context.Context, p *parcaprofile.Profile) (*pb.Top, er
 						Line:       location.Lines[0].Line,
 					}
 				}
-				if i == 0 {
-					node.Flat = sample.Value
-					node.Diff = sample.DiffValue
-				}
 				locationsTopNodes[location.ID] = node
 			}
+
+			if !counted {
+				node.Cumulative += sample.Value
+				node.Diff += sample.DiffValue
+			}
+			if i == 0 {
+				node.Flat += sample.Value
+			}
 		}
 	}
 
@@ -79,7 +91,21 @@ func GenerateTopTable(ctx context.Context, p *parcaprofile.Profile) (*pb.Top, er
 		Unit:     p.Meta.SampleType.Unit,
 	}
 
-	return aggregateTopByFunction(top), nil
+	top = aggregateTopByFunction(top)
+	if limit > 0 && len(top.List) > limit {
+		top.List = top.List[:limit]
+		top.Reported = int32(limit)
+	}
+	return top, nil
+}
+
+// topAggregationKey returns the key that aggregateTopByFunction aggregates
+// the location by.
+func topAggregationKey(location *parcaprofile.Location) string {
+	if len(location.Lines) > 0 && location.Lines[0].Function != nil {
+		return "function:" + location.Lines[0].Function.Name
+	}
+	return fmt.Sprintf("address:%s:%d", location.Mapping.GetId(), location.Address)
 }
 
 func aggregateTopByFunction(top *pb.Top) *pb.Top {
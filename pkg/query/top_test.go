@@ -54,7 +54,7 @@ func TestGenerateTopTable(t *testing.T) {
 	symbolizedProfile, err := parcacol.NewArrowToProfileConverter(tracer, metastore).SymbolizeNormalizedProfile(ctx, profiles[0])
 	require.NoError(t, err)
 
-	res, err := GenerateTopTable(ctx, symbolizedProfile)
+	res, err := GenerateTopTable(ctx, symbolizedProfile, 0)
 	require.NoError(t, err)
 
 	require.Equal(t, int32(1886), res.Total)
@@ -152,7 +152,7 @@ func TestGenerateTopTableAggregateFlat(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	top, err := GenerateTopTable(ctx, p)
+	top, err := GenerateTopTable(ctx, p, 0)
 	require.NoError(t, err)
 
 	require.Equal(t, 4, len(top.List))
@@ -209,7 +209,7 @@ func TestGenerateDiffTopTable(t *testing.T) {
 	p, err := parcacol.NewArrowToProfileConverter(tracer, metastore).SymbolizeNormalizedProfile(ctx, profiles[0])
 	require.NoError(t, err)
 
-	res, err := GenerateTopTable(ctx, p)
+	res, err := GenerateTopTable(ctx, p, 0)
 	require.NoError(t, err)
 
 	found = false
@@ -0,0 +1,52 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/query/v1alpha1"
+	"github.com/parca-dev/parca/pkg/profile"
+)
+
+// scaleFlamegraph scales the values of every node of the flame graph, and its
+// total, to the unit.
+func scaleFlamegraph(fg *pb.Flamegraph, scaler profile.Scaler, unit string) {
+	fg.Total = scaler.Scale(fg.Total)
+	fg.Unit = unit
+	if fg.Root == nil {
+		return
+	}
+
+	fg.Root.Cumulative = scaler.Scale(fg.Root.Cumulative)
+	fg.Root.Diff = scaler.Scale(fg.Root.Diff)
+
+	nodes := fg.Root.Children
+	for len(nodes) > 0 {
+		n := nodes[len(nodes)-1]
+		nodes = nodes[:len(nodes)-1]
+
+		n.Cumulative = scaler.Scale(n.Cumulative)
+		n.Diff = scaler.Scale(n.Diff)
+		nodes = append(nodes, n.Children...)
+	}
+}
+
+// scaleTop scales the values of every node of the top table to the unit.
+func scaleTop(top *pb.Top, scaler profile.Scaler, unit string) {
+	top.Unit = unit
+	for _, n := range top.List {
+		n.Cumulative = scaler.Scale(n.Cumulative)
+		n.Flat = scaler.Scale(n.Flat)
+		n.Diff = scaler.Scale(n.Diff)
+	}
+}
@@ -45,11 +45,11 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	grpc_health "google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 
 	"github.com/parca-dev/parca/pkg/debuginfo"
 	"github.com/parca-dev/parca/pkg/prober"
+	"github.com/parca-dev/parca/pkg/tenant"
 	"github.com/parca-dev/parca/ui"
 )
 
@@ -76,18 +76,29 @@ var MapAllowedLevels = map[string][]string{
 type Server struct {
 	http.Server
 	grpcProbe *prober.GRPCProbe
+	health    *prober.Health
 	reg       *prometheus.Registry
 	version   string
 }
 
-func NewServer(reg *prometheus.Registry, version string) *Server {
+// NewServer returns a server that is ready once the dependencies checked by
+// the health are reachable. If health is nil, the server is ready as soon as
+// it listens.
+func NewServer(reg *prometheus.Registry, version string, health *prober.Health) *Server {
 	return &Server{
 		grpcProbe: prober.NewGRPC(),
+		health:    health,
 		reg:       reg,
 		version:   version,
 	}
 }
 
+// HealthServer returns the gRPC health service reporting the serving status
+// of the server, to be registered with RegisterServices.
+func (s *Server) HealthServer() grpc_health.HealthServer {
+	return s.grpcProbe.HealthServer()
+}
+
 // ListenAndServe starts the http grpc gateway server.
 func (s *Server) ListenAndServe(ctx context.Context, logger log.Logger, port string, allowedCORSOrigins []string, pathPrefix string, registerables ...Registerable) error {
 	level.Info(logger).Log("msg", "starting server", "addr", port)
@@ -121,30 +132,39 @@ func (s *Server) ListenAndServe(ctx context.Context, logger log.Logger, port str
 				otelgrpc.StreamServerInterceptor(),
 				met.StreamServerInterceptor(),
 				grpc_logging.StreamServerInterceptor(kit.InterceptorLogger(logger), logOpts...),
+				tenant.StreamServerInterceptor(),
 			)),
 		grpc.UnaryInterceptor(
 			grpc_middleware.ChainUnaryServer(
 				otelgrpc.UnaryServerInterceptor(),
 				met.UnaryServerInterceptor(),
 				grpc_logging.UnaryServerInterceptor(kit.InterceptorLogger(logger), logOpts...),
+				tenant.UnaryServerInterceptor(),
 			),
 		),
 	)
 
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
 
-	grpcWebMux := runtime.NewServeMux()
+	grpcWebMux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(tenant.HeaderMatcher),
+	)
 	for _, r := range registerables {
 		if err := r.Register(ctx, srv, grpcWebMux, port, opts); err != nil {
 			return err
 		}
 	}
-	reflection.Register(srv)
-	grpc_health.RegisterHealthServer(srv, s.grpcProbe.HealthServer())
 
 	internalMux := chi.NewRouter()
 	internalMux.Mount("/api", grpcWebMux)
 
+	internalMux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		if s.health == nil {
+			fmt.Fprintln(w, "ready")
+			return
+		}
+		s.health.ServeHTTP(w, r)
+	})
 	internalMux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
 		promhttp.HandlerFor(s.reg, promhttp.HandlerOpts{}).ServeHTTP(w, r)
 	})
@@ -192,8 +212,14 @@ func (s *Server) ListenAndServe(ctx context.Context, logger log.Logger, port str
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 	)
 
-	s.grpcProbe.Ready()
+	// Healthy resumes serving of all services, so it has to be set before
+	// the readiness.
 	s.grpcProbe.Healthy()
+	if s.health != nil {
+		s.health.AddProbe(s.grpcProbe)
+	} else {
+		s.grpcProbe.Ready()
+	}
 	return s.Server.ListenAndServe()
 }
 
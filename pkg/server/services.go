@@ -0,0 +1,103 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	grpc_health "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	otlpcollectorpb "github.com/parca-dev/parca/gen/proto/go/opentelemetry/proto/collector/profiles/v1development"
+	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+	profilestorepb "github.com/parca-dev/parca/gen/proto/go/parca/profilestore/v1alpha1"
+	querypb "github.com/parca-dev/parca/gen/proto/go/parca/query/v1alpha1"
+	scrapepb "github.com/parca-dev/parca/gen/proto/go/parca/scrape/v1alpha1"
+)
+
+// Services are the gRPC services served by Parca. Services that are nil are
+// not served.
+type Services struct {
+	DebugInfo    debuginfopb.DebugInfoServiceServer
+	ProfileStore profilestorepb.ProfileStoreServiceServer
+	Query        querypb.QueryServiceServer
+	Scrape       scrapepb.ScrapeServiceServer
+	OTLPProfiles otlpcollectorpb.ProfilesServiceServer
+
+	// Health reports the serving status of the server. If nil, the server is
+	// always reported to be serving.
+	Health grpc_health.HealthServer
+}
+
+// RegisterServices registers the services with the gRPC server, along with
+// the gRPC server reflection and health services.
+func RegisterServices(srv *grpc.Server, services Services) {
+	if services.DebugInfo != nil {
+		debuginfopb.RegisterDebugInfoServiceServer(srv, services.DebugInfo)
+	}
+	if services.ProfileStore != nil {
+		profilestorepb.RegisterProfileStoreServiceServer(srv, services.ProfileStore)
+	}
+	if services.Query != nil {
+		querypb.RegisterQueryServiceServer(srv, services.Query)
+	}
+	if services.Scrape != nil {
+		scrapepb.RegisterScrapeServiceServer(srv, services.Scrape)
+	}
+	if services.OTLPProfiles != nil {
+		otlpcollectorpb.RegisterProfilesServiceServer(srv, services.OTLPProfiles)
+	}
+
+	healthServer := services.Health
+	if healthServer == nil {
+		healthServer = health.NewServer()
+	}
+	grpc_health.RegisterHealthServer(srv, healthServer)
+	reflection.Register(srv)
+}
+
+// RegisterServiceHandlers registers the HTTP handlers of the services with
+// the gateway mux, that forwards the requests to the gRPC server at the
+// endpoint.
+func RegisterServiceHandlers(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption, services Services) error {
+	if services.DebugInfo != nil {
+		if err := debuginfopb.RegisterDebugInfoServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
+			return err
+		}
+	}
+	if services.ProfileStore != nil {
+		if err := profilestorepb.RegisterProfileStoreServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
+			return err
+		}
+	}
+	if services.Query != nil {
+		if err := querypb.RegisterQueryServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
+			return err
+		}
+	}
+	if services.Scrape != nil {
+		if err := scrapepb.RegisterScrapeServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
+			return err
+		}
+	}
+	if services.OTLPProfiles != nil {
+		if err := otlpcollectorpb.RegisterProfilesServiceHandlerFromEndpoint(ctx, mux, endpoint, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpc_health "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+
+	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
+	profilestorepb "github.com/parca-dev/parca/gen/proto/go/parca/profilestore/v1alpha1"
+	querypb "github.com/parca-dev/parca/gen/proto/go/parca/query/v1alpha1"
+	scrapepb "github.com/parca-dev/parca/gen/proto/go/parca/scrape/v1alpha1"
+)
+
+func TestRegisterServices(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	t.Cleanup(srv.Stop)
+
+	RegisterServices(srv, Services{
+		DebugInfo:    debuginfopb.UnimplementedDebugInfoServiceServer{},
+		ProfileStore: profilestorepb.UnimplementedProfileStoreServiceServer{},
+		Query:        querypb.UnimplementedQueryServiceServer{},
+		Scrape:       scrapepb.UnimplementedScrapeServiceServer{},
+	})
+	go srv.Serve(lis) //nolint:errcheck
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	stream, err := grpc_reflection_v1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_ListServices{},
+	}))
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseSend())
+
+	services := []string{}
+	for _, s := range res.GetListServicesResponse().GetService() {
+		services = append(services, s.Name)
+	}
+	sort.Strings(services)
+	require.Equal(t, []string{
+		"grpc.health.v1.Health",
+		"grpc.reflection.v1alpha.ServerReflection",
+		"parca.debuginfo.v1alpha1.DebugInfoService",
+		"parca.profilestore.v1alpha1.ProfileStoreService",
+		"parca.query.v1alpha1.QueryService",
+		"parca.scrape.v1alpha1.ScrapeService",
+	}, services)
+
+	hres, err := grpc_health.NewHealthClient(conn).Check(ctx, &grpc_health.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, grpc_health.HealthCheckResponse_SERVING, hres.Status)
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signedupload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/thanos-io/objstore/client"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrUnsupportedProvider is returned when the object storage provider can't
+// issue signed URLs.
+var ErrUnsupportedProvider = errors.New("signed URLs are not supported by the object storage provider")
+
+// Client issues signed URLs to upload objects directly to the object storage.
+type Client interface {
+	// SignedPUT returns a URL that the object with the given key can be
+	// uploaded to with a PUT request until the expiry time.
+	SignedPUT(ctx context.Context, objectKey string, expiry time.Time) (string, error)
+	Close() error
+}
+
+// NewClient returns a client for the object storage of the given bucket
+// configuration, as accepted by client.NewBucket. Object keys are relative to
+// the prefix of the configuration.
+func NewClient(ctx context.Context, bucketConf []byte) (Client, error) {
+	bucketConfig := client.BucketConfig{}
+	if err := yaml.Unmarshal(bucketConf, &bucketConfig); err != nil {
+		return nil, fmt.Errorf("parse object storage config: %w", err)
+	}
+
+	conf, err := yaml.Marshal(bucketConfig.Config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal object storage provider config: %w", err)
+	}
+
+	var c Client
+	switch strings.ToUpper(string(bucketConfig.Type)) {
+	case string(client.GCS):
+		c, err = newGCSClient(ctx, conf)
+	case string(client.S3):
+		c, err = newS3Client(conf)
+	default:
+		return nil, ErrUnsupportedProvider
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrefixedClient(c, bucketConfig.Prefix), nil
+}
+
+// PrefixedClient prepends a prefix to the object keys of its client, like
+// objstore.PrefixedBucket does for buckets.
+type PrefixedClient struct {
+	client Client
+	prefix string
+}
+
+// NewPrefixedClient returns a client prepending the prefix to every object
+// key. An empty prefix returns the client itself.
+func NewPrefixedClient(c Client, prefix string) Client {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return c
+	}
+	return &PrefixedClient{client: c, prefix: prefix}
+}
+
+func (c *PrefixedClient) SignedPUT(ctx context.Context, objectKey string, expiry time.Time) (string, error) {
+	return c.client.SignedPUT(ctx, path.Join(c.prefix, objectKey), expiry)
+}
+
+func (c *PrefixedClient) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signedupload
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientUnsupportedProvider(t *testing.T) {
+	_, err := NewClient(context.Background(), []byte(`
+type: FILESYSTEM
+config:
+  directory: ./data
+`))
+	require.ErrorIs(t, err, ErrUnsupportedProvider)
+}
+
+func TestS3SignedPUT(t *testing.T) {
+	c, err := NewClient(context.Background(), []byte(`
+type: S3
+config:
+  bucket: parca
+  endpoint: s3.example.com
+  region: us-east-1
+  access_key: access
+  secret_key: secret
+prefix: tenant
+`))
+	require.NoError(t, err)
+	defer c.Close()
+
+	c = NewPrefixedClient(c, "debuginfo")
+	signed, err := c.SignedPUT(context.Background(), "uploads/abcd", time.Now().Add(15*time.Minute))
+	require.NoError(t, err)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	require.Equal(t, "https", u.Scheme)
+	require.Equal(t, "/parca/tenant/debuginfo/uploads/abcd", u.Path)
+	require.NotEmpty(t, u.Query().Get("X-Amz-Expires"))
+	require.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signedupload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/thanos-io/objstore/providers/gcs"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+	"gopkg.in/yaml.v2"
+)
+
+type GCSClient struct {
+	client *storage.Client
+	bucket *storage.BucketHandle
+}
+
+func newGCSClient(ctx context.Context, conf []byte) (*GCSClient, error) {
+	var config gcs.Config
+	if err := yaml.Unmarshal(conf, &config); err != nil {
+		return nil, fmt.Errorf("parse GCS config: %w", err)
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("missing Google Cloud Storage bucket name")
+	}
+
+	// The credentials are looked up the same way as for the bucket.
+	var opts []option.ClientOption
+	if config.ServiceAccount != "" {
+		credentials, err := google.CredentialsFromJSON(ctx, []byte(config.ServiceAccount), storage.ScopeFullControl)
+		if err != nil {
+			return nil, fmt.Errorf("create credentials from JSON: %w", err)
+		}
+		opts = append(opts, option.WithCredentials(credentials))
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create GCS client: %w", err)
+	}
+
+	return &GCSClient{
+		client: client,
+		bucket: client.Bucket(config.Bucket),
+	}, nil
+}
+
+func (c *GCSClient) SignedPUT(_ context.Context, objectKey string, expiry time.Time) (string, error) {
+	return c.bucket.SignedURL(objectKey, &storage.SignedURLOptions{
+		Method:  http.MethodPut,
+		Expires: expiry,
+		Scheme:  storage.SigningSchemeV4,
+	})
+}
+
+func (c *GCSClient) Close() error {
+	return c.client.Close()
+}
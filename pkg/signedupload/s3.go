@@ -0,0 +1,95 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signedupload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/thanos-io/objstore/providers/s3"
+	"gopkg.in/yaml.v2"
+)
+
+type S3Client struct {
+	client *minio.Client
+	bucket string
+}
+
+func newS3Client(conf []byte) (*S3Client, error) {
+	var config s3.Config
+	if err := yaml.Unmarshal(conf, &config); err != nil {
+		return nil, fmt.Errorf("parse S3 config: %w", err)
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("missing S3 bucket name")
+	}
+	if config.Endpoint == "" {
+		return nil, errors.New("missing S3 endpoint")
+	}
+
+	// The credentials are looked up the same way as for the bucket, except
+	// for the AWS SDK authentication that isn't supported.
+	var chain []credentials.Provider
+	if config.AccessKey != "" {
+		chain = []credentials.Provider{&credentials.Static{
+			Value: credentials.Value{
+				AccessKeyID:     config.AccessKey,
+				SecretAccessKey: config.SecretKey,
+				SignerType:      credentials.SignatureV4,
+			},
+		}}
+	} else {
+		chain = []credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{
+				Client: &http.Client{
+					Transport: http.DefaultTransport,
+				},
+			},
+		}
+	}
+
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:        credentials.NewChainCredentials(chain),
+		Secure:       !config.Insecure,
+		Region:       config.Region,
+		BucketLookup: config.BucketLookupType.MinioType(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create S3 client: %w", err)
+	}
+
+	return &S3Client{
+		client: client,
+		bucket: config.Bucket,
+	}, nil
+}
+
+func (c *S3Client) SignedPUT(ctx context.Context, objectKey string, expiry time.Time) (string, error) {
+	u, err := c.client.PresignedPutObject(ctx, c.bucket, objectKey, time.Until(expiry))
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (c *S3Client) Close() error {
+	return nil
+}
@@ -14,6 +14,7 @@
 package addr2line
 
 import (
+	"context"
 	"fmt"
 	"runtime/debug"
 
@@ -32,8 +33,10 @@ type DwarfLiner struct {
 }
 
 // DWARF is a symbolizer that uses DWARF debug info to symbolize addresses.
-func DWARF(logger log.Logger, path string, demangler *demangle.Demangler) (*DwarfLiner, error) {
-	dbgFile, err := elfutils.NewDebugInfoFile(path, demangler)
+// The pool, which may be nil, provides the buffers to read the DWARF data
+// with.
+func DWARF(logger log.Logger, path string, demangler *demangle.Demangler, pool *elfutils.BufferPool) (*DwarfLiner, error) {
+	dbgFile, err := elfutils.NewDebugInfoFile(path, demangler, pool)
 	if err != nil {
 		return nil, err
 	}
@@ -44,15 +47,15 @@ func DWARF(logger log.Logger, path string, demangler *demangle.Demangler) (*Dwar
 	}, nil
 }
 
-func (dl *DwarfLiner) PCToLines(addr uint64) (lines []profile.LocationLine, err error) {
+func (dl *DwarfLiner) PCToLines(ctx context.Context, addr uint64) (lines []profile.LocationLine, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			fmt.Println("recovered stack stares:\n", string(debug.Stack()))
-			err = fmt.Errorf("recovering from panic in DWARF add2line: %v", r)
+			err = fmt.Errorf("%w: recovering from panic in DWARF add2line: %v", elfutils.ErrCorruptDWARF, r)
 		}
 	}()
 
-	lines, err = dl.dbgFile.SourceLines(addr)
+	lines, err = dl.dbgFile.SourceLines(ctx, addr)
 	if err != nil {
 		level.Debug(dl.logger).Log("msg", "failed to symbolize location", "addr", addr, "err", err)
 		return nil, err
@@ -14,6 +14,7 @@
 package addr2line
 
 import (
+	"context"
 	"debug/elf"
 	"debug/gosym"
 	"errors"
@@ -21,6 +22,7 @@ import (
 	"runtime/debug"
 
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
 	"github.com/parca-dev/parca/pkg/profile"
@@ -29,22 +31,42 @@ import (
 type GoLiner struct {
 	logger log.Logger
 
-	symtab *gosym.Table
+	symtab  *gosym.Table
+	inlines *inlineTable
 }
 
 func Go(logger log.Logger, path string) (*GoLiner, error) {
-	tab, err := gosymtab(path)
+	logger = log.With(logger, "liner", "go")
+
+	objFile, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open elf: %w", err)
+	}
+	defer objFile.Close()
+
+	pclntab, symtab, text, err := readGoSymbolSections(objFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create go symbtab: %w", err)
 	}
 
+	tab, err := gosym.NewTable(symtab, gosym.NewLineTable(pclntab, text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build symtab or pclinetab: %w", err)
+	}
+
+	inlines, err := newInlineTable(objFile, pclntab, text)
+	if err != nil {
+		level.Debug(logger).Log("msg", "failed to read inline trees, inlined functions are not symbolized", "err", err)
+	}
+
 	return &GoLiner{
-		logger: log.With(logger, "liner", "go"),
-		symtab: tab,
+		logger:  logger,
+		symtab:  tab,
+		inlines: inlines,
 	}, nil
 }
 
-func (gl *GoLiner) PCToLines(addr uint64) (lines []profile.LocationLine, err error) {
+func (gl *GoLiner) PCToLines(_ context.Context, addr uint64) (lines []profile.LocationLine, err error) {
 	defer func() {
 		// PCToLine panics with "invalid memory address or nil pointer dereference",
 		//	- when it refers to an address that doesn't actually exist.
@@ -61,8 +83,25 @@ func (gl *GoLiner) PCToLines(addr uint64) (lines []profile.LocationLine, err err
 		name = fn.Name
 	}
 
-	// TODO(kakkoyun): These lines miss the inline functions.
-	// - Find a way to symbolize inline functions.
+	if fn != nil && gl.inlines != nil {
+		calls, err := gl.inlines.inlinedCalls(fn.Entry, addr)
+		if err != nil {
+			level.Debug(gl.logger).Log("msg", "failed to resolve inlined functions", "addr", addr, "err", err)
+		}
+		// The file and line of each inlined function are the ones of its
+		// innermost instruction, which for the callers is the call site.
+		for _, call := range calls {
+			lines = append(lines, profile.LocationLine{
+				Line: int64(line),
+				Function: &pb.Function{
+					Name:     call.name,
+					Filename: file,
+				},
+			})
+			file, line, _ = gl.symtab.PCToLine(call.parentPC)
+		}
+	}
+
 	lines = append(lines, profile.LocationLine{
 		Line: int64(line),
 		Function: &pb.Function{
@@ -73,42 +112,29 @@ func (gl *GoLiner) PCToLines(addr uint64) (lines []profile.LocationLine, err err
 	return lines, nil
 }
 
-func gosymtab(path string) (*gosym.Table, error) {
-	objFile, err := elf.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open elf: %w", err)
-	}
-	defer objFile.Close()
-
-	var pclntab []byte
+func readGoSymbolSections(objFile *elf.File) (pclntab, symtab []byte, text uint64, err error) {
 	if sec := objFile.Section(".gopclntab"); sec != nil {
 		if sec.Type == elf.SHT_NOBITS {
-			return nil, errors.New(".gopclntab section has no bits")
+			return nil, nil, 0, errors.New(".gopclntab section has no bits")
 		}
 
 		pclntab, err = sec.Data()
 		if err != nil {
-			return nil, fmt.Errorf("could not find .gopclntab section: %w", err)
+			return nil, nil, 0, fmt.Errorf("could not find .gopclntab section: %w", err)
 		}
 	}
 
 	if len(pclntab) <= 0 {
-		return nil, errors.New(".gopclntab section has no bits")
+		return nil, nil, 0, errors.New(".gopclntab section has no bits")
 	}
 
-	var symtab []byte
 	if sec := objFile.Section(".gosymtab"); sec != nil {
 		symtab, _ = sec.Data()
 	}
 
-	var text uint64
 	if sec := objFile.Section(".text"); sec != nil {
 		text = sec.Addr
 	}
 
-	table, err := gosym.NewTable(symtab, gosym.NewLineTable(pclntab, text))
-	if err != nil {
-		return nil, fmt.Errorf("failed to build symtab or pclinetab: %w", err)
-	}
-	return table, nil
+	return pclntab, symtab, text, nil
 }
@@ -0,0 +1,379 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package addr2line
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// The pclntab layouts the inline tree can be read from. Older layouts are
+// not supported, inlined frames are then omitted.
+type pclntabVersion int
+
+const (
+	ver116 pclntabVersion = iota
+	ver118
+	ver120
+)
+
+const (
+	go116Magic = 0xfffffffa
+	go118Magic = 0xfffffff0
+	go120Magic = 0xfffffff1
+
+	// See internal/abi/symtab.go of the Go runtime.
+	pcdataInlTreeIndex = 2
+	funcdataInlTree    = 3
+
+	// Guards against cycles in malformed inline trees.
+	maxInlineDepth = 1000
+)
+
+// inlineTable reads the inline tree the Go linker writes for every function,
+// which debug/gosym doesn't expose. The inline tree records the functions
+// that were inlined at each program counter, which is what allows resolving
+// inlined frames without DWARF.
+type inlineTable struct {
+	version   pclntabVersion
+	order     binary.ByteOrder
+	quantum   uint32
+	ptrsize   uint32
+	textStart uint64
+
+	// Address of the pclntab, needed to compute the alignment of the
+	// funcdata of Go 1.16 and 1.17 binaries.
+	addr        uint64
+	pclnOffset  uint64
+	funcnametab []byte
+	pctab       []byte
+	functab     []byte
+	funcdata    []byte
+	nfunctab    int
+
+	// Base address of the funcdata of Go 1.18+ binaries (go:func.*).
+	gofunc uint64
+	// Contents of the section holding the inline trees.
+	rodataAddr uint64
+	rodata     []byte
+}
+
+// inlinedCall is a function inlined at a program counter.
+type inlinedCall struct {
+	name string
+	// Program counter of an instruction of the caller, whose position is the
+	// call site of the inlined function.
+	parentPC uint64
+}
+
+func newInlineTable(objFile *elf.File, pclntab []byte, text uint64) (*inlineTable, error) {
+	if len(pclntab) < 16 || pclntab[4] != 0 || pclntab[5] != 0 {
+		return nil, errors.New("invalid pclntab header")
+	}
+
+	t := &inlineTable{
+		quantum:   uint32(pclntab[6]),
+		ptrsize:   uint32(pclntab[7]),
+		textStart: text,
+	}
+	if t.ptrsize != 4 && t.ptrsize != 8 {
+		return nil, errors.New("invalid pclntab pointer size")
+	}
+
+	le, be := binary.LittleEndian.Uint32(pclntab), binary.BigEndian.Uint32(pclntab)
+	for _, order := range []struct {
+		order binary.ByteOrder
+		magic uint32
+	}{{binary.LittleEndian, le}, {binary.BigEndian, be}} {
+		switch order.magic {
+		case go116Magic:
+			t.order, t.version = order.order, ver116
+		case go118Magic:
+			t.order, t.version = order.order, ver118
+		case go120Magic:
+			t.order, t.version = order.order, ver120
+		}
+	}
+	if t.order == nil {
+		return nil, errors.New("unsupported pclntab version")
+	}
+
+	offset := func(word uint32) uint64 {
+		return t.uintptr(pclntab[8+word*t.ptrsize:])
+	}
+	data := func(word uint32) []byte {
+		return pclntab[offset(word):]
+	}
+
+	t.nfunctab = int(offset(0))
+	switch t.version {
+	case ver116:
+		t.funcnametab = data(2)
+		t.pctab = data(5)
+		t.pclnOffset = offset(6)
+	default:
+		t.funcnametab = data(3)
+		t.pctab = data(6)
+		t.pclnOffset = offset(7)
+	}
+	t.funcdata = pclntab[t.pclnOffset:]
+	t.functab = t.funcdata[:(t.nfunctab*2+1)*t.functabFieldSize()]
+
+	if sec := objFile.Section(".gopclntab"); sec != nil {
+		t.addr = sec.Addr
+	}
+
+	// The inline trees are part of the go:func.* symbol, which is located
+	// in the read-only data.
+	var sec *elf.Section
+	if syms, err := objFile.Symbols(); err == nil {
+		for _, sym := range syms {
+			if sym.Name == "go:func.*" || sym.Name == "go.func.*" {
+				t.gofunc = sym.Value
+				sec = sectionContaining(objFile, sym.Value)
+				break
+			}
+		}
+	}
+	if t.version >= ver118 && t.gofunc == 0 {
+		return nil, errors.New("failed to find go:func.* symbol")
+	}
+	if sec == nil {
+		sec = objFile.Section(".rodata")
+	}
+	if sec == nil || sec.Type == elf.SHT_NOBITS {
+		return nil, errors.New("failed to find section of the inline trees")
+	}
+
+	var err error
+	t.rodataAddr = sec.Addr
+	t.rodata, err = sec.Data()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read section of the inline trees: %w", err)
+	}
+
+	return t, nil
+}
+
+func sectionContaining(objFile *elf.File, addr uint64) *elf.Section {
+	for _, sec := range objFile.Sections {
+		if sec.Flags&elf.SHF_ALLOC != 0 && sec.Addr <= addr && addr < sec.Addr+sec.Size {
+			return sec
+		}
+	}
+	return nil
+}
+
+// inlinedCalls returns the functions inlined at the given program counter
+// of the function with the given entry, innermost first.
+func (t *inlineTable) inlinedCalls(entry, pc uint64) (calls []inlinedCall, err error) {
+	defer func() {
+		// Malformed tables cause out of range reads.
+		if r := recover(); r != nil {
+			calls, err = nil, fmt.Errorf("recovering from panic reading inline tree: %v", r)
+		}
+	}()
+
+	f, ok := t.findFunc(entry)
+	if !ok {
+		return nil, fmt.Errorf("no function with entry %#x", entry)
+	}
+
+	indexTab, tree := t.inlineTree(f)
+	if indexTab == 0 || tree == 0 {
+		// Nothing was inlined into this function.
+		return nil, nil
+	}
+
+	for ix := t.pcvalue(indexTab, entry, pc); ix >= 0; ix = t.pcvalue(indexTab, entry, pc) {
+		if len(calls) == maxInlineDepth {
+			return nil, errors.New("inline tree too deep")
+		}
+
+		nameOff, parentPC, err := t.inlinedCallAt(tree, ix)
+		if err != nil {
+			return nil, err
+		}
+
+		calls = append(calls, inlinedCall{name: t.funcName(nameOff), parentPC: entry + uint64(parentPC)})
+		pc = entry + uint64(parentPC)
+	}
+
+	return calls, nil
+}
+
+// findFunc returns the _func of the function with the given entry.
+func (t *inlineTable) findFunc(entry uint64) ([]byte, bool) {
+	i := sort.Search(t.nfunctab, func(i int) bool {
+		return t.functabPC(i) >= entry
+	})
+	if i == t.nfunctab || t.functabPC(i) != entry {
+		return nil, false
+	}
+
+	off := t.functabField(2*i + 1)
+	if off >= uint64(len(t.funcdata)) {
+		return nil, false
+	}
+	return t.funcdata[off:], true
+}
+
+// inlineTree returns the offset of the inline tree index table in the pctab
+// and the address of the inline tree of the function. Either is zero if the
+// function has none.
+func (t *inlineTable) inlineTree(f []byte) (uint32, uint64) {
+	// The size of the fixed fields of _func, which are followed by the
+	// pcdata and funcdata offsets.
+	var (
+		size      uint32
+		npcdata   uint32
+		nfuncdata uint32
+	)
+	switch t.version {
+	case ver116:
+		size = t.ptrsize + 9*4
+		npcdata = t.order.Uint32(f[t.ptrsize+6*4:])
+	case ver118:
+		size = 10 * 4
+		npcdata = t.order.Uint32(f[7*4:])
+	default:
+		size = 11 * 4
+		npcdata = t.order.Uint32(f[7*4:])
+	}
+	// nfuncdata is the last byte of the fixed fields.
+	nfuncdata = uint32(f[size-1])
+
+	if npcdata <= pcdataInlTreeIndex || nfuncdata <= funcdataInlTree {
+		return 0, 0
+	}
+	indexTab := t.order.Uint32(f[size+pcdataInlTreeIndex*4:])
+
+	funcdata := size + npcdata*4
+	if t.version == ver116 {
+		// Funcdata are pointers aligned to the pointer size.
+		if t.ptrsize == 8 && (t.addr+t.pclnOffset+uint64(len(t.funcdata)-len(f))+uint64(funcdata))&4 != 0 {
+			funcdata += 4
+		}
+		return indexTab, t.uintptr(f[funcdata+funcdataInlTree*t.ptrsize:])
+	}
+
+	off := t.order.Uint32(f[funcdata+funcdataInlTree*4:])
+	if off == ^uint32(0) {
+		return indexTab, 0
+	}
+	return indexTab, t.gofunc + uint64(off)
+}
+
+// inlinedCallAt returns the name offset and the parent program counter offset
+// of the ix-th entry of the inline tree at the given address.
+func (t *inlineTable) inlinedCallAt(tree uint64, ix int32) (uint32, uint32, error) {
+	size := uint64(20)
+	if t.version >= ver120 {
+		size = 16
+	}
+
+	addr := tree + uint64(ix)*size
+	if addr < t.rodataAddr || addr+size > t.rodataAddr+uint64(len(t.rodata)) {
+		return 0, 0, fmt.Errorf("inline tree entry at %#x out of bounds", addr)
+	}
+	b := t.rodata[addr-t.rodataAddr:]
+
+	if t.version >= ver120 {
+		return t.order.Uint32(b[4:]), t.order.Uint32(b[8:]), nil
+	}
+	return t.order.Uint32(b[12:]), t.order.Uint32(b[16:]), nil
+}
+
+func (t *inlineTable) funcName(off uint32) string {
+	if uint64(off) >= uint64(len(t.funcnametab)) {
+		return "?"
+	}
+	name := t.funcnametab[off:]
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+	return string(name)
+}
+
+// pcvalue returns the value the pc-value table at the given offset of the
+// pctab associates with the target program counter, -1 if there is none.
+func (t *inlineTable) pcvalue(off uint32, entry, target uint64) int32 {
+	p := t.pctab[off:]
+	val := int32(-1)
+	pc := entry
+	for first := true; ; first = false {
+		uvdelta := t.readvarint(&p)
+		if uvdelta == 0 && !first {
+			return -1
+		}
+		if uvdelta&1 != 0 {
+			uvdelta = ^(uvdelta >> 1)
+		} else {
+			uvdelta >>= 1
+		}
+		val += int32(uvdelta)
+		pc += uint64(t.readvarint(&p) * t.quantum)
+		if target < pc {
+			return val
+		}
+	}
+}
+
+func (t *inlineTable) readvarint(p *[]byte) uint32 {
+	var v, shift uint32
+	b := *p
+	for shift = 0; ; shift += 7 {
+		v |= uint32(b[0]&0x7f) << shift
+		if b[0]&0x80 == 0 {
+			break
+		}
+		b = b[1:]
+	}
+	*p = b[1:]
+	return v
+}
+
+func (t *inlineTable) functabFieldSize() int {
+	if t.version >= ver118 {
+		return 4
+	}
+	return int(t.ptrsize)
+}
+
+func (t *inlineTable) functabField(i int) uint64 {
+	sz := t.functabFieldSize()
+	if sz == 4 {
+		return uint64(t.order.Uint32(t.functab[i*sz:]))
+	}
+	return t.order.Uint64(t.functab[i*sz:])
+}
+
+func (t *inlineTable) functabPC(i int) uint64 {
+	pc := t.functabField(2 * i)
+	if t.version >= ver118 {
+		pc += t.textStart
+	}
+	return pc
+}
+
+func (t *inlineTable) uintptr(b []byte) uint64 {
+	if t.ptrsize == 4 {
+		return uint64(t.order.Uint32(b))
+	}
+	return t.order.Uint64(b)
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package addr2line
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/parca-dev/parca/pkg/profile"
+	"github.com/parca-dev/parca/pkg/symbol/elfutils"
+	"github.com/parca-dev/parca/pkg/symbol/pdbutils"
+)
+
+type PDBLiner struct {
+	logger log.Logger
+
+	pdb *pdbutils.File
+}
+
+// PDB is a symbolizer that uses the PDB file of a PE image to symbolize
+// addresses relative to the start of the image.
+func PDB(logger log.Logger, path string) (*PDBLiner, error) {
+	f, err := pdbutils.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pdb: %w", err)
+	}
+
+	return &PDBLiner{
+		logger: log.With(logger, "liner", "pdb", "file", path),
+		pdb:    f,
+	}, nil
+}
+
+func (pl *PDBLiner) PCToLines(ctx context.Context, addr uint64) ([]profile.LocationLine, error) {
+	lines, err := pl.pdb.SourceLines(ctx, addr)
+	if err != nil {
+		level.Debug(pl.logger).Log("msg", "failed to symbolize location", "addr", addr, "err", err)
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, elfutils.ErrAddressNotFound
+	}
+	return lines, nil
+}
@@ -15,8 +15,8 @@
 package addr2line
 
 import (
+	"context"
 	"debug/elf"
-	"errors"
 	"fmt"
 	"sort"
 
@@ -25,34 +25,40 @@ import (
 
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
 	"github.com/parca-dev/parca/pkg/profile"
+	"github.com/parca-dev/parca/pkg/symbol/demangle"
+	"github.com/parca-dev/parca/pkg/symbol/elfutils"
 )
 
 type SymtabLiner struct {
-	logger log.Logger
+	logger    log.Logger
+	demangler *demangle.Demangler
 
 	symbols []elf.Symbol
 }
 
-func Symbols(logger log.Logger, path string) (*SymtabLiner, error) {
+func Symbols(logger log.Logger, path string, demangler *demangle.Demangler) (*SymtabLiner, error) {
 	symbols, err := symtab(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch symbols from object file: %w", err)
 	}
 
 	return &SymtabLiner{
-		logger:  log.With(logger, "liner", "symtab"),
-		symbols: symbols,
+		logger:    log.With(logger, "liner", "symtab"),
+		demangler: demangler,
+		symbols:   symbols,
 	}, nil
 }
 
-func (lnr *SymtabLiner) PCToLines(addr uint64) (lines []profile.LocationLine, err error) {
+// PCToLines returns the function of the symbol the address belongs to, the
+// last one starting at or before it. Symbols don't carry any source
+// information, so the file is unknown and the line is 0.
+func (lnr *SymtabLiner) PCToLines(_ context.Context, addr uint64) (lines []profile.LocationLine, err error) {
 	i := sort.Search(len(lnr.symbols), func(i int) bool {
-		sym := lnr.symbols[i]
-		return sym.Value >= addr
-	})
-	if i >= len(lnr.symbols) {
+		return lnr.symbols[i].Value > addr
+	}) - 1
+	if i < 0 || lnr.symbols[i].Size > 0 && addr-lnr.symbols[i].Value >= lnr.symbols[i].Size {
 		level.Debug(lnr.logger).Log("msg", "failed to find symbol for address", "addr", addr)
-		return nil, errors.New("failed to find symbol for address")
+		return nil, elfutils.ErrAddressNotFound
 	}
 
 	var (
@@ -61,10 +67,10 @@ func (lnr *SymtabLiner) PCToLines(addr uint64) (lines []profile.LocationLine, er
 	)
 	lines = append(lines, profile.LocationLine{
 		Line: line,
-		Function: &pb.Function{
-			Name:     lnr.symbols[i].Name,
-			Filename: file,
-		},
+		Function: lnr.demangler.Demangle(&pb.Function{
+			SystemName: lnr.symbols[i].Name,
+			Filename:   file,
+		}),
 	})
 	return lines, nil
 }
@@ -84,6 +90,15 @@ func symtab(path string) ([]elf.Symbol, error) {
 	}
 
 	syms = append(syms, dynSyms...)
+	// Only functions can contain an address of a stack, other symbols, like
+	// sections and files, would shadow them.
+	funcs := syms[:0]
+	for _, sym := range syms {
+		if elf.ST_TYPE(sym.Info) == elf.STT_FUNC {
+			funcs = append(funcs, sym)
+		}
+	}
+	syms = funcs
 	sort.SliceStable(syms, func(i, j int) bool {
 		return syms[i].Value < syms[j].Value
 	})
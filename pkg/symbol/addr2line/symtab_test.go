@@ -15,6 +15,7 @@
 package addr2line
 
 import (
+	"context"
 	"debug/elf"
 	"testing"
 
@@ -66,7 +67,7 @@ func TestSymtabLiner_PCToLines(t *testing.T) {
 				},
 			},
 			args: args{
-				addr: 4,
+				addr: 5,
 			},
 			wantErr: true,
 		},
@@ -97,8 +98,39 @@ func TestSymtabLiner_PCToLines(t *testing.T) {
 			wantLines: []profile.LocationLine{
 				{
 					Function: &metastorev1alpha1.Function{
-						Name:     "foo",
-						Filename: "?",
+						Name:       "foo",
+						SystemName: "foo",
+						Filename:   "?",
+					},
+					Line: 0,
+				},
+			},
+		},
+		{
+			name: "address inside symbol",
+			fields: fields{
+				symbols: []elf.Symbol{
+					{
+						Name:  "foo",
+						Value: 1,
+						Size:  3,
+					},
+					{
+						Name:  "bar",
+						Value: 4,
+						Size:  3,
+					},
+				},
+			},
+			args: args{
+				addr: 6,
+			},
+			wantLines: []profile.LocationLine{
+				{
+					Function: &metastorev1alpha1.Function{
+						Name:       "bar",
+						SystemName: "bar",
+						Filename:   "?",
 					},
 					Line: 0,
 				},
@@ -111,7 +143,7 @@ func TestSymtabLiner_PCToLines(t *testing.T) {
 				logger:  log.NewNopLogger(),
 				symbols: tt.fields.symbols,
 			}
-			gotLines, err := lnr.PCToLines(tt.args.addr)
+			gotLines, err := lnr.PCToLines(context.Background(), tt.args.addr)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("PCToLines() error = %v, wantErr %v", err, tt.wantErr)
 				return
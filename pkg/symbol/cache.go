@@ -0,0 +1,269 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symbol
+
+import (
+	"container/list"
+	"io"
+	"sync"
+	"time"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/profile"
+)
+
+// linerCache is a least-recently-used cache of liners keyed by build ID.
+// It is bounded by both the number of entries and the accumulated size of
+// the debug information files the liners were created from. Entries that
+// haven't been accessed within the TTL are treated as absent. Evicted liners
+// that hold resources are closed.
+type linerCache struct {
+	mtx sync.Mutex
+
+	maxEntries int
+	maxBytes   int64
+	ttl        time.Duration
+
+	size  int64
+	ll    *list.List
+	items map[string]*list.Element
+
+	now func() time.Time
+}
+
+type linerCacheEntry struct {
+	key        string
+	liner      liner
+	size       int64
+	lastAccess time.Time
+}
+
+func newLinerCache(maxEntries int, maxBytes int64, ttl time.Duration) *linerCache {
+	return &linerCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		ttl:        ttl,
+		ll:         list.New(),
+		items:      map[string]*list.Element{},
+		now:        time.Now,
+	}
+}
+
+// Get returns the liner cached for the given key, if any.
+func (c *linerCache) Get(key string) (liner, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := e.Value.(*linerCacheEntry)
+	now := c.now()
+	if c.ttl > 0 && now.Sub(entry.lastAccess) > c.ttl {
+		c.removeElement(e)
+		return nil, false
+	}
+
+	entry.lastAccess = now
+	c.ll.MoveToFront(e)
+	return entry.liner, true
+}
+
+// Add caches the liner for the given key. The size is the number of bytes
+// accounted against the maximum total size of the cache.
+func (c *linerCache) Add(key string, lnr liner, size int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.removeElement(e)
+	}
+
+	c.items[key] = c.ll.PushFront(&linerCacheEntry{
+		key:        key,
+		liner:      lnr,
+		size:       size,
+		lastAccess: c.now(),
+	})
+	c.size += size
+
+	// Never evict the entry that was just added, even if it alone exceeds
+	// the size limit, otherwise it would be re-created for every request.
+	for c.ll.Len() > 1 && c.overCapacity() {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Remove drops the liner cached for the given key, if any.
+func (c *linerCache) Remove(key string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.removeElement(e)
+	}
+}
+
+// Len returns the number of cached liners.
+func (c *linerCache) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.ll.Len()
+}
+
+// Close evicts all cached liners.
+func (c *linerCache) Close() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for c.ll.Len() > 0 {
+		c.removeElement(c.ll.Back())
+	}
+	return nil
+}
+
+func (c *linerCache) overCapacity() bool {
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		return true
+	}
+	return c.maxBytes > 0 && c.size > c.maxBytes
+}
+
+func (c *linerCache) removeElement(e *list.Element) {
+	entry := c.ll.Remove(e).(*linerCacheEntry)
+	delete(c.items, entry.key)
+	c.size -= entry.size
+
+	if closer, ok := entry.liner.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+// linesKey identifies an address of an object file. Addresses within their
+// mapping are identified by their file offset, as the same object file can
+// be loaded at different addresses.
+type linesKey struct {
+	buildID string
+	addr    uint64
+	offset  bool
+}
+
+// linesCache is a least-recently-used cache of the resolved lines of
+// addresses, so that addresses seen again don't need the debug information.
+type linesCache struct {
+	mtx sync.Mutex
+
+	maxEntries int
+
+	ll    *list.List
+	items map[linesKey]*list.Element
+}
+
+type linesCacheEntry struct {
+	key   linesKey
+	lines []profile.LocationLine
+}
+
+func newLinesCache(maxEntries int) *linesCache {
+	return &linesCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      map[linesKey]*list.Element{},
+	}
+}
+
+// Get returns a copy of the lines cached for the given key, if any. The
+// functions are copied too, as they are modified once they are stored.
+func (c *linesCache) Get(key linesKey) ([]profile.LocationLine, bool) {
+	c.mtx.Lock()
+	e, ok := c.items[key]
+	if !ok {
+		c.mtx.Unlock()
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	lines := e.Value.(*linesCacheEntry).lines
+	c.mtx.Unlock()
+
+	return cloneLines(lines), true
+}
+
+// Add caches a copy of the lines for the given key. Nothing is cached if the
+// cache has no room for any entries.
+func (c *linesCache) Add(key linesKey, lines []profile.LocationLine) {
+	if c.maxEntries <= 0 {
+		return
+	}
+	lines = cloneLines(lines)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*linesCacheEntry).lines = lines
+		c.ll.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&linesCacheEntry{key: key, lines: lines})
+	for c.ll.Len() > c.maxEntries {
+		entry := c.ll.Remove(c.ll.Back()).(*linesCacheEntry)
+		delete(c.items, entry.key)
+	}
+}
+
+// RemoveBuildID drops the lines cached for all addresses of the given build
+// ID.
+func (c *linesCache) RemoveBuildID(buildID string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for key, e := range c.items {
+		if key.buildID == buildID {
+			c.ll.Remove(e)
+			delete(c.items, key)
+		}
+	}
+}
+
+// Len returns the number of addresses with cached lines.
+func (c *linesCache) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.ll.Len()
+}
+
+// cloneLines returns a copy of the lines with copies of their functions. The
+// functions are copied field by field, as reflecting on them would modify the
+// internal state of the messages of the caller.
+func cloneLines(lines []profile.LocationLine) []profile.LocationLine {
+	cloned := make([]profile.LocationLine, 0, len(lines))
+	for _, line := range lines {
+		if f := line.Function; f != nil {
+			line.Function = &pb.Function{
+				Id:         f.Id,
+				StartLine:  f.StartLine,
+				Name:       f.Name,
+				SystemName: f.SystemName,
+				Filename:   f.Filename,
+			}
+		}
+		cloned = append(cloned, line)
+	}
+	return cloned
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symbol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/parca-dev/parca/pkg/profile"
+)
+
+type closingLiner struct {
+	closed bool
+}
+
+func (l *closingLiner) PCToLines(_ context.Context, pc uint64) ([]profile.LocationLine, error) {
+	return nil, nil
+}
+
+func (l *closingLiner) Close() error {
+	l.closed = true
+	return nil
+}
+
+func TestLinerCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLinerCache(2, 0, 0)
+
+	a, b, d := &closingLiner{}, &closingLiner{}, &closingLiner{}
+	c.Add("a", a, 1)
+	c.Add("b", b, 1)
+
+	// Touch "a" so that "b" becomes the least recently used entry.
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	c.Add("d", d, 1)
+	require.Equal(t, 2, c.Len())
+
+	_, ok = c.Get("b")
+	require.False(t, ok)
+	require.True(t, b.closed)
+	require.False(t, a.closed)
+
+	require.NoError(t, c.Close())
+	require.True(t, a.closed)
+	require.True(t, d.closed)
+	require.Equal(t, 0, c.Len())
+}
+
+func TestLinerCacheEvictsOverMaxBytes(t *testing.T) {
+	c := newLinerCache(0, 10, 0)
+
+	a, b := &closingLiner{}, &closingLiner{}
+	c.Add("a", a, 6)
+	c.Add("b", b, 6)
+
+	_, ok := c.Get("a")
+	require.False(t, ok)
+	require.True(t, a.closed)
+
+	// A single entry exceeding the limit is kept.
+	c.Add("c", &closingLiner{}, 20)
+	_, ok = c.Get("c")
+	require.True(t, ok)
+	require.Equal(t, 1, c.Len())
+}
+
+func TestLinerCacheExpiresAfterAccess(t *testing.T) {
+	c := newLinerCache(0, 0, time.Minute)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Add("a", &closingLiner{}, 1)
+	now = now.Add(30 * time.Second)
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = c.Get("a")
+	require.False(t, ok)
+}
+
+func TestLinesCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLinesCache(2)
+
+	a, b, d := linesKey{buildID: "a"}, linesKey{buildID: "b"}, linesKey{buildID: "d"}
+	c.Add(a, []profile.LocationLine{{Line: 1}})
+	c.Add(b, []profile.LocationLine{{Line: 2}})
+
+	// Touch "a" so that "b" becomes the least recently used entry.
+	_, ok := c.Get(a)
+	require.True(t, ok)
+
+	c.Add(d, []profile.LocationLine{{Line: 3}})
+	require.Equal(t, 2, c.Len())
+
+	_, ok = c.Get(b)
+	require.False(t, ok)
+	lines, ok := c.Get(a)
+	require.True(t, ok)
+	require.Equal(t, []profile.LocationLine{{Line: 1}}, lines)
+
+	c.RemoveBuildID("a")
+	_, ok = c.Get(a)
+	require.False(t, ok)
+	require.Equal(t, 1, c.Len())
+}
@@ -16,43 +16,57 @@ package demangle
 import (
 	"strings"
 
-	"github.com/ianlancetaylor/demangle"
-
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
 )
 
 type Demangler struct {
-	options []demangle.Option
+	schemes []Scheme
 	mode    string
 	force   bool
 }
 
-func NewDemangler(mode string, force bool) *Demangler {
-	var options []demangle.Option
-	switch mode {
-	case "", "simple": // demangled, simplified: no parameters, no templates, no return type
-		options = []demangle.Option{demangle.NoParams, demangle.NoTemplateParams}
-	case "templates": // demangled, simplified: no parameters, no return type
-		options = []demangle.Option{demangle.NoParams}
-	case "full":
-		options = []demangle.Option{demangle.NoClones}
-	case "none": // no demangling
+// Option configures a Demangler.
+type Option func(*Demangler)
+
+// WithSchemes sets the schemes to try in turn to demangle names, the first
+// one that succeeds is used. It defaults to the built-in DefaultSchemes.
+func WithSchemes(schemes ...Scheme) Option {
+	return func(d *Demangler) {
+		d.schemes = schemes
+	}
+}
+
+func NewDemangler(mode string, force bool, opts ...Option) *Demangler {
+	if mode == "none" { // no demangling
 		return nil
 	}
 
-	return &Demangler{
-		options: options,
+	schemes, err := SchemesByName(DefaultSchemes)
+	if err != nil {
+		panic(err)
+	}
+	d := &Demangler{
+		schemes: schemes,
 		mode:    mode,
 		force:   force,
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
-// Demangle updates the function names in a profile demangling C++ and
-// Rust names, simplified according to demanglerMode. If force is set,
-// overwrite any names that appear already demangled.
+// Demangle updates the function names in a profile demangling them with the
+// first scheme that recognizes them, simplified according to demanglerMode.
+// If force is set, overwrite any names that appear already demangled.
+// The original name is kept as the system name. If demangling is disabled,
+// or no scheme recognizes the name, the system name is used as is.
 // A modified version of pprof demangler.
 func (d *Demangler) Demangle(fn *pb.Function) *pb.Function {
 	if d == nil {
+		if fn.Name == "" {
+			fn.Name = fn.SystemName
+		}
 		return fn
 	}
 
@@ -67,9 +81,11 @@ func (d *Demangler) Demangle(fn *pb.Function) *pb.Function {
 		return fn // Already demangled.
 	}
 
-	if demangled := demangle.Filter(fn.SystemName, d.options...); demangled != fn.SystemName {
-		fn.Name = demangled
-		return fn
+	for _, s := range d.schemes {
+		if demangled, ok := d.demangle(s, fn.SystemName); ok && demangled != fn.SystemName {
+			fn.Name = demangled
+			return fn
+		}
 	}
 	// Could not demangle. Apply heuristics in case the name is
 	// already demangled.
@@ -89,6 +105,17 @@ func (d *Demangler) Demangle(fn *pb.Function) *pb.Function {
 // looksLikeDemangledCPlusPlus is a heuristic to decide if a name is
 // the result of demangling C++. If so, further heuristics will be
 // applied to simplify the name.
+// demangle demangles the name with the given scheme. A scheme panicking is
+// treated as not recognizing the name, demangling is best-effort.
+func (d *Demangler) demangle(s Scheme, name string) (demangled string, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			demangled, ok = "", false
+		}
+	}()
+	return s.Demangle(name, d.mode)
+}
+
 func looksLikeDemangledCPlusPlus(demangled string) bool {
 	if strings.Contains(demangled, ".<") { // Skip java names of the form "class.<init>"
 		return false
@@ -14,6 +14,7 @@
 package demangle
 
 import (
+	"strings"
 	"testing"
 
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
@@ -61,6 +62,7 @@ func TestDemangleNone(t *testing.T) {
 		SystemName: "_ZNSaIcEC1ERKS_",
 	}
 	expected_function := pb.Function{
+		Name:       "_ZNSaIcEC1ERKS_",
 		SystemName: "_ZNSaIcEC1ERKS_",
 	}
 
@@ -97,3 +99,78 @@ func TestDemanglerSimpleRustDemangling(t *testing.T) {
 	demangled := demangler.Demangle(&function)
 	require.Equal(t, &expected_function, demangled)
 }
+
+func TestDemanglerSimpleRustV0Demangling(t *testing.T) {
+	demangler := NewDemangler("simple", true)
+
+	function := pb.Function{
+		SystemName: "_RNvNtCsgaYGWQqCqrV_3fib3fib9fibonacci",
+	}
+	expected_function := pb.Function{
+		Name:       "fib::fib::fibonacci",
+		SystemName: "_RNvNtCsgaYGWQqCqrV_3fib3fib9fibonacci",
+	}
+
+	demangled := demangler.Demangle(&function)
+	require.Equal(t, &expected_function, demangled)
+}
+
+func TestDemanglerCustomScheme(t *testing.T) {
+	// A Swift demangler recognizing a single name, tried after the built-in
+	// ones that don't recognize it.
+	swift := SchemeFunc(func(name, _ string) (string, bool) {
+		if name != "$s4main5helloyyF" {
+			return "", false
+		}
+		return "main.hello()", true
+	})
+	schemes, err := SchemesByName(DefaultSchemes)
+	require.NoError(t, err)
+	demangler := NewDemangler("simple", true, WithSchemes(append(schemes, swift)...))
+
+	require.Equal(t, &pb.Function{
+		Name:       "main.hello()",
+		SystemName: "$s4main5helloyyF",
+	}, demangler.Demangle(&pb.Function{SystemName: "$s4main5helloyyF"}))
+
+	// The built-in schemes still apply.
+	require.Equal(t, &pb.Function{
+		Name:       "std::allocator::allocator",
+		SystemName: "_ZNSaIcEC1ERKS_",
+	}, demangler.Demangle(&pb.Function{SystemName: "_ZNSaIcEC1ERKS_"}))
+}
+
+func TestDemanglerSchemesInOrder(t *testing.T) {
+	panicking := SchemeFunc(func(string, string) (string, bool) {
+		panic("unexpected")
+	})
+	upper := SchemeFunc(func(name, _ string) (string, bool) {
+		return strings.ToUpper(name), true
+	})
+	demangler := NewDemangler("simple", true, WithSchemes(panicking, upper, SchemeFunc(demangleCPlusPlus)))
+
+	require.Equal(t, &pb.Function{
+		Name:       "_ZNSAICEC1ERKS_",
+		SystemName: "_ZNSaIcEC1ERKS_",
+	}, demangler.Demangle(&pb.Function{SystemName: "_ZNSaIcEC1ERKS_"}))
+}
+
+func TestDemanglerDDemangling(t *testing.T) {
+	demangler := NewDemangler("simple", true)
+
+	require.Equal(t, &pb.Function{
+		Name:       "std.stdio.writeln",
+		SystemName: "_D3std5stdio7writelnFAyaZv",
+	}, demangler.Demangle(&pb.Function{SystemName: "_D3std5stdio7writelnFAyaZv"}))
+}
+
+func TestDemanglerUnrecognizedName(t *testing.T) {
+	demangler := NewDemangler("full", true)
+
+	for _, name := range []string{"$s4main5helloyyF", "_Zinvalid", "_D", "runtime.main"} {
+		require.Equal(t, &pb.Function{
+			Name:       name,
+			SystemName: name,
+		}, demangler.Demangle(&pb.Function{SystemName: name}))
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package demangle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ianlancetaylor/demangle"
+)
+
+// Scheme demangles the names of one mangling scheme.
+type Scheme interface {
+	// Demangle returns the demangled name, or false if the name is not
+	// mangled with the scheme. The mode is one of the demangle modes and
+	// tells how much of the name to keep.
+	Demangle(name, mode string) (string, bool)
+}
+
+// SchemeFunc is a function implementing Scheme.
+type SchemeFunc func(name, mode string) (string, bool)
+
+func (f SchemeFunc) Demangle(name, mode string) (string, bool) {
+	return f(name, mode)
+}
+
+// DefaultSchemes are the names of the built-in schemes in the order they are
+// tried in by default.
+var DefaultSchemes = []string{"rust", "cpp", "d"}
+
+var builtinSchemes = map[string]Scheme{
+	"rust": SchemeFunc(demangleRust),
+	"cpp":  SchemeFunc(demangleCPlusPlus),
+	"d":    SchemeFunc(demangleD),
+}
+
+// SchemesByName returns the built-in schemes with the given names, in the
+// same order.
+func SchemesByName(names []string) ([]Scheme, error) {
+	schemes := make([]Scheme, 0, len(names))
+	for _, name := range names {
+		s, ok := builtinSchemes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown demangler %q", name)
+		}
+		schemes = append(schemes, s)
+	}
+	return schemes, nil
+}
+
+func modeOptions(mode string) []demangle.Option {
+	switch mode {
+	case "", "simple": // demangled, simplified: no parameters, no templates, no return type
+		return []demangle.Option{demangle.NoParams, demangle.NoTemplateParams}
+	case "templates": // demangled, simplified: no parameters, no return type
+		return []demangle.Option{demangle.NoParams}
+	case "full":
+		return []demangle.Option{demangle.NoClones}
+	}
+	return nil
+}
+
+// demangleRust demangles Rust v0 and legacy names. Legacy names are Itanium
+// C++ names ending with a hash.
+func demangleRust(name, mode string) (string, bool) {
+	if !strings.HasPrefix(name, "_R") && !isRustLegacy(name) {
+		return "", false
+	}
+	demangled, err := demangle.ToString(name, modeOptions(mode)...)
+	if err != nil {
+		return "", false
+	}
+	return demangled, true
+}
+
+// isRustLegacy reports whether the name starts with _ZN and ends with "17h"
+// followed by 16 hex digits and "E", ignoring a suffix starting with ".".
+func isRustLegacy(name string) bool {
+	if !strings.HasPrefix(name, "_ZN") {
+		return false
+	}
+	if pos := strings.LastIndex(name, "E."); pos > 0 {
+		name = name[:pos+1]
+	}
+	return strings.HasSuffix(name, "E") && len(name) > 23 && name[len(name)-20:len(name)-17] == "17h"
+}
+
+// demangleCPlusPlus demangles Itanium C++ names.
+func demangleCPlusPlus(name, mode string) (string, bool) {
+	options := modeOptions(mode)
+	a, err := demangle.ToAST(name, options...)
+	if err != nil {
+		return "", false
+	}
+	return demangle.ASTToString(a, options...), true
+}
+
+// demangleD demangles the qualified name of D symbols, their parameters and
+// types are left out whatever the mode is.
+func demangleD(name, _ string) (string, bool) {
+	if name == "_Dmain" {
+		return "D main", true
+	}
+	if !strings.HasPrefix(name, "_D") {
+		return "", false
+	}
+
+	var parts []string
+	s := name[2:]
+	for len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil || n == 0 || n > len(s)-i {
+			return "", false
+		}
+		parts = append(parts, s[i:i+n])
+		s = s[i+n:]
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	return strings.Join(parts, "."), true
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elfutils
+
+import (
+	"debug/elf"
+	"fmt"
+
+	"github.com/parca-dev/parca/pkg/symbol/pdbutils"
+)
+
+// ExecInfo is the information of an object file that is needed to translate
+// runtime addresses of its mappings to the addresses its debug information
+// refers to.
+type ExecInfo struct {
+	Type elf.Type
+	// TextSegment is the executable load segment containing the .text
+	// section, if any.
+	TextSegment *elf.ProgHeader
+	// ExecSegments are all executable load segments. Object files can have
+	// several of them, e.g. statically linked ones, which are mapped
+	// separately.
+	ExecSegments []elf.ProgHeader
+}
+
+// ReadExecInfo reads the ExecInfo of the specified object file.
+func ReadExecInfo(path string) (*ExecInfo, error) {
+	if IsMachO(path) {
+		return readMachOExecInfo(path)
+	}
+	if pdbutils.IsPDB(path) {
+		// The debug information of PDB files refers to addresses relative
+		// to the start of the image, which is mapped as a whole.
+		return &ExecInfo{Type: elf.ET_DYN}, nil
+	}
+
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open elf: %w", err)
+	}
+	defer f.Close()
+
+	info := &ExecInfo{Type: f.Type}
+	text := f.Section(".text")
+	for _, p := range f.Progs {
+		if p.Type != elf.PT_LOAD || p.Flags&elf.PF_X == 0 {
+			continue
+		}
+		info.ExecSegments = append(info.ExecSegments, p.ProgHeader)
+		if text != nil && info.TextSegment == nil && text.Addr >= p.Vaddr && text.Addr < p.Vaddr+p.Memsz {
+			h := p.ProgHeader
+			info.TextSegment = &h
+		}
+	}
+	return info, nil
+}
+
+// Base returns the base address to subtract from a runtime address within the
+// mapping with the given start, limit and file offset to get the virtual
+// address used by the object file's symbols and debug information.
+func (e *ExecInfo) Base(start, limit, offset uint64) (uint64, error) {
+	if start == 0 && offset == 0 && (limit == ^uint64(0) || limit == 0) {
+		// A fake mapping spanning the entire address space, the addresses
+		// are assumed to be adjusted already.
+		return 0, nil
+	}
+
+	switch e.Type {
+	case elf.ET_EXEC:
+		// Executables are loaded at fixed addresses.
+		return 0, nil
+	case elf.ET_DYN:
+		// A runtime address x maps to the file offset
+		// fx = x - start + offset, and the file offset maps to the virtual
+		// address fx - segment offset + segment virtual address.
+		segment := e.segment(offset)
+		if segment == nil {
+			return start - offset, nil
+		}
+		return start - offset + segment.Off - segment.Vaddr, nil
+	default:
+		return 0, fmt.Errorf("don't know how to handle object file type %v", e.Type)
+	}
+}
+
+// segment returns the executable load segment of the file offset a mapping
+// starts at, or the text segment if there is none. Segments are mapped from
+// the start of the page they begin in.
+func (e *ExecInfo) segment(offset uint64) *elf.ProgHeader {
+	const pageSize = 0x1000
+	for i := range e.ExecSegments {
+		s := &e.ExecSegments[i]
+		if offset >= s.Off&^(pageSize-1) && offset < s.Off+s.Filesz {
+			return s
+		}
+	}
+	return e.TextSegment
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elfutils
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/parca-dev/parca/pkg/symbol/pdbutils"
+)
+
+var ErrNoBuildID = errors.New("object file has no GNU build ID note")
+
+const noteTypeGNUBuildID = 3
+
+// BuildID returns the hex encoded GNU build ID of the specified ELF object
+// file, the UUID of the specified Mach-O object file, or the GUID and age of
+// the specified PE image or PDB file.
+func BuildID(path string) (string, error) {
+	if IsMachO(path) {
+		return MachOUUID(path)
+	}
+	if pdbutils.IsPE(path) {
+		return pdbutils.PEBuildID(path)
+	}
+	if pdbutils.IsPDB(path) {
+		return pdbutils.PDBBuildID(path)
+	}
+	return GNUBuildID(path)
+}
+
+// GNUBuildID returns the hex encoded build ID found in the .note.gnu.build-id
+// section of the specified object file.
+func GNUBuildID(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open elf: %w", err)
+	}
+	defer f.Close()
+
+	s := f.Section(".note.gnu.build-id")
+	if s == nil {
+		return "", ErrNoBuildID
+	}
+	data, err := s.Data()
+	if err != nil {
+		return "", fmt.Errorf("failed to read build ID section: %w", err)
+	}
+	desc, err := findNote(f.ByteOrder, data, "GNU", noteTypeGNUBuildID)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse build ID section: %w", err)
+	}
+	if len(desc) == 0 {
+		return "", ErrNoBuildID
+	}
+	return hex.EncodeToString(desc), nil
+}
+
+// findNote returns the descriptor of the first note with the given name and
+// type in the given note section data.
+func findNote(order interface{ Uint32([]byte) uint32 }, data []byte, name string, typ uint32) ([]byte, error) {
+	align := func(n uint32) uint32 { return (n + 3) &^ 3 }
+
+	for len(data) > 0 {
+		if len(data) < 12 {
+			return nil, errors.New("note header truncated")
+		}
+		var (
+			namesz = order.Uint32(data[0:4])
+			descsz = order.Uint32(data[4:8])
+			ntype  = order.Uint32(data[8:12])
+		)
+		data = data[12:]
+
+		if uint64(align(namesz))+uint64(align(descsz)) > uint64(len(data)) {
+			return nil, errors.New("note truncated")
+		}
+		nname := bytes.TrimRight(data[:namesz], "\x00")
+		desc := data[align(namesz) : align(namesz)+descsz]
+		data = data[align(namesz)+align(descsz):]
+
+		if ntype == typ && string(nname) == name {
+			return desc, nil
+		}
+	}
+	return nil, nil
+}
@@ -14,12 +14,16 @@
 package elfutils
 
 import (
+	"context"
 	"debug/dwarf"
 	"debug/elf"
+	"debug/macho"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"sort"
+	"sync"
 
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/reader"
@@ -29,22 +33,66 @@ import (
 	"github.com/parca-dev/parca/pkg/symbol/demangle"
 )
 
+var (
+	// ErrAddressNotFound is returned if the debug information has no source
+	// lines for an address.
+	ErrAddressNotFound = errors.New("address not found in debug information")
+	// ErrCorruptDWARF is returned if the DWARF data of an object file is
+	// malformed.
+	ErrCorruptDWARF = errors.New("corrupt DWARF data")
+	// ErrNoLineProgram is returned if the compile unit of an address has no
+	// line program, e.g. because the .debug_line section was stripped.
+	ErrNoLineProgram = errors.New("compile unit has no line program")
+)
+
+// corruptDWARF returns an ErrCorruptDWARF error with the given details.
+func corruptDWARF(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrCorruptDWARF, fmt.Sprintf(format, args...))
+}
+
 type DebugInfoFile interface {
 	// SourceLines returns the resolved source lines for a given address.
-	SourceLines(addr uint64) ([]profile.LocationLine, error)
+	SourceLines(ctx context.Context, addr uint64) ([]profile.LocationLine, error)
 }
 
 type debugInfoFile struct {
 	demangler *demangle.Demangler
+	pool      *BufferPool
+
+	// mtx guards the lazily built look up tables.
+	mtx sync.Mutex
 
 	debugData           *dwarf.Data
 	lineEntries         map[dwarf.Offset][]dwarf.LineEntry
+	lineFiles           map[dwarf.Offset][]*dwarf.LineFile
 	subprograms         map[dwarf.Offset][]*godwarf.Tree
 	abstractSubprograms map[dwarf.Offset]*dwarf.Entry
+
+	// split resolves skeleton units, if the object file has a DWARF package
+	// file next to it. The entries of split units have offsets of their own,
+	// so their abstract subprograms are kept by skeleton unit.
+	split                    *splitDWARF
+	splitAbstractSubprograms map[dwarf.Offset]map[dwarf.Offset]*dwarf.Entry
 }
 
-// NewDebugInfoFile creates a new DebugInfoFile.
-func NewDebugInfoFile(path string, demangler *demangle.Demangler) (DebugInfoFile, error) {
+// NewDebugInfoFile creates a new DebugInfoFile of an ELF or Mach-O object
+// file. The look up tables of its compile units are collected in buffers of
+// the pool, which may be nil.
+func NewDebugInfoFile(path string, demangler *demangle.Demangler, pool *BufferPool) (DebugInfoFile, error) {
+	if IsMachO(path) {
+		f, err := macho.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open macho: %w", err)
+		}
+		defer f.Close()
+
+		debugData, err := f.DWARF()
+		if err != nil {
+			return nil, corruptDWARF("failed to read DWARF data: %v", err)
+		}
+		return newDebugInfoFile(debugData, nil, demangler, pool), nil
+	}
+
 	f, err := elf.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open elf: %w", err)
@@ -53,35 +101,56 @@ func NewDebugInfoFile(path string, demangler *demangle.Demangler) (DebugInfoFile
 
 	debugData, err := f.DWARF()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read DWARF data: %w", err)
+		return nil, corruptDWARF("failed to read DWARF data: %v", err)
+	}
+
+	var split *splitDWARF
+	if _, err := os.Stat(DWPPath(path)); err == nil {
+		split, err = newSplitDWARF(f, DWPPath(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DWARF package file: %w", err)
+		}
 	}
 
+	return newDebugInfoFile(debugData, split, demangler, pool), nil
+}
+
+func newDebugInfoFile(debugData *dwarf.Data, split *splitDWARF, demangler *demangle.Demangler, pool *BufferPool) *debugInfoFile {
 	return &debugInfoFile{
 		demangler: demangler,
+		pool:      pool,
 
 		debugData:           debugData,
 		lineEntries:         make(map[dwarf.Offset][]dwarf.LineEntry),
+		lineFiles:           make(map[dwarf.Offset][]*dwarf.LineFile),
 		subprograms:         make(map[dwarf.Offset][]*godwarf.Tree),
 		abstractSubprograms: make(map[dwarf.Offset]*dwarf.Entry),
-	}, nil
+
+		split:                    split,
+		splitAbstractSubprograms: make(map[dwarf.Offset]map[dwarf.Offset]*dwarf.Entry),
+	}
 }
 
-func (f *debugInfoFile) SourceLines(addr uint64) ([]profile.LocationLine, error) {
+func (f *debugInfoFile) SourceLines(ctx context.Context, addr uint64) ([]profile.LocationLine, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
 	// The reader is positioned at byte offset 0 in the DWARF “info” section.
 	er := f.debugData.Reader()
 	cu, err := er.SeekPC(addr)
-	if err != nil {
-		return nil, err
+	if errors.Is(err, dwarf.ErrUnknownPC) || err == nil && cu == nil {
+		return nil, ErrAddressNotFound
 	}
-	if cu == nil {
-		return nil, errors.New("failed to find a corresponding dwarf entry for given address")
+	if err != nil {
+		return nil, corruptDWARF("failed to find compile unit: %v", err)
 	}
 
-	if err := f.ensureLookUpTablesBuilt(cu); err != nil {
+	if err := f.ensureLookUpTablesBuilt(ctx, cu); err != nil {
 		return nil, err
 	}
 
 	lines := []profile.LocationLine{}
+	abstractSubprograms := f.abstractSubprogramsOf(cu)
 	var tr *godwarf.Tree
 	for _, t := range f.subprograms[cu.Offset] {
 		if t.ContainsPC(addr) {
@@ -93,43 +162,78 @@ func (f *debugInfoFile) SourceLines(addr uint64) ([]profile.LocationLine, error)
 		return lines, nil
 	}
 
-	name, ok := tr.Entry.Val(dwarf.AttrName).(string)
-	if !ok {
-		name = ""
-	}
-	file, line := findLineInfo(f.lineEntries[cu.Offset], tr.Ranges)
-	lines = append(lines, profile.LocationLine{
-		Line: line,
-		Function: f.demangler.Demangle(&pb.Function{
-			Name:     name,
-			Filename: file,
-		}),
-	})
+	// The innermost frame is resolved using the line table entry of the
+	// address itself. Every enclosing frame is resolved using the call site
+	// of the frame it inlines, as recorded in the inlined subroutine entry.
+	file, line := findLineInfoForPC(f.lineEntries[cu.Offset], addr)
 
-	// If pc is 0 then all inlined calls will be returned.
+	// InlineStack returns the inlined calls innermost first, which is the
+	// order pprof expects the lines of a location to be in.
 	for _, ch := range reader.InlineStack(tr, addr) {
-		var name string
-		if ch.Tag == dwarf.TagSubprogram {
-			name = tr.Entry.Val(dwarf.AttrName).(string)
-		} else {
-			abstractOrigin := f.abstractSubprograms[ch.Entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)]
-			name = getFunctionName(abstractOrigin)
-		}
-
-		file, line := findLineInfo(f.lineEntries[cu.Offset], ch.Ranges)
+		abstractOrigin := abstractSubprograms[ch.Entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)]
 		lines = append(lines, profile.LocationLine{
 			Line: line,
 			Function: f.demangler.Demangle(&pb.Function{
-				Name:     name,
-				Filename: file,
+				SystemName: getFunctionName(abstractOrigin),
+				Filename:   file,
 			}),
 		})
+
+		file, line = f.callSite(cu.Offset, ch)
 	}
 
+	name := ""
+	if tr.Entry.Val(dwarf.AttrName) != nil {
+		name = functionName(tr.Entry)
+	} else if offset, ok := tr.Entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset); ok {
+		// Out-of-line instances of functions that are also inlined elsewhere
+		// carry their name on the abstract origin.
+		if abstractOrigin, ok := abstractSubprograms[offset]; ok {
+			name = getFunctionName(abstractOrigin)
+		}
+	}
+	lines = append(lines, profile.LocationLine{
+		Line: line,
+		Function: f.demangler.Demangle(&pb.Function{
+			SystemName: name,
+			Filename:   file,
+		}),
+	})
+
 	return lines, nil
 }
 
-func (f *debugInfoFile) ensureLookUpTablesBuilt(cu *dwarf.Entry) error {
+// abstractSubprogramsOf returns the abstract subprograms that the entries of
+// the given compile unit can refer to.
+func (f *debugInfoFile) abstractSubprogramsOf(cu *dwarf.Entry) map[dwarf.Offset]*dwarf.Entry {
+	if abstractSubprograms, ok := f.splitAbstractSubprograms[cu.Offset]; ok {
+		return abstractSubprograms
+	}
+	return f.abstractSubprograms
+}
+
+// callSite returns the file and line the given inlined subroutine was called from.
+func (f *debugInfoFile) callSite(cuOffset dwarf.Offset, inlined *godwarf.Tree) (string, int64) {
+	var (
+		file = "?"
+		line int64 // 0
+	)
+	if l, ok := inlined.Entry.Val(dwarf.AttrCallLine).(int64); ok {
+		line = l
+	}
+	if i, ok := inlined.Entry.Val(dwarf.AttrCallFile).(int64); ok {
+		files := f.lineFiles[cuOffset]
+		if i >= 0 && i < int64(len(files)) && files[i] != nil {
+			file = files[i].Name
+		}
+	}
+	return file, line
+}
+
+// ensureLookUpTablesBuilt builds the look up tables of the compile unit. It
+// stops when the context is done, as malformed debug information can make it
+// run for a very long time.
+func (f *debugInfoFile) ensureLookUpTablesBuilt(ctx context.Context, cu *dwarf.Entry) error {
 	if _, ok := f.lineEntries[cu.Offset]; ok {
 		// Already created.
 		return nil
@@ -138,37 +242,66 @@ func (f *debugInfoFile) ensureLookUpTablesBuilt(cu *dwarf.Entry) error {
 	// The reader is positioned at byte offset 0 in the DWARF “line” section.
 	lr, err := f.debugData.LineReader(cu)
 	if err != nil {
-		return err
+		return corruptDWARF("failed to read line table: %v", err)
 	}
 	if lr == nil {
-		return errors.New("failed to initialize line reader")
+		return ErrNoLineProgram
 	}
 
+	entries := f.pool.getLineEntries()
 	for {
+		if err := ctx.Err(); err != nil {
+			f.pool.putLineEntries(entries)
+			return err
+		}
 		le := dwarf.LineEntry{}
 		err := lr.Next(&le)
 		if err != nil {
 			break
 		}
-		if le.IsStmt {
-			f.lineEntries[cu.Offset] = append(f.lineEntries[cu.Offset], le)
+		entries = append(entries, le)
+	}
+	// A compile unit can consist of multiple sequences, which are not
+	// necessarily ordered by address.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Address < entries[j].Address
+	})
+	entries = f.pool.keepLineEntries(entries)
+
+	// The entries of a skeleton unit are in its split unit, which is the
+	// only unit of its DWARF data.
+	debugData, unitOffset := f.debugData, cu.Offset
+	if cu.Tag == dwarf.TagSkeletonUnit {
+		if f.split == nil {
+			return errors.New("failed to find DWARF package file for skeleton unit")
 		}
+		debugData, err = f.split.unitData(cu)
+		if err != nil {
+			return corruptDWARF("failed to read split unit: %v", err)
+		}
+		unitOffset = 0
 	}
 
-	er := f.debugData.Reader()
+	er := debugData.Reader()
 	// The reader is positioned at byte offset of compile unit in the DWARF “info” section.
-	er.Seek(cu.Offset)
+	er.Seek(unitOffset)
 	entry, err := er.Next()
 	if err != nil || entry == nil {
-		return errors.New("failed to read entry for compile unit")
+		return corruptDWARF("failed to read entry for compile unit")
 	}
 
 	if entry.Tag != dwarf.TagCompileUnit {
-		return errors.New("failed to find entry for compile unit")
+		return corruptDWARF("failed to find entry for compile unit")
 	}
 
+	subprograms := f.pool.getSubprograms()
+	abstractSubprograms := map[dwarf.Offset]*dwarf.Entry{}
 outer:
 	for {
+		if err := ctx.Err(); err != nil {
+			f.pool.putSubprograms(subprograms)
+			return err
+		}
 		entry, err := er.Next()
 		if err != nil {
 			if err == io.EOF {
@@ -187,53 +320,79 @@ outer:
 		if entry.Tag == dwarf.TagSubprogram {
 			for _, field := range entry.Field {
 				if field.Attr == dwarf.AttrInline {
-					f.abstractSubprograms[entry.Offset] = entry
+					abstractSubprograms[entry.Offset] = entry
 					continue outer
 				}
 			}
 
-			tr, err := godwarf.LoadTree(entry.Offset, f.debugData, 0)
+			tr, err := godwarf.LoadTree(entry.Offset, debugData, 0)
 			if err != nil {
-				return fmt.Errorf("failed to extract dwarf tree: %w", err)
+				f.pool.putSubprograms(subprograms)
+				return corruptDWARF("failed to extract dwarf tree: %v", err)
 			}
 
-			f.subprograms[cu.Offset] = append(f.subprograms[cu.Offset], tr)
+			subprograms = append(subprograms, tr)
 		}
 	}
-
+	subprograms = f.pool.keepSubprograms(subprograms)
+
+	// The tables are only stored once complete, the compile unit is looked
+	// at again if building them was interrupted.
+	f.lineEntries[cu.Offset] = entries
+	f.lineFiles[cu.Offset] = lr.Files()
+	f.subprograms[cu.Offset] = subprograms
+	if cu.Tag == dwarf.TagSkeletonUnit {
+		f.splitAbstractSubprograms[cu.Offset] = abstractSubprograms
+		return nil
+	}
+	for offset, entry := range abstractSubprograms {
+		f.abstractSubprograms[offset] = entry
+	}
 	return nil
 }
 
-func findLineInfo(entries []dwarf.LineEntry, rg [][2]uint64) (string, int64) {
+// findLineInfoForPC returns the file and line of the last line entry that
+// starts at or before the given address.
+func findLineInfoForPC(entries []dwarf.LineEntry, pc uint64) (string, int64) {
 	var (
 		file = "?"
 		line int64 // 0
 	)
 	i := sort.Search(len(entries), func(i int) bool {
-		return entries[i].Address >= rg[0][0]
+		return entries[i].Address > pc
 	})
-	if i >= len(entries) {
+	if i == 0 {
 		return file, line
 	}
 
-	le := dwarf.LineEntry{}
-	pc := entries[i].Address
-	if rg[0][0] <= pc && pc < rg[0][1] {
-		le = entries[i]
-		return le.File.Name, int64(le.Line)
+	le := entries[i-1]
+	if le.EndSequence || le.File == nil {
+		return file, line
 	}
-
-	return file, line
+	return le.File.Name, int64(le.Line)
 }
 
+// DW_AT_MIPS_linkage_name is used by older compilers instead of DW_AT_linkage_name.
+const attrMIPSLinkageName dwarf.Attr = 0x2007
+
 func getFunctionName(entry *dwarf.Entry) string {
-	name := "?"
-	if entry != nil {
-		for _, field := range entry.Field {
-			if field.Attr == dwarf.AttrName {
-				name = field.Val.(string)
-			}
+	if entry == nil {
+		return "?"
+	}
+	return functionName(entry)
+}
+
+// functionName returns the linkage (mangled) name of the function if
+// present, so that it can be demangled into a fully qualified name,
+// otherwise its plain name.
+func functionName(entry godwarf.Entry) string {
+	for _, attr := range []dwarf.Attr{dwarf.AttrLinkageName, attrMIPSLinkageName} {
+		if name, ok := entry.Val(attr).(string); ok && name != "" {
+			return name
 		}
 	}
-	return name
+	if name, ok := entry.Val(dwarf.AttrName).(string); ok {
+		return name
+	}
+	return "?"
 }
@@ -0,0 +1,54 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elfutils
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"fmt"
+)
+
+var ErrNoDebugLink = errors.New("object file has no .gnu_debuglink section")
+
+// DebugLink returns the file name and the CRC32 checksum of the separate debug
+// file found in the .gnu_debuglink section of the specified object file.
+func DebugLink(path string) (string, uint32, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open elf: %w", err)
+	}
+	defer f.Close()
+
+	s := f.Section(".gnu_debuglink")
+	if s == nil {
+		return "", 0, ErrNoDebugLink
+	}
+	data, err := s.Data()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read debuglink section: %w", err)
+	}
+
+	// The section contains the null-terminated file name, padded to a
+	// multiple of 4 bytes, followed by the 4-byte checksum.
+	i := bytes.IndexByte(data, 0)
+	if i <= 0 {
+		return "", 0, errors.New("failed to parse debuglink section: invalid file name")
+	}
+	offset := (i + 4) &^ 3
+	if offset+4 > len(data) {
+		return "", 0, errors.New("failed to parse debuglink section: checksum truncated")
+	}
+	return string(data[:i]), f.ByteOrder.Uint32(data[offset : offset+4]), nil
+}
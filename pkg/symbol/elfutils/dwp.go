@@ -0,0 +1,306 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elfutils
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Section identifiers of the columns of a DWARF 5 package index.
+const (
+	dwSectInfo       = 1
+	dwSectAbbrev     = 3
+	dwSectLine       = 4
+	dwSectLoclists   = 5
+	dwSectStrOffsets = 6
+	dwSectMacro      = 7
+	dwSectRnglists   = 8
+)
+
+var dwpSections = map[string]uint32{
+	".debug_info.dwo":        dwSectInfo,
+	".debug_abbrev.dwo":      dwSectAbbrev,
+	".debug_line.dwo":        dwSectLine,
+	".debug_loclists.dwo":    dwSectLoclists,
+	".debug_str_offsets.dwo": dwSectStrOffsets,
+	".debug_macro.dwo":       dwSectMacro,
+	".debug_rnglists.dwo":    dwSectRnglists,
+}
+
+// HasSplitDWARF reports whether the specified object file has skeleton units,
+// whose debug information is split into a DWARF package file (.dwp).
+func HasSplitDWARF(path string) (bool, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open elf: %w", err)
+	}
+	defer f.Close()
+
+	if f.Section(".debug_info") == nil && f.Section(".zdebug_info") == nil {
+		return false, nil
+	}
+	d, err := f.DWARF()
+	if err != nil {
+		return false, fmt.Errorf("failed to read DWARF data: %w", err)
+	}
+
+	r := d.Reader()
+	for {
+		entry, err := r.Next()
+		if err != nil {
+			return false, fmt.Errorf("failed to read DWARF entry: %w", err)
+		}
+		if entry == nil {
+			return false, nil
+		}
+		if entry.Tag == dwarf.TagSkeletonUnit {
+			return true, nil
+		}
+		r.SkipChildren()
+	}
+}
+
+// ValidateDWP returns an error if the specified file is not a DWARF 5 package
+// file.
+func ValidateDWP(path string) error {
+	p, err := openDWP(path)
+	if err != nil {
+		return err
+	}
+	if len(p.units) == 0 {
+		return errors.New("DWARF package file has no units")
+	}
+	return nil
+}
+
+// dwpContribution is the part of a section of a DWARF package file that
+// belongs to a split unit.
+type dwpContribution struct {
+	offset uint32
+	size   uint32
+}
+
+// dwpFile is a DWARF package file, which holds the debug information of the
+// split units of an object file, indexed by their DWO ID.
+type dwpFile struct {
+	order    binary.ByteOrder
+	sections map[uint32][]byte
+	str      []byte
+	units    map[uint64]map[uint32]dwpContribution
+}
+
+func openDWP(path string) (*dwpFile, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open elf: %w", err)
+	}
+	defer f.Close()
+
+	index := f.Section(".debug_cu_index")
+	if index == nil {
+		return nil, errors.New("DWARF package file has no .debug_cu_index section")
+	}
+	data, err := index.Data()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .debug_cu_index section: %w", err)
+	}
+
+	p := &dwpFile{
+		order:    f.ByteOrder,
+		sections: map[uint32][]byte{},
+		units:    map[uint64]map[uint32]dwpContribution{},
+	}
+	for name, id := range dwpSections {
+		s := f.Section(name)
+		if s == nil {
+			continue
+		}
+		if p.sections[id], err = s.Data(); err != nil {
+			return nil, fmt.Errorf("failed to read %s section: %w", name, err)
+		}
+	}
+	if s := f.Section(".debug_str.dwo"); s != nil {
+		if p.str, err = s.Data(); err != nil {
+			return nil, fmt.Errorf("failed to read .debug_str.dwo section: %w", err)
+		}
+	}
+	if err := p.parseIndex(data); err != nil {
+		return nil, fmt.Errorf("failed to parse .debug_cu_index section: %w", err)
+	}
+	return p, nil
+}
+
+// parseIndex parses the unit index of a DWARF 5 package file, as described in
+// section 7.3.5 of the DWARF 5 standard.
+func (p *dwpFile) parseIndex(b []byte) error {
+	if len(b) < 16 {
+		return io.ErrUnexpectedEOF
+	}
+	if version := p.order.Uint16(b); version != 5 {
+		return fmt.Errorf("unsupported version %d", version)
+	}
+	var (
+		columns = uint64(p.order.Uint32(b[4:]))
+		units   = uint64(p.order.Uint32(b[8:]))
+		slots   = uint64(p.order.Uint32(b[12:]))
+
+		signatures = uint64(16)
+		rows       = signatures + slots*8
+		ids        = rows + slots*4
+		offsets    = ids + columns*4
+		sizes      = offsets + units*columns*4
+	)
+	if sizes+units*columns*4 > uint64(len(b)) {
+		return io.ErrUnexpectedEOF
+	}
+
+	for slot := uint64(0); slot < slots; slot++ {
+		row := uint64(p.order.Uint32(b[rows+slot*4:]))
+		if row == 0 {
+			// Empty slot.
+			continue
+		}
+		if row > units {
+			return fmt.Errorf("row %d out of range", row)
+		}
+		contributions := map[uint32]dwpContribution{}
+		for col := uint64(0); col < columns; col++ {
+			cell := ((row-1)*columns + col) * 4
+			contributions[p.order.Uint32(b[ids+col*4:])] = dwpContribution{
+				offset: p.order.Uint32(b[offsets+cell:]),
+				size:   p.order.Uint32(b[sizes+cell:]),
+			}
+		}
+		p.units[p.order.Uint64(b[signatures+slot*8:])] = contributions
+	}
+	return nil
+}
+
+// section returns the contribution of the given split unit to a section.
+func (p *dwpFile) section(contributions map[uint32]dwpContribution, id uint32) []byte {
+	c, ok := contributions[id]
+	if !ok {
+		return nil
+	}
+	s := p.sections[id]
+	if uint64(c.offset)+uint64(c.size) > uint64(len(s)) {
+		return nil
+	}
+	return s[c.offset : c.offset+c.size]
+}
+
+// unitData returns the DWARF data of the split unit with the given DWO ID. The
+// addresses the unit refers to by index are read from addr, the contribution
+// of its skeleton unit to the .debug_addr section of the object file.
+func (p *dwpFile) unitData(dwoID uint64, addr []byte) (*dwarf.Data, error) {
+	contributions, ok := p.units[dwoID]
+	if !ok {
+		return nil, fmt.Errorf("DWARF package file has no unit with DWO ID %#x", dwoID)
+	}
+
+	info := p.section(contributions, dwSectInfo)
+	if info == nil {
+		return nil, fmt.Errorf("DWARF package file has no debug information for DWO ID %#x", dwoID)
+	}
+	d, err := dwarf.New(p.section(contributions, dwSectAbbrev), nil, nil, info, nil, nil, nil, p.str)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split units have no attributes for the bases of their string offsets
+	// and range lists, they implicitly start right after the header of their
+	// contributions.
+	if err := d.AddSection(".debug_str_offsets", skipHeader(p.section(contributions, dwSectStrOffsets), 8)); err != nil {
+		return nil, err
+	}
+	if err := d.AddSection(".debug_rnglists", skipHeader(p.section(contributions, dwSectRnglists), 12)); err != nil {
+		return nil, err
+	}
+	if err := d.AddSection(".debug_addr", addr); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// skipHeader returns the given contribution without its header of the given
+// size in the 32-bit DWARF format. The header is 8 bytes longer in the 64-bit
+// DWARF format.
+func skipHeader(b []byte, size int) []byte {
+	if len(b) >= 4 && b[0] == 0xff && b[1] == 0xff && b[2] == 0xff && b[3] == 0xff {
+		size += 8
+	}
+	if len(b) < size {
+		return nil
+	}
+	return b[size:]
+}
+
+// DWPPath returns the path of the DWARF package file of the object file at the
+// given path.
+func DWPPath(path string) string {
+	return path + ".dwp"
+}
+
+// splitDWARF resolves the skeleton units of an object file to their split
+// units in a DWARF package file.
+type splitDWARF struct {
+	dwp *dwpFile
+
+	// info and addr are the .debug_info and .debug_addr sections of the
+	// object file.
+	info []byte
+	addr []byte
+}
+
+func newSplitDWARF(f *elf.File, dwpPath string) (*splitDWARF, error) {
+	dwp, err := openDWP(dwpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &splitDWARF{dwp: dwp}
+	if sec := f.Section(".debug_info"); sec != nil {
+		if s.info, err = sec.Data(); err != nil {
+			return nil, fmt.Errorf("failed to read .debug_info section: %w", err)
+		}
+	}
+	if sec := f.Section(".debug_addr"); sec != nil {
+		if s.addr, err = sec.Data(); err != nil {
+			return nil, fmt.Errorf("failed to read .debug_addr section: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// unitData returns the DWARF data of the split unit of the given skeleton unit.
+func (s *splitDWARF) unitData(skeleton *dwarf.Entry) (*dwarf.Data, error) {
+	// The DWO ID is the last field of the header of the skeleton unit, right
+	// before its entry.
+	off := int(skeleton.Offset)
+	if off < 8 || off > len(s.info) {
+		return nil, errors.New("skeleton unit out of range")
+	}
+	dwoID := s.dwp.order.Uint64(s.info[off-8 : off])
+
+	addrBase, _ := skeleton.Val(dwarf.AttrAddrBase).(int64)
+	if addrBase < 0 || addrBase > int64(len(s.addr)) {
+		return nil, errors.New("address base of skeleton unit out of range")
+	}
+	return s.dwp.unitData(dwoID, s.addr[addrBase:])
+}
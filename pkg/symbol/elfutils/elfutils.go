@@ -20,9 +20,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"github.com/nanmu42/limitio"
+
+	"github.com/parca-dev/parca/pkg/symbol/pdbutils"
 )
 
 var dwarfSuffix = func(s *elf.Section) string {
@@ -38,8 +41,31 @@ var dwarfSuffix = func(s *elf.Section) string {
 	}
 }
 
+// IsELF reports whether the specified file is an ELF object file.
+func IsELF(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(elf.ELFMAG))
+	if _, err := f.ReadAt(magic, 0); err != nil {
+		return false
+	}
+	return string(magic) == elf.ELFMAG
+}
+
 // HasDWARF reports whether the specified executable or library file contains DWARF debug information.
 func HasDWARF(path string) (bool, error) {
+	if IsMachO(path) {
+		return hasMachODWARF(path)
+	}
+	if pdbutils.IsPDB(path) {
+		// PDB files hold CodeView instead of DWARF debug information.
+		return false, nil
+	}
+
 	f, err := elf.Open(path)
 	if err != nil {
 		return false, fmt.Errorf("failed to open elf: %w", err)
@@ -54,6 +80,24 @@ func HasDWARF(path string) (bool, error) {
 	return len(sections) > 0, nil
 }
 
+// HasDWARFLineInfo reports whether the specified ELF object file contains a
+// DWARF line table, which is needed to resolve addresses to source lines.
+func HasDWARFLineInfo(path string) (bool, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open elf: %w", err)
+	}
+	defer f.Close()
+
+	sections, err := readableDWARFSections(f)
+	if err != nil {
+		return false, fmt.Errorf("failed to read DWARF sections: %w", err)
+	}
+
+	_, ok := sections["line"]
+	return ok, nil
+}
+
 // A simplified and modified version of debug/elf.DWARF().
 func readableDWARFSections(f *elf.File) (map[string]struct{}, error) {
 	// There are many DWARf sections, but these are the ones
@@ -159,8 +203,33 @@ func HasSymbols(path string) (bool, error) {
 	return false, nil
 }
 
+// HasSymtab reports whether the specified ELF object file contains a symbol
+// table, unlike HasSymbols not counting the dynamic symbols.
+func HasSymtab(path string) (bool, error) {
+	ef, err := elf.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open elf: %w", err)
+	}
+	defer ef.Close()
+
+	for _, section := range ef.Sections {
+		if section.Type == elf.SHT_SYMTAB {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // ValidateFile returns an error if the given object file is not valid.
 func ValidateFile(path string) error {
+	if IsMachO(path) {
+		return validateMachOFile(path)
+	}
+	if pdbutils.IsPDB(path) {
+		_, err := pdbutils.PDBBuildID(path)
+		return err
+	}
+
 	elfFile, err := elf.Open(path)
 	if err != nil {
 		return err
@@ -198,6 +267,9 @@ func ValidateHeader(r io.Reader) error {
 	if err != nil {
 		return err
 	}
+	if isMachOMagic(ident[:]) || pdbutils.IsPDBMagic(b) {
+		return nil
+	}
 	if ident[0] != '\x7f' || ident[1] != 'E' || ident[2] != 'L' || ident[3] != 'F' {
 		return fmt.Errorf("invalid magic number, %s", ident[0:4])
 	}
@@ -0,0 +1,231 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elfutils
+
+import (
+	"bufio"
+	"debug/elf"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// headerLayout holds the offsets of the fields of the ELF header and the
+// section headers that are read or rewritten when extracting debug
+// information.
+type headerLayout struct {
+	ehsize   int
+	addrSize int
+
+	phoff, shoff                  int
+	phentsize, phnum              int
+	shentsize, shstrndx           int
+	shType, shOffset, shAddralign int
+}
+
+var (
+	headerLayout32 = headerLayout{
+		ehsize: 52, addrSize: 4,
+		phoff: 0x1c, shoff: 0x20,
+		phentsize: 0x2a, phnum: 0x2c,
+		shentsize: 0x2e, shstrndx: 0x32,
+		shType: 0x04, shOffset: 0x10, shAddralign: 0x20,
+	}
+	headerLayout64 = headerLayout{
+		ehsize: 64, addrSize: 8,
+		phoff: 0x20, shoff: 0x28,
+		phentsize: 0x36, phnum: 0x38,
+		shentsize: 0x3a, shstrndx: 0x3e,
+		shType: 0x04, shOffset: 0x18, shAddralign: 0x30,
+	}
+)
+
+// ExtractDebugInfo writes the parts of the object file at src that are needed
+// for symbolization to dst, similar to `objcopy --only-keep-debug`. These are
+// the DWARF sections, the symbol tables, the notes, the Go symbol tables, and
+// the headers of the ELF file and its program and sections. The contents of
+// all other sections, like code and data, are left out, their headers are
+// kept as sections without content.
+func ExtractDebugInfo(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open object file: %w", err)
+	}
+	defer in.Close()
+
+	return ExtractDebugInfoFrom(dst, in)
+}
+
+// ExtractDebugInfoFrom is like ExtractDebugInfo, but reads the object file
+// from in. Only the headers and the contents of the kept sections are read.
+func ExtractDebugInfoFrom(dst string, in io.ReaderAt) error {
+	f, err := elf.NewFile(in)
+	if err != nil {
+		return fmt.Errorf("failed to open elf: %w", err)
+	}
+
+	layout := headerLayout64
+	if f.Class == elf.ELFCLASS32 {
+		layout = headerLayout32
+	}
+	putAddr := func(b []byte, v uint64) {
+		if layout.addrSize == 4 {
+			f.ByteOrder.PutUint32(b, uint32(v))
+		} else {
+			f.ByteOrder.PutUint64(b, v)
+		}
+	}
+	getAddr := func(b []byte) uint64 {
+		if layout.addrSize == 4 {
+			return uint64(f.ByteOrder.Uint32(b))
+		}
+		return f.ByteOrder.Uint64(b)
+	}
+
+	ehdr := make([]byte, layout.ehsize)
+	if _, err := in.ReadAt(ehdr, 0); err != nil {
+		return fmt.Errorf("failed to read ELF header: %w", err)
+	}
+	var (
+		phoff     = getAddr(ehdr[layout.phoff:])
+		shoff     = getAddr(ehdr[layout.shoff:])
+		phentsize = uint64(f.ByteOrder.Uint16(ehdr[layout.phentsize:]))
+		phnum     = uint64(f.ByteOrder.Uint16(ehdr[layout.phnum:]))
+		shentsize = uint64(f.ByteOrder.Uint16(ehdr[layout.shentsize:]))
+		shstrndx  = int(f.ByteOrder.Uint16(ehdr[layout.shstrndx:]))
+	)
+	if shstrndx == int(elf.SHN_XINDEX) && len(f.Sections) > 0 {
+		shstrndx = int(f.Sections[0].Link)
+	}
+
+	phdrs := make([]byte, phnum*phentsize)
+	if _, err := in.ReadAt(phdrs, int64(phoff)); err != nil {
+		return fmt.Errorf("failed to read program headers: %w", err)
+	}
+	shdrs := make([]byte, uint64(len(f.Sections))*shentsize)
+	if _, err := in.ReadAt(shdrs, int64(shoff)); err != nil {
+		return fmt.Errorf("failed to read section headers: %w", err)
+	}
+
+	keep := keptSections(f, shstrndx)
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create extracted object file: %w", err)
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+
+	// The program headers directly follow the ELF header, the kept sections
+	// follow them, and the section headers come last.
+	off := uint64(layout.ehsize)
+	if phnum > 0 {
+		putAddr(ehdr[layout.phoff:], off)
+		off += uint64(len(phdrs))
+	}
+	if _, err := w.Write(ehdr); err != nil {
+		return err
+	}
+	if _, err := w.Write(phdrs); err != nil {
+		return err
+	}
+
+	for i, s := range f.Sections {
+		if s.Type == elf.SHT_NULL {
+			continue
+		}
+		shdr := shdrs[uint64(i)*shentsize : uint64(i+1)*shentsize]
+		if !keep[i] || s.Type == elf.SHT_NOBITS {
+			f.ByteOrder.PutUint32(shdr[layout.shType:], uint32(elf.SHT_NOBITS))
+			putAddr(shdr[layout.shOffset:], off)
+			continue
+		}
+
+		aligned := align(off, getAddr(shdr[layout.shAddralign:]))
+		if _, err := w.Write(make([]byte, aligned-off)); err != nil {
+			return err
+		}
+		off = aligned
+		putAddr(shdr[layout.shOffset:], off)
+
+		// The raw content is copied, compressed sections stay compressed.
+		n, err := io.Copy(w, io.NewSectionReader(in, int64(s.Offset), int64(s.FileSize)))
+		if err != nil {
+			return fmt.Errorf("failed to copy section %s: %w", s.Name, err)
+		}
+		off += uint64(n)
+	}
+
+	aligned := align(off, uint64(layout.addrSize))
+	if _, err := w.Write(make([]byte, aligned-off)); err != nil {
+		return err
+	}
+	if _, err := w.Write(shdrs); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Point the ELF header to the moved section headers.
+	putAddr(ehdr[layout.shoff:], aligned)
+	if _, err := out.WriteAt(ehdr, 0); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// keptSections returns the indexes of the sections whose contents are needed
+// for symbolization.
+func keptSections(f *elf.File, shstrndx int) map[int]bool {
+	keep := map[int]bool{shstrndx: true}
+	for i, s := range f.Sections {
+		switch {
+		case dwarfSuffix(s) != "",
+			s.Type == elf.SHT_NOTE,
+			s.Name == ".gnu_debuglink",
+			s.Name == ".gopclntab",
+			s.Name == ".gosymtab":
+			keep[i] = true
+		case s.Type == elf.SHT_SYMTAB, s.Type == elf.SHT_DYNSYM:
+			// Including the string table of the symbols.
+			keep[i] = true
+			keep[int(s.Link)] = true
+		case strings.HasPrefix(s.Name, ".rela.debug_"), strings.HasPrefix(s.Name, ".rel.debug_"):
+			// Relocations of the DWARF sections of relocatable files.
+			keep[i] = true
+		}
+	}
+
+	// The inline trees of Go binaries are part of the go:func.* symbol.
+	if f.Section(".gopclntab") != nil {
+		if syms, err := f.Symbols(); err == nil {
+			for _, sym := range syms {
+				if sym.Name == "go:func.*" || sym.Name == "go.func.*" {
+					keep[int(sym.Section)] = true
+					break
+				}
+			}
+		}
+	}
+	return keep
+}
+
+func align(off, alignment uint64) uint64 {
+	if alignment <= 1 {
+		return off
+	}
+	return (off + alignment - 1) / alignment * alignment
+}
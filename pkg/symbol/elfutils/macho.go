@@ -0,0 +1,159 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elfutils
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mach-O object files of macOS and iOS are identified by the UUID of their
+// LC_UUID load command instead of a GNU build ID. Their DWARF debug
+// information is usually kept in a separate Mach-O file of a dSYM bundle.
+
+const loadCmdUUID macho.LoadCmd = 0x1b
+
+// isMachOMagic reports whether b starts with the magic number of a 32-bit or
+// 64-bit Mach-O object file of either byte order.
+func isMachOMagic(b []byte) bool {
+	if len(b) < 4 {
+		return false
+	}
+	for _, magic := range []uint32{macho.Magic32, macho.Magic64} {
+		if binary.LittleEndian.Uint32(b) == magic || binary.BigEndian.Uint32(b) == magic {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMachO reports whether the specified file is a Mach-O object file.
+func IsMachO(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if _, err := f.ReadAt(magic, 0); err != nil {
+		return false
+	}
+	return isMachOMagic(magic)
+}
+
+// MachOUUID returns the hex encoded UUID of the specified Mach-O object file.
+func MachOUUID(path string) (string, error) {
+	f, err := macho.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open macho: %w", err)
+	}
+	defer f.Close()
+
+	for _, l := range f.Loads {
+		raw := l.Raw()
+		if len(raw) < 24 || macho.LoadCmd(f.ByteOrder.Uint32(raw)) != loadCmdUUID {
+			continue
+		}
+		return hex.EncodeToString(raw[8:24]), nil
+	}
+	return "", ErrNoBuildID
+}
+
+// DSYMPath returns the path of the Mach-O file with the DWARF debug
+// information of the dSYM bundle at the given path, which is the only entry of
+// its Contents/Resources/DWARF directory. Paths of files are returned as is.
+func DSYMPath(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return path, nil
+	}
+
+	dir := filepath.Join(path, "Contents", "Resources", "DWARF")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read dSYM bundle: %w", err)
+	}
+	if len(entries) != 1 {
+		return "", fmt.Errorf("dSYM bundle has %d DWARF files, expected 1", len(entries))
+	}
+	return filepath.Join(dir, entries[0].Name()), nil
+}
+
+// hasMachODWARF reports whether the Mach-O object file has DWARF sections
+// with content.
+func hasMachODWARF(path string) (bool, error) {
+	f, err := macho.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open macho: %w", err)
+	}
+	defer f.Close()
+
+	for _, s := range f.Sections {
+		if s.Seg == "__DWARF" && s.Size > 0 && (strings.HasPrefix(s.Name, "__debug_info") || strings.HasPrefix(s.Name, "__zdebug_info")) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// readMachOExecInfo reads the ExecInfo of the specified Mach-O object file.
+// Mach-O images are slid to arbitrary addresses, so they are treated like
+// position-independent ELF files, with the __TEXT segment taking the place of
+// the executable load segment.
+func readMachOExecInfo(path string) (*ExecInfo, error) {
+	f, err := macho.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open macho: %w", err)
+	}
+	defer f.Close()
+
+	info := &ExecInfo{Type: elf.ET_DYN}
+	if text := f.Segment("__TEXT"); text != nil {
+		info.TextSegment = &elf.ProgHeader{
+			Type:   elf.PT_LOAD,
+			Flags:  elf.PF_R | elf.PF_X,
+			Off:    text.Offset,
+			Vaddr:  text.Addr,
+			Filesz: text.Filesz,
+			Memsz:  text.Memsz,
+		}
+	}
+	return info, nil
+}
+
+// validateMachOFile returns an error if the given Mach-O object file is not
+// valid.
+func validateMachOFile(path string) error {
+	f, err := macho.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if len(f.Sections) == 0 {
+		return errors.New("Mach-O file does not have any sections")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elfutils
+
+import (
+	"debug/dwarf"
+	"sync"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// BufferPool holds the buffers that the look up tables of compile units are
+// collected in while their DWARF data is read. The tables are copied out of
+// the buffers once complete, so that the buffers grown for large compile
+// units are reused instead of growing new ones for every compile unit of
+// every object file. It is safe for concurrent use, and a nil *BufferPool
+// doesn't reuse anything.
+type BufferPool struct {
+	lineEntries sync.Pool
+	subprograms sync.Pool
+}
+
+// NewBufferPool creates a new BufferPool.
+func NewBufferPool() *BufferPool {
+	return &BufferPool{
+		lineEntries: sync.Pool{New: func() interface{} {
+			buf := make([]dwarf.LineEntry, 0, 1024)
+			return &buf
+		}},
+		subprograms: sync.Pool{New: func() interface{} {
+			buf := make([]*godwarf.Tree, 0, 256)
+			return &buf
+		}},
+	}
+}
+
+// getLineEntries returns an empty buffer to collect line entries in.
+func (p *BufferPool) getLineEntries() []dwarf.LineEntry {
+	if p == nil {
+		return []dwarf.LineEntry{}
+	}
+	return (*p.lineEntries.Get().(*[]dwarf.LineEntry))[:0]
+}
+
+// keepLineEntries returns a copy of the line entries collected in the buffer,
+// and puts the buffer back.
+func (p *BufferPool) keepLineEntries(buf []dwarf.LineEntry) []dwarf.LineEntry {
+	if p == nil {
+		return buf
+	}
+	entries := make([]dwarf.LineEntry, len(buf))
+	copy(entries, buf)
+	p.putLineEntries(buf)
+	return entries
+}
+
+// putLineEntries puts the buffer back, without the entries, which refer to
+// the files of the line table they were read from.
+func (p *BufferPool) putLineEntries(buf []dwarf.LineEntry) {
+	if p == nil {
+		return
+	}
+	for i := range buf {
+		buf[i] = dwarf.LineEntry{}
+	}
+	buf = buf[:0]
+	p.lineEntries.Put(&buf)
+}
+
+// getSubprograms returns an empty buffer to collect subprograms in.
+func (p *BufferPool) getSubprograms() []*godwarf.Tree {
+	if p == nil {
+		return []*godwarf.Tree{}
+	}
+	return (*p.subprograms.Get().(*[]*godwarf.Tree))[:0]
+}
+
+// keepSubprograms returns a copy of the subprograms collected in the buffer,
+// and puts the buffer back.
+func (p *BufferPool) keepSubprograms(buf []*godwarf.Tree) []*godwarf.Tree {
+	if p == nil {
+		return buf
+	}
+	subprograms := make([]*godwarf.Tree, len(buf))
+	copy(subprograms, buf)
+	p.putSubprograms(buf)
+	return subprograms
+}
+
+// putSubprograms puts the buffer back, without the subprograms.
+func (p *BufferPool) putSubprograms(buf []*godwarf.Tree) {
+	if p == nil {
+		return
+	}
+	for i := range buf {
+		buf[i] = nil
+	}
+	buf = buf[:0]
+	p.subprograms.Put(&buf)
+}
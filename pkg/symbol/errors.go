@@ -0,0 +1,93 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symbol
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DebugInfoError reports that the debug information of an object file can't
+// be used to symbolize any of its addresses, e.g. because it isn't uploaded,
+// or because its DWARF data is corrupt.
+type DebugInfoError struct {
+	BuildID string
+	Err     error
+}
+
+func (e *DebugInfoError) Error() string {
+	return fmt.Sprintf("build ID %q: %v", e.BuildID, e.Err)
+}
+
+func (e *DebugInfoError) Unwrap() error {
+	return e.Err
+}
+
+// AddressError reports an address of an object file that couldn't be
+// symbolized, e.g. because the debug information has no lines for it.
+type AddressError struct {
+	BuildID string
+	Address uint64
+	Err     error
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("address %#x of build ID %q: %v", e.Address, e.BuildID, e.Err)
+}
+
+func (e *AddressError) Unwrap() error {
+	return e.Err
+}
+
+// AddressErrors are the errors of the addresses of an object file that
+// couldn't be symbolized, while others could be.
+type AddressErrors []*AddressError
+
+func (e AddressErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more addresses)", e[0].Error(), len(e)-1)
+}
+
+// Is reports whether any of the address errors matches the target. The
+// errors are matched one by one, as errors.Is only unwraps multiple errors
+// since Go 1.20.
+func (e AddressErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the address errors that matches the target, and if
+// so, sets the target to it.
+func (e AddressErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// err returns nil if there are no errors.
+func (e AddressErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
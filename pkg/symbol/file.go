@@ -0,0 +1,169 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symbol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-kit/log"
+	pprofprofile "github.com/google/pprof/profile"
+	"github.com/prometheus/client_golang/prometheus"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/symbol/elfutils"
+)
+
+// SymbolizeFile symbolizes the pprof profile at profilePath with the ELF or
+// Mach-O file, or the dSYM bundle, at objPath, and writes the symbolized
+// profile to outPath. Only the mappings with the build ID of the object file
+// are symbolized, or the main mapping if the object file has no build ID.
+// Locations that are already symbolized are left as they are.
+func SymbolizeFile(ctx context.Context, profilePath, objPath, outPath string) error {
+	p, err := readProfile(profilePath)
+	if err != nil {
+		return err
+	}
+
+	objPath, err = elfutils.DSYMPath(objPath)
+	if err != nil {
+		return err
+	}
+	if err := elfutils.ValidateFile(objPath); err != nil {
+		return fmt.Errorf("invalid object file: %w", err)
+	}
+	buildID, err := elfutils.BuildID(objPath)
+	if err != nil {
+		buildID = ""
+	}
+
+	sym, err := NewSymbolizer(log.NewNopLogger(), prometheus.NewRegistry())
+	if err != nil {
+		return err
+	}
+	defer sym.Close()
+
+	debugInfoFile := func(context.Context) (string, error) {
+		return objPath, nil
+	}
+	functions := newProfileFunctions(p)
+	for i, m := range p.Mapping {
+		if buildID != "" && m.BuildID != buildID || buildID == "" && i != 0 {
+			continue
+		}
+
+		var locations []*pprofprofile.Location
+		for _, loc := range p.Location {
+			if loc.Mapping == m && len(loc.Line) == 0 {
+				locations = append(locations, loc)
+			}
+		}
+		if len(locations) == 0 {
+			continue
+		}
+
+		pbLocations := make([]*pb.Location, 0, len(locations))
+		for _, loc := range locations {
+			pbLocations = append(pbLocations, &pb.Location{Address: loc.Address})
+		}
+		// The build ID of the mapping is only used to cache the liner, the
+		// object file is the same for all of them.
+		locationsLines, err := sym.Symbolize(ctx, &pb.Mapping{
+			Start:   m.Start,
+			Limit:   m.Limit,
+			Offset:  m.Offset,
+			File:    m.File,
+			BuildId: objPath,
+		}, pbLocations, debugInfoFile)
+		var addrErrs AddressErrors
+		if err != nil && !errors.As(err, &addrErrs) {
+			return fmt.Errorf("symbolize mapping %q: %w", m.File, err)
+		}
+
+		for i, lines := range locationsLines {
+			for _, line := range lines {
+				locations[i].Line = append(locations[i].Line, pprofprofile.Line{
+					Function: functions.get(line.Function),
+					Line:     line.Line,
+				})
+			}
+		}
+		m.HasFunctions = true
+		m.HasFilenames = true
+		m.HasLineNumbers = true
+		m.HasInlineFrames = true
+	}
+
+	return writeProfile(outPath, p)
+}
+
+func readProfile(path string) (*pprofprofile.Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open profile: %w", err)
+	}
+	defer f.Close()
+
+	p, err := pprofprofile.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse profile: %w", err)
+	}
+	return p, nil
+}
+
+func writeProfile(path string, p *pprofprofile.Profile) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create symbolized profile: %w", err)
+	}
+	if err := p.Write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("write symbolized profile: %w", err)
+	}
+	return f.Close()
+}
+
+// profileFunctions adds the functions of resolved lines to a profile, each
+// distinct function only once.
+type profileFunctions struct {
+	p         *pprofprofile.Profile
+	functions map[pprofprofile.Function]*pprofprofile.Function
+}
+
+func newProfileFunctions(p *pprofprofile.Profile) *profileFunctions {
+	return &profileFunctions{
+		p:         p,
+		functions: map[pprofprofile.Function]*pprofprofile.Function{},
+	}
+}
+
+func (f *profileFunctions) get(fn *pb.Function) *pprofprofile.Function {
+	key := pprofprofile.Function{
+		Name:       fn.Name,
+		SystemName: fn.SystemName,
+		Filename:   fn.Filename,
+		StartLine:  fn.StartLine,
+	}
+	if existing, ok := f.functions[key]; ok {
+		return existing
+	}
+
+	function := key
+	function.ID = uint64(len(f.p.Function) + 1)
+	f.p.Function = append(f.p.Function, &function)
+	f.functions[key] = &function
+	return &function
+}
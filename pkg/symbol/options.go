@@ -16,9 +16,8 @@ package symbol
 import (
 	"time"
 
-	"github.com/goburrow/cache"
-
 	"github.com/parca-dev/parca/pkg/symbol/demangle"
+	"github.com/parca-dev/parca/pkg/symbol/elfutils"
 )
 
 type Option func(*Symbolizer)
@@ -31,18 +30,73 @@ func WithAttemptThreshold(t int) Option {
 
 func WithDemangleMode(mode string) Option {
 	return func(s *Symbolizer) {
-		s.demangler = demangle.NewDemangler(mode, false)
+		s.demangleMode = mode
 	}
 }
 
+// WithDemangleSchemes sets the schemes tried in turn to demangle symbol names.
+// Names that none of them recognizes are kept as they are.
+func WithDemangleSchemes(schemes ...demangle.Scheme) Option {
+	return func(s *Symbolizer) {
+		s.demangleSchemes = schemes
+	}
+}
+
+// WithPathRewrites sets the rewrites of the source file paths of the
+// resolved functions. The first rewrite matching a path is applied.
+func WithPathRewrites(rewrites ...PathRewrite) Option {
+	return func(s *Symbolizer) {
+		s.pathRewrites = rewrites
+	}
+}
+
+// WithCacheSize sets the maximum number of liners kept in the cache.
 func WithCacheSize(size int) Option {
 	return func(s *Symbolizer) {
-		s.cacheOpts = append(s.cacheOpts, cache.WithMaximumSize(size))
+		s.cacheSize = size
+	}
+}
+
+// WithCacheMaxBytes sets the maximum accumulated size of the debug information
+// files backing the cached liners. Zero means unlimited.
+func WithCacheMaxBytes(n int64) Option {
+	return func(s *Symbolizer) {
+		s.cacheMaxBytes = n
+	}
+}
+
+// WithLinesCacheSize sets the maximum number of addresses whose resolved lines
+// are kept in the cache. Zero disables the cache.
+func WithLinesCacheSize(size int) Option {
+	return func(s *Symbolizer) {
+		s.linesCacheSize = size
+	}
+}
+
+// WithDWARFBufferPool sets whether the buffers that DWARF data is read with
+// are reused across object files. It is enabled by default.
+func WithDWARFBufferPool(enabled bool) Option {
+	return func(s *Symbolizer) {
+		if enabled {
+			s.dwarfBufferPool = elfutils.NewBufferPool()
+		} else {
+			s.dwarfBufferPool = nil
+		}
 	}
 }
 
 func WithCacheItemTTL(ttl time.Duration) Option {
 	return func(s *Symbolizer) {
-		s.cacheOpts = append(s.cacheOpts, cache.WithExpireAfterAccess(ttl))
+		s.cacheItemTTL = ttl
+	}
+}
+
+// WithParseTimeout sets the maximum duration of reading the debug information
+// of an object file, both to open it and to resolve the addresses of a batch
+// of locations. Object files that time out are not read again. Zero means no
+// timeout.
+func WithParseTimeout(timeout time.Duration) Option {
+	return func(s *Symbolizer) {
+		s.parseTimeout = timeout
 	}
 }
@@ -0,0 +1,76 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symbol
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/profile"
+)
+
+// PathRewrite replaces the prefix From of source file paths with To, e.g. to
+// map the paths of the machine an object file was built on to the ones of a
+// checkout of its sources.
+type PathRewrite struct {
+	From string
+	To   string
+}
+
+// ParsePathRewrites parses path rewrites in the form "from=to".
+func ParsePathRewrites(rules []string) ([]PathRewrite, error) {
+	rewrites := make([]PathRewrite, 0, len(rules))
+	for _, rule := range rules {
+		from, to, ok := strings.Cut(rule, "=")
+		if !ok || from == "" {
+			return nil, fmt.Errorf("invalid path rewrite %q, expected from=to", rule)
+		}
+		rewrites = append(rewrites, PathRewrite{From: from, To: to})
+	}
+	return rewrites, nil
+}
+
+// rewritePath applies the first rewrite whose prefix matches the path.
+func rewritePath(rewrites []PathRewrite, path string) (string, bool) {
+	for _, r := range rewrites {
+		if strings.HasPrefix(path, r.From) {
+			return r.To + strings.TrimPrefix(path, r.From), true
+		}
+	}
+	return path, false
+}
+
+// rewritePaths returns the lines with the file paths of their functions
+// rewritten. Liners can hand out the same functions repeatedly, so the
+// rewritten ones are copies.
+func (s *Symbolizer) rewritePaths(lines []profile.LocationLine) []profile.LocationLine {
+	if len(s.pathRewrites) == 0 {
+		return lines
+	}
+	rewritten := make([]profile.LocationLine, 0, len(lines))
+	for _, line := range lines {
+		if line.Function != nil {
+			if path, ok := rewritePath(s.pathRewrites, line.Function.Filename); ok {
+				fn := proto.Clone(line.Function).(*pb.Function)
+				fn.Filename = path
+				line.Function = fn
+			}
+		}
+		rewritten = append(rewritten, line)
+	}
+	return rewritten
+}
@@ -0,0 +1,203 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdbutils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// msfMagic starts every PDB file, which is a multi-stream file (MSF)
+// containing numbered streams split into blocks.
+var msfMagic = []byte("Microsoft C/C++ MSF 7.00\r\n\x1aDS\x00\x00\x00")
+
+// nilStreamSize is the size of streams that don't exist.
+const nilStreamSize = 0xffffffff
+
+// msf reads the streams of a multi-stream file.
+type msf struct {
+	r         io.ReaderAt
+	blockSize uint32
+	// streams holds the blocks of every stream, and sizes their sizes.
+	streams [][]uint32
+	sizes   []uint32
+}
+
+func newMSF(r io.ReaderAt) (*msf, error) {
+	sb := make([]byte, len(msfMagic)+24)
+	if _, err := r.ReadAt(sb, 0); err != nil {
+		return nil, fmt.Errorf("read superblock: %w", err)
+	}
+	if !bytes.Equal(sb[:len(msfMagic)], msfMagic) {
+		return nil, errors.New("invalid MSF magic")
+	}
+	var (
+		fields       = sb[len(msfMagic):]
+		blockSize    = binary.LittleEndian.Uint32(fields[0:])
+		numBlocks    = binary.LittleEndian.Uint32(fields[8:])
+		dirBytes     = binary.LittleEndian.Uint32(fields[12:])
+		blockMapAddr = binary.LittleEndian.Uint32(fields[20:])
+	)
+	switch blockSize {
+	case 512, 1024, 2048, 4096:
+	default:
+		return nil, fmt.Errorf("invalid MSF block size %d", blockSize)
+	}
+	if blockMapAddr >= numBlocks {
+		return nil, fmt.Errorf("invalid MSF block map address %d", blockMapAddr)
+	}
+
+	m := &msf{r: r, blockSize: blockSize}
+
+	// The block map lists the blocks of the stream directory.
+	dirBlocks := make([]uint32, m.blockCount(dirBytes))
+	blockMap := make([]byte, 4*len(dirBlocks))
+	if _, err := r.ReadAt(blockMap, int64(blockMapAddr)*int64(blockSize)); err != nil {
+		return nil, fmt.Errorf("read block map: %w", err)
+	}
+	for i := range dirBlocks {
+		dirBlocks[i] = binary.LittleEndian.Uint32(blockMap[4*i:])
+	}
+	dir, err := m.read(dirBlocks, dirBytes)
+	if err != nil {
+		return nil, fmt.Errorf("read stream directory: %w", err)
+	}
+
+	d := newReader(dir)
+	numStreams := d.u32()
+	m.sizes = make([]uint32, numStreams)
+	for i := range m.sizes {
+		m.sizes[i] = d.u32()
+	}
+	m.streams = make([][]uint32, numStreams)
+	for i, size := range m.sizes {
+		if size == nilStreamSize {
+			continue
+		}
+		blocks := make([]uint32, m.blockCount(size))
+		for j := range blocks {
+			blocks[j] = d.u32()
+		}
+		m.streams[i] = blocks
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("read stream directory: %w", d.err)
+	}
+	return m, nil
+}
+
+func (m *msf) blockCount(size uint32) uint32 {
+	return (size + m.blockSize - 1) / m.blockSize
+}
+
+// stream returns the content of the stream with the given index.
+func (m *msf) stream(i int) ([]byte, error) {
+	if i < 0 || i >= len(m.streams) {
+		return nil, fmt.Errorf("stream %d does not exist", i)
+	}
+	if m.sizes[i] == nilStreamSize {
+		return nil, nil
+	}
+	return m.read(m.streams[i], m.sizes[i])
+}
+
+func (m *msf) read(blocks []uint32, size uint32) ([]byte, error) {
+	b := make([]byte, size)
+	for i, block := range blocks {
+		off := uint32(i) * m.blockSize
+		end := off + m.blockSize
+		if end > size {
+			end = size
+		}
+		if _, err := m.r.ReadAt(b[off:end], int64(block)*int64(m.blockSize)); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// reader reads little-endian values from a byte slice. Reading past its end
+// sets err and returns zero values.
+type reader struct {
+	b   []byte
+	off int
+	err error
+}
+
+func newReader(b []byte) *reader {
+	return &reader{b: b}
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.off+n > len(r.b) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.b[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *reader) u8() uint8 {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) u16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) u32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// cstring reads a zero-terminated string.
+func (r *reader) cstring() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.b[r.off:], 0)
+	if i < 0 {
+		r.err = io.ErrUnexpectedEOF
+		return ""
+	}
+	s := string(r.b[r.off : r.off+i])
+	r.off += i + 1
+	return s
+}
+
+// align skips to the next offset that is a multiple of n.
+func (r *reader) align(n int) {
+	if rem := r.off % n; rem != 0 {
+		r.next(n - rem)
+	}
+}
+
+func (r *reader) len() int {
+	return len(r.b) - r.off
+}
@@ -0,0 +1,517 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pdbutils reads the program database (PDB) files with the debug
+// information of Windows PE executables and libraries.
+package pdbutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/profile"
+)
+
+const (
+	streamPDB   = 1
+	streamDBI   = 3
+	noStream    = 0xffff
+	namesMagic  = 0xeffeeffe
+	dbiHdrSize  = 64
+	modInfoSize = 64
+
+	// The index of the section headers stream in the optional debug header
+	// of the DBI stream.
+	dbgHeaderSectionHdr = 5
+
+	symGProc32   = 0x1110
+	symLProc32   = 0x110f
+	symGProc32ID = 0x1147
+	symLProc32ID = 0x1146
+
+	debugSLines         = 0xf2
+	debugSFileChecksums = 0xf4
+	linesHaveColumns    = 0x1
+)
+
+// File is a PDB file.
+type File struct {
+	guid [16]byte
+	age  uint32
+
+	msf *msf
+	// names is the /names string table the file names refer to.
+	names []byte
+	// sections are the virtual addresses of the sections of the image,
+	// which the segments of symbols and lines refer to.
+	sections []uint32
+	modules  []*module
+	// functions are sorted by their relative virtual address.
+	functions []function
+
+	// mtx guards the lazily read lines of the modules.
+	mtx sync.Mutex
+}
+
+type module struct {
+	stream     uint16
+	symSize    uint32
+	c11Size    uint32
+	c13Size    uint32
+	linesRead  bool
+	lineBlocks []lineBlock
+}
+
+type function struct {
+	name   string
+	rva    uint32
+	size   uint32
+	module int
+}
+
+// lineBlock holds the lines of a contiguous range of code of a file.
+type lineBlock struct {
+	file  string
+	rva   uint32
+	size  uint32
+	lines []line
+}
+
+type line struct {
+	rva  uint32
+	line uint32
+}
+
+// IsPDB reports whether the specified file is a PDB file.
+func IsPDB(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(msfMagic))
+	if _, err := f.ReadAt(magic, 0); err != nil {
+		return false
+	}
+	return IsPDBMagic(magic)
+}
+
+// IsPDBMagic reports whether b starts with the magic bytes of a PDB file.
+func IsPDBMagic(b []byte) bool {
+	return bytes.HasPrefix(b, msfMagic)
+}
+
+// Open reads the PDB file at the given path. The procedure symbols of all
+// modules are read right away, their lines only once they are needed.
+func Open(path string) (*File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMSF(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{msf: m}
+	if err := f.readPDBStream(); err != nil {
+		return nil, fmt.Errorf("read PDB stream: %w", err)
+	}
+	if err := f.readDBIStream(); err != nil {
+		return nil, fmt.Errorf("read DBI stream: %w", err)
+	}
+	for i := range f.modules {
+		if err := f.readFunctions(i); err != nil {
+			return nil, fmt.Errorf("read symbols of module %d: %w", i, err)
+		}
+	}
+	sort.Slice(f.functions, func(i, j int) bool { return f.functions[i].rva < f.functions[j].rva })
+	return f, nil
+}
+
+// BuildID returns the build ID of the PE image the PDB file belongs to.
+func (f *File) BuildID() string {
+	return buildID(f.guid, f.age)
+}
+
+// PDBBuildID returns the build ID of the PE image the specified PDB file
+// belongs to, without reading its symbols.
+func PDBBuildID(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	m, err := newMSF(f)
+	if err != nil {
+		return "", err
+	}
+	pdb := &File{msf: m}
+	if err := pdb.readPDBStream(); err != nil {
+		return "", fmt.Errorf("read PDB stream: %w", err)
+	}
+	dbi, err := m.stream(streamDBI)
+	if err != nil {
+		return "", fmt.Errorf("read DBI stream: %w", err)
+	}
+	if len(dbi) < dbiHdrSize {
+		return "", errors.New("DBI stream truncated")
+	}
+	pdb.age = binary.LittleEndian.Uint32(dbi[8:])
+	return pdb.BuildID(), nil
+}
+
+// buildID returns the hex encoded GUID of a PE image and its PDB file, as
+// used by symbol servers, followed by the hex encoded age.
+func buildID(guid [16]byte, age uint32) string {
+	return fmt.Sprintf("%08x%04x%04x%x%08x",
+		binary.LittleEndian.Uint32(guid[0:4]),
+		binary.LittleEndian.Uint16(guid[4:6]),
+		binary.LittleEndian.Uint16(guid[6:8]),
+		guid[8:16],
+		age,
+	)
+}
+
+func (f *File) readPDBStream() error {
+	b, err := f.msf.stream(streamPDB)
+	if err != nil {
+		return err
+	}
+	r := newReader(b)
+	r.u32() // Version.
+	r.u32() // Signature.
+	f.age = r.u32()
+	copy(f.guid[:], r.next(16))
+	names, err := namedStream(r, "/names")
+	if err != nil {
+		return err
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	if names == noStream {
+		return nil
+	}
+	b, err = f.msf.stream(int(names))
+	if err != nil {
+		return fmt.Errorf("read /names stream: %w", err)
+	}
+	r = newReader(b)
+	if r.u32() != namesMagic {
+		return errors.New("invalid /names stream")
+	}
+	r.u32() // Hash version.
+	f.names = r.next(int(r.u32()))
+	return r.err
+}
+
+// namedStream returns the index of the stream with the given name from the
+// named stream map read from r, or noStream if there is none.
+func namedStream(r *reader, name string) (uint16, error) {
+	strs := r.next(int(r.u32()))
+	r.u32() // Size.
+	capacity := r.u32()
+	present := bitVector(r)
+	bitVector(r) // Deleted.
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	for i := uint32(0); i < capacity; i++ {
+		if i/32 >= uint32(len(present)) || present[i/32]&(1<<(i%32)) == 0 {
+			continue
+		}
+		key, value := r.u32(), r.u32()
+		if r.err != nil {
+			return 0, r.err
+		}
+		if int(key) >= len(strs) {
+			continue
+		}
+		end := bytes.IndexByte(strs[key:], 0)
+		if end >= 0 && string(strs[key:int(key)+end]) == name {
+			return uint16(value), nil
+		}
+	}
+	return noStream, nil
+}
+
+func bitVector(r *reader) []uint32 {
+	words := make([]uint32, r.u32())
+	for i := range words {
+		words[i] = r.u32()
+	}
+	return words
+}
+
+func (f *File) readDBIStream() error {
+	b, err := f.msf.stream(streamDBI)
+	if err != nil {
+		return err
+	}
+	r := newReader(b)
+	hdr := r.next(dbiHdrSize)
+	if r.err != nil {
+		return r.err
+	}
+	// The age of the DBI stream is the one stored in the PE image.
+	f.age = binary.LittleEndian.Uint32(hdr[8:])
+	var (
+		modInfo        = int(binary.LittleEndian.Uint32(hdr[24:]))
+		secContrib     = int(binary.LittleEndian.Uint32(hdr[28:]))
+		secMap         = int(binary.LittleEndian.Uint32(hdr[32:]))
+		sourceInfo     = int(binary.LittleEndian.Uint32(hdr[36:]))
+		typeServerMap  = int(binary.LittleEndian.Uint32(hdr[40:]))
+		optionalDbgHdr = int(binary.LittleEndian.Uint32(hdr[48:]))
+		ec             = int(binary.LittleEndian.Uint32(hdr[52:]))
+	)
+
+	mods := newReader(r.next(modInfo))
+	for mods.len() >= modInfoSize {
+		mi := mods.next(modInfoSize)
+		mods.cstring() // Module name.
+		mods.cstring() // Object file name.
+		mods.align(4)
+		if mods.err != nil {
+			return fmt.Errorf("read module info: %w", mods.err)
+		}
+		f.modules = append(f.modules, &module{
+			stream:  binary.LittleEndian.Uint16(mi[34:]),
+			symSize: binary.LittleEndian.Uint32(mi[36:]),
+			c11Size: binary.LittleEndian.Uint32(mi[40:]),
+			c13Size: binary.LittleEndian.Uint32(mi[44:]),
+		})
+	}
+
+	r.next(secContrib + secMap + sourceInfo + typeServerMap + ec)
+	dbg := newReader(r.next(optionalDbgHdr))
+	if r.err != nil {
+		return r.err
+	}
+	sectionHdr := uint16(noStream)
+	if optionalDbgHdr >= 2*(dbgHeaderSectionHdr+1) {
+		dbg.next(2 * dbgHeaderSectionHdr)
+		sectionHdr = dbg.u16()
+	}
+	if sectionHdr == noStream {
+		return errors.New("no section headers")
+	}
+
+	b, err = f.msf.stream(int(sectionHdr))
+	if err != nil {
+		return fmt.Errorf("read section headers: %w", err)
+	}
+	// The section headers are the IMAGE_SECTION_HEADER structures of the
+	// image, the virtual address is at offset 12.
+	const sectionHeaderSize = 40
+	for off := 0; off+sectionHeaderSize <= len(b); off += sectionHeaderSize {
+		f.sections = append(f.sections, binary.LittleEndian.Uint32(b[off+12:]))
+	}
+	return nil
+}
+
+// rva returns the relative virtual address of the given offset into the
+// section with the given 1-based index.
+func (f *File) rva(segment uint16, offset uint32) (uint32, bool) {
+	if segment == 0 || int(segment) > len(f.sections) {
+		return 0, false
+	}
+	return f.sections[segment-1] + offset, true
+}
+
+// moduleStream returns the symbols and the C13 debug subsections of the module
+// with the given index.
+func (f *File) moduleStream(i int) ([]byte, []byte, error) {
+	mod := f.modules[i]
+	if mod.stream == noStream {
+		return nil, nil, nil
+	}
+	b, err := f.msf.stream(int(mod.stream))
+	if err != nil {
+		return nil, nil, err
+	}
+	c13 := uint64(mod.symSize) + uint64(mod.c11Size)
+	if c13+uint64(mod.c13Size) > uint64(len(b)) || mod.symSize < 4 {
+		return nil, nil, errors.New("module stream truncated")
+	}
+	// The symbols start with a signature. The C11 lines following them are
+	// not written by recent toolchains.
+	return b[4:mod.symSize], b[c13 : c13+uint64(mod.c13Size)], nil
+}
+
+func (f *File) readFunctions(i int) error {
+	syms, _, err := f.moduleStream(i)
+	if err != nil {
+		return err
+	}
+	r := newReader(syms)
+	for r.len() >= 4 {
+		rec := newReader(r.next(int(r.u16())))
+		switch rec.u16() {
+		case symGProc32, symLProc32, symGProc32ID, symLProc32ID:
+			rec.next(12) // Parent, end and next.
+			size := rec.u32()
+			rec.next(12) // Debug start, debug end and type.
+			offset := rec.u32()
+			segment := rec.u16()
+			rec.u8() // Flags.
+			name := rec.cstring()
+			if rec.err != nil {
+				return fmt.Errorf("read procedure symbol: %w", rec.err)
+			}
+			if rva, ok := f.rva(segment, offset); ok {
+				f.functions = append(f.functions, function{name: name, rva: rva, size: size, module: i})
+			}
+		}
+	}
+	return r.err
+}
+
+func (f *File) readLines(i int) error {
+	_, c13, err := f.moduleStream(i)
+	if err != nil {
+		return err
+	}
+
+	// The line blocks refer to the files by their offset in the file
+	// checksums subsection.
+	var checksums []byte
+	var linesSubsections [][]byte
+	r := newReader(c13)
+	for r.len() >= 8 {
+		kind, size := r.u32(), r.u32()
+		data := r.next(int(size))
+		r.align(4)
+		switch kind {
+		case debugSFileChecksums:
+			checksums = data
+		case debugSLines:
+			linesSubsections = append(linesSubsections, data)
+		}
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	var blocks []lineBlock
+	for _, data := range linesSubsections {
+		r := newReader(data)
+		offset := r.u32()
+		segment := r.u16()
+		flags := r.u16()
+		codeSize := r.u32()
+		start, ok := f.rva(segment, offset)
+		for r.len() >= 12 {
+			fileOffset, numLines, blockSize := r.u32(), r.u32(), r.u32()
+			block := newReader(r.next(int(blockSize) - 12))
+			if !ok {
+				continue
+			}
+			lb := lineBlock{file: f.checksumFile(checksums, fileOffset), rva: start, size: codeSize}
+			for j := uint32(0); j < numLines && block.err == nil; j++ {
+				off, l := block.u32(), block.u32()
+				lb.lines = append(lb.lines, line{rva: start + off, line: l & 0xffffff})
+			}
+			if flags&linesHaveColumns != 0 {
+				block.next(4 * int(numLines))
+			}
+			if block.err != nil {
+				return fmt.Errorf("read lines: %w", block.err)
+			}
+			blocks = append(blocks, lb)
+		}
+		if r.err != nil {
+			return fmt.Errorf("read lines: %w", r.err)
+		}
+	}
+
+	f.modules[i].lineBlocks = blocks
+	f.modules[i].linesRead = true
+	return nil
+}
+
+// checksumFile returns the name of the file of the file checksum entry at the
+// given offset.
+func (f *File) checksumFile(checksums []byte, offset uint32) string {
+	if uint64(offset)+4 > uint64(len(checksums)) {
+		return ""
+	}
+	name := binary.LittleEndian.Uint32(checksums[offset:])
+	if uint64(name) >= uint64(len(f.names)) {
+		return ""
+	}
+	end := bytes.IndexByte(f.names[name:], 0)
+	if end < 0 {
+		return ""
+	}
+	return string(f.names[name : name+uint32(end)])
+}
+
+// SourceLines returns the source lines of the given relative virtual address.
+// Inlined functions are not resolved, only the function containing the
+// address is. No lines are returned if no function contains the address.
+func (f *File) SourceLines(ctx context.Context, addr uint64) ([]profile.LocationLine, error) {
+	i := sort.Search(len(f.functions), func(i int) bool { return uint64(f.functions[i].rva) > addr }) - 1
+	if i < 0 || addr >= uint64(f.functions[i].rva)+uint64(f.functions[i].size) {
+		return nil, nil
+	}
+	fn := f.functions[i]
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	mod := f.modules[fn.module]
+	if !mod.linesRead {
+		if err := f.readLines(fn.module); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		file            string
+		lineNo, startNo uint32
+	)
+	for _, b := range mod.lineBlocks {
+		if len(b.lines) == 0 || uint64(b.rva) > addr || b.rva >= fn.rva+fn.size || b.rva+b.size <= fn.rva {
+			continue
+		}
+		for _, l := range b.lines {
+			if l.rva == fn.rva {
+				startNo = l.line
+			}
+			if uint64(l.rva) <= addr {
+				file, lineNo = b.file, l.line
+			}
+		}
+	}
+
+	return []profile.LocationLine{{
+		Line: int64(lineNo),
+		Function: &pb.Function{
+			Name:       fn.name,
+			SystemName: fn.name,
+			Filename:   file,
+			StartLine:  int64(startNo),
+		},
+	}}, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdbutils
+
+import (
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	debugDirectoryEntrySize = 28
+	debugTypeCodeView       = 2
+)
+
+// codeViewMagic starts the CodeView record of PE images that refers to the
+// PDB 7.0 file with their debug information.
+var codeViewMagic = []byte("RSDS")
+
+// ErrNoCodeView is returned when a PE image doesn't have a CodeView record.
+var ErrNoCodeView = errors.New("no CodeView record")
+
+// IsPE reports whether the specified file is a PE image.
+func IsPE(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, 2)
+	if _, err := f.ReadAt(magic, 0); err != nil {
+		return false
+	}
+	return IsPEMagic(magic)
+}
+
+// IsPEMagic reports whether b starts with the magic bytes of the MS-DOS stub
+// of PE images.
+func IsPEMagic(b []byte) bool {
+	return len(b) >= 2 && b[0] == 'M' && b[1] == 'Z'
+}
+
+// PEBuildID returns the build ID of the specified PE image, made of the GUID
+// and age of its CodeView record. They match the ones of its PDB file.
+func PEBuildID(path string) (string, error) {
+	f, err := pe.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open PE: %w", err)
+	}
+	defer f.Close()
+
+	var dirs []pe.DataDirectory
+	switch h := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		dirs = h.DataDirectory[:h.NumberOfRvaAndSizes]
+	case *pe.OptionalHeader64:
+		dirs = h.DataDirectory[:h.NumberOfRvaAndSizes]
+	}
+	if len(dirs) <= pe.IMAGE_DIRECTORY_ENTRY_DEBUG {
+		return "", ErrNoCodeView
+	}
+	debugDir := dirs[pe.IMAGE_DIRECTORY_ENTRY_DEBUG]
+	if debugDir.Size == 0 {
+		return "", ErrNoCodeView
+	}
+
+	b, err := readRVA(f, debugDir.VirtualAddress, debugDir.Size)
+	if err != nil {
+		return "", fmt.Errorf("read debug directory: %w", err)
+	}
+	for off := 0; off+debugDirectoryEntrySize <= len(b); off += debugDirectoryEntrySize {
+		entry := b[off : off+debugDirectoryEntrySize]
+		if binary.LittleEndian.Uint32(entry[12:]) != debugTypeCodeView {
+			continue
+		}
+		size := binary.LittleEndian.Uint32(entry[16:])
+		rva := binary.LittleEndian.Uint32(entry[20:])
+		if size < 24 {
+			continue
+		}
+		cv, err := readRVA(f, rva, size)
+		if err != nil {
+			return "", fmt.Errorf("read CodeView record: %w", err)
+		}
+		if string(cv[:4]) != string(codeViewMagic) {
+			continue
+		}
+		var guid [16]byte
+		copy(guid[:], cv[4:20])
+		return buildID(guid, binary.LittleEndian.Uint32(cv[20:])), nil
+	}
+	return "", ErrNoCodeView
+}
+
+// readRVA reads size bytes at the given relative virtual address of the image.
+func readRVA(f *pe.File, rva, size uint32) ([]byte, error) {
+	for _, s := range f.Sections {
+		if rva < s.VirtualAddress || uint64(rva)+uint64(size) > uint64(s.VirtualAddress)+uint64(s.Size) {
+			continue
+		}
+		b := make([]byte, size)
+		if _, err := s.ReadAt(b, int64(rva-s.VirtualAddress)); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	return nil, io.ErrUnexpectedEOF
+}
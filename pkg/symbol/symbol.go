@@ -17,201 +17,545 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
-	"github.com/goburrow/cache"
+	"github.com/prometheus/client_golang/prometheus"
 
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
-	"github.com/parca-dev/parca/pkg/hash"
+	"github.com/parca-dev/parca/pkg/logfields"
 	"github.com/parca-dev/parca/pkg/profile"
 	"github.com/parca-dev/parca/pkg/symbol/addr2line"
 	"github.com/parca-dev/parca/pkg/symbol/demangle"
 	"github.com/parca-dev/parca/pkg/symbol/elfutils"
+	"github.com/parca-dev/parca/pkg/symbol/pdbutils"
 )
 
-var ErrLinerCreationFailedBefore = errors.New("failed to initialize liner")
+var (
+	ErrLinerCreationFailedBefore = errors.New("failed to initialize liner")
+	// ErrParseTimeout is returned if reading the debug information of an
+	// object file took longer than the parse timeout.
+	ErrParseTimeout = errors.New("timed out reading debug information")
+	// ErrAddressNotFound is the cause of an AddressError if the debug
+	// information has no source lines for the address.
+	ErrAddressNotFound = elfutils.ErrAddressNotFound
+	// ErrCorruptDWARF is the cause of errors reading malformed DWARF data.
+	ErrCorruptDWARF = elfutils.ErrCorruptDWARF
+)
 
 type Symbolizer struct {
 	logger    log.Logger
 	demangler *demangle.Demangler
-
-	cacheOpts  []cache.Option
-	linerCache cache.Cache
-
-	attemptThreshold    int
+	// demangleMode and demangleSchemes configure the demangler.
+	demangleMode    string
+	demangleSchemes []demangle.Scheme
+	pathRewrites    []PathRewrite
+
+	// dwarfBufferPool provides the buffers to read DWARF data with, shared
+	// by all object files. It is nil if disabled.
+	dwarfBufferPool *elfutils.BufferPool
+
+	cacheSize     int
+	cacheMaxBytes int64
+	cacheItemTTL  time.Duration
+	linerCache    *linerCache
+
+	// linesCache holds the resolved lines of addresses by build ID, so that
+	// they are only resolved once.
+	linesCacheSize int
+	linesCache     *linesCache
+
+	cacheHits      prometheus.Counter
+	cacheMisses    prometheus.Counter
+	linesCacheHits prometheus.Counter
+	linesCacheMiss prometheus.Counter
+	parseDuration  prometheus.Histogram
+	parseTimeouts  prometheus.Counter
+
+	attemptThreshold int
+
+	// parseTimeout bounds reading the debug information of an object file,
+	// both to open it and to resolve the addresses of a batch of locations.
+	// Zero means no timeout.
+	parseTimeout time.Duration
+	// parse opens the debug information file of an object file, it is
+	// replaced in tests.
+	parse func(ctx context.Context, buildID, path string) (liner, error)
+
+	// mtx guards the bookkeeping of failed attempts below.
+	mtx                 sync.Mutex
 	linerCreationFailed map[string]struct{}
 
 	symbolizationAttempts map[string]map[uint64]int
-	symbolizationFailed   map[string]map[uint64]struct{}
+	// symbolizationFailed holds why addresses failed to be symbolized.
+	symbolizationFailed map[string]map[uint64]error
 }
 
 type liner interface {
-	PCToLines(pc uint64) ([]profile.LocationLine, error)
+	PCToLines(ctx context.Context, pc uint64) ([]profile.LocationLine, error)
+}
+
+// objectLiner is the liner of an object file together with the information
+// to translate runtime addresses of the object file's mappings.
+type objectLiner struct {
+	liner
+	exec *elfutils.ExecInfo
+}
+
+func (l *objectLiner) Close() error {
+	if closer, ok := l.liner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// base returns the base address to subtract from runtime addresses of the
+// given mapping, or 0 if the addresses can't be translated.
+func (l *objectLiner) base(logger log.Logger, m *pb.Mapping) uint64 {
+	if l.exec == nil {
+		return 0
+	}
+	base, err := l.exec.Base(m.Start, m.Limit, m.Offset)
+	if err != nil {
+		level.Debug(logger).Log("msg", "failed to determine base address of mapping", "err", err)
+		return 0
+	}
+	return base
+}
+
+// symtabFallbackLiner resolves addresses with DWARF, and falls back to the
+// symbol table for the addresses DWARF has no lines for, e.g. because the
+// line program was stripped. The function names are still useful even though
+// the symbol table has no source lines.
+type symtabFallbackLiner struct {
+	dwarf  liner
+	symtab liner
 }
 
-func NewSymbolizer(logger log.Logger, opts ...Option) (*Symbolizer, error) {
+func (l *symtabFallbackLiner) PCToLines(ctx context.Context, pc uint64) ([]profile.LocationLine, error) {
+	lines, err := l.dwarf.PCToLines(ctx, pc)
+	if err == nil && len(lines) > 0 || err != nil && !errors.Is(err, elfutils.ErrNoLineProgram) {
+		return lines, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.symtab.PCToLines(ctx, pc)
+}
+
+// DebugInfoFileFunc returns the path to the debug information file of a
+// mapping on the local filesystem. It is only called when there is no cached
+// liner for the mapping's build ID.
+type DebugInfoFileFunc func(ctx context.Context) (string, error)
+
+func NewSymbolizer(logger log.Logger, reg prometheus.Registerer, opts ...Option) (*Symbolizer, error) {
 	const (
 		defaultDemangleMode     = "simple"
 		defaultCacheSize        = 1000
+		defaultCacheMaxBytes    = 0 // Unlimited.
 		defaultCacheItemTTL     = time.Minute
+		defaultLinesCacheSize   = 100000
 		defaultAttemptThreshold = 3
+		defaultParseTimeout     = time.Minute
+	)
+
+	cacheRequests := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "parca_symbolizer_liner_cache_requests_total",
+			Help: "Total number of liner cache lookups by result.",
+		},
+		[]string{"result"},
+	)
+	linesCacheRequests := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "parca_symbolizer_lines_cache_requests_total",
+			Help: "Total number of lookups of the resolved lines of addresses in the cache by result.",
+		},
+		[]string{"result"},
+	)
+	parseDuration := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "parca_symbolizer_debuginfo_parse_duration_seconds",
+			Help:    "Duration of reading the debug information of an object file to symbolize it.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+		},
+	)
+	parseTimeouts := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "parca_symbolizer_debuginfo_parse_timeouts_total",
+			Help: "Total number of object files whose debug information took too long to read to symbolize them.",
+		},
 	)
+	reg.MustRegister(cacheRequests, linesCacheRequests, parseDuration, parseTimeouts)
 
 	sym := &Symbolizer{
-		logger:    log.With(logger, "component", "symbolizer"),
-		demangler: demangle.NewDemangler(defaultDemangleMode, false),
+		logger:       logfields.WithComponent(logger, "symbolizer"),
+		demangleMode: defaultDemangleMode,
 
 		// e.g: Parca binary compressed DWARF data size ~8mb as of 10.2021
-		cacheOpts: []cache.Option{
-			cache.WithMaximumSize(defaultCacheSize),
-			cache.WithExpireAfterAccess(defaultCacheItemTTL),
-		},
+		cacheSize:     defaultCacheSize,
+		cacheMaxBytes: defaultCacheMaxBytes,
+		cacheItemTTL:  defaultCacheItemTTL,
+
+		linesCacheSize: defaultLinesCacheSize,
+
+		dwarfBufferPool: elfutils.NewBufferPool(),
+
+		cacheHits:      cacheRequests.WithLabelValues("hit"),
+		cacheMisses:    cacheRequests.WithLabelValues("miss"),
+		linesCacheHits: linesCacheRequests.WithLabelValues("hit"),
+		linesCacheMiss: linesCacheRequests.WithLabelValues("miss"),
+		parseDuration:  parseDuration,
+		parseTimeouts:  parseTimeouts,
 
 		attemptThreshold: defaultAttemptThreshold,
+		parseTimeout:     defaultParseTimeout,
 
 		linerCreationFailed: map[string]struct{}{},
 
 		symbolizationAttempts: map[string]map[uint64]int{},
-		symbolizationFailed:   map[string]map[uint64]struct{}{},
+		symbolizationFailed:   map[string]map[uint64]error{},
 	}
+	sym.parse = sym.newLiner
 	for _, opt := range opts {
 		opt(sym)
 	}
-	sym.linerCache = cache.New(sym.cacheOpts...)
+	sym.linerCache = newLinerCache(sym.cacheSize, sym.cacheMaxBytes, sym.cacheItemTTL)
+	sym.linesCache = newLinesCache(sym.linesCacheSize)
+	var demangleOpts []demangle.Option
+	if sym.demangleSchemes != nil {
+		demangleOpts = append(demangleOpts, demangle.WithSchemes(sym.demangleSchemes...))
+	}
+	sym.demangler = demangle.NewDemangler(sym.demangleMode, false, demangleOpts...)
 
 	return sym, nil
 }
 
-func (s *Symbolizer) Symbolize(ctx context.Context, m *pb.Mapping, locations []*pb.Location, debugInfoFile string) ([][]profile.LocationLine, error) {
+// Symbolize resolves the source lines of the given locations. The debug
+// information file is only requested if the lines of some locations and the
+// mapping's liner aren't cached yet.
+//
+// If the debug information can't be used at all, a *DebugInfoError is
+// returned. If only some of the locations can't be resolved, their lines are
+// empty and an AddressErrors with an *AddressError for each of them is
+// returned along with the lines of all locations.
+func (s *Symbolizer) Symbolize(ctx context.Context, m *pb.Mapping, locations []*pb.Location, debugInfoFile DebugInfoFileFunc) ([][]profile.LocationLine, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
 	}
 
-	logger := log.With(s.logger, "buildid", m.BuildId, "debuginfo_file", debugInfoFile)
+	logger := logfields.WithBuildID(s.logger, m.BuildId)
 
-	liner, err := s.liner(m, debugInfoFile)
-	if err != nil {
-		const msg = "failed to create liner"
-		level.Debug(logger).Log("msg", msg, "err", err)
-		return nil, fmt.Errorf(msg+": %w", err)
+	// The address of a caller is the return address of its call, the
+	// instruction after the call, which may be on another line or even in
+	// another function, so the address before it is resolved instead.
+	lookup := func(loc *pb.Location) uint64 {
+		if loc.ReturnAddress && loc.Address > 0 {
+			return loc.Address - 1
+		}
+		return loc.Address
 	}
 
-	// Generate a hash key to use for error tracking.
-	key, err := hash.File(debugInfoFile)
-	if err != nil {
-		level.Warn(s.logger).Log("msg", "failed to generate cache key", "err", err)
-		key = m.BuildId
+	// Resolve every distinct address only once and in ascending order, so
+	// that consecutive lookups hit the same compile unit. Addresses resolved
+	// before are taken from the cache.
+	linesByAddr := make(map[uint64][]profile.LocationLine, len(locations))
+	addrs := make([]uint64, 0, len(locations))
+	for _, loc := range locations {
+		addr := lookup(loc)
+		if _, ok := linesByAddr[addr]; ok {
+			continue
+		}
+		if lines, ok := s.linesCache.Get(linesCacheKey(m, addr)); ok {
+			s.linesCacheHits.Inc()
+			linesByAddr[addr] = lines
+			continue
+		}
+		s.linesCacheMiss.Inc()
+		linesByAddr[addr] = nil
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+
+	errsByAddr := map[uint64]error{}
+	if len(addrs) > 0 {
+		liner, err := s.liner(ctx, m, debugInfoFile)
+		if err != nil {
+			const msg = "failed to create liner"
+			level.Debug(logger).Log("msg", msg, "err", err)
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			return nil, &DebugInfoError{BuildID: m.BuildId, Err: fmt.Errorf(msg+": %w", err)}
+		}
+
+		// Position-independent object files are loaded at arbitrary
+		// addresses, so runtime addresses need to be translated to the
+		// virtual addresses of the object file. Addresses outside of the
+		// mapping are assumed to have been translated by the client already.
+		base := liner.base(logger, m)
+		pc := func(addr uint64) uint64 {
+			if base != 0 && addr >= m.Start && addr < m.Limit {
+				return addr - base
+			}
+			return addr
+		}
+
+		parseCtx, cancel := s.parseContext(ctx)
+		defer cancel()
+
+		for _, addr := range addrs {
+			lines, err := s.pcToLines(parseCtx, liner, m.BuildId, pc(addr))
+			linesByAddr[addr] = s.rewritePaths(lines)
+
+			if err := s.parseErr(ctx, parseCtx, m.BuildId); err != nil {
+				if ctx.Err() != nil {
+					return nil, err
+				}
+				return nil, &DebugInfoError{BuildID: m.BuildId, Err: err}
+			}
+			if err != nil {
+				errsByAddr[addr] = err
+				continue
+			}
+			s.linesCache.Add(linesCacheKey(m, addr), linesByAddr[addr])
+		}
 	}
 
+	// The errors are reported by the addresses of the locations.
+	var errs AddressErrors
+	reported := map[uint64]struct{}{}
 	locationsLines := make([][]profile.LocationLine, 0, len(locations))
 	for _, loc := range locations {
-		locationsLines = append(locationsLines, s.pcToLines(liner, m.BuildId, key, loc.Address))
+		addr := lookup(loc)
+		locationsLines = append(locationsLines, linesByAddr[addr])
+
+		err, ok := errsByAddr[addr]
+		if _, seen := reported[loc.Address]; !ok || seen {
+			continue
+		}
+		reported[loc.Address] = struct{}{}
+		errs = append(errs, &AddressError{BuildID: m.BuildId, Address: loc.Address, Err: err})
 	}
-	return locationsLines, nil
+	return locationsLines, errs.err()
 }
 
-// pcToLines returns the line number of the given PC while keeping the track of symbolization attempts and failures.
-func (s *Symbolizer) pcToLines(liner liner, buildID, key string, addr uint64) []profile.LocationLine {
-	logger := log.With(s.logger, "addr", addr, "buildid", buildID)
+// pcToLines returns the line number of the given PC while keeping the track
+// of symbolization attempts and failures. If there are no lines for the PC,
+// it returns why.
+func (s *Symbolizer) pcToLines(ctx context.Context, liner liner, buildID string, addr uint64) ([]profile.LocationLine, error) {
+	logger := log.With(s.logger, logfields.Address, logfields.FormatAddress(addr), logfields.BuildID, buildID)
 	// Check if we already attempt to symbolize this location and failed.
-	if _, failedBefore := s.symbolizationFailed[key][addr]; failedBefore {
+	s.mtx.Lock()
+	failedErr, failedBefore := s.symbolizationFailed[buildID][addr]
+	s.mtx.Unlock()
+	if failedBefore {
 		level.Debug(logger).Log("msg", "location already had been attempted to be symbolized and failed, skipping")
-		return nil
+		return nil, failedErr
 	}
 	// Where the magic happens.
-	lines, err := liner.PCToLines(addr)
+	lines, err := liner.PCToLines(ctx, addr)
+	if ctx.Err() != nil {
+		// Interrupted, this is no failed attempt of the address.
+		return nil, nil
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
 	if err != nil {
 		// Error bookkeeping.
-		if prev, ok := s.symbolizationAttempts[key][addr]; ok {
+		if prev, ok := s.symbolizationAttempts[buildID][addr]; ok {
 			prev++
 			if prev >= s.attemptThreshold {
-				if _, ok := s.symbolizationFailed[key]; ok {
-					s.symbolizationFailed[key][addr] = struct{}{}
-				} else {
-					s.symbolizationFailed[key] = map[uint64]struct{}{addr: {}}
-				}
-				delete(s.symbolizationAttempts[key], addr)
+				s.markFailed(buildID, addr, err)
+				delete(s.symbolizationAttempts[buildID], addr)
 			} else {
-				s.symbolizationAttempts[key][addr] = prev
+				s.symbolizationAttempts[buildID][addr] = prev
 			}
-			return nil
+			return nil, err
 		}
 		// First failed attempt.
-		s.symbolizationAttempts[key] = map[uint64]int{addr: 1}
+		if _, ok := s.symbolizationAttempts[buildID]; ok {
+			s.symbolizationAttempts[buildID][addr] = 1
+		} else {
+			s.symbolizationAttempts[buildID] = map[uint64]int{addr: 1}
+		}
 		level.Debug(logger).Log("msg", "failed to extract source lines", "err", err)
-		return nil
+		return nil, err
 	}
 	if len(lines) == 0 {
-		if _, ok := s.symbolizationFailed[key]; ok {
-			s.symbolizationFailed[key][addr] = struct{}{}
-		} else {
-			s.symbolizationFailed[key] = map[uint64]struct{}{addr: {}}
-		}
-		delete(s.symbolizationAttempts[key], addr)
+		s.markFailed(buildID, addr, ErrAddressNotFound)
+		delete(s.symbolizationAttempts[buildID], addr)
 		level.Debug(logger).Log("msg", "could not find any lines for given address")
+		return nil, ErrAddressNotFound
+	}
+	return lines, nil
+}
+
+// markFailed records that the given address failed to be symbolized for the
+// given reason, so that it isn't attempted again. It must be called with the
+// mutex held.
+func (s *Symbolizer) markFailed(buildID string, addr uint64, err error) {
+	if _, ok := s.symbolizationFailed[buildID]; ok {
+		s.symbolizationFailed[buildID][addr] = err
+	} else {
+		s.symbolizationFailed[buildID] = map[uint64]error{addr: err}
 	}
-	return lines
 }
 
 func (s *Symbolizer) Close() error {
 	return s.linerCache.Close()
 }
 
-// liner creates a new liner for the given mapping and object file path and caches it.
-func (s *Symbolizer) liner(m *pb.Mapping, path string) (liner, error) {
-	h, err := hash.File(path)
-	if err != nil {
-		level.Warn(s.logger).Log("msg", "failed to generate cache key", "err", err)
-		h = path
+// Invalidate forgets everything known about the debug information of the
+// given build ID, so that it is read again the next time it is needed.
+func (s *Symbolizer) Invalidate(buildID string) {
+	s.mtx.Lock()
+	delete(s.linerCreationFailed, buildID)
+	delete(s.symbolizationAttempts, buildID)
+	delete(s.symbolizationFailed, buildID)
+	s.mtx.Unlock()
+	s.linerCache.Remove(buildID)
+	s.linesCache.RemoveBuildID(buildID)
+}
+
+// linesCacheKey returns the key of the lines of the given address of the
+// mapping in the lines cache.
+func linesCacheKey(m *pb.Mapping, addr uint64) linesKey {
+	if addr >= m.Start && addr < m.Limit {
+		return linesKey{buildID: m.BuildId, addr: addr - m.Start + m.Offset, offset: true}
 	}
+	return linesKey{buildID: m.BuildId, addr: addr}
+}
 
-	// Check if we already attempt to build a liner for this path.
-	if _, failedBefore := s.linerCreationFailed[h]; failedBefore {
-		level.Debug(s.logger).Log("msg", "already failed to create liner for this debug info file, skipping")
+// liner returns the cached liner for the given mapping or creates a new one
+// from its debug information file and caches it.
+func (s *Symbolizer) liner(ctx context.Context, m *pb.Mapping, debugInfoFile DebugInfoFileFunc) (*objectLiner, error) {
+	logger := logfields.WithBuildID(s.logger, m.BuildId)
+
+	// Check if we already attempt to build a liner for this build ID.
+	s.mtx.Lock()
+	_, failedBefore := s.linerCreationFailed[m.BuildId]
+	s.mtx.Unlock()
+	if failedBefore {
+		level.Debug(logger).Log("msg", "already failed to create liner for this debug info file, skipping")
 		return nil, ErrLinerCreationFailedBefore
 	}
 
-	if val, ok := s.linerCache.GetIfPresent(h); ok {
-		level.Debug(s.logger).Log("msg", "using cached liner to resolve symbols", "file", path)
-		return val.(liner), nil
+	if lnr, ok := s.linerCache.Get(m.BuildId); ok {
+		s.cacheHits.Inc()
+		level.Debug(logger).Log("msg", "using cached liner to resolve symbols")
+		return lnr.(*objectLiner), nil
 	}
+	s.cacheMisses.Inc()
 
-	lnr, err := s.newLiner(m.BuildId, path)
+	path, err := debugInfoFile(ctx)
 	if err != nil {
-		level.Error(s.logger).Log(
+		return nil, err
+	}
+
+	parseCtx, cancel := s.parseContext(ctx)
+	defer cancel()
+
+	start := time.Now()
+	lnr, err := s.parse(parseCtx, m.BuildId, path)
+	s.parseDuration.Observe(time.Since(start).Seconds())
+	if err := s.parseErr(ctx, parseCtx, m.BuildId); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		level.Error(logger).Log(
 			"msg", "failed to open object file",
 			"file", path,
-			"buildid", m.BuildId,
 			"err", err,
 		)
-		s.linerCreationFailed[h] = struct{}{}
-		s.linerCache.Invalidate(h)
+		s.mtx.Lock()
+		s.linerCreationFailed[m.BuildId] = struct{}{}
+		s.mtx.Unlock()
+		s.linerCache.Remove(m.BuildId)
 		return nil, err
 	}
 
-	level.Debug(s.logger).Log("msg", "liner cached", "file", path)
-	s.linerCache.Put(h, lnr)
-	return lnr, nil
+	exec, err := elfutils.ReadExecInfo(path)
+	if err != nil {
+		level.Debug(logger).Log("msg", "failed to read program headers, addresses are not translated", "err", err)
+	}
+	olnr := &objectLiner{liner: lnr, exec: exec}
+
+	var size int64
+	if fi, err := os.Stat(path); err == nil {
+		size = fi.Size()
+	}
+
+	level.Debug(logger).Log("msg", "liner cached", "file", path)
+	s.linerCache.Add(m.BuildId, olnr, size)
+	return olnr, nil
+}
+
+// parseContext returns the context to read debug information with, which is
+// canceled once the parse timeout elapsed.
+func (s *Symbolizer) parseContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.parseTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.parseTimeout)
+}
+
+// parseErr returns ErrParseTimeout if reading the debug information of the
+// given build ID timed out, in which case the object file is marked as failed
+// so that it isn't read again.
+func (s *Symbolizer) parseErr(ctx, parseCtx context.Context, buildID string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if parseCtx.Err() == nil {
+		return nil
+	}
+
+	level.Warn(s.logger).Log("msg", "timed out reading debug information, skipping object file", logfields.BuildID, buildID, "timeout", s.parseTimeout)
+	s.parseTimeouts.Inc()
+	s.mtx.Lock()
+	s.linerCreationFailed[buildID] = struct{}{}
+	s.mtx.Unlock()
+	s.linerCache.Remove(buildID)
+	return fmt.Errorf("%w after %s", ErrParseTimeout, s.parseTimeout)
 }
 
 // newLiner creates a new liner for the given mapping and object file path.
-func (s *Symbolizer) newLiner(buildID, path string) (liner, error) {
-	logger := log.With(s.logger, "file", path, "buildid", buildID)
+// The context is checked in between trying the different kinds of liners.
+func (s *Symbolizer) newLiner(ctx context.Context, buildID, path string) (liner, error) {
+	logger := log.With(s.logger, "file", path, logfields.BuildID, buildID)
+	// The debug information of PE images is kept in PDB files.
+	if pdbutils.IsPDB(path) {
+		lnr, err := addr2line.PDB(logger, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create PDB liner: %w", err)
+		}
+		level.Debug(logger).Log("msg", "using PDB liner to resolve symbols")
+		return lnr, nil
+	}
+
 	hasDWARF, err := elfutils.HasDWARF(path)
 	if err != nil {
 		level.Debug(logger).Log("msg", "failed to determine if binary has DWARF info", "err", err)
 	}
 	if hasDWARF {
-		level.Debug(logger).Log("msg", "using DWARF liner to resolve symbols")
-		lnr, err := addr2line.DWARF(logger, path, s.demangler)
-		if err != nil {
-			return nil, err
+		lnr, err := addr2line.DWARF(logger, path, s.demangler, s.dwarfBufferPool)
+		if err == nil {
+			level.Debug(logger).Log("msg", "using DWARF liner to resolve symbols")
+			return s.withSymtabFallback(logger, path, lnr), nil
 		}
-		return lnr, nil
+		level.Error(logger).Log("msg", "failed to create DWARF liner, falling back to other liners", "err", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	// Go binaries has a special case. They use ".gopclntab" section to symbolize addresses.
@@ -230,6 +574,9 @@ func (s *Symbolizer) newLiner(buildID, path string) (liner, error) {
 		}
 		level.Error(logger).Log("msg", "failed to create go liner, falling back to symtab liner", "err", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// As a last resort, use the symtab liner which utilizes .symtab section and .dynsym section.
 	hasSymbols, err := elfutils.HasSymbols(path)
@@ -237,7 +584,7 @@ func (s *Symbolizer) newLiner(buildID, path string) (liner, error) {
 		level.Debug(logger).Log("msg", "failed to determine if binary has symbols", "err", err)
 	}
 	if hasSymbols {
-		lnr, err := addr2line.Symbols(logger, path)
+		lnr, err := addr2line.Symbols(logger, path, s.demangler)
 		if err == nil {
 			level.Debug(logger).Log("msg", "using symtab liner to resolve symbols")
 			return lnr, nil
@@ -247,3 +594,21 @@ func (s *Symbolizer) newLiner(buildID, path string) (liner, error) {
 
 	return nil, errors.New("cannot create a liner from given object file")
 }
+
+// withSymtabFallback wraps the DWARF liner to fall back to the symbol table
+// of the object file, if it has one.
+func (s *Symbolizer) withSymtabFallback(logger log.Logger, path string, dwarf liner) liner {
+	hasSymbols, err := elfutils.HasSymbols(path)
+	if err != nil {
+		level.Debug(logger).Log("msg", "failed to determine if binary has symbols", "err", err)
+	}
+	if !hasSymbols {
+		return dwarf
+	}
+	symtab, err := addr2line.Symbols(logger, path, s.demangler)
+	if err != nil {
+		level.Debug(logger).Log("msg", "failed to create symtab liner, not falling back to it", "err", err)
+		return dwarf
+	}
+	return &symtabFallbackLiner{dwarf: dwarf, symtab: symtab}
+}
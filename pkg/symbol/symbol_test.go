@@ -0,0 +1,512 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symbol
+
+import (
+	"context"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	pprofprofile "github.com/google/pprof/profile"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/profile"
+	"github.com/parca-dev/parca/pkg/symbol/addr2line"
+	"github.com/parca-dev/parca/pkg/symbol/demangle"
+	"github.com/parca-dev/parca/pkg/symbol/elfutils"
+)
+
+// blockingLiner blocks resolving addresses until the context is done.
+type blockingLiner struct{}
+
+func (blockingLiner) PCToLines(ctx context.Context, _ uint64) ([]profile.LocationLine, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+type staticLiner struct{}
+
+func (staticLiner) PCToLines(_ context.Context, _ uint64) ([]profile.LocationLine, error) {
+	return []profile.LocationLine{{Line: 1, Function: &pb.Function{Name: "main"}}}, nil
+}
+
+func TestSymbolizerParseTimeout(t *testing.T) {
+	sym, err := NewSymbolizer(log.NewNopLogger(), prometheus.NewRegistry(), WithParseTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+
+	sym.parse = func(ctx context.Context, buildID, _ string) (liner, error) {
+		switch buildID {
+		case "blocking-open":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case "blocking-resolve":
+			return blockingLiner{}, nil
+		default:
+			return staticLiner{}, nil
+		}
+	}
+
+	ctx := context.Background()
+	locations := []*pb.Location{{Address: 0x1000}}
+	debugInfoFile := func(context.Context) (string, error) {
+		return "testdata/debuginfo", nil
+	}
+
+	_, err = sym.Symbolize(ctx, &pb.Mapping{BuildId: "blocking-open"}, locations, debugInfoFile)
+	require.ErrorIs(t, err, ErrParseTimeout)
+	require.Equal(t, 1.0, testutil.ToFloat64(sym.parseTimeouts))
+
+	// The object file is not read again.
+	_, err = sym.Symbolize(ctx, &pb.Mapping{BuildId: "blocking-open"}, locations, debugInfoFile)
+	require.ErrorIs(t, err, ErrLinerCreationFailedBefore)
+
+	_, err = sym.Symbolize(ctx, &pb.Mapping{BuildId: "blocking-resolve"}, locations, debugInfoFile)
+	require.ErrorIs(t, err, ErrParseTimeout)
+	require.Equal(t, 2.0, testutil.ToFloat64(sym.parseTimeouts))
+
+	_, err = sym.Symbolize(ctx, &pb.Mapping{BuildId: "blocking-resolve"}, locations, debugInfoFile)
+	require.ErrorIs(t, err, ErrLinerCreationFailedBefore)
+
+	// Other object files are not affected.
+	lines, err := sym.Symbolize(ctx, &pb.Mapping{BuildId: "other"}, locations, debugInfoFile)
+	require.NoError(t, err)
+	requireEqualLines(t, [][]profile.LocationLine{{{Line: 1, Function: &pb.Function{Name: "main"}}}}, lines)
+}
+
+// requireEqualLines asserts that the lines of all locations are equal, with
+// the functions compared by proto.Equal.
+func requireEqualLines(t *testing.T, expected, actual [][]profile.LocationLine) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		require.Len(t, actual[i], len(expected[i]), "location %d", i)
+		for j := range expected[i] {
+			require.Equal(t, expected[i][j].Line, actual[i][j].Line, "location %d, line %d", i, j)
+			require.True(t, proto.Equal(expected[i][j].Function, actual[i][j].Function), "location %d, line %d: %v", i, j, actual[i][j].Function)
+		}
+	}
+}
+
+// countingLiner counts the addresses it resolves.
+type countingLiner struct {
+	resolved int
+}
+
+func (l *countingLiner) PCToLines(_ context.Context, pc uint64) ([]profile.LocationLine, error) {
+	l.resolved++
+	return []profile.LocationLine{{Line: int64(pc), Function: &pb.Function{Name: "main"}}}, nil
+}
+
+func TestSymbolizerLinesCache(t *testing.T) {
+	sym, err := NewSymbolizer(log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	lnr := &countingLiner{}
+	parsed := 0
+	sym.parse = func(context.Context, string, string) (liner, error) {
+		parsed++
+		return lnr, nil
+	}
+
+	ctx := context.Background()
+	m := &pb.Mapping{BuildId: "buildid", Start: 0x1000, Limit: 0x2000}
+	locations := []*pb.Location{{Address: 0x1100}}
+	debugInfoFile := func(context.Context) (string, error) {
+		return "testdata/debuginfo", nil
+	}
+
+	lines, err := sym.Symbolize(ctx, m, locations, debugInfoFile)
+	require.NoError(t, err)
+	require.Equal(t, 1, parsed)
+	require.Equal(t, 1, lnr.resolved)
+
+	// The address is resolved from the cache even once the liner is evicted,
+	// without reading the debug information again.
+	require.NoError(t, sym.linerCache.Close())
+	debugInfoFile = func(context.Context) (string, error) {
+		t.Fatal("debug information requested for a cached address")
+		return "", nil
+	}
+	cached, err := sym.Symbolize(ctx, m, locations, debugInfoFile)
+	require.NoError(t, err)
+	requireEqualLines(t, lines, cached)
+	require.Equal(t, 1, parsed)
+	require.Equal(t, 1, lnr.resolved)
+	require.Equal(t, 1.0, testutil.ToFloat64(sym.linesCacheHits))
+
+	// The same object file loaded elsewhere has the same lines at the same
+	// offset.
+	moved := &pb.Mapping{BuildId: "buildid", Start: 0x5000, Limit: 0x6000}
+	cached, err = sym.Symbolize(ctx, moved, []*pb.Location{{Address: 0x5100}}, debugInfoFile)
+	require.NoError(t, err)
+	requireEqualLines(t, lines, cached)
+	require.Equal(t, 1, lnr.resolved)
+
+	// Re-uploaded debug information is read again.
+	sym.Invalidate("buildid")
+	require.Equal(t, 0, sym.linesCache.Len())
+	debugInfoFile = func(context.Context) (string, error) {
+		return "testdata/debuginfo", nil
+	}
+	_, err = sym.Symbolize(ctx, m, locations, debugInfoFile)
+	require.NoError(t, err)
+	require.Equal(t, 2, parsed)
+	require.Equal(t, 2, lnr.resolved)
+}
+
+func TestSymbolizeFile(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "profile.pb.gz")
+	require.NoError(t, SymbolizeFile(
+		context.Background(),
+		"../symbolizer/testdata/profile.pb.gz",
+		"../symbolizer/testdata/2d6912fd3dd64542f6f6294f4bf9cb6c265b3085/debuginfo",
+		out,
+	))
+
+	f, err := os.Open(out)
+	require.NoError(t, err)
+	defer f.Close()
+
+	p, err := pprofprofile.Parse(f)
+	require.NoError(t, err)
+	require.NoError(t, p.CheckValid())
+	require.True(t, p.Mapping[0].HasFunctions)
+
+	var loc *pprofprofile.Location
+	for _, l := range p.Location {
+		if l.Mapping == p.Mapping[0] {
+			require.NotEmpty(t, l.Line, "location %#x is not symbolized", l.Address)
+		}
+		if l.Address == 0x463784 {
+			loc = l
+		}
+	}
+	require.NotNil(t, loc)
+
+	names := make([]string, 0, len(loc.Line))
+	for _, line := range loc.Line {
+		names = append(names, line.Function.Name)
+	}
+	require.Equal(t, []string{"main.iterate", "main.iteratePerTenant", "main.main"}, names)
+	require.Equal(t, int64(27), loc.Line[0].Line)
+}
+
+func TestSymbolizeMappingsOfSameObjectFile(t *testing.T) {
+	const path = "../symbolizer/testdata/a695b153282bb4da64ca7397a7cf029b63a6419f/debuginfo"
+
+	sym, err := NewSymbolizer(log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	defer sym.Close()
+
+	debugInfoFile := func(context.Context) (string, error) {
+		return path, nil
+	}
+
+	// The executable segment at file offset 0x1000 is mapped in two parts at
+	// unrelated addresses, "work" is at 0x1149 and "main" at 0x1154.
+	for _, tc := range []struct {
+		mapping  *pb.Mapping
+		address  uint64
+		function string
+	}{{
+		mapping:  &pb.Mapping{Start: 0x7f0000000000, Limit: 0x7f0000000150, Offset: 0x1000},
+		address:  0x7f0000000149,
+		function: "work",
+	}, {
+		mapping:  &pb.Mapping{Start: 0x7f8000000000, Limit: 0x7f8000000059, Offset: 0x1150},
+		address:  0x7f8000000004,
+		function: "main",
+	}} {
+		tc.mapping.BuildId = "a695b153282bb4da64ca7397a7cf029b63a6419f"
+		lines, err := sym.Symbolize(context.Background(), tc.mapping, []*pb.Location{{Address: tc.address}}, debugInfoFile)
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		require.NotEmpty(t, lines[0])
+		require.Equal(t, tc.function, lines[0][len(lines[0])-1].Function.Name)
+	}
+
+	// Each mapping is translated by the segment it maps, even if the
+	// segments are at different distances from their virtual addresses.
+	lnr := &objectLiner{exec: &elfutils.ExecInfo{
+		Type: elf.ET_DYN,
+		ExecSegments: []elf.ProgHeader{
+			{Type: elf.PT_LOAD, Flags: elf.PF_R | elf.PF_X, Off: 0x1000, Vaddr: 0x1000, Filesz: 0x1000},
+			{Type: elf.PT_LOAD, Flags: elf.PF_R | elf.PF_X, Off: 0x2040, Vaddr: 0x4040, Filesz: 0x1000},
+		},
+	}}
+	first := &pb.Mapping{Start: 0x7f0000000000, Limit: 0x7f0000001000, Offset: 0x1000}
+	require.Equal(t, uint64(0x1100), 0x7f0000000100-lnr.base(log.NewNopLogger(), first))
+	second := &pb.Mapping{Start: 0x7f8000000000, Limit: 0x7f8000001000, Offset: 0x2000}
+	require.Equal(t, uint64(0x4100), 0x7f8000000100-lnr.base(log.NewNopLogger(), second))
+}
+
+// functionAddresses returns the addresses of up to n functions of the object
+// file, spread over all of its functions.
+func functionAddresses(t testing.TB, path string, n int) []uint64 {
+	t.Helper()
+
+	f, err := elf.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	symbols, err := f.Symbols()
+	require.NoError(t, err)
+
+	var addrs []uint64
+	for _, s := range symbols {
+		if elf.ST_TYPE(s.Info) == elf.STT_FUNC && s.Value != 0 {
+			addrs = append(addrs, s.Value)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	if len(addrs) <= n {
+		return addrs
+	}
+	spread := make([]uint64, 0, n)
+	for i := 0; i < n; i++ {
+		spread = append(spread, addrs[i*len(addrs)/n])
+	}
+	return spread
+}
+
+// symbolizeDWARF resolves the addresses with a new DWARF liner of the object
+// file.
+func symbolizeDWARF(t testing.TB, path string, pool *elfutils.BufferPool, addrs []uint64) [][]profile.LocationLine {
+	lnr, err := addr2line.DWARF(log.NewNopLogger(), path, demangle.NewDemangler("simple", false), pool)
+	require.NoError(t, err)
+
+	lines := make([][]profile.LocationLine, 0, len(addrs))
+	for _, addr := range addrs {
+		l, err := lnr.PCToLines(context.Background(), addr)
+		if err != nil {
+			require.ErrorIs(t, err, ErrAddressNotFound)
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+func BenchmarkSymbolizeAllocs(b *testing.B) {
+	const path = "../symbolizer/testdata/2d6912fd3dd64542f6f6294f4bf9cb6c265b3085/debuginfo"
+	addrs := functionAddresses(b, path, 200)
+
+	for _, bc := range []struct {
+		name string
+		pool *elfutils.BufferPool
+	}{{
+		name: "without-pool",
+	}, {
+		name: "with-pool",
+		pool: elfutils.NewBufferPool(),
+	}} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				symbolizeDWARF(b, path, bc.pool, addrs)
+			}
+		})
+	}
+}
+
+func TestDWARFBufferPoolConcurrentSymbolization(t *testing.T) {
+	paths := []string{
+		"../symbolizer/testdata/2d6912fd3dd64542f6f6294f4bf9cb6c265b3085/debuginfo",
+		"../symbolizer/testdata/e94c2ed1e1276255de44b79f0e74234cf7c70bb3/debuginfo",
+		"../symbolizer/testdata/a695b153282bb4da64ca7397a7cf029b63a6419f/debuginfo",
+	}
+	addrs := make([][]uint64, len(paths))
+	expected := make([][][]profile.LocationLine, len(paths))
+	for i, path := range paths {
+		addrs[i] = functionAddresses(t, path, 50)
+		expected[i] = symbolizeDWARF(t, path, nil, addrs[i])
+	}
+
+	// The object files are symbolized at the same time with buffers of the
+	// same pool, which mustn't leak entries of one into another.
+	pool := elfutils.NewBufferPool()
+	var wg sync.WaitGroup
+	results := make([][][]profile.LocationLine, 4*len(paths))
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = symbolizeDWARF(t, paths[i%len(paths)], pool, addrs[i%len(paths)])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, lines := range results {
+		requireEqualLines(t, expected[i%len(paths)], lines)
+	}
+}
+
+func TestSymbolizeMachODSYM(t *testing.T) {
+	path, err := elfutils.DSYMPath("../symbolizer/testdata/hello.dSYM")
+	require.NoError(t, err)
+	require.Equal(t, "../symbolizer/testdata/hello.dSYM/Contents/Resources/DWARF/hello", path)
+	require.True(t, elfutils.IsMachO(path))
+	require.NoError(t, elfutils.ValidateFile(path))
+
+	buildID, err := elfutils.BuildID(path)
+	require.NoError(t, err)
+	require.Equal(t, "6a016c5405e8329ebe68600dcdf3903b", buildID)
+
+	sym, err := NewSymbolizer(log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	defer sym.Close()
+
+	// The __TEXT segment has the virtual address 0x1000000, and was slid to
+	// 0x6000000 at runtime.
+	lines, err := sym.Symbolize(context.Background(), &pb.Mapping{
+		Start:   0x6000000,
+		Limit:   0x6106000,
+		BuildId: buildID,
+	}, []*pb.Location{{Address: 0x6079924}}, func(context.Context) (string, error) {
+		return path, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	require.Len(t, lines[0], 1)
+	require.Equal(t, "main.square", lines[0][0].Function.Name)
+	require.Equal(t, "example.com/macho/main.go", lines[0][0].Function.Filename)
+	require.Equal(t, int64(5), lines[0][0].Line)
+}
+
+func TestSymbolizePDB(t *testing.T) {
+	const path = "../symbolizer/testdata/hello.pdb"
+	require.NoError(t, elfutils.ValidateFile(path))
+
+	// The PDB file and its PE image share the GUID and age.
+	buildID, err := elfutils.BuildID(path)
+	require.NoError(t, err)
+	require.Equal(t, "b9db443817204967be7aa4a2c20430fa00000002", buildID)
+	peBuildID, err := elfutils.BuildID("../symbolizer/testdata/hello.exe")
+	require.NoError(t, err)
+	require.Equal(t, buildID, peBuildID)
+
+	sym, err := NewSymbolizer(log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	defer sym.Close()
+
+	// square is at the relative virtual address 0x1010 of the image, which
+	// was mapped at 0x7ff6a0000000 at runtime.
+	lines, err := sym.Symbolize(context.Background(), &pb.Mapping{
+		Start:   0x7ff6a0000000,
+		Limit:   0x7ff6a0003000,
+		BuildId: buildID,
+	}, []*pb.Location{{Address: 0x7ff6a0001012}, {Address: 0x7ff6a000101c}}, func(context.Context) (string, error) {
+		return path, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	for i, line := range []int64{3, 4} {
+		require.Len(t, lines[i], 1)
+		require.Equal(t, "square", lines[i][0].Function.Name)
+		require.Equal(t, `C:\src\hello.c`, lines[i][0].Function.Filename)
+		require.Equal(t, int64(3), lines[i][0].Function.StartLine)
+		require.Equal(t, line, lines[i][0].Line)
+	}
+}
+
+func TestSymbolizeWithoutLineProgram(t *testing.T) {
+	// The object file still has DWARF, but its .debug_line section was
+	// stripped, so only the symbol table can resolve the functions.
+	const path = "../symbolizer/testdata/nolineprogram/debuginfo"
+	hasDWARF, err := elfutils.HasDWARF(path)
+	require.NoError(t, err)
+	require.True(t, hasDWARF)
+
+	sym, err := NewSymbolizer(log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	defer sym.Close()
+
+	lines, err := sym.Symbolize(context.Background(), &pb.Mapping{
+		Start:   0x400000,
+		Limit:   0x402000,
+		BuildId: "e94c2ed1e1276255de44b79f0e74234cf7c70bb3",
+	}, []*pb.Location{{Address: 0x401138}, {Address: 0x401151}}, func(context.Context) (string, error) {
+		return path, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	for i, name := range []string{"work", "main"} {
+		require.Len(t, lines[i], 1)
+		require.Equal(t, name, lines[i][0].Function.Name)
+		require.Equal(t, "?", lines[i][0].Function.Filename)
+		require.Equal(t, int64(0), lines[i][0].Line)
+	}
+}
+
+func TestSymbolizePathRewrites(t *testing.T) {
+	rewrites, err := ParsePathRewrites([]string{
+		"/usr/src=/src",
+		"/home/brancz/src=/repo",
+		// Not applied, the rewrite before matches first.
+		"/home=/other",
+	})
+	require.NoError(t, err)
+
+	sym, err := NewSymbolizer(log.NewNopLogger(), prometheus.NewRegistry(), WithPathRewrites(rewrites...))
+	require.NoError(t, err)
+	defer sym.Close()
+
+	lines, err := sym.Symbolize(context.Background(), &pb.Mapping{
+		Start:   4194304,
+		Limit:   4603904,
+		BuildId: "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085",
+	}, []*pb.Location{{Address: 0x463781}}, func(context.Context) (string, error) {
+		return "../symbolizer/testdata/2d6912fd3dd64542f6f6294f4bf9cb6c265b3085/debuginfo", nil
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	require.Len(t, lines[0], 3)
+	for _, line := range lines[0] {
+		require.Equal(t, "/repo/github.com/polarsignals/pprof-labels-example/main.go", line.Function.Filename)
+	}
+
+	_, err = ParsePathRewrites([]string{"/home/brancz/src"})
+	require.Error(t, err)
+}
+
+func TestAddressErrorsIsAs(t *testing.T) {
+	errNoLines := errors.New("no lines")
+	var err error = fmt.Errorf("symbolize: %w", AddressErrors{
+		{BuildID: "abcd", Address: 0x1000, Err: errors.New("other")},
+		{BuildID: "abcd", Address: 0x2000, Err: errNoLines},
+	})
+
+	require.ErrorIs(t, err, errNoLines)
+	require.NotErrorIs(t, err, context.Canceled)
+
+	var addrErr *AddressError
+	require.ErrorAs(t, err, &addrErr)
+	require.Equal(t, uint64(0x1000), addrErr.Address)
+
+	var addrErrs AddressErrors
+	require.ErrorAs(t, err, &addrErrs)
+	require.Len(t, addrErrs, 2)
+}
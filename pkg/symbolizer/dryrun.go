@@ -0,0 +1,87 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symbolizer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/debuginfo"
+)
+
+// DryRunReport is the outcome symbolizing locations would have.
+type DryRunReport struct {
+	// BuildIDs are the counts of the locations of every build ID that would
+	// be looked up in debug info.
+	BuildIDs map[string]*DryRunCounts
+	// Skipped are the numbers of the other locations by the reason they are
+	// skipped for, either "already-symbolized", "unsymbolizable-mapping" or
+	// "missing-build-id".
+	Skipped map[string]int
+}
+
+// DryRunCounts are the numbers of locations of a build ID.
+type DryRunCounts struct {
+	// Resolvable locations have debug info to be symbolized with, though
+	// their addresses may still not be found in it.
+	Resolvable int
+	// MissingDebugInfo locations are skipped, as there is no debug info for
+	// them or it is known to be missing.
+	MissingDebugInfo int
+}
+
+// DryRun reports what symbolizing the given locations would do, without
+// fetching or reading debug info or writing to the metastore. Locations are
+// grouped by build ID the same way Symbolize groups them, and the debug info
+// of every build ID is only checked for existence. Debug info that only
+// debuginfod servers have is reported as missing, as finding out would mean
+// downloading it.
+func (s *Symbolizer) DryRun(ctx context.Context, locations []*pb.Location) (*DryRunReport, error) {
+	locationsByBuildIDs, skipped, err := s.groupLocations(ctx, locations)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DryRunReport{
+		BuildIDs: make(map[string]*DryRunCounts, len(locationsByBuildIDs)),
+		Skipped:  skipped,
+	}
+	for _, locationsByBuildID := range locationsByBuildIDs {
+		buildID := locationsByBuildID.BuildID
+		found, err := s.hasDebugInfo(ctx, buildID)
+		if err != nil {
+			return nil, fmt.Errorf("check debuginfo (BuildID: %q): %w", buildID, err)
+		}
+
+		counts := &DryRunCounts{}
+		if found {
+			counts.Resolvable = len(locationsByBuildID.Locations)
+		} else {
+			counts.MissingDebugInfo = len(locationsByBuildID.Locations)
+		}
+		report.BuildIDs[buildID] = counts
+	}
+	return report, nil
+}
+
+// hasDebugInfo reports whether the debug info of the build ID would be used
+// to symbolize its locations.
+func (s *Symbolizer) hasDebugInfo(ctx context.Context, buildID string) (bool, error) {
+	if st, ok := s.debuginfo.DebugInfoStatus(buildID); ok && st.State == debuginfo.StatusStateNotUploaded && time.Since(st.UpdatedAt) < s.missingDebugInfoTTL {
+		return false, nil
+	}
+	return s.debuginfo.HasDebugInfo(ctx, buildID)
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package symbolizer
+
+import (
+	"time"
+)
+
+type Option func(*Symbolizer)
+
+// WithBatchSize sets the maximum number of locations symbolized per cycle. A
+// size of 0 means unlimited.
+func WithBatchSize(size uint32) Option {
+	return func(s *Symbolizer) {
+		s.batchSize = size
+	}
+}
+
+// WithConcurrency sets the number of object files symbolized in parallel.
+func WithConcurrency(n int) Option {
+	return func(s *Symbolizer) {
+		s.concurrency = n
+	}
+}
+
+// WithFetchConcurrency sets the maximum number of debug info files fetched in
+// parallel for symbolization.
+func WithFetchConcurrency(n int) Option {
+	return func(s *Symbolizer) {
+		s.fetchConcurrency = n
+	}
+}
+
+// WithMissingDebugInfoTTL sets the duration to wait before looking for debug
+// info again that was found to be missing, unless it is uploaded.
+func WithMissingDebugInfoTTL(ttl time.Duration) Option {
+	return func(s *Symbolizer) {
+		s.missingDebugInfoTTL = ttl
+	}
+}
+
+// WithReverification makes the symbolizer symbolize locations again that were
+// symbolized longer than maxAge ago, e.g. to pick up debug info uploaded
+// since, and store their lines if they changed. Up to batchSize locations
+// are re-verified per cycle, only once there are no unsymbolized locations
+// left to look at. A maxAge of 0 disables it.
+func WithReverification(maxAge time.Duration, batchSize uint32) Option {
+	return func(s *Symbolizer) {
+		s.reverifyAge = maxAge
+		s.reverifyBatchSize = batchSize
+	}
+}
+
+// WithMaxLinesPerLocation limits the number of lines stored per location,
+// which deeply inlined code can make grow into the hundreds. Only the
+// outermost lines of a location exceeding it are kept, so that it still
+// continues the stacks of its callers, and it is marked as truncated. A max
+// of 0 means unlimited.
+func WithMaxLinesPerLocation(max int) Option {
+	return func(s *Symbolizer) {
+		s.maxLinesPerLocation = max
+	}
+}
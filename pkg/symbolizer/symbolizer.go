@@ -19,21 +19,59 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
+	"github.com/parca-dev/parca/pkg/debuginfo"
+	"github.com/parca-dev/parca/pkg/logfields"
+	"github.com/parca-dev/parca/pkg/metastore"
 	"github.com/parca-dev/parca/pkg/profile"
-	"github.com/parca-dev/parca/pkg/runutil"
 	"github.com/parca-dev/parca/pkg/symbol"
 )
 
+// Reasons for locations failing to be symbolized.
+const (
+	failureReasonFetch           = "fetch-error"
+	failureReasonParse           = "parse-error"
+	failureReasonAddressNotFound = "address-not-found"
+	failureReasonNotUploaded     = "not-uploaded"
+)
+
+// Results of re-verifying the symbolization of locations.
+const (
+	reverifyResultChanged   = "changed"
+	reverifyResultUnchanged = "unchanged"
+	reverifyResultFailed    = "failed"
+)
+
+// Reasons for locations being skipped without looking at debug info.
+const (
+	skipReasonSymbolized     = "already-symbolized"
+	skipReasonUnsymbolizable = "unsymbolizable-mapping"
+	skipReasonNoBuildID      = "missing-build-id"
+)
+
+// defaultMissingDebugInfoTTL is how long debug info found to be missing is not
+// looked for again by default.
+const defaultMissingDebugInfoTTL = 10 * time.Minute
+
 type Symbolizer struct {
 	logger log.Logger
 
+	fetchDuration prometheus.Histogram
+	failures      *prometheus.CounterVec
+	skippedCycles prometheus.Counter
+	reverified    *prometheus.CounterVec
+	truncated     prometheus.Counter
+
 	metastore  pb.MetastoreServiceClient
 	symbolizer *symbol.Symbolizer
 	debuginfo  DebugInfoFetcher
@@ -44,76 +82,350 @@ type Symbolizer struct {
 	debuginfodCacheDir string
 	debuginfoCacheDir  string
 
+	// Debug info that was found to be missing is not looked for again until
+	// this much time passed, or it is uploaded.
+	missingDebugInfoTTL time.Duration
+
+	// batchSize is the maximum number of locations symbolized per cycle, 0
+	// means unlimited. The next cycle continues after the last location of
+	// the previous one.
 	batchSize uint32
+	nextKey   string
+	// cycle is held while a symbolization cycle is running.
+	cycle chan struct{}
+
+	// Locations symbolized longer than reverifyAge ago are symbolized again
+	// in cycles without unsymbolized locations left to look at, at most
+	// reverifyBatchSize of them per cycle. A reverifyAge of 0 disables it.
+	reverifyAge       time.Duration
+	reverifyBatchSize uint32
+	now               func() time.Time
+
+	// The debug info of up to concurrency build IDs is symbolized in
+	// parallel, of which only up to fetchConcurrency may be fetching their
+	// debug info files at the same time.
+	concurrency      int
+	fetchConcurrency int
+	fetchSem         chan struct{}
+
+	// maxLinesPerLocation is the maximum number of lines stored per
+	// location, 0 means unlimited.
+	maxLinesPerLocation int
+
+	// done is canceled by Stop, which then waits for Run and the
+	// re-symbolizations started in the background to return.
+	mtx     sync.Mutex
+	done    context.Context
+	stop    context.CancelFunc
+	running sync.WaitGroup
 }
 
 type DebugInfoFetcher interface {
 	// Fetch ensures that the debug info for the given build ID is available on
 	// a local filesystem and returns a path to it.
 	FetchDebugInfo(ctx context.Context, buildID string) (string, debuginfopb.DownloadInfo_Source, error)
+	// DebugInfoStatus returns the last known status of the debug info for the given build ID.
+	DebugInfoStatus(buildID string) (debuginfo.Status, bool)
+	// MarkSymbolized records that the debug info for the given build ID was used for symbolization.
+	MarkSymbolized(buildID string)
+	// HasDebugInfo reports whether the debug info for the given build ID is
+	// available, without fetching it.
+	HasDebugInfo(ctx context.Context, buildID string) (bool, error)
 }
 
 func New(
 	logger log.Logger,
+	reg prometheus.Registerer,
 	metastore pb.MetastoreServiceClient,
 	debuginfo DebugInfoFetcher,
 	symbolizer *symbol.Symbolizer,
 	debuginfodCacheDir string,
 	debuginfoCacheDir string,
-	batchSize uint32,
+	opts ...Option,
 ) *Symbolizer {
-	return &Symbolizer{
-		logger:             log.With(logger, "component", "symbolizer"),
+	done, stop := context.WithCancel(context.Background())
+
+	fetchDuration := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "parca_symbolizer_debuginfo_fetch_duration_seconds",
+			Help:    "Duration of fetching the debug information of an object file to symbolize it.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 8),
+		},
+	)
+	failures := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "parca_symbolizer_location_failures_total",
+			Help: "Total number of locations that failed to be symbolized by reason.",
+		},
+		[]string{"reason"},
+	)
+	for _, reason := range []string{failureReasonFetch, failureReasonParse, failureReasonAddressNotFound, failureReasonNotUploaded} {
+		failures.WithLabelValues(reason)
+	}
+	skippedCycles := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "parca_symbolizer_cycles_skipped_total",
+			Help: "Total number of symbolization cycles skipped because the previous one was still running.",
+		},
+	)
+	reverified := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "parca_symbolizer_reverified_locations_total",
+			Help: "Total number of symbolized locations that were symbolized again by whether their lines changed.",
+		},
+		[]string{"result"},
+	)
+	for _, result := range []string{reverifyResultChanged, reverifyResultUnchanged, reverifyResultFailed} {
+		reverified.WithLabelValues(result)
+	}
+	truncated := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "parca_symbolizer_truncated_locations_total",
+			Help: "Total number of symbolized locations whose lines were truncated because they exceeded the maximum number of lines per location.",
+		},
+	)
+	reg.MustRegister(fetchDuration, failures, skippedCycles, reverified, truncated)
+
+	s := &Symbolizer{
+		logger:             logfields.WithComponent(logger, "symbolizer"),
+		fetchDuration:      fetchDuration,
+		failures:           failures,
+		skippedCycles:      skippedCycles,
+		reverified:         reverified,
+		truncated:          truncated,
 		metastore:          metastore,
 		symbolizer:         symbolizer,
 		debuginfo:          debuginfo,
 		debuginfodCacheDir: debuginfodCacheDir,
 		debuginfoCacheDir:  debuginfoCacheDir,
-		batchSize:          batchSize,
+
+		missingDebugInfoTTL: defaultMissingDebugInfoTTL,
+		cycle:               make(chan struct{}, 1),
+		now:                 time.Now,
+
+		concurrency:      1,
+		fetchConcurrency: 1,
+
+		done: done,
+		stop: stop,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.concurrency < 1 {
+		s.concurrency = 1
+	}
+	if s.fetchConcurrency < 1 {
+		s.fetchConcurrency = 1
+	}
+	s.fetchSem = make(chan struct{}, s.fetchConcurrency)
+
+	return s
 }
 
+// Run symbolizes unsymbolized locations right away and then in the given
+// interval, until the context is canceled or the symbolizer is stopped. A
+// cycle is skipped if the previous one is still running.
 func (s *Symbolizer) Run(ctx context.Context, interval time.Duration) error {
-	return runutil.Repeat(interval, ctx.Done(), func() error {
-		level.Debug(s.logger).Log("msg", "start symbolization cycle")
-		s.runSymbolizationCycle(ctx)
-		level.Debug(s.logger).Log("msg", "symbolization loop completed")
+	if !s.track() {
 		return nil
-	})
+	}
+	defer s.running.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	return s.run(ctx, ticker.C)
+}
+
+func (s *Symbolizer) run(ctx context.Context, ticks <-chan time.Time) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	start := func() {
+		select {
+		case s.cycle <- struct{}{}:
+		default:
+			level.Debug(s.logger).Log("msg", "skipping symbolization cycle, the previous one is still running")
+			s.skippedCycles.Inc()
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-s.cycle }()
+
+			level.Debug(s.logger).Log("msg", "start symbolization cycle")
+			s.runSymbolizationCycle(ctx)
+			level.Debug(s.logger).Log("msg", "symbolization loop completed")
+		}()
+	}
+
+	start()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done.Done():
+			return nil
+		case <-ticks:
+			start()
+		}
+	}
 }
 
 func (s *Symbolizer) runSymbolizationCycle(ctx context.Context) {
+	lres, err := s.metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{
+		Limit:  s.batchSize,
+		MinKey: s.nextKey,
+	})
+	if err != nil {
+		level.Error(s.logger).Log("msg", "failed to fetch unsymbolized locations", "err", err)
+		// Try again on the next cycle.
+		return
+	}
+
+	// Once all locations were looked at, the next cycle starts over.
+	s.nextKey = ""
+	if s.batchSize > 0 && uint32(len(lres.Locations)) == s.batchSize {
+		s.nextKey = lres.MaxKey
+	}
+
+	if len(lres.Locations) > 0 {
+		level.Debug(s.logger).Log("msg", "attempting to symbolize locations", "count", len(lres.Locations))
+		err = s.Symbolize(ctx, lres.Locations)
+		if err != nil {
+			level.Warn(s.logger).Log("msg", "symbolization attempt finished with errors")
+			level.Debug(s.logger).Log("msg", "errors occurred during symbolization", "err", err)
+		}
+	} else {
+		level.Debug(s.logger).Log("msg", "no locations to symbolize")
+	}
+
+	// Re-verification only gets the cycles that have no new locations left
+	// to symbolize, so that it never delays their symbolization.
+	if s.reverifyAge > 0 && len(lres.Locations) == 0 {
+		s.runReverificationCycle(ctx)
+	}
+}
+
+// runReverificationCycle symbolizes the locations again that were
+// symbolized the longest ago, if longer than the re-verification age.
+func (s *Symbolizer) runReverificationCycle(ctx context.Context) {
+	lres, err := s.metastore.SymbolizedLocations(ctx, &pb.SymbolizedLocationsRequest{
+		SymbolizedBefore: timestamppb.New(s.now().Add(-s.reverifyAge)),
+		Limit:            s.reverifyBatchSize,
+	})
+	if err != nil {
+		level.Error(s.logger).Log("msg", "failed to fetch symbolized locations to re-verify", "err", err)
+		return
+	}
+	if len(lres.Locations) == 0 {
+		level.Debug(s.logger).Log("msg", "no locations to re-verify")
+		return
+	}
+
+	level.Debug(s.logger).Log("msg", "attempting to re-verify locations", "count", len(lres.Locations))
+	if err := s.reverify(ctx, lres.Locations); err != nil {
+		level.Debug(s.logger).Log("msg", "errors occurred during re-verification", "err", err)
+	}
+}
+
+// reverify symbolizes the symbolized locations again, and replaces their
+// lines if they changed. The locations that can't be symbolized anymore keep
+// their lines, all of them are recorded as symbolized now so that they are
+// not re-verified again until they are old enough.
+func (s *Symbolizer) reverify(ctx context.Context, locations []*pb.Location) error {
+	previousLines := make(map[string][]*pb.Line, len(locations))
+	unsymbolized := make([]*pb.Location, 0, len(locations))
+	for _, loc := range locations {
+		previousLines[loc.Id] = loc.Lines
+		unsymbolized = append(unsymbolized, &pb.Location{
+			Id:            loc.Id,
+			Address:       loc.Address,
+			MappingId:     loc.MappingId,
+			IsFolded:      loc.IsFolded,
+			ReturnAddress: loc.ReturnAddress,
+		})
+	}
+
+	return s.symbolize(ctx, unsymbolized, previousLines)
+}
+
+// ReSymbolize symbolizes all unsymbolized locations of the object file with
+// the given build ID right away, e.g. as its debug info was just uploaded,
+// instead of waiting for the next symbolization cycle.
+func (s *Symbolizer) ReSymbolize(ctx context.Context, buildID string) error {
+	var errs symbolizationErrors
 	prevMaxKey := ""
 	for {
 		lres, err := s.metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{
-			Limit:  s.batchSize,
-			MinKey: prevMaxKey,
+			Limit:   s.batchSize,
+			MinKey:  prevMaxKey,
+			BuildId: buildID,
 		})
 		if err != nil {
-			level.Error(s.logger).Log("msg", "failed to fetch unsymbolized locations", "err", err)
-			// Try again on the next cycle.
-			return
+			return fmt.Errorf("fetch unsymbolized locations of build ID %q: %w", buildID, err)
 		}
 		if len(lres.Locations) == 0 {
-			level.Debug(s.logger).Log("msg", "no locations to symbolize")
-			// Nothing to symbolize.
-			return
+			return errs.err()
 		}
 		prevMaxKey = lres.MaxKey
 
-		level.Debug(s.logger).Log("msg", "attempting to symbolize locations", "count", len(lres.Locations))
-		err = s.Symbolize(ctx, lres.Locations)
-		if err != nil {
-			level.Warn(s.logger).Log("msg", "symbolization attempt finished with errors")
-			level.Debug(s.logger).Log("msg", "errors occurred during symbolization", "err", err)
+		level.Debug(s.logger).Log("msg", "attempting to re-symbolize locations", logfields.BuildID, buildID, "count", len(lres.Locations))
+		if err := s.Symbolize(ctx, lres.Locations); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			errs = append(errs, err)
 		}
 
 		if s.batchSize == 0 {
-			// If batch size is 0 we won't continue with the next batch as we
-			// should have already processed everything.
-			return
+			return errs.err()
+		}
+	}
+}
+
+// ReSymbolizeInBackground re-symbolizes the locations of the object file
+// with the given build ID like ReSymbolize, but in a goroutine that Stop
+// cancels and waits for.
+func (s *Symbolizer) ReSymbolizeInBackground(buildID string) {
+	if !s.track() {
+		return
+	}
+	go func() {
+		defer s.running.Done()
+		if err := s.ReSymbolize(s.done, buildID); err != nil {
+			level.Debug(s.logger).Log("msg", "failed to re-symbolize locations", logfields.BuildID, buildID, "err", err)
 		}
+	}()
+}
+
+// Stop makes Run return and cancels the re-symbolizations in the background,
+// and waits for all of their goroutines to return. Nothing is started
+// anymore once the symbolizer is stopped.
+func (s *Symbolizer) Stop() {
+	s.mtx.Lock()
+	s.stop()
+	s.mtx.Unlock()
+
+	s.running.Wait()
+}
+
+// track adds a goroutine for Stop to wait for, unless the symbolizer is
+// already stopped.
+func (s *Symbolizer) track() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.done.Err() != nil {
+		return false
 	}
+	s.running.Add(1)
+	return true
 }
 
 // UnsymbolizableMapping returns true if a mapping points to a binary for which
@@ -124,8 +436,17 @@ func UnsymbolizableMapping(m *pb.Mapping) bool {
 	return strings.HasPrefix(name, "[") || strings.HasPrefix(name, "linux-vdso") || strings.HasPrefix(m.File, "/dev/dri/")
 }
 
+// MappingLocations are the locations of all mappings of the same object
+// file, identified by its build ID.
 type MappingLocations struct {
-	Mapping   *pb.Mapping
+	// BuildID is the normalized build ID of the object file.
+	BuildID string
+	// Mapping is the first seen mapping of the object file.
+	Mapping *pb.Mapping
+	// Mappings are the mappings of the locations. Different processes may
+	// map the same object file at different addresses, so every location is
+	// translated with its own mapping.
+	Mappings  []*pb.Mapping
 	Locations []*pb.Location
 
 	// LocationsLines is a list of lines per location.
@@ -133,74 +454,104 @@ type MappingLocations struct {
 }
 
 func (s *Symbolizer) Symbolize(ctx context.Context, locations []*pb.Location) error {
-	mappingsIndex := map[string]int{}
-	mappingIDs := []string{}
-	for _, loc := range locations {
-		if _, ok := mappingsIndex[loc.MappingId]; !ok {
-			mappingIDs = append(mappingIDs, loc.MappingId)
-			mappingsIndex[loc.MappingId] = len(mappingIDs) - 1
-		}
-	}
+	return s.symbolize(ctx, locations, nil)
+}
 
-	mres, err := s.metastore.Mappings(ctx, &pb.MappingsRequest{MappingIds: mappingIDs})
-	if err != nil {
-		return fmt.Errorf("get mappings: %w", err)
-	}
+// symbolize symbolizes the locations and stores their lines. If
+// previousLines is not nil the locations are re-verified: their lines
+// replace the previous ones, and the locations that couldn't be symbolized
+// are stored with their previous lines. Once the context is done, the error
+// of the context is returned and nothing is stored.
+func (s *Symbolizer) symbolize(ctx context.Context, locations []*pb.Location, previousLines map[string][]*pb.Line) error {
+	reverify := previousLines != nil
 
-	// Aggregate locations per mapping to get prepared for batch request.
-	locationsByMappings := make([]*MappingLocations, len(mres.Mappings))
-	for i, m := range mres.Mappings {
-		locationsByMappings[i] = &MappingLocations{Mapping: m}
+	locationsByBuildIDs, _, err := s.groupLocations(ctx, locations)
+	if err != nil {
+		return err
 	}
 
-	for _, loc := range locations {
-		locationsByMapping := locationsByMappings[mappingsIndex[loc.MappingId]]
-		// Already symbolized!
-		if loc.Lines != nil && len(loc.Lines) > 0 {
-			level.Debug(s.logger).Log("msg", "location already symbolized, skipping")
-			continue
+	// The object files are independent of each other, a failure to symbolize
+	// one of them doesn't prevent storing the symbols found for the others.
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, s.concurrency)
+		mtx  sync.Mutex
+		errs symbolizationErrors
+	)
+	for _, locationsByBuildID := range locationsByBuildIDs {
+		locationsByBuildID := locationsByBuildID
+
+		// Once the context is done, the remaining object files are not
+		// looked at anymore.
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
 		}
-		locationsByMapping.Locations = append(locationsByMapping.Locations, loc)
-	}
-
-	for _, locationsByMapping := range locationsByMappings {
-		mapping := locationsByMapping.Mapping
-
-		// If Mapping or Mapping.BuildID is empty, we cannot associate an object file with functions.
-		if mapping == nil || len(mapping.BuildId) == 0 || UnsymbolizableMapping(mapping) {
-			level.Debug(s.logger).Log("msg", "mapping of location is empty, skipping")
-			continue
+		if ctx.Err() != nil {
+			break
 		}
-		logger := log.With(s.logger, "buildid", mapping.BuildId)
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			buildID := locationsByBuildID.BuildID
+			logger := logfields.WithBuildID(s.logger, buildID)
+
+			level.Debug(logger).Log("msg", "storage symbolization request started", "build_id_length", len(buildID))
+			// Symbolize returns a list of lines per location passed to it.
+			// The lines of the locations that could be resolved are stored
+			// even if others couldn't.
+			lines, err := s.symbolizeLocationsForMapping(ctx, buildID, locationsByBuildID.Mappings, locationsByBuildID.Locations)
+			locationsByBuildID.LocationsLines = lines
+			if err != nil {
+				level.Debug(logger).Log("msg", "storage symbolization request failed", "err", err)
+				mtx.Lock()
+				errs = append(errs, err)
+				mtx.Unlock()
+				return
+			}
+			level.Debug(logger).Log("msg", "storage symbolization request done")
+		}()
+	}
+	wg.Wait()
 
-		locations := locationsByMapping.Locations
-		level.Debug(logger).Log("msg", "storage symbolization request started", "build_id_length", len(mapping.BuildId))
-		// Symbolize returns a list of lines per location passed to it.
-		locationsByMapping.LocationsLines, err = s.symbolizeLocationsForMapping(ctx, mapping, locations)
-		if err != nil {
-			level.Debug(logger).Log("msg", "storage symbolization request failed", "err", err)
-			continue
-		}
-		level.Debug(logger).Log("msg", "storage symbolization request done")
+	// Nothing is stored once cancelled, the locations are left as they were
+	// to be symbolized by a later pass.
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
 	numFunctions := 0
-	for _, locationsByMapping := range locationsByMappings {
-		for _, locationLines := range locationsByMapping.LocationsLines {
+	for _, locationsByBuildID := range locationsByBuildIDs {
+		for j, locationLines := range locationsByBuildID.LocationsLines {
+			// The lines are ordered from the innermost inlined function to
+			// the function the address belongs to.
+			truncated := s.maxLinesPerLocation > 0 && len(locationLines) > s.maxLinesPerLocation
+			if truncated {
+				locationLines = locationLines[len(locationLines)-s.maxLinesPerLocation:]
+				locationsByBuildID.LocationsLines[j] = locationLines
+				s.truncated.Inc()
+			}
+			if len(locationLines) > 0 {
+				locationsByBuildID.Locations[j].LinesTruncated = truncated
+			}
 			numFunctions += len(locationLines)
 		}
 	}
-	if numFunctions == 0 {
+	if numFunctions == 0 && !reverify {
 		level.Debug(s.logger).Log("msg", "nothing to store after symbolization")
-		return nil
+		return errs.err()
 	}
 	level.Debug(s.logger).Log("msg", "storing found symbols")
 
 	functions := make([]*pb.Function, numFunctions)
 	numLocations := 0
 	i := 0
-	for _, locationsByMapping := range locationsByMappings {
-		for _, locationLines := range locationsByMapping.LocationsLines {
+	for _, locationsByBuildID := range locationsByBuildIDs {
+		for _, locationLines := range locationsByBuildID.LocationsLines {
 			if len(locationLines) == 0 {
 				continue
 			}
@@ -212,22 +563,20 @@ func (s *Symbolizer) Symbolize(ctx context.Context, locations []*pb.Location) er
 		}
 	}
 
-	fres, err := s.metastore.GetOrCreateFunctions(ctx, &pb.GetOrCreateFunctionsRequest{Functions: functions})
-	if err != nil {
-		return fmt.Errorf("get or create functions: %w", err)
-	}
-
-	locations = make([]*pb.Location, 0, numLocations)
+	symbolized := make([]*pb.Location, 0, numLocations)
 	i = 0
-	for _, locationsByMapping := range locationsByMappings {
-		for j, locationLines := range locationsByMapping.LocationsLines {
+	for _, locationsByBuildID := range locationsByBuildIDs {
+		for j, locationLines := range locationsByBuildID.LocationsLines {
 			if len(locationLines) == 0 {
 				continue
 			}
 			lines := make([]*pb.Line, 0, len(locationLines))
 			for _, line := range locationLines {
+				// The metastore derives the IDs of functions from their
+				// content, so the lines can refer to functions that are
+				// created along with them.
 				lines = append(lines, &pb.Line{
-					FunctionId: fres.Functions[i].Id,
+					FunctionId: metastore.MakeFunctionID(functions[i]),
 					Line:       line.Line,
 				})
 				i++
@@ -235,43 +584,343 @@ func (s *Symbolizer) Symbolize(ctx context.Context, locations []*pb.Location) er
 			// Update the location with the lines in-place so that in the next
 			// step we can just reuse the same locations as were originally
 			// passed in.
-			locations = append(locations, locationsByMapping.Locations[j])
-			locationsByMapping.Locations[j].Lines = lines
+			symbolized = append(symbolized, locationsByBuildID.Locations[j])
+			locationsByBuildID.Locations[j].Lines = lines
 		}
 	}
 
-	// At this point the locations are symbolized in-place and we can send them to the metastore.
+	if reverify {
+		for _, location := range symbolized {
+			if linesEqual(location.Lines, previousLines[location.Id]) {
+				s.reverified.WithLabelValues(reverifyResultUnchanged).Inc()
+			} else {
+				s.reverified.WithLabelValues(reverifyResultChanged).Inc()
+			}
+		}
+		for _, location := range locations {
+			if len(location.Lines) == 0 {
+				s.reverified.WithLabelValues(reverifyResultFailed).Inc()
+				location.Lines = previousLines[location.Id]
+				symbolized = append(symbolized, location)
+			}
+		}
+	}
+
+	// At this point the locations are symbolized in-place and we can send
+	// them to the metastore. The functions are created in the same
+	// transaction, so a location is either stored with all of its lines or
+	// left unsymbolized to be retried.
 	_, err = s.metastore.CreateLocationLines(ctx, &pb.CreateLocationLinesRequest{
-		Locations: locations,
+		Locations:    symbolized,
+		Functions:    functions,
+		Replace:      reverify,
+		SymbolizedAt: timestamppb.New(s.now()),
 	})
 	if err != nil {
+		for _, location := range symbolized {
+			location.Lines = nil
+		}
 		return fmt.Errorf("create location lines: %w", err)
 	}
 
-	return nil
+	return errs.err()
 }
 
-// symbolizeLocationsForMapping fetches the debug info for a given build ID and symbolizes it the
-// given location.
-func (s *Symbolizer) symbolizeLocationsForMapping(ctx context.Context, m *pb.Mapping, locations []*pb.Location) ([][]profile.LocationLine, error) {
-	logger := log.With(s.logger, "buildid", m.BuildId)
+// linesEqual returns whether both locations have the same lines.
+func linesEqual(a, b []*pb.Line) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
 
-	// Fetch the debug info for the build ID.
-	objFile, _, err := s.debuginfo.FetchDebugInfo(ctx, m.BuildId)
-	if err != nil {
-		return nil, fmt.Errorf("fetch debuginfo (BuildID: %q): %w", m.BuildId, err)
+// groupLocations aggregates the locations that can be symbolized by the
+// build ID of their mapping, and counts the others by the reason they are
+// skipped for.
+func (s *Symbolizer) groupLocations(ctx context.Context, locations []*pb.Location) ([]*MappingLocations, map[string]int, error) {
+	mappingsIndex := map[string]int{}
+	mappingIDs := []string{}
+	for _, loc := range locations {
+		if _, ok := mappingsIndex[loc.MappingId]; !ok {
+			mappingIDs = append(mappingIDs, loc.MappingId)
+			mappingsIndex[loc.MappingId] = len(mappingIDs) - 1
+		}
 	}
 
-	// At this point we have the best version of the debug information file that we could find.
-	// Let's symbolize it.
-	lines, err := s.symbolizer.Symbolize(ctx, m, locations, objFile)
+	mres, err := s.metastore.Mappings(ctx, &pb.MappingsRequest{MappingIds: mappingIDs})
 	if err != nil {
-		if errors.Is(err, symbol.ErrLinerCreationFailedBefore) {
-			level.Debug(logger).Log("msg", "failed to symbolize before", "err", err)
-			return nil, nil
+		return nil, nil, fmt.Errorf("get mappings: %w", err)
+	}
+
+	// Aggregate locations per build ID to get prepared for batch request.
+	// The same object file is often mapped by many processes, this way its
+	// debug information is only looked at once.
+	buildIDsIndex := map[string]int{}
+	locationsByBuildIDs := []*MappingLocations{}
+	skipped := map[string]int{}
+	for _, loc := range locations {
+		// Already symbolized!
+		if loc.Lines != nil && len(loc.Lines) > 0 {
+			level.Debug(s.logger).Log("msg", "location already symbolized, skipping")
+			skipped[skipReasonSymbolized]++
+			continue
+		}
+
+		mapping := mres.Mappings[mappingsIndex[loc.MappingId]]
+		// If Mapping is empty, we cannot associate an object file with functions.
+		if mapping == nil || UnsymbolizableMapping(mapping) {
+			level.Debug(s.logger).Log("msg", "mapping of location is empty, skipping")
+			skipped[skipReasonUnsymbolizable]++
+			continue
+		}
+		// Anonymous memory and JIT compiled code are mapped without a build
+		// ID. There is no debug info to look up for them, and all of them
+		// would otherwise be grouped under the same empty build ID.
+		if len(mapping.BuildId) == 0 {
+			level.Debug(logfields.WithLocation(s.logger, loc)).Log("msg", "mapping of location has no build ID, skipping")
+			skipped[skipReasonNoBuildID]++
+			continue
+		}
+
+		// The debug info is looked up by the same normalized build ID it
+		// was uploaded with. Build IDs that aren't hex encoded are looked up
+		// as they are.
+		buildID, err := debuginfo.NormalizeBuildID(mapping.BuildId)
+		if err != nil {
+			buildID = mapping.BuildId
+		}
+
+		i, ok := buildIDsIndex[buildID]
+		if !ok {
+			locationsByBuildIDs = append(locationsByBuildIDs, &MappingLocations{BuildID: buildID, Mapping: mapping})
+			i = len(locationsByBuildIDs) - 1
+			buildIDsIndex[buildID] = i
+		}
+		locationsByBuildIDs[i].Mappings = append(locationsByBuildIDs[i].Mappings, mapping)
+		locationsByBuildIDs[i].Locations = append(locationsByBuildIDs[i].Locations, loc)
+	}
+	return locationsByBuildIDs, skipped, nil
+}
+
+// symbolizationErrors are the errors of symbolizing the locations of
+// multiple object files.
+type symbolizationErrors []error
+
+func (e symbolizationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches the target. The errors are
+// matched one by one, as errors.Is only unwraps multiple errors since Go 1.20.
+func (e symbolizationErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches the target, and if so, sets
+// the target to it.
+func (e symbolizationErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// err returns nil if there are no errors.
+func (e symbolizationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// SkippedLocationsError reports the locations of an object file that were
+// left unsymbolized, because its debug information couldn't be used for their
+// mappings. The locations of other mappings and object files are not affected.
+type SkippedLocationsError struct {
+	BuildID string
+	// Reason is why the locations were skipped, it is one of the reasons of
+	// the parca_symbolizer_location_failures_total metric.
+	Reason      string
+	LocationIDs []string
+	Err         error
+}
+
+func (e *SkippedLocationsError) Error() string {
+	return fmt.Sprintf("skipped %d locations of build ID %q (%s): %v", len(e.LocationIDs), e.BuildID, e.Reason, e.Err)
+}
+
+func (e *SkippedLocationsError) Unwrap() error {
+	return e.Err
+}
+
+// symbolizeLocationsForMapping fetches the debug info for a given build ID
+// and symbolizes the given locations, each of them with its own mapping. If
+// only some of the locations can't be resolved, the lines of all locations
+// are returned along with a symbol.AddressErrors reporting them. Likewise, if
+// the debug info can't be used for one of the mappings, the lines of the
+// others are returned along with a SkippedLocationsError.
+func (s *Symbolizer) symbolizeLocationsForMapping(ctx context.Context, buildID string, mappings []*pb.Mapping, locations []*pb.Location) ([][]profile.LocationLine, error) {
+	logger := logfields.WithBuildID(s.logger, buildID)
+
+	if st, ok := s.debuginfo.DebugInfoStatus(buildID); ok && st.State == debuginfo.StatusStateNotUploaded && s.now().Sub(st.UpdatedAt) < s.missingDebugInfoTTL {
+		level.Debug(logger).Log("msg", "debuginfo is known to be missing, skipping", "since", st.UpdatedAt)
+		s.failures.WithLabelValues(failureReasonNotUploaded).Add(float64(len(locations)))
+		return nil, nil
+	}
+
+	// The debug info for the build ID is only fetched if the symbolizer
+	// doesn't already have it opened.
+	var fetchErr error
+	debugInfoFile := func(ctx context.Context) (string, error) {
+		select {
+		case s.fetchSem <- struct{}{}:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		defer func() { <-s.fetchSem }()
+
+		start := time.Now()
+		objFile, _, err := s.debuginfo.FetchDebugInfo(ctx, buildID)
+		s.fetchDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
+			fetchErr = err
+			return "", fmt.Errorf("fetch debuginfo (BuildID: %q): %w", buildID, err)
+		}
+		// At this point we have the best version of the debug information file that we could find.
+		return objFile, nil
+	}
+
+	// Group the locations by mapping, the symbolizer opens the debug info
+	// only once for all of them.
+	mappingIndex := map[string]int{}
+	mappingLocations := [][]int{}
+	for i, m := range mappings {
+		j, ok := mappingIndex[m.Id]
+		if !ok {
+			mappingLocations = append(mappingLocations, nil)
+			j = len(mappingLocations) - 1
+			mappingIndex[m.Id] = j
+		}
+		mappingLocations[j] = append(mappingLocations[j], i)
+	}
+
+	var (
+		addrErrs symbol.AddressErrors
+		skipped  *SkippedLocationsError
+		skip     = make([]bool, len(locations))
+		resolved bool
+	)
+	lines := make([][]profile.LocationLine, len(locations))
+	for _, indices := range mappingLocations {
+		locs := make([]*pb.Location, 0, len(indices))
+		for _, i := range indices {
+			locs = append(locs, locations[i])
+		}
+
+		mapping := mappings[indices[0]]
+		if mapping.BuildId != buildID {
+			// The symbolizer caches the debug info by the build ID of the
+			// mapping, which has to be the one it is invalidated by.
+			mapping = proto.Clone(mapping).(*pb.Mapping)
+			mapping.BuildId = buildID
 		}
 
-		return nil, fmt.Errorf("failed to symbolize locations for mapping: %w", err)
+		mappingLines, err := s.symbolizer.Symbolize(ctx, mapping, locs, debugInfoFile)
+		var mappingAddrErrs symbol.AddressErrors
+		if errors.As(err, &mappingAddrErrs) {
+			addrErrs = append(addrErrs, mappingAddrErrs...)
+			err = nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to symbolize locations for mapping: %w", err)
+			}
+
+			reason := failureReasonParse
+			switch {
+			case errors.Is(fetchErr, debuginfo.ErrDebugInfoNotFound):
+				reason = failureReasonNotUploaded
+			case fetchErr != nil:
+				reason = failureReasonFetch
+			}
+			s.failures.WithLabelValues(reason).Add(float64(len(locs)))
+
+			// Only the locations of this mapping are skipped, the lines
+			// already resolved for the other mappings are kept.
+			for _, i := range indices {
+				skip[i] = true
+			}
+			if errors.Is(err, symbol.ErrLinerCreationFailedBefore) {
+				level.Debug(logger).Log("msg", "failed to symbolize before", "err", err)
+				continue
+			}
+
+			if skipped == nil {
+				skipped = &SkippedLocationsError{
+					BuildID: buildID,
+					Reason:  reason,
+					Err:     fmt.Errorf("failed to symbolize locations for mapping: %w", err),
+				}
+			}
+			for _, loc := range locs {
+				skipped.LocationIDs = append(skipped.LocationIDs, loc.Id)
+			}
+			continue
+		}
+
+		resolved = true
+		for j, i := range indices {
+			lines[i] = mappingLines[j]
+		}
+	}
+	if !resolved {
+		if skipped != nil {
+			return nil, skipped
+		}
+		return nil, nil
+	}
+	s.debuginfo.MarkSymbolized(buildID)
+
+	notFound := 0
+	for i, l := range lines {
+		if len(l) == 0 && !skip[i] {
+			notFound++
+		}
+	}
+	s.failures.WithLabelValues(failureReasonAddressNotFound).Add(float64(notFound))
+
+	if len(addrErrs) > 0 {
+		errs := make(map[uint64]error, len(addrErrs))
+		for _, addrErr := range addrErrs {
+			errs[addrErr.Address] = addrErr.Err
+		}
+		for i, loc := range locations {
+			if err, ok := errs[loc.Address]; ok && len(lines[i]) == 0 {
+				level.Debug(logfields.WithLocation(logger, loc)).Log("msg", "failed to symbolize location", "err", err)
+			}
+		}
+	}
+	if skipped != nil {
+		return lines, skipped
+	}
+	if len(addrErrs) > 0 {
+		return lines, addrErrs
 	}
 	return lines, nil
 }
@@ -14,32 +14,55 @@
 package symbolizer
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"debug/elf"
+	"encoding/json"
+	"fmt"
 	"io"
 	stdlog "log"
 	"net"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
+	"github.com/klauspost/compress/zstd"
 	"github.com/polarsignals/frostdb"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
 	"github.com/thanos-io/objstore/client"
 	"github.com/thanos-io/objstore/providers/filesystem"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"gopkg.in/yaml.v2"
 
+	pprofpb "github.com/parca-dev/parca/gen/proto/go/google/pprof"
 	debuginfopb "github.com/parca-dev/parca/gen/proto/go/parca/debuginfo/v1alpha1"
 	pb "github.com/parca-dev/parca/gen/proto/go/parca/metastore/v1alpha1"
 	profilestorepb "github.com/parca-dev/parca/gen/proto/go/parca/profilestore/v1alpha1"
 	"github.com/parca-dev/parca/pkg/debuginfo"
+	"github.com/parca-dev/parca/pkg/logfields"
 	"github.com/parca-dev/parca/pkg/metastore"
 	"github.com/parca-dev/parca/pkg/metastoretest"
+	"github.com/parca-dev/parca/pkg/objstoretest"
 	"github.com/parca-dev/parca/pkg/parcacol"
 	"github.com/parca-dev/parca/pkg/profilestore"
+	"github.com/parca-dev/parca/pkg/server"
 	"github.com/parca-dev/parca/pkg/symbol"
 )
 
@@ -92,35 +115,2131 @@ func TestSymbolizer(t *testing.T) {
 	})
 	require.NoError(t, err)
 	require.Equal(t, 1, len(lres.Locations))
-	require.Equal(t, 3, len(lres.Locations[0].Lines))
 
-	functionIds := []string{}
-	for _, location := range lres.Locations {
-		for _, line := range location.Lines {
-			functionIds = append(functionIds, line.FunctionId)
+	requireLines(t, metastore, lres.Locations[0], []expectedLine{
+		{name: "main.iterate", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 27},
+		{name: "main.iteratePerTenant", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 23},
+		{name: "main.main", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 10},
+	})
+}
+
+func TestSymbolizerNormalizesBuildID(t *testing.T) {
+	_, metastore, sym := setup(t)
+	ctx := context.Background()
+
+	// The debug info is stored under the lowercase build ID.
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   4194304,
+			Limit:   4603904,
+			BuildId: "2D6912FD3DD64542F6F6294F4BF9CB6C265B3085",
+		}},
+	})
+	require.NoError(t, err)
+
+	clres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			MappingId: mres.Mappings[0].Id,
+			Address:   0x463781,
+		}},
+	})
+	require.NoError(t, err)
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.NoError(t, sym.Symbolize(ctx, ures.Locations))
+
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{
+		LocationIds: []string{clres.Locations[0].Id},
+	})
+	require.NoError(t, err)
+	requireLines(t, metastore, lres.Locations[0], []expectedLine{
+		{name: "main.iterate", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 27},
+		{name: "main.iteratePerTenant", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 23},
+		{name: "main.main", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 10},
+	})
+}
+
+func TestSymbolizerGoWithoutDWARF(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	ctx := context.Background()
+
+	// The binary of TestSymbolizer with its DWARF stripped, so it can only
+	// be symbolized using the .gopclntab.
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   4194304,
+			Limit:   4603904,
+			BuildId: "624631536e6879525a794a42506d4a6a4f4258482f36784b4268655a45425230646d47784c5f526e672f7351724856694d67424e794376525f4b674a5f692f733968736f6266664f792d6a4e38314937346c75",
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(mres.Mappings))
+	m := mres.Mappings[0]
+
+	clres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			MappingId: m.Id,
+			Address:   0x463781,
+		}},
+	})
+	require.NoError(t, err)
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ures.Locations))
+
+	err = sym.Symbolize(ctx, ures.Locations)
+	require.NoError(t, err)
+
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{
+		LocationIds: []string{clres.Locations[0].Id},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(lres.Locations))
+
+	// The inlined functions are resolved the same as with DWARF.
+	requireLines(t, metastore, lres.Locations[0], []expectedLine{
+		{name: "main.iterate", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 27},
+		{name: "main.iteratePerTenant", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 23},
+		{name: "main.main", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 10},
+	})
+}
+
+func TestSymbolizerInlinedFunctions(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	ctx := context.Background()
+
+	// Built from testdata/inlined.c using:
+	// gcc -O1 -g -gdwarf-4 -no-pie -fno-pie -Wl,--build-id -fdebug-prefix-map=$(pwd)=/src -o inlined inlined.c
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   0x401000,
+			Limit:   0x402000,
+			BuildId: "e94c2ed1e1276255de44b79f0e74234cf7c70bb3",
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(mres.Mappings))
+	m := mres.Mappings[0]
+
+	clres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			// Call to work() from leaf(), inlined into middle(), inlined into main().
+			MappingId: m.Id,
+			Address:   0x401151,
+		}, {
+			// Return address of the call to work(), only middle() is inlined.
+			MappingId: m.Id,
+			Address:   0x401156,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(clres.Locations))
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ures.Locations))
+
+	require.NoError(t, sym.Symbolize(ctx, ures.Locations))
+
+	// Once all inlined frames are resolved, the locations are considered symbolized.
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(ures.Locations))
+
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{
+		LocationIds: []string{clres.Locations[0].Id, clres.Locations[1].Id},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(lres.Locations))
+
+	requireLines(t, metastore, lres.Locations[0], []expectedLine{
+		{name: "leaf", filename: "/src/inlined.c", line: 9},
+		{name: "middle", filename: "/src/inlined.c", line: 13},
+		{name: "main", filename: "/src/inlined.c", line: 18},
+	})
+	requireLines(t, metastore, lres.Locations[1], []expectedLine{
+		{name: "middle", filename: "/src/inlined.c", line: 13},
+		{name: "main", filename: "/src/inlined.c", line: 18},
+	})
+}
+
+func TestSymbolizerPositionIndependentExecutable(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	ctx := context.Background()
+
+	// Built from testdata/inlined.c using:
+	// gcc -O1 -g -gdwarf-4 -fpie -pie -Wl,--build-id -fdebug-prefix-map=$(pwd)=/src -o inlined-pie inlined.c
+	// The executable segment at file offset 0x1000 is mapped to the
+	// virtual address 0x1000, and the executable is loaded at 0x55d3e0a00000.
+	const loadAddress = 0x55d3e0a00000
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   loadAddress + 0x1000,
+			Limit:   loadAddress + 0x2000,
+			Offset:  0x1000,
+			BuildId: "a695b153282bb4da64ca7397a7cf029b63a6419f",
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(mres.Mappings))
+	m := mres.Mappings[0]
+
+	clres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			// Call to work() from leaf(), inlined into middle(), inlined into main().
+			MappingId: m.Id,
+			Address:   loadAddress + 0x1164,
+		}, {
+			// Already translated by the client.
+			MappingId: m.Id,
+			Address:   0x1169,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(clres.Locations))
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ures.Locations))
+
+	require.NoError(t, sym.Symbolize(ctx, ures.Locations))
+
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{
+		LocationIds: []string{clres.Locations[0].Id, clres.Locations[1].Id},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(lres.Locations))
+
+	requireLines(t, metastore, lres.Locations[0], []expectedLine{
+		{name: "leaf", filename: "/src/inlined.c", line: 9},
+		{name: "middle", filename: "/src/inlined.c", line: 13},
+		{name: "main", filename: "/src/inlined.c", line: 18},
+	})
+	requireLines(t, metastore, lres.Locations[1], []expectedLine{
+		{name: "middle", filename: "/src/inlined.c", line: 13},
+		{name: "main", filename: "/src/inlined.c", line: 18},
+	})
+}
+
+type expectedLine struct {
+	name     string
+	filename string
+	line     int64
+}
+
+// requireLines asserts that the lines of the given location match the
+// expected lines, innermost frame first.
+func TestSymbolizerRust(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	ctx := context.Background()
+
+	// Built from testdata/fib.rs using:
+	// rustc -C panic=abort -C opt-level=1 -g -C relocation-model=static -C link-arg=-nostartfiles -C link-arg=-static -C link-arg=-Wl,--build-id --remap-path-prefix=$(pwd)=/src fib.rs
+	// once with -C symbol-mangling-version=v0 and once with the default
+	// legacy mangling and stripped of DWARF using strip --strip-debug.
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   0x201000,
+			Limit:   0x202000,
+			BuildId: "0d11d1bf6f6d455389ce2d6b671016ec8a86d8e2", // v0, with DWARF.
+		}, {
+			Start:   0x201000,
+			Limit:   0x202000,
+			BuildId: "af2cabd35504fd7b26613123a1f5334b39e7d7ed", // legacy, symbols only.
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(mres.Mappings))
+
+	clres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			// Return address of the recursive call in fib::fibonacci.
+			MappingId: mres.Mappings[0].Id,
+			Address:   0x201209,
+		}, {
+			// Start of fib::fibonacci.
+			MappingId: mres.Mappings[1].Id,
+			Address:   0x2011f0,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(clres.Locations))
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ures.Locations))
+
+	require.NoError(t, sym.Symbolize(ctx, ures.Locations))
+
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{
+		LocationIds: []string{clres.Locations[0].Id, clres.Locations[1].Id},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(lres.Locations))
+
+	expected := []*pb.Function{{
+		Name:       "fib::fib::fibonacci",
+		SystemName: "_RNvNtCsgaYGWQqCqrV_3fib3fib9fibonacci",
+		Filename:   "/src/fib.rs",
+	}, {
+		Name:       "fib::fib::fibonacci",
+		SystemName: "_ZN3fib3fib9fibonacci17h8dbc841a3a79d695E",
+		Filename:   "?",
+	}}
+	for i, location := range lres.Locations {
+		require.Equal(t, 1, len(location.Lines))
+
+		fres, err := metastore.Functions(ctx, &pb.FunctionsRequest{
+			FunctionIds: []string{location.Lines[0].FunctionId},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(fres.Functions))
+		require.Equal(t, expected[i].Name, fres.Functions[0].Name)
+		require.Equal(t, expected[i].SystemName, fres.Functions[0].SystemName)
+		require.Equal(t, expected[i].Filename, fres.Functions[0].Filename)
+	}
+	require.Equal(t, int64(10), lres.Locations[0].Lines[0].Line)
+}
+
+func TestSymbolizeRPC(t *testing.T) {
+	conn, metastore, _ := setup(t)
+
+	ctx := context.Background()
+	client := debuginfopb.NewDebugInfoServiceClient(conn)
+
+	res, err := client.Symbolize(ctx, &debuginfopb.SymbolizeRequest{
+		BuildId:   "e94c2ed1e1276255de44b79f0e74234cf7c70bb3",
+		Addresses: []uint64{0x401151, 0x401156},
+	})
+	require.NoError(t, err)
+
+	expected := []*debuginfopb.SymbolizedAddress{{
+		Address: 0x401151,
+		Lines: []*debuginfopb.SymbolizedLine{
+			{FunctionName: "leaf", SystemName: "leaf", Filename: "/src/inlined.c", Line: 9},
+			{FunctionName: "middle", SystemName: "middle", Filename: "/src/inlined.c", Line: 13},
+			{FunctionName: "main", SystemName: "main", Filename: "/src/inlined.c", Line: 18},
+		},
+	}, {
+		Address: 0x401156,
+		Lines: []*debuginfopb.SymbolizedLine{
+			{FunctionName: "middle", SystemName: "middle", Filename: "/src/inlined.c", Line: 13},
+			{FunctionName: "main", SystemName: "main", Filename: "/src/inlined.c", Line: 18},
+		},
+	}}
+	require.Equal(t, len(expected), len(res.Addresses))
+	for i := range expected {
+		require.True(t, proto.Equal(expected[i], res.Addresses[i]), "address %x: %v", expected[i].Address, res.Addresses[i])
+	}
+
+	// Nothing is written to the metastore.
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(ures.Locations))
+
+	_, err = client.Symbolize(ctx, &debuginfopb.SymbolizeRequest{
+		BuildId:   "0000000000000000000000000000000000000000",
+		Addresses: []uint64{0x401151},
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSymbolizeCompressedDebugInfo(t *testing.T) {
+	const buildID = "e94c2ed1e1276255de44b79f0e74234cf7c70bb3"
+
+	raw := mustReadAll(t, "testdata/"+buildID+"/debuginfo")
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, err := gw.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var zst bytes.Buffer
+	zw, err := zstd.NewWriter(&zst)
+	require.NoError(t, err)
+	_, err = zw.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	symbolize := func(t *testing.T, object []byte) *debuginfopb.SymbolizeResponse {
+		logger := log.NewNopLogger()
+		bucket := objstore.NewInMemBucket()
+		require.NoError(t, bucket.Upload(context.Background(), buildID+"/debuginfo", bytes.NewReader(object)))
+
+		sym, err := symbol.NewSymbolizer(logger, prometheus.NewRegistry())
+		require.NoError(t, err)
+
+		dbgStr, err := debuginfo.NewStore(
+			logger,
+			prometheus.NewRegistry(),
+			t.TempDir(),
+			debuginfo.NewObjectStoreMetadata(logger, bucket),
+			bucket,
+			debuginfo.NopDebugInfodClient{},
+			sym,
+			nil,
+			debuginfo.WithCompression(debuginfo.CompressionZstd),
+		)
+		require.NoError(t, err)
+
+		res, err := dbgStr.Symbolize(context.Background(), &debuginfopb.SymbolizeRequest{
+			BuildId:   buildID,
+			Addresses: []uint64{0x401151, 0x401156},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	expected := symbolize(t, raw)
+	require.Len(t, expected.Addresses, 2)
+	require.Len(t, expected.Addresses[0].Lines, 3)
+
+	require.True(t, proto.Equal(expected, symbolize(t, gz.Bytes())))
+	require.True(t, proto.Equal(expected, symbolize(t, zst.Bytes())))
+}
+
+func TestSymbolizeWithDebugLink(t *testing.T) {
+	const buildID = "1ce8f2a0a8e60e0de27c0c4ad73a39b3c1f26f0d"
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	bucket := objstore.NewInMemBucket()
+
+	sym, err := symbol.NewSymbolizer(logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	dbgStr, err := debuginfo.NewStore(
+		logger,
+		prometheus.NewRegistry(),
+		t.TempDir(),
+		debuginfo.NewObjectStoreMetadata(logger, bucket),
+		bucket,
+		debuginfo.NopDebugInfodClient{},
+		sym,
+		nil,
+		// The separate debug file has no build ID of its own.
+		debuginfo.WithAllowMissingBuildID(true),
+	)
+	require.NoError(t, err)
+	c := serveDebugInfo(t, dbgStr)
+
+	// The stripped executable only refers to its separate debug file with
+	// the .gnu_debuglink section.
+	_, err = c.Upload(ctx, "6d61696e2e6465627567", "abcd", bytes.NewReader(mustReadAll(t, "testdata/debuglink/main.debug")))
+	require.NoError(t, err)
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(mustReadAll(t, "testdata/debuglink/main")))
+	require.NoError(t, err)
+
+	res, err := dbgStr.Symbolize(ctx, &debuginfopb.SymbolizeRequest{
+		BuildId:   buildID,
+		Addresses: []uint64{0x40106f},
+	})
+	require.NoError(t, err)
+
+	expected := &debuginfopb.SymbolizedAddress{
+		Address: 0x40106f,
+		Lines: []*debuginfopb.SymbolizedLine{
+			{FunctionName: "leaf", SystemName: "leaf", Filename: "/src/inlined.c", Line: 9},
+			{FunctionName: "middle", SystemName: "middle", Filename: "/src/inlined.c", Line: 13},
+			{FunctionName: "main", SystemName: "main", Filename: "/src/inlined.c", Line: 18},
+		},
+	}
+	require.Len(t, res.Addresses, 1)
+	require.True(t, proto.Equal(expected, res.Addresses[0]), "%v", res.Addresses[0])
+}
+
+func TestSymbolizeSplitDWARF(t *testing.T) {
+	const buildID = "7fafb54cb77898ceb45f6b2c5f194432ac5a401b"
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	bucket := objstore.NewInMemBucket()
+
+	sym, err := symbol.NewSymbolizer(logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	dbgStr, err := debuginfo.NewStore(
+		logger,
+		prometheus.NewRegistry(),
+		t.TempDir(),
+		debuginfo.NewObjectStoreMetadata(logger, bucket),
+		bucket,
+		debuginfo.NopDebugInfodClient{},
+		sym,
+		nil,
+	)
+	require.NoError(t, err)
+	c := serveDebugInfo(t, dbgStr)
+
+	// Built from testdata/splitdwarf/split.c and work.c using:
+	// gcc -O1 -g -gdwarf-5 -gsplit-dwarf -fpie -pie -Wl,--build-id -fdebug-prefix-map=$(pwd)=/src -o split split.c work.c
+	// The executable only has skeleton units, split.dwp was packaged from
+	// the resulting split-split.dwo and split-work.dwo files.
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(mustReadAll(t, "testdata/splitdwarf/split")))
+	require.NoError(t, err)
+
+	addresses := []uint64{
+		// Call to work() from leaf(), inlined into middle(), inlined into main().
+		0x1132,
+		// In work(), a compile unit of its own.
+		0x1142,
+	}
+
+	// Without the DWARF package file there are no functions to resolve.
+	res, err := dbgStr.Symbolize(ctx, &debuginfopb.SymbolizeRequest{BuildId: buildID, Addresses: addresses})
+	require.NoError(t, err)
+	require.Len(t, res.Addresses, len(addresses))
+	for _, addr := range res.Addresses {
+		require.Empty(t, addr.Lines)
+	}
+
+	_, err = c.UploadDWP(ctx, buildID, bytes.NewReader(mustReadAll(t, "testdata/splitdwarf/split.dwp")))
+	require.NoError(t, err)
+
+	res, err = dbgStr.Symbolize(ctx, &debuginfopb.SymbolizeRequest{BuildId: buildID, Addresses: addresses})
+	require.NoError(t, err)
+
+	expected := []*debuginfopb.SymbolizedAddress{{
+		Address: 0x1132,
+		Lines: []*debuginfopb.SymbolizedLine{
+			{FunctionName: "leaf", SystemName: "leaf", Filename: "/src/split.c", Line: 4},
+			{FunctionName: "middle", SystemName: "middle", Filename: "/src/split.c", Line: 8},
+			{FunctionName: "main", SystemName: "main", Filename: "/src/split.c", Line: 12},
+		},
+	}, {
+		Address: 0x1142,
+		Lines: []*debuginfopb.SymbolizedLine{
+			{FunctionName: "work", SystemName: "work", Filename: "/src/work.c", Line: 2},
+		},
+	}}
+	require.Len(t, res.Addresses, len(expected))
+	for i := range expected {
+		require.True(t, proto.Equal(expected[i], res.Addresses[i]), "%v", res.Addresses[i])
+	}
+
+	// Only DWARF package files are accepted.
+	_, err = c.UploadDWP(ctx, buildID, bytes.NewReader(mustReadAll(t, "testdata/splitdwarf/split")))
+	require.ErrorContains(t, err, "invalid DWARF package file")
+}
+
+func TestSymbolizeSourceContext(t *testing.T) {
+	const (
+		buildID    = "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085"
+		sourcePath = "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go"
+		// main.go:23 in main.main.
+		address = 0x463748
+	)
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	bucket := objstore.NewInMemBucket()
+
+	sym, err := symbol.NewSymbolizer(logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	dbgStr, err := debuginfo.NewStore(
+		logger,
+		prometheus.NewRegistry(),
+		t.TempDir(),
+		debuginfo.NewObjectStoreMetadata(logger, bucket),
+		bucket,
+		debuginfo.NopDebugInfodClient{},
+		sym,
+		nil,
+		// The Go executable has no GNU build ID note.
+		debuginfo.WithAllowMissingBuildID(true),
+	)
+	require.NoError(t, err)
+	c := serveDebugInfo(t, dbgStr)
+
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(mustReadAll(t, "testdata/"+buildID+"/debuginfo")))
+	require.NoError(t, err)
+
+	req := &debuginfopb.SymbolizeRequest{
+		BuildId:            buildID,
+		Addresses:          []uint64{address},
+		SourceContextLines: 2,
+	}
+
+	// Without the source file, the lines are resolved all the same.
+	res, err := dbgStr.Symbolize(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, res.Addresses, 1)
+	require.Equal(t, sourcePath, res.Addresses[0].Lines[0].Filename)
+	require.Equal(t, int64(23), res.Addresses[0].Lines[0].Line)
+	require.Empty(t, res.Addresses[0].Lines[0].SourceContext)
+
+	// Only the lines matter, not what they say.
+	var source strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&source, "// line %d\n", i)
+	}
+	_, err = c.UploadSource(ctx, buildID, sourcePath, strings.NewReader(source.String()))
+	require.NoError(t, err)
+
+	res, err = dbgStr.Symbolize(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, res.Addresses, 1)
+	for _, line := range res.Addresses[0].Lines {
+		if line.Filename != sourcePath {
+			// The source files of other packages weren't uploaded.
+			require.Empty(t, line.SourceContext)
+			continue
+		}
+		if line.Line != 23 {
+			continue
+		}
+		expected := []*debuginfopb.SourceLine{
+			{Line: 21, Text: "// line 21"},
+			{Line: 22, Text: "// line 22"},
+			{Line: 23, Text: "// line 23"},
+			{Line: 24, Text: "// line 24"},
+			{Line: 25, Text: "// line 25"},
+		}
+		require.Len(t, line.SourceContext, len(expected))
+		for i := range expected {
+			require.True(t, proto.Equal(expected[i], line.SourceContext[i]), "%v", line.SourceContext[i])
+		}
+	}
+
+	// The source is only returned if asked for.
+	req.SourceContextLines = 0
+	res, err = dbgStr.Symbolize(ctx, req)
+	require.NoError(t, err)
+	for _, line := range res.Addresses[0].Lines {
+		require.Empty(t, line.SourceContext)
+	}
+}
+
+func TestSymbolizeSourceArchive(t *testing.T) {
+	const (
+		buildID    = "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085"
+		sourcePath = "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go"
+		// main.go:23 in main.main.
+		address = 0x463748
+	)
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	bucket := objstore.NewInMemBucket()
+
+	sym, err := symbol.NewSymbolizer(logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	dbgStr, err := debuginfo.NewStore(
+		logger,
+		prometheus.NewRegistry(),
+		t.TempDir(),
+		debuginfo.NewObjectStoreMetadata(logger, bucket),
+		bucket,
+		debuginfo.NopDebugInfodClient{},
+		sym,
+		nil,
+		// The Go executable has no GNU build ID note.
+		debuginfo.WithAllowMissingBuildID(true),
+	)
+	require.NoError(t, err)
+	c := serveDebugInfo(t, dbgStr)
+
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(mustReadAll(t, "testdata/"+buildID+"/debuginfo")))
+	require.NoError(t, err)
+
+	// The archive entries are relative to wherever the sources were
+	// archived.
+	var source strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&source, "// line %d\n", i)
+	}
+	var archive bytes.Buffer
+	gw := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gw)
+	for name, content := range map[string]string{
+		"pprof-labels-example/main.go": source.String(),
+		"other/main.go":                "package main\n",
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	_, err = c.UploadSourceArchive(ctx, buildID, bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+
+	res, err := dbgStr.Symbolize(ctx, &debuginfopb.SymbolizeRequest{
+		BuildId:            buildID,
+		Addresses:          []uint64{address},
+		SourceContextLines: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Addresses, 1)
+	var found bool
+	for _, line := range res.Addresses[0].Lines {
+		if line.Filename != sourcePath {
+			require.Empty(t, line.SourceContext)
+			require.Empty(t, line.SourceArchiveHash)
+			continue
+		}
+		if line.Line != 23 {
+			continue
+		}
+		found = true
+		require.NotEmpty(t, line.SourceArchiveHash)
+		expected := []*debuginfopb.SourceLine{
+			{Line: 22, Text: "// line 22"},
+			{Line: 23, Text: "// line 23"},
+			{Line: 24, Text: "// line 24"},
+		}
+		require.Len(t, line.SourceContext, len(expected))
+		for i := range expected {
+			require.True(t, proto.Equal(expected[i], line.SourceContext[i]), "%v", line.SourceContext[i])
+		}
+	}
+	require.True(t, found)
+
+	src, err := dbgStr.Source(ctx, &debuginfopb.SourceRequest{
+		BuildId:  buildID,
+		Filename: sourcePath,
+		Line:     10,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, src.SourceArchiveHash)
+	require.Len(t, src.Lines, 1)
+	require.True(t, proto.Equal(&debuginfopb.SourceLine{Line: 10, Text: "// line 10"}, src.Lines[0]), "%v", src.Lines[0])
+
+	// Lines past the end of the file aren't found.
+	_, err = dbgStr.Source(ctx, &debuginfopb.SourceRequest{
+		BuildId:  buildID,
+		Filename: sourcePath,
+		Line:     31,
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// Only archives are accepted.
+	_, err = c.UploadSourceArchive(ctx, buildID, strings.NewReader(source.String()))
+	require.ErrorContains(t, err, "invalid source archive")
+}
+
+func TestSymbolizeExtractedDebugInfo(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		buildID   string
+		addresses []uint64
+	}{{
+		name:      "c",
+		buildID:   "e94c2ed1e1276255de44b79f0e74234cf7c70bb3",
+		addresses: []uint64{0x401151, 0x401156},
+	}, {
+		name:      "go",
+		buildID:   "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085",
+		addresses: []uint64{0x463748, 0x463784},
+	}, {
+		name:      "go-without-dwarf",
+		buildID:   "624631536e6879525a794a42506d4a6a4f4258482f36784b4268655a45425230646d47784c5f526e672f7351724856694d67424e794376525f4b674a5f692f733968736f6266664f792d6a4e38314937346c75",
+		addresses: []uint64{0x463748, 0x463781},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			object := mustReadAll(t, "testdata/"+tc.buildID+"/debuginfo")
+
+			symbolize := func(t *testing.T, extract bool) (*debuginfopb.SymbolizeResponse, int64) {
+				logger := log.NewNopLogger()
+				bucket := objstore.NewInMemBucket()
+
+				sym, err := symbol.NewSymbolizer(logger, prometheus.NewRegistry())
+				require.NoError(t, err)
+
+				dbgStr, err := debuginfo.NewStore(
+					logger,
+					prometheus.NewRegistry(),
+					t.TempDir(),
+					debuginfo.NewObjectStoreMetadata(logger, bucket),
+					bucket,
+					debuginfo.NopDebugInfodClient{},
+					sym,
+					nil,
+					// The Go executables have no GNU build ID note.
+					debuginfo.WithAllowMissingBuildID(true),
+				)
+				require.NoError(t, err)
+				c := serveDebugInfo(t, dbgStr)
+
+				if extract {
+					_, err = c.UploadExtract(ctx, tc.buildID, "abcd", bytes.NewReader(object))
+				} else {
+					_, err = c.Upload(ctx, tc.buildID, "abcd", bytes.NewReader(object))
+				}
+				require.NoError(t, err)
+
+				var stored int64
+				require.NoError(t, bucket.Iter(ctx, "blobs/", func(name string) error {
+					attrs, err := bucket.Attributes(ctx, name)
+					stored += attrs.Size
+					return err
+				}))
+
+				res, err := dbgStr.Symbolize(ctx, &debuginfopb.SymbolizeRequest{
+					BuildId:   tc.buildID,
+					Addresses: tc.addresses,
+				})
+				require.NoError(t, err)
+				return res, stored
+			}
+
+			expected, stored := symbolize(t, false)
+			require.Len(t, expected.Addresses, len(tc.addresses))
+			for _, addr := range expected.Addresses {
+				require.NotEmpty(t, addr.Lines)
+			}
+
+			res, extractedStored := symbolize(t, true)
+			require.True(t, proto.Equal(expected, res), "%v", res)
+			require.Less(t, extractedStored, stored)
+		})
+	}
+}
+
+func TestReSymbolizeAfterUpload(t *testing.T) {
+	const (
+		buildID      = "e94c2ed1e1276255de44b79f0e74234cf7c70bb3"
+		otherBuildID = "1111111111111111111111111111111111111111"
+	)
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+	bucket := objstore.NewInMemBucket()
+
+	metastore := metastore.NewInProcessClient(metastoretest.NewTestMetastore(
+		t,
+		logger,
+		prometheus.NewRegistry(),
+		trace.NewNoopTracerProvider().Tracer(""),
+	))
+
+	sym, err := symbol.NewSymbolizer(logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	var s *Symbolizer
+	reSymbolized := make(chan error, 1)
+	dbgStr, err := debuginfo.NewStore(
+		logger,
+		prometheus.NewRegistry(),
+		t.TempDir(),
+		debuginfo.NewObjectStoreMetadata(logger, bucket),
+		bucket,
+		debuginfo.NopDebugInfodClient{},
+		sym,
+		nil,
+		debuginfo.WithOnUploaded(func(uploaded string) {
+			require.Equal(t, buildID, uploaded)
+			reSymbolized <- s.ReSymbolize(ctx, uploaded)
+		}),
+	)
+	require.NoError(t, err)
+
+	s = New(logger, prometheus.NewRegistry(), metastore, dbgStr, sym, t.TempDir(), t.TempDir(), WithMissingDebugInfoTTL(time.Minute))
+	c := serveDebugInfo(t, dbgStr)
+
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   0x401000,
+			Limit:   0x402000,
+			BuildId: buildID,
+		}, {
+			Start:   0x501000,
+			Limit:   0x502000,
+			BuildId: otherBuildID,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(mres.Mappings))
+
+	clres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			MappingId: mres.Mappings[0].Id,
+			Address:   0x401151,
+		}, {
+			MappingId: mres.Mappings[1].Id,
+			Address:   0x501151,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(clres.Locations))
+
+	// Without any debug info, the locations stay unsymbolized.
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ures.Locations))
+	require.Error(t, s.Symbolize(ctx, ures.Locations))
+
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{BuildId: buildID})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ures.Locations))
+	require.Equal(t, clres.Locations[0].Id, ures.Locations[0].Id)
+
+	_, err = c.Upload(ctx, buildID, "abcd", bytes.NewReader(mustReadAll(t, "testdata/"+buildID+"/debuginfo")))
+	require.NoError(t, err)
+	require.NoError(t, <-reSymbolized)
+
+	// Only the locations of the uploaded build ID were symbolized.
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ures.Locations))
+	require.Equal(t, clres.Locations[1].Id, ures.Locations[0].Id)
+
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{
+		LocationIds: []string{clres.Locations[0].Id},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(lres.Locations))
+
+	requireLines(t, metastore, lres.Locations[0], []expectedLine{
+		{name: "leaf", filename: "/src/inlined.c", line: 9},
+		{name: "middle", filename: "/src/inlined.c", line: 13},
+		{name: "main", filename: "/src/inlined.c", line: 18},
+	})
+}
+
+// serveDebugInfo serves the debug info store over gRPC and returns a client
+// of it.
+func serveDebugInfo(t *testing.T, dbgStr *debuginfo.Store) *debuginfo.Client {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	t.Cleanup(grpcServer.GracefulStop)
+	server.RegisterServices(grpcServer, server.Services{DebugInfo: dbgStr})
+	go func() {
+		err := grpcServer.Serve(lis)
+		if err != nil {
+			stdlog.Fatalf("failed to serve: %v", err)
+		}
+	}()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return debuginfo.NewDebugInfoClient(conn)
+}
+
+type countingDebugInfoFetcher struct {
+	DebugInfoFetcher
+	calls int
+}
+
+func (f *countingDebugInfoFetcher) FetchDebugInfo(ctx context.Context, buildID string) (string, debuginfopb.DownloadInfo_Source, error) {
+	f.calls++
+	return f.DebugInfoFetcher.FetchDebugInfo(ctx, buildID)
+}
+
+func TestSymbolizerCachesDebugInfo(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	fetcher := &countingDebugInfoFetcher{DebugInfoFetcher: sym.debuginfo}
+	sym.debuginfo = fetcher
+
+	ctx := context.Background()
+
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   0x401000,
+			Limit:   0x402000,
+			BuildId: "e94c2ed1e1276255de44b79f0e74234cf7c70bb3",
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(mres.Mappings))
+	m := mres.Mappings[0]
+
+	for _, addr := range []uint64{0x401151, 0x401156} {
+		_, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+			Locations: []*pb.Location{{
+				MappingId: m.Id,
+				Address:   addr,
+			}},
+		})
+		require.NoError(t, err)
+
+		ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(ures.Locations))
+
+		require.NoError(t, sym.Symbolize(ctx, ures.Locations))
+	}
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(ures.Locations))
+
+	require.Equal(t, 1, fetcher.calls)
+}
+
+func TestSymbolizerSkipsMissingDebugInfo(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	fetcher := &countingDebugInfoFetcher{DebugInfoFetcher: sym.debuginfo}
+	sym.debuginfo = fetcher
+
+	ctx := context.Background()
+
+	const buildID = "1111111111111111111111111111111111111111"
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   0x401000,
+			Limit:   0x402000,
+			BuildId: buildID,
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			MappingId: mres.Mappings[0].Id,
+			Address:   0x401151,
+		}},
+	})
+	require.NoError(t, err)
+
+	symbolize := func() error {
+		ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(ures.Locations))
+		return sym.Symbolize(ctx, ures.Locations)
+	}
+
+	require.Error(t, symbolize())
+	require.Equal(t, 1, fetcher.calls)
+
+	st, ok := fetcher.DebugInfoStatus(buildID)
+	require.True(t, ok)
+	require.Equal(t, debuginfo.StatusStateNotUploaded, st.State)
+
+	// Known to be missing, nothing is fetched before the TTL elapses.
+	require.NoError(t, symbolize())
+	require.Equal(t, 1, fetcher.calls)
+	require.Equal(t, 2.0, testutil.ToFloat64(sym.failures.WithLabelValues(failureReasonNotUploaded)))
+
+	// Once it elapsed, the debug info is fetched again.
+	sym.now = func() time.Time { return st.UpdatedAt.Add(sym.missingDebugInfoTTL) }
+	require.Error(t, symbolize())
+	require.Equal(t, 2, fetcher.calls)
+}
+
+func TestSymbolizerSkipsMappingsWithoutBuildID(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	fetcher := &countingDebugInfoFetcher{DebugInfoFetcher: sym.debuginfo}
+	sym.debuginfo = fetcher
+
+	ctx := context.Background()
+
+	// An executable with debug info and an anonymous mapping, e.g. of JIT
+	// compiled code.
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   0x401000,
+			Limit:   0x402000,
+			BuildId: "e94c2ed1e1276255de44b79f0e74234cf7c70bb3",
+		}, {
+			Start: 0x7f0000000000,
+			Limit: 0x7f0000100000,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(mres.Mappings))
+
+	lres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{MappingId: mres.Mappings[0].Id, Address: 0x401151},
+			{MappingId: mres.Mappings[1].Id, Address: 0x7f0000000100},
+		},
+	})
+	require.NoError(t, err)
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ures.Locations))
+	require.NoError(t, sym.Symbolize(ctx, ures.Locations))
+
+	// Only the debug info of the executable was fetched.
+	require.Equal(t, 1, fetcher.calls)
+
+	locs, err := metastore.Locations(ctx, &pb.LocationsRequest{LocationIds: []string{lres.Locations[0].Id, lres.Locations[1].Id}})
+	require.NoError(t, err)
+	require.NotEmpty(t, locs.Locations[0].Lines)
+	require.Empty(t, locs.Locations[1].Lines)
+
+	// The location without a build ID is skipped for that reason, not
+	// because of its mapping being unsymbolizable.
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ures.Locations))
+	report, err := sym.DryRun(ctx, ures.Locations)
+	require.NoError(t, err)
+	require.Equal(t, &DryRunReport{
+		BuildIDs: map[string]*DryRunCounts{},
+		Skipped:  map[string]int{skipReasonNoBuildID: 1},
+	}, report)
+}
+
+func TestSymbolizerMaxLinesPerLocation(t *testing.T) {
+	_, metastore, sym := setup(t)
+	sym.maxLinesPerLocation = 2
+
+	ctx := context.Background()
+
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   0x401000,
+			Limit:   0x402000,
+			BuildId: "e94c2ed1e1276255de44b79f0e74234cf7c70bb3",
+		}},
+	})
+	require.NoError(t, err)
+	m := mres.Mappings[0]
+
+	clres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			// Three lines: leaf() inlined into middle() inlined into main().
+			MappingId: m.Id,
+			Address:   0x401151,
+		}, {
+			// Two lines: middle() inlined into main().
+			MappingId: m.Id,
+			Address:   0x401156,
+		}},
+	})
+	require.NoError(t, err)
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.NoError(t, sym.Symbolize(ctx, ures.Locations))
+
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{
+		LocationIds: []string{clres.Locations[0].Id, clres.Locations[1].Id},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(lres.Locations))
+
+	// The innermost line is dropped, the outermost ones are kept.
+	require.True(t, lres.Locations[0].LinesTruncated)
+	requireLines(t, metastore, lres.Locations[0], []expectedLine{
+		{name: "middle", filename: "/src/inlined.c", line: 13},
+		{name: "main", filename: "/src/inlined.c", line: 18},
+	})
+	require.False(t, lres.Locations[1].LinesTruncated)
+	requireLines(t, metastore, lres.Locations[1], []expectedLine{
+		{name: "middle", filename: "/src/inlined.c", line: 13},
+		{name: "main", filename: "/src/inlined.c", line: 18},
+	})
+	require.Equal(t, 1.0, testutil.ToFloat64(sym.truncated))
+}
+
+func TestSymbolizerCountsFailures(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	ctx := context.Background()
+
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   4194304,
+			Limit:   4603904,
+			BuildId: "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085",
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			MappingId: mres.Mappings[0].Id,
+			Address:   0x463781,
+		}, {
+			// The ELF header, which is not part of any function.
+			MappingId: mres.Mappings[0].Id,
+			Address:   0x400010,
+		}},
+	})
+	require.NoError(t, err)
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ures.Locations))
+
+	// The location that was found is stored all the same.
+	var addrErr *symbol.AddressError
+	require.ErrorAs(t, sym.Symbolize(ctx, ures.Locations), &addrErr)
+	require.Equal(t, uint64(0x400010), addrErr.Address)
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ures.Locations))
+
+	require.Equal(t, 1.0, testutil.ToFloat64(sym.failures.WithLabelValues(failureReasonAddressNotFound)))
+	require.Equal(t, 0.0, testutil.ToFloat64(sym.failures.WithLabelValues(failureReasonFetch)))
+	require.Equal(t, 0.0, testutil.ToFloat64(sym.failures.WithLabelValues(failureReasonParse)))
+	require.Equal(t, 0.0, testutil.ToFloat64(sym.failures.WithLabelValues(failureReasonNotUploaded)))
+}
+
+func TestSymbolizerLogsFailureFields(t *testing.T) {
+	const buildID = "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085"
+
+	buf := &bytes.Buffer{}
+	_, metastore, sym := setupWithLogger(t, log.NewJSONLogger(log.NewSyncWriter(buf)))
+
+	ctx := context.Background()
+
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   4194304,
+			Limit:   4603904,
+			BuildId: buildID,
+		}},
+	})
+	require.NoError(t, err)
+
+	// The ELF header, which is not part of any function.
+	lres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			MappingId: mres.Mappings[0].Id,
+			Address:   0x400010,
+		}},
+	})
+	require.NoError(t, err)
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Error(t, sym.Symbolize(ctx, ures.Locations))
+
+	var failure map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		fields := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(line), &fields))
+		if fields["msg"] == "failed to symbolize location" {
+			failure = fields
 		}
 	}
+	require.NotNil(t, failure, "no log line for the failed location in:\n%s", buf.String())
+	require.Equal(t, "symbolizer", failure[logfields.Component])
+	require.Equal(t, buildID, failure[logfields.BuildID])
+	require.Equal(t, lres.Locations[0].Id, failure[logfields.LocationID])
+	require.Equal(t, "0x400010", failure[logfields.Address])
+	require.Contains(t, failure["err"], "address not found")
+}
+
+func TestSymbolizerBucketFailures(t *testing.T) {
+	const buildID = "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085"
+
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+
+	bucket := objstoretest.NewBucket(0)
+	require.NoError(t, bucket.Upload(ctx, buildID+"/debuginfo", bytes.NewReader(mustReadAll(t, "testdata/"+buildID+"/debuginfo"))))
+
+	metastore := metastore.NewInProcessClient(metastoretest.NewTestMetastore(
+		t,
+		logger,
+		prometheus.NewRegistry(),
+		trace.NewNoopTracerProvider().Tracer(""),
+	))
+
+	sym, err := symbol.NewSymbolizer(logger, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	dbgStr, err := debuginfo.NewStore(
+		logger,
+		prometheus.NewRegistry(),
+		t.TempDir(),
+		debuginfo.NewObjectStoreMetadata(logger, bucket),
+		bucket,
+		debuginfo.NopDebugInfodClient{},
+		sym,
+		nil,
+		debuginfo.WithRetry(debuginfo.RetryConfig{
+			MaxRetries: 1,
+			BaseDelay:  time.Millisecond,
+			MaxDelay:   time.Millisecond,
+		}),
+	)
+	require.NoError(t, err)
+
+	s := New(logger, prometheus.NewRegistry(), metastore, dbgStr, sym, t.TempDir(), t.TempDir(), WithMissingDebugInfoTTL(time.Minute))
+
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   4194304,
+			Limit:   4603904,
+			BuildId: buildID,
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			MappingId: mres.Mappings[0].Id,
+			Address:   0x463781,
+		}},
+	})
+	require.NoError(t, err)
+
+	// Fetching the debug info fails even when retried. Ranged reads fail
+	// too, for the store to download the whole object instead.
+	isDebugInfo := func(name string) bool { return name == buildID+"/debuginfo" }
+	bucket.Inject(objstoretest.Fault{
+		Op:    objstoretest.OpAttributes,
+		Match: isDebugInfo,
+	})
+	bucket.Inject(objstoretest.Fault{
+		Op:    objstoretest.OpGet,
+		Match: isDebugInfo,
+		Times: 2,
+	})
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	err = s.Symbolize(ctx, ures.Locations)
+	require.ErrorIs(t, err, objstoretest.ErrInjected)
+	require.Equal(t, 2, bucket.ObjectCalls(objstoretest.OpGet, buildID+"/debuginfo"))
+	require.Equal(t, 1.0, testutil.ToFloat64(s.failures.WithLabelValues(failureReasonFetch)))
+
+	// The location is symbolized once the bucket recovers.
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ures.Locations))
+	require.NoError(t, s.Symbolize(ctx, ures.Locations))
+
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(ures.Locations))
+	require.Equal(t, 1.0, testutil.ToFloat64(s.failures.WithLabelValues(failureReasonFetch)))
+}
+
+// staticDebugInfoFetcher returns the given debug info files, and fetches
+// the debug info of other build IDs.
+type staticDebugInfoFetcher struct {
+	DebugInfoFetcher
+	files map[string]string
+}
+
+func (f *staticDebugInfoFetcher) FetchDebugInfo(ctx context.Context, buildID string) (string, debuginfopb.DownloadInfo_Source, error) {
+	if file, ok := f.files[buildID]; ok {
+		return file, debuginfopb.DownloadInfo_SOURCE_UPLOAD, nil
+	}
+	return f.DebugInfoFetcher.FetchDebugInfo(ctx, buildID)
+}
+
+func TestSymbolizerErrors(t *testing.T) {
+	const (
+		buildID        = "e94c2ed1e1276255de44b79f0e74234cf7c70bb3"
+		corruptBuildID = "3333333333333333333333333333333333333333"
+		missingBuildID = "1111111111111111111111111111111111111111"
+	)
+
+	// The debug info of the executable with a line table of an unknown
+	// version.
+	corrupt := filepath.Join(t.TempDir(), "debuginfo")
+	object := mustReadAll(t, "testdata/"+buildID+"/debuginfo")
+	f, err := elf.NewFile(bytes.NewReader(object))
+	require.NoError(t, err)
+	lineTable := f.Section(".debug_line")
+	require.NotNil(t, lineTable)
+	f.ByteOrder.PutUint16(object[lineTable.Offset+4:], 0xffff)
+	require.NoError(t, os.WriteFile(corrupt, object, 0o600))
+
+	for _, tc := range []struct {
+		name    string
+		buildID string
+		address uint64
+		check   func(t *testing.T, err error)
+	}{{
+		name:    "debuginfo-not-found",
+		buildID: missingBuildID,
+		address: 0x401151,
+		check: func(t *testing.T, err error) {
+			require.ErrorIs(t, err, debuginfo.ErrDebugInfoNotFound)
+			var dbgErr *symbol.DebugInfoError
+			require.ErrorAs(t, err, &dbgErr)
+			require.Equal(t, missingBuildID, dbgErr.BuildID)
+		},
+	}, {
+		name:    "address-not-found",
+		buildID: buildID,
+		// The ELF header, which is not part of any function.
+		address: 0x400010,
+		check: func(t *testing.T, err error) {
+			require.ErrorIs(t, err, symbol.ErrAddressNotFound)
+			var addrErr *symbol.AddressError
+			require.ErrorAs(t, err, &addrErr)
+			require.Equal(t, buildID, addrErr.BuildID)
+			require.Equal(t, uint64(0x400010), addrErr.Address)
+		},
+	}, {
+		name:    "corrupt-dwarf",
+		buildID: corruptBuildID,
+		address: 0x401151,
+		check: func(t *testing.T, err error) {
+			require.ErrorIs(t, err, symbol.ErrCorruptDWARF)
+			var addrErr *symbol.AddressError
+			require.ErrorAs(t, err, &addrErr)
+			require.Equal(t, corruptBuildID, addrErr.BuildID)
+			require.Equal(t, uint64(0x401151), addrErr.Address)
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, metastore, sym := setup(t)
+			sym.debuginfo = &staticDebugInfoFetcher{
+				DebugInfoFetcher: sym.debuginfo,
+				files:            map[string]string{corruptBuildID: corrupt},
+			}
+
+			ctx := context.Background()
+			mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+				Mappings: []*pb.Mapping{{
+					Start:   0x401000,
+					Limit:   0x402000,
+					BuildId: tc.buildID,
+				}},
+			})
+			require.NoError(t, err)
+			_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+				Locations: []*pb.Location{{
+					MappingId: mres.Mappings[0].Id,
+					Address:   tc.address,
+				}},
+			})
+			require.NoError(t, err)
+
+			ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+			require.NoError(t, err)
+			require.Equal(t, 1, len(ures.Locations))
+
+			tc.check(t, sym.Symbolize(ctx, ures.Locations))
+		})
+	}
+}
+
+func TestSymbolizationErrorsIsAs(t *testing.T) {
+	var err error = symbolizationErrors{
+		&SkippedLocationsError{BuildID: "abcd", Reason: failureReasonNotUploaded, Err: debuginfo.ErrDebugInfoNotFound},
+		symbol.AddressErrors{{BuildID: "ef01", Address: 0x1000, Err: symbol.ErrAddressNotFound}},
+	}
+
+	require.ErrorIs(t, err, debuginfo.ErrDebugInfoNotFound)
+	require.ErrorIs(t, err, symbol.ErrAddressNotFound)
+	require.NotErrorIs(t, err, context.Canceled)
+
+	var skipped *SkippedLocationsError
+	require.ErrorAs(t, err, &skipped)
+	require.Equal(t, "abcd", skipped.BuildID)
+
+	var addrErr *symbol.AddressError
+	require.ErrorAs(t, err, &addrErr)
+	require.Equal(t, "ef01", addrErr.BuildID)
+}
+
+func TestSymbolizerMultipleMappings(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	ctx := context.Background()
+
+	// The same position-independent executable loaded by two processes at
+	// different addresses, both of which also map a shared library that
+	// has no debug info uploaded. The executable mappings differ in size,
+	// otherwise they would be the same mapping to the metastore.
+	const (
+		loadAddress1 = 0x55d3e0a00000
+		loadAddress2 = 0x5612b4400000
+		libcBuildID  = "2222222222222222222222222222222222222222"
+	)
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   loadAddress1 + 0x1000,
+			Limit:   loadAddress1 + 0x2000,
+			Offset:  0x1000,
+			BuildId: "a695b153282bb4da64ca7397a7cf029b63a6419f",
+		}, {
+			Start:   loadAddress2 + 0x1000,
+			Limit:   loadAddress2 + 0x3000,
+			Offset:  0x1000,
+			BuildId: "a695b153282bb4da64ca7397a7cf029b63a6419f",
+		}, {
+			Start:   0x7f3c1a628000,
+			Limit:   0x7f3c1a7bd000,
+			Offset:  0x28000,
+			File:    "/usr/lib/x86_64-linux-gnu/libc.so.6",
+			BuildId: libcBuildID,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(mres.Mappings))
+
+	clres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			MappingId: mres.Mappings[0].Id,
+			Address:   loadAddress1 + 0x1164,
+		}, {
+			MappingId: mres.Mappings[1].Id,
+			Address:   loadAddress2 + 0x1164,
+		}, {
+			MappingId: mres.Mappings[2].Id,
+			Address:   0x7f3c1a6a1d90,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(clres.Locations))
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(ures.Locations))
+
+	err = sym.Symbolize(ctx, ures.Locations)
+	var skipped *SkippedLocationsError
+	require.ErrorAs(t, err, &skipped)
+	require.Equal(t, libcBuildID, skipped.BuildID)
+	require.Equal(t, failureReasonNotUploaded, skipped.Reason)
+	require.Equal(t, []string{clres.Locations[2].Id}, skipped.LocationIDs)
+	require.Equal(t, 1.0, testutil.ToFloat64(sym.failures.WithLabelValues(failureReasonNotUploaded)))
+
+	// Only the locations of the shared library are left unsymbolized.
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ures.Locations))
+	require.Equal(t, clres.Locations[2].Id, ures.Locations[0].Id)
+
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{
+		LocationIds: []string{clres.Locations[0].Id, clres.Locations[1].Id},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(lres.Locations))
+
+	for _, loc := range lres.Locations {
+		requireLines(t, metastore, loc, []expectedLine{
+			{name: "leaf", filename: "/src/inlined.c", line: 9},
+			{name: "middle", filename: "/src/inlined.c", line: 13},
+			{name: "main", filename: "/src/inlined.c", line: 18},
+		})
+	}
+}
+
+// failingDebugInfoFetcher fails to fetch debug info after the given number of
+// successful fetches.
+type failingDebugInfoFetcher struct {
+	DebugInfoFetcher
+	successes int
+}
+
+func (f *failingDebugInfoFetcher) FetchDebugInfo(ctx context.Context, buildID string) (string, debuginfopb.DownloadInfo_Source, error) {
+	if f.successes == 0 {
+		return "", debuginfopb.DownloadInfo_SOURCE_UNKNOWN_UNSPECIFIED, fmt.Errorf("object storage unavailable")
+	}
+	f.successes--
+	return f.DebugInfoFetcher.FetchDebugInfo(ctx, buildID)
+}
+
+func TestSymbolizerMappingFailureKeepsOtherMappings(t *testing.T) {
+	_, metastore, sym := setup(t)
+	sym.debuginfo = &failingDebugInfoFetcher{DebugInfoFetcher: sym.debuginfo, successes: 1}
+
+	// The opened debug info expires right away and no lines are cached, so
+	// the debug info is fetched again for the second mapping, which fails.
+	var err error
+	sym.symbolizer, err = symbol.NewSymbolizer(
+		log.NewNopLogger(),
+		prometheus.NewRegistry(),
+		symbol.WithCacheItemTTL(time.Nanosecond),
+		symbol.WithLinesCacheSize(0),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	const (
+		buildID      = "a695b153282bb4da64ca7397a7cf029b63a6419f"
+		loadAddress1 = 0x55d3e0a00000
+		loadAddress2 = 0x5612b4400000
+	)
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   loadAddress1 + 0x1000,
+			Limit:   loadAddress1 + 0x2000,
+			Offset:  0x1000,
+			BuildId: buildID,
+		}, {
+			Start:   loadAddress2 + 0x1000,
+			Limit:   loadAddress2 + 0x3000,
+			Offset:  0x1000,
+			BuildId: buildID,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(mres.Mappings))
+
+	clres, err := metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{{
+			MappingId: mres.Mappings[0].Id,
+			Address:   loadAddress1 + 0x1164,
+		}, {
+			MappingId: mres.Mappings[1].Id,
+			Address:   loadAddress2 + 0x1164,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(clres.Locations))
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ures.Locations))
+
+	err = sym.Symbolize(ctx, ures.Locations)
+	var skipped *SkippedLocationsError
+	require.ErrorAs(t, err, &skipped)
+	require.Equal(t, buildID, skipped.BuildID)
+	require.Equal(t, failureReasonFetch, skipped.Reason)
+	require.Equal(t, 1, len(skipped.LocationIDs))
+	require.Equal(t, 1.0, testutil.ToFloat64(sym.failures.WithLabelValues(failureReasonFetch)))
+
+	// The location of the mapping symbolized first is stored nonetheless.
+	symbolizedID := clres.Locations[0].Id
+	if skipped.LocationIDs[0] == symbolizedID {
+		symbolizedID = clres.Locations[1].Id
+	}
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ures.Locations))
+	require.Equal(t, skipped.LocationIDs[0], ures.Locations[0].Id)
 
-	fres, err := metastore.Functions(ctx, &pb.FunctionsRequest{
-		FunctionIds: functionIds,
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{
+		LocationIds: []string{symbolizedID},
+	})
+	require.NoError(t, err)
+	requireLines(t, metastore, lres.Locations[0], []expectedLine{
+		{name: "leaf", filename: "/src/inlined.c", line: 9},
+		{name: "middle", filename: "/src/inlined.c", line: 13},
+		{name: "main", filename: "/src/inlined.c", line: 18},
+	})
+}
+
+func TestSymbolizerDryRun(t *testing.T) {
+	_, metastore, sym := setup(t)
+	fetcher := &countingDebugInfoFetcher{DebugInfoFetcher: sym.debuginfo}
+	sym.debuginfo = fetcher
+
+	ctx := context.Background()
+
+	// An executable with debug info, a shared library without, and a mapping
+	// without a build ID.
+	const (
+		buildID     = "a695b153282bb4da64ca7397a7cf029b63a6419f"
+		libcBuildID = "2222222222222222222222222222222222222222"
+		loadAddress = 0x55d3e0a00000
+	)
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   loadAddress + 0x1000,
+			Limit:   loadAddress + 0x2000,
+			Offset:  0x1000,
+			BuildId: buildID,
+		}, {
+			Start:   0x7f3c1a628000,
+			Limit:   0x7f3c1a7bd000,
+			Offset:  0x28000,
+			File:    "/usr/lib/x86_64-linux-gnu/libc.so.6",
+			BuildId: libcBuildID,
+		}, {
+			Start: 0x400000,
+			Limit: 0x500000,
+			File:  "/usr/bin/stripped",
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(mres.Mappings))
+
+	_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{MappingId: mres.Mappings[0].Id, Address: loadAddress + 0x1164},
+			{MappingId: mres.Mappings[0].Id, Address: loadAddress + 0x114d},
+			{MappingId: mres.Mappings[1].Id, Address: 0x7f3c1a6a1d90},
+			{MappingId: mres.Mappings[2].Id, Address: 0x401000},
+		},
+	})
+	require.NoError(t, err)
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 4, len(ures.Locations))
+	// A location that was symbolized in the meantime.
+	locations := append(ures.Locations, &pb.Location{
+		Id:        "symbolized",
+		MappingId: mres.Mappings[0].Id,
+		Lines:     []*pb.Line{{FunctionId: "main", Line: 1}},
+	})
+
+	report, err := sym.DryRun(ctx, locations)
+	require.NoError(t, err)
+	require.Equal(t, &DryRunReport{
+		BuildIDs: map[string]*DryRunCounts{
+			buildID:     {Resolvable: 2},
+			libcBuildID: {MissingDebugInfo: 1},
+		},
+		Skipped: map[string]int{
+			skipReasonSymbolized: 1,
+			skipReasonNoBuildID:  1,
+		},
+	}, report)
+
+	// Nothing was fetched nor written.
+	require.Equal(t, 0, fetcher.calls)
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 4, len(ures.Locations))
+
+	// Symbolizing has the outcome the report predicted.
+	err = sym.Symbolize(ctx, ures.Locations)
+	var skipped *SkippedLocationsError
+	require.ErrorAs(t, err, &skipped)
+	require.Equal(t, libcBuildID, skipped.BuildID)
+	require.Len(t, skipped.LocationIDs, report.BuildIDs[libcBuildID].MissingDebugInfo)
+
+	// Only the skipped locations are left unsymbolized.
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ures.Locations))
+
+	// The debug info is known to be missing now, which the report still
+	// reflects.
+	report, err = sym.DryRun(ctx, ures.Locations)
+	require.NoError(t, err)
+	require.Equal(t, &DryRunReport{
+		BuildIDs: map[string]*DryRunCounts{libcBuildID: {MissingDebugInfo: 1}},
+		Skipped:  map[string]int{skipReasonNoBuildID: 1},
+	}, report)
+}
+
+// slowDebugInfoFetcher returns the same debug info file for every build ID,
+// keeping track of how many fetches are in flight at the same time.
+type slowDebugInfoFetcher struct {
+	path string
+
+	mtx         sync.Mutex
+	inFlight    int
+	maxInFlight int
+	fetched     map[string]struct{}
+}
+
+func (f *slowDebugInfoFetcher) FetchDebugInfo(ctx context.Context, buildID string) (string, debuginfopb.DownloadInfo_Source, error) {
+	f.mtx.Lock()
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.fetched[buildID] = struct{}{}
+	f.mtx.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	f.mtx.Lock()
+	f.inFlight--
+	f.mtx.Unlock()
+
+	return f.path, debuginfopb.DownloadInfo_SOURCE_UPLOAD, nil
+}
+
+func (f *slowDebugInfoFetcher) DebugInfoStatus(buildID string) (debuginfo.Status, bool) {
+	return debuginfo.Status{}, false
+}
+
+func (f *slowDebugInfoFetcher) MarkSymbolized(buildID string) {}
+
+func (f *slowDebugInfoFetcher) HasDebugInfo(context.Context, string) (bool, error) {
+	return true, nil
+}
+
+func TestSymbolizerConcurrency(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	fetcher := &slowDebugInfoFetcher{
+		path:    "testdata/2d6912fd3dd64542f6f6294f4bf9cb6c265b3085/debuginfo",
+		fetched: map[string]struct{}{},
+	}
+	sym.debuginfo = fetcher
+	sym.concurrency = 4
+	sym.fetchSem = make(chan struct{}, 2)
+
+	ctx := context.Background()
+
+	// Every build ID is a distinct object file as far as the symbolizer is
+	// concerned.
+	const n = 8
+	mappings := make([]*pb.Mapping, 0, n)
+	for i := 0; i < n; i++ {
+		mappings = append(mappings, &pb.Mapping{
+			Start:   4194304,
+			Limit:   4603904,
+			BuildId: fmt.Sprintf("%040x", i+1),
+		})
+	}
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{Mappings: mappings})
+	require.NoError(t, err)
+
+	locations := make([]*pb.Location, 0, n)
+	for _, m := range mres.Mappings {
+		locations = append(locations, &pb.Location{MappingId: m.Id, Address: 0x463781})
+	}
+	_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: locations})
+	require.NoError(t, err)
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, n, len(ures.Locations))
+
+	require.NoError(t, sym.Symbolize(ctx, ures.Locations))
+
+	require.Len(t, fetcher.fetched, n)
+	require.LessOrEqual(t, fetcher.maxInFlight, 2)
+
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(ures.Locations))
+}
+
+// cancelledDebugInfoFetcher signals every fetch it starts and blocks it until
+// its context is done. It counts the build IDs the symbolizer looked at.
+type cancelledDebugInfoFetcher struct {
+	slowDebugInfoFetcher
+
+	started  chan string
+	lookedAt int32
+}
+
+func (f *cancelledDebugInfoFetcher) DebugInfoStatus(buildID string) (debuginfo.Status, bool) {
+	atomic.AddInt32(&f.lookedAt, 1)
+	return debuginfo.Status{}, false
+}
+
+func (f *cancelledDebugInfoFetcher) FetchDebugInfo(ctx context.Context, buildID string) (string, debuginfopb.DownloadInfo_Source, error) {
+	f.started <- buildID
+	<-ctx.Done()
+	return "", debuginfopb.DownloadInfo_SOURCE_UNKNOWN_UNSPECIFIED, ctx.Err()
+}
+
+func TestSymbolizerCancellation(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	fetcher := &cancelledDebugInfoFetcher{started: make(chan string, 8)}
+	sym.debuginfo = fetcher
+	sym.concurrency = 2
+	sym.fetchSem = make(chan struct{}, 2)
+
+	ctx := context.Background()
+
+	const n = 8
+	mappings := make([]*pb.Mapping, 0, n)
+	for i := 0; i < n; i++ {
+		mappings = append(mappings, &pb.Mapping{
+			Start:   4194304,
+			Limit:   4603904,
+			BuildId: fmt.Sprintf("%040x", i+1),
+		})
+	}
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{Mappings: mappings})
+	require.NoError(t, err)
+
+	locations := make([]*pb.Location, 0, n)
+	for _, m := range mres.Mappings {
+		locations = append(locations, &pb.Location{MappingId: m.Id, Address: 0x463781})
+	}
+	_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: locations})
+	require.NoError(t, err)
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, n, len(ures.Locations))
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		done <- sym.Symbolize(ctx, ures.Locations)
+	}()
+
+	// Cancel while the first object files are being fetched.
+	<-fetcher.started
+	cancel()
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("symbolization didn't return after it was cancelled")
+	}
+	// Only the object files in flight when cancelled were looked at.
+	require.Equal(t, int32(2), atomic.LoadInt32(&fetcher.lookedAt))
+
+	// None of the goroutines of the pass outlive it.
+	requireNoSymbolizerGoroutines(t)
+
+	// The locations are left to be symbolized later.
+	ures, err = metastore.UnsymbolizedLocations(context.Background(), &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, n, len(ures.Locations))
+}
+
+func TestSymbolizerStop(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	fetcher := &cancelledDebugInfoFetcher{started: make(chan string, 8)}
+	sym.debuginfo = fetcher
+	sym.fetchSem = make(chan struct{}, 2)
+
+	ctx := context.Background()
+
+	buildIDs := []string{
+		"1111111111111111111111111111111111111111",
+		"2222222222222222222222222222222222222222",
+	}
+	mappings := make([]*pb.Mapping, 0, len(buildIDs))
+	for _, buildID := range buildIDs {
+		mappings = append(mappings, &pb.Mapping{Start: 4194304, Limit: 4603904, BuildId: buildID})
+	}
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{Mappings: mappings})
+	require.NoError(t, err)
+
+	locations := make([]*pb.Location, 0, len(mres.Mappings))
+	for _, m := range mres.Mappings {
+		locations = append(locations, &pb.Location{MappingId: m.Id, Address: 0x463781})
+	}
+	_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: locations})
+	require.NoError(t, err)
+
+	// Both the cycle and the re-symbolization block fetching debug info
+	// until they are canceled.
+	done := make(chan error)
+	go func() {
+		done <- sym.Run(ctx, time.Hour)
+	}()
+	<-fetcher.started
+	sym.ReSymbolizeInBackground(buildIDs[1])
+	<-fetcher.started
+
+	sym.Stop()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run didn't return after the symbolizer was stopped")
+	}
+	requireNoSymbolizerGoroutines(t)
+
+	// Nothing is started anymore once stopped.
+	require.NoError(t, sym.Run(ctx, time.Hour))
+	sym.ReSymbolizeInBackground(buildIDs[0])
+	sym.Stop()
+	require.Len(t, fetcher.started, 0)
+	requireNoSymbolizerGoroutines(t)
+}
+
+// requireNoSymbolizerGoroutines requires the goroutines started by the
+// symbolizer to have returned. Other goroutines are left to the leak check of
+// the package, as the ones of gRPC are started and stopped asynchronously.
+func requireNoSymbolizerGoroutines(t *testing.T) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		buf := make([]byte, 1<<20)
+		stacks := string(buf[:runtime.Stack(buf, true)])
+		return !strings.Contains(stacks, "symbolizer.(*Symbolizer)") && !strings.Contains(stacks, "cancelledDebugInfoFetcher")
+	}, time.Second, 10*time.Millisecond)
+}
+
+// blockingMetastore records the requests for unsymbolized locations and
+// blocks them until they are released.
+type blockingMetastore struct {
+	pb.MetastoreServiceClient
+
+	requests chan *pb.UnsymbolizedLocationsRequest
+	release  chan struct{}
+}
+
+func (m *blockingMetastore) UnsymbolizedLocations(ctx context.Context, in *pb.UnsymbolizedLocationsRequest, opts ...grpc.CallOption) (*pb.UnsymbolizedLocationsResponse, error) {
+	m.requests <- in
+	<-m.release
+	return m.MetastoreServiceClient.UnsymbolizedLocations(ctx, in, opts...)
+}
+
+func TestSymbolizerRunBatches(t *testing.T) {
+	_, metastore, sym := setup(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Locations of mappings without build ID stay unsymbolized.
+	const n = 5
+	mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{Start: 4194304, Limit: 4603904}},
+	})
+	require.NoError(t, err)
+	locations := make([]*pb.Location, 0, n)
+	for i := 0; i < n; i++ {
+		locations = append(locations, &pb.Location{MappingId: mres.Mappings[0].Id, Address: uint64(0x463781 + i)})
+	}
+	_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: locations})
+	require.NoError(t, err)
+
+	bm := &blockingMetastore{
+		MetastoreServiceClient: metastore,
+		requests:               make(chan *pb.UnsymbolizedLocationsRequest, n),
+		release:                make(chan struct{}),
+	}
+	sym.metastore = bm
+	sym.batchSize = 2
+
+	ticks := make(chan time.Time)
+	done := make(chan error)
+	go func() {
+		done <- sym.run(ctx, ticks)
+	}()
+
+	// runCycle waits for the cycle to fetch the unsymbolized locations, lets
+	// it finish and returns its request.
+	runCycle := func() *pb.UnsymbolizedLocationsRequest {
+		req := <-bm.requests
+		bm.release <- struct{}{}
+		require.Eventually(t, func() bool { return len(sym.cycle) == 0 }, time.Second, time.Millisecond)
+		// Every cycle looks at a single batch only.
+		require.Len(t, bm.requests, 0)
+		return req
+	}
+
+	// The first cycle runs right away, ticks during it are skipped.
+	req := <-bm.requests
+	ticks <- time.Now()
+	require.Eventually(t, func() bool { return testutil.ToFloat64(sym.skippedCycles) == 1 }, time.Second, time.Millisecond)
+	bm.release <- struct{}{}
+	require.Eventually(t, func() bool { return len(sym.cycle) == 0 }, time.Second, time.Millisecond)
+	require.Len(t, bm.requests, 0)
+	require.Equal(t, uint32(2), req.Limit)
+	require.Equal(t, "", req.MinKey)
+
+	// The following cycles continue where the previous one stopped, until
+	// all locations were looked at.
+	ticks <- time.Now()
+	second := runCycle()
+	require.Equal(t, uint32(2), second.Limit)
+	require.NotEqual(t, "", second.MinKey)
+
+	ticks <- time.Now()
+	third := runCycle()
+	require.Greater(t, third.MinKey, second.MinKey)
+
+	ticks <- time.Now()
+	require.Equal(t, "", runCycle().MinKey)
+	require.Equal(t, float64(1), testutil.ToFloat64(sym.skippedCycles))
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestSymbolizerReverification(t *testing.T) {
+	_, ms, sym := setup(t)
+	ctx := context.Background()
+
+	mres, err := ms.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+		Mappings: []*pb.Mapping{{
+			Start:   4194304,
+			Limit:   4603904,
+			BuildId: "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085",
+		}},
+	})
+	require.NoError(t, err)
+	lres, err := ms.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{
+		Locations: []*pb.Location{
+			{MappingId: mres.Mappings[0].Id, Address: 0x463781},
+			{MappingId: mres.Mappings[0].Id, Address: 0x463784},
+		},
+	})
+	require.NoError(t, err)
+	aged, fresh := lres.Locations[0], lres.Locations[1]
+
+	// Both locations are stored with stale lines, one of them a long time
+	// ago.
+	now := time.Unix(1000000, 0)
+	stale := &pb.Function{Name: "stale"}
+	staleLines := []*pb.Line{{FunctionId: metastore.MakeFunctionID(stale), Line: 1}}
+	for _, l := range []struct {
+		location     *pb.Location
+		symbolizedAt time.Time
+	}{
+		{aged, now.Add(-2 * time.Hour)},
+		{fresh, now.Add(-time.Minute)},
+	} {
+		_, err = ms.CreateLocationLines(ctx, &pb.CreateLocationLinesRequest{
+			Locations:    []*pb.Location{{Id: l.location.Id, Address: l.location.Address, MappingId: l.location.MappingId, Lines: staleLines}},
+			Functions:    []*pb.Function{stale},
+			SymbolizedAt: timestamppb.New(l.symbolizedAt),
+		})
+		require.NoError(t, err)
+	}
+
+	sym.now = func() time.Time { return now }
+	sym.reverifyAge = time.Hour
+	sym.reverifyBatchSize = 10
+	sym.runSymbolizationCycle(ctx)
+
+	res, err := ms.Locations(ctx, &pb.LocationsRequest{LocationIds: []string{aged.Id, fresh.Id}})
+	require.NoError(t, err)
+
+	// Only the aged location was symbolized again.
+	requireLines(t, ms, res.Locations[0], []expectedLine{
+		{name: "main.iterate", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 27},
+		{name: "main.iteratePerTenant", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 23},
+		{name: "main.main", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 10},
+	})
+	require.Len(t, res.Locations[1].Lines, 1)
+	require.True(t, proto.Equal(staleLines[0], res.Locations[1].Lines[0]))
+	require.Equal(t, float64(1), testutil.ToFloat64(sym.reverified.WithLabelValues(reverifyResultChanged)))
+
+	// The aged location was recorded as symbolized now, so nothing is old
+	// enough to be re-verified anymore.
+	sres, err := ms.SymbolizedLocations(ctx, &pb.SymbolizedLocationsRequest{
+		SymbolizedBefore: timestamppb.New(now.Add(-time.Hour)),
 	})
 	require.NoError(t, err)
-	require.Equal(t, 3, len(fres.Functions))
+	require.Len(t, sres.Locations, 0)
+
+	// Once both are old enough, the lines of the aged location are found
+	// unchanged and the stale lines of the other one are replaced.
+	sym.now = func() time.Time { return now.Add(2 * time.Hour) }
+	sym.runSymbolizationCycle(ctx)
+	require.Equal(t, float64(2), testutil.ToFloat64(sym.reverified.WithLabelValues(reverifyResultChanged)))
+	require.Equal(t, float64(1), testutil.ToFloat64(sym.reverified.WithLabelValues(reverifyResultUnchanged)))
+}
+
+func requireLines(t *testing.T, metastore pb.MetastoreServiceClient, location *pb.Location, expected []expectedLine) {
+	t.Helper()
+
+	require.Equal(t, len(expected), len(location.Lines))
 
-	require.Equal(t, fres.Functions[0].Id, lres.Locations[0].Lines[0].FunctionId)
-	require.Equal(t, "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", fres.Functions[0].Filename)
-	require.Equal(t, "main.main", fres.Functions[0].Name)
-	require.Equal(t, int64(7), lres.Locations[0].Lines[0].Line) // llvm-addr2line gives 10
+	functionIds := make([]string, 0, len(location.Lines))
+	for _, line := range location.Lines {
+		functionIds = append(functionIds, line.FunctionId)
+	}
 
-	require.Equal(t, fres.Functions[1].Id, lres.Locations[0].Lines[1].FunctionId)
-	require.Equal(t, "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", fres.Functions[1].Filename)
-	require.Equal(t, "main.iterate", fres.Functions[1].Name)
-	require.Equal(t, int64(27), lres.Locations[0].Lines[1].Line)
+	fres, err := metastore.Functions(context.Background(), &pb.FunctionsRequest{
+		FunctionIds: functionIds,
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(expected), len(fres.Functions))
 
-	require.Equal(t, fres.Functions[2].Id, lres.Locations[0].Lines[2].FunctionId)
-	require.Equal(t, "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", fres.Functions[2].Filename)
-	require.Equal(t, "main.iteratePerTenant", fres.Functions[2].Name)
-	require.Equal(t, int64(23), lres.Locations[0].Lines[2].Line)
+	for i, e := range expected {
+		require.Equal(t, fres.Functions[i].Id, location.Lines[i].FunctionId)
+		require.Equal(t, e.name, fres.Functions[i].Name)
+		require.Equal(t, e.filename, fres.Functions[i].Filename)
+		require.Equal(t, e.line, location.Lines[i].Line)
+	}
 }
 
 func findIndexWithAddress(locs []*pb.Location, address uint64) int {
@@ -139,9 +2258,11 @@ func TestRealSymbolizer(t *testing.T) {
 
 	ctx := context.Background()
 
+	// Two of the 11 addresses are both the leaf of a stack and a caller in
+	// another one, which are distinct locations.
 	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
 	require.NoError(t, err)
-	require.Equal(t, 11, len(ures.Locations))
+	require.Equal(t, 13, len(ures.Locations))
 	id := ures.Locations[findIndexWithAddress(ures.Locations, 0x463784)].Id
 
 	require.NoError(t, sym.Symbolize(ctx, ures.Locations))
@@ -156,30 +2277,72 @@ func TestRealSymbolizer(t *testing.T) {
 	})
 	require.NoError(t, err)
 	require.Equal(t, 1, len(lres.Locations))
-	require.Equal(t, 3, len(lres.Locations[0].Lines))
 
-	functionIds := []string{}
-	for _, location := range lres.Locations {
-		for _, line := range location.Lines {
-			functionIds = append(functionIds, line.FunctionId)
-		}
+	requireLines(t, metastore, lres.Locations[0], []expectedLine{
+		{name: "main.iterate", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 27},
+		{name: "main.iteratePerTenant", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 23},
+		{name: "main.main", filename: "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", line: 10},
+	})
+}
+
+func TestRealSymbolizerReturnAddress(t *testing.T) {
+	conn, metastore, sym := setup(t)
+
+	// 0x4313d6 is the return address of a call of runtime.main on line 225,
+	// the instruction at it is on line 234 already. It is the leaf of the
+	// first sample and the caller in the second one.
+	p := &pprofpb.Profile{
+		StringTable: []string{"", "samples", "count", "2d6912fd3dd64542f6f6294f4bf9cb6c265b3085"},
+		SampleType:  []*pprofpb.ValueType{{Type: 1, Unit: 2}},
+		Mapping:     []*pprofpb.Mapping{{Id: 1, MemoryStart: 0x400000, MemoryLimit: 0x464000, BuildId: 3}},
+		Location: []*pprofpb.Location{
+			{Id: 1, MappingId: 1, Address: 0x4313d6},
+			{Id: 2, MappingId: 1, Address: 0x463784},
+		},
+		Sample: []*pprofpb.Sample{
+			{LocationId: []uint64{1}, Value: []int64{1}},
+			{LocationId: []uint64{2, 1}, Value: []int64{1}},
+		},
 	}
+	content, err := p.MarshalVT()
+	require.NoError(t, err)
 
-	fres, err := metastore.Functions(ctx, &pb.FunctionsRequest{
-		FunctionIds: functionIds,
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err = w.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	ctx := context.Background()
+	_, err = profilestorepb.NewProfileStoreServiceClient(conn).WriteRaw(ctx, &profilestorepb.WriteRawRequest{
+		Series: []*profilestorepb.RawProfileSeries{{
+			Labels:  &profilestorepb.LabelSet{Labels: []*profilestorepb.Label{{Name: "__name__", Value: "process_cpu"}}},
+			Samples: []*profilestorepb.RawSample{{RawProfile: buf.Bytes()}},
+		}},
 	})
 	require.NoError(t, err)
-	require.Equal(t, 3, len(fres.Functions))
 
-	require.Equal(t, "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", fres.Functions[0].Filename)
-	require.Equal(t, "main.main", fres.Functions[0].Name)
-	require.Equal(t, int64(7), lres.Locations[0].Lines[0].Line) // llvm-addr2line gives 10
-	require.Equal(t, "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", fres.Functions[1].Filename)
-	require.Equal(t, "main.iterate", fres.Functions[1].Name)
-	require.Equal(t, int64(27), lres.Locations[0].Lines[1].Line)
-	require.Equal(t, "/home/brancz/src/github.com/polarsignals/pprof-labels-example/main.go", fres.Functions[2].Filename)
-	require.Equal(t, "main.iteratePerTenant", fres.Functions[2].Name)
-	require.Equal(t, int64(23), lres.Locations[0].Lines[2].Line)
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(ures.Locations))
+	require.NoError(t, sym.Symbolize(ctx, ures.Locations))
+
+	ids := make([]string, 0, len(ures.Locations))
+	for _, l := range ures.Locations {
+		ids = append(ids, l.Id)
+	}
+	lres, err := metastore.Locations(ctx, &pb.LocationsRequest{LocationIds: ids})
+	require.NoError(t, err)
+
+	lines := map[bool]int64{}
+	for _, l := range lres.Locations {
+		if l.Address != 0x4313d6 {
+			continue
+		}
+		require.Len(t, l.Lines, 1)
+		lines[l.ReturnAddress] = l.Lines[0].Line
+	}
+	require.Equal(t, map[bool]int64{false: 234, true: 225}, lines)
 }
 
 func TestRealSymbolizerDwarfAndSymbols(t *testing.T) {
@@ -218,11 +2381,11 @@ func TestRealSymbolizerDwarfAndSymbols(t *testing.T) {
 
 	require.Equal(t, "/home/kakkoyun/Workspace/PolarSignals/pprof-example-app-go/fib/fib.go", fres.Functions[0].Filename)
 	require.Equal(t, "github.com/polarsignals/pprof-example-app-go/fib.Fibonacci", fres.Functions[0].Name)
-	require.Equal(t, int64(5), lres.Locations[0].Lines[0].Line)
+	require.Equal(t, int64(13), lres.Locations[0].Lines[0].Line)
 
 	require.Equal(t, "/home/kakkoyun/Workspace/PolarSignals/pprof-example-app-go/main.go", fres.Functions[1].Filename)
 	require.Equal(t, "main.busyCPU", fres.Functions[1].Name)
-	require.Equal(t, int64(86), lres.Locations[1].Lines[0].Line)
+	require.Equal(t, int64(89), lres.Locations[1].Lines[0].Line)
 }
 
 func TestRealSymbolizerInliningDisabled(t *testing.T) {
@@ -268,11 +2431,11 @@ func TestRealSymbolizerInliningDisabled(t *testing.T) {
 
 	require.Equal(t, "/home/kakkoyun/Workspace/PolarSignals/pprof-example-app-go/fib/fib.go", fres.Functions[0].Filename)
 	require.Equal(t, "github.com/polarsignals/pprof-example-app-go/fib.Fibonacci", fres.Functions[0].Name)
-	require.Equal(t, int64(5), lres.Locations[0].Lines[0].Line)
+	require.Equal(t, int64(13), lres.Locations[0].Lines[0].Line)
 
 	require.Equal(t, "/home/kakkoyun/Workspace/PolarSignals/pprof-example-app-go/main.go", fres.Functions[1].Filename)
 	require.Equal(t, "main.busyCPU", fres.Functions[1].Name)
-	require.Equal(t, int64(86), lres.Locations[1].Lines[0].Line)
+	require.Equal(t, int64(89), lres.Locations[1].Lines[0].Line)
 }
 
 func TestRealSymbolizerWithoutDWARF(t *testing.T) {
@@ -393,7 +2556,7 @@ func mustReadAll(t require.TestingT, filename string) []byte {
 	return content
 }
 
-func ingest(t *testing.T, conn *grpc.ClientConn, path string) error {
+func ingest(t testing.TB, conn *grpc.ClientConn, path string) error {
 	fileContent := mustReadAll(t, path)
 	wc := profilestorepb.NewProfileStoreServiceClient(conn)
 	_, err := wc.WriteRaw(context.Background(), &profilestorepb.WriteRawRequest{
@@ -407,10 +2570,15 @@ func ingest(t *testing.T, conn *grpc.ClientConn, path string) error {
 	return err
 }
 
-func setup(t *testing.T) (*grpc.ClientConn, pb.MetastoreServiceClient, *Symbolizer) {
+func setup(t testing.TB) (*grpc.ClientConn, pb.MetastoreServiceClient, *Symbolizer) {
+	t.Helper()
+
+	return setupWithLogger(t, log.NewNopLogger())
+}
+
+func setupWithLogger(t testing.TB, logger log.Logger) (*grpc.ClientConn, pb.MetastoreServiceClient, *Symbolizer) {
 	t.Helper()
 
-	logger := log.NewNopLogger()
 	reg := prometheus.NewRegistry()
 	tracer := trace.NewNoopTracerProvider().Tracer("")
 	col, err := frostdb.New(
@@ -418,6 +2586,7 @@ func setup(t *testing.T) (*grpc.ClientConn, pb.MetastoreServiceClient, *Symboliz
 		reg,
 	)
 	require.NoError(t, err)
+	t.Cleanup(func() { col.Close() })
 
 	colDB, err := col.DB(context.Background(), "parca")
 	require.NoError(t, err)
@@ -443,7 +2612,7 @@ func setup(t *testing.T) (*grpc.ClientConn, pb.MetastoreServiceClient, *Symboliz
 		os.RemoveAll(symbolizerCacheDir)
 	})
 
-	sym, err := symbol.NewSymbolizer(logger)
+	sym, err := symbol.NewSymbolizer(logger, prometheus.NewRegistry())
 	require.NoError(t, err)
 
 	cfg, err := yaml.Marshal(&client.BucketConfig{
@@ -460,10 +2629,13 @@ func setup(t *testing.T) (*grpc.ClientConn, pb.MetastoreServiceClient, *Symboliz
 	metadata := debuginfo.NewObjectStoreMetadata(logger, bucket)
 	dbgStr, err := debuginfo.NewStore(
 		logger,
+		prometheus.NewRegistry(),
 		debugInfoCacheDir,
 		metadata,
 		bucket,
 		debuginfo.NopDebugInfodClient{},
+		sym,
+		nil,
 	)
 	require.NoError(t, err)
 
@@ -478,6 +2650,7 @@ func setup(t *testing.T) (*grpc.ClientConn, pb.MetastoreServiceClient, *Symboliz
 
 	pStr := profilestore.NewProfileColumnStore(
 		logger,
+		prometheus.NewRegistry(),
 		tracer,
 		metastore,
 		table,
@@ -494,8 +2667,10 @@ func setup(t *testing.T) (*grpc.ClientConn, pb.MetastoreServiceClient, *Symboliz
 		grpcServer.GracefulStop()
 	})
 
-	debuginfopb.RegisterDebugInfoServiceServer(grpcServer, dbgStr)
-	profilestorepb.RegisterProfileStoreServiceServer(grpcServer, pStr)
+	server.RegisterServices(grpcServer, server.Services{
+		DebugInfo:    dbgStr,
+		ProfileStore: pStr,
+	})
 
 	go func() {
 		err := grpcServer.Serve(lis)
@@ -512,11 +2687,84 @@ func setup(t *testing.T) (*grpc.ClientConn, pb.MetastoreServiceClient, *Symboliz
 
 	return conn, metastore, New(
 		logger,
+		prometheus.NewRegistry(),
 		metastore,
 		dbgStr,
 		sym,
 		symbolizerCacheDir,
 		symbolizerCacheDir,
-		0,
+		WithMissingDebugInfoTTL(time.Minute),
 	)
 }
+
+func BenchmarkSymbolizeLargeProfile(b *testing.B) {
+	conn, metastore, sym := setup(b)
+
+	// Generated from https://github.com/polarsignals/pprof-example-app-go
+	require.NoError(b, ingest(b, conn, "testdata/normal-cpu.stripped.pprof"))
+
+	ctx := context.Background()
+
+	ures, err := metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(b, err)
+
+	mres, err := metastore.Mappings(ctx, &pb.MappingsRequest{MappingIds: []string{ures.Locations[0].MappingId}})
+	require.NoError(b, err)
+	m := mres.Mappings[0]
+
+	// Simulate the same binary running as many processes, each of them
+	// having its own mapping of the same object file.
+	const processes = 10
+	for i := 1; i < processes; i++ {
+		mres, err := metastore.GetOrCreateMappings(ctx, &pb.GetOrCreateMappingsRequest{
+			Mappings: []*pb.Mapping{{
+				Start:   m.Start,
+				Limit:   m.Limit,
+				Offset:  m.Offset,
+				File:    fmt.Sprintf("/proc/%d/exe", i),
+				BuildId: m.BuildId,
+			}},
+		})
+		require.NoError(b, err)
+
+		locations := make([]*pb.Location, 0, len(ures.Locations))
+		for _, loc := range ures.Locations {
+			locations = append(locations, &pb.Location{
+				MappingId: mres.Mappings[0].Id,
+				Address:   loc.Address,
+			})
+		}
+		_, err = metastore.GetOrCreateLocations(ctx, &pb.GetOrCreateLocationsRequest{Locations: locations})
+		require.NoError(b, err)
+	}
+
+	ures, err = metastore.UnsymbolizedLocations(ctx, &pb.UnsymbolizedLocationsRequest{})
+	require.NoError(b, err)
+
+	fetcher := &countingDebugInfoFetcher{DebugInfoFetcher: sym.debuginfo}
+	sym.debuginfo = fetcher
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// Start every iteration with nothing opened and nothing symbolized.
+		sym.symbolizer, err = symbol.NewSymbolizer(log.NewNopLogger(), prometheus.NewRegistry())
+		require.NoError(b, err)
+
+		locations := make([]*pb.Location, 0, len(ures.Locations))
+		for _, loc := range ures.Locations {
+			locations = append(locations, &pb.Location{
+				Id:        loc.Id,
+				MappingId: loc.MappingId,
+				Address:   loc.Address,
+			})
+		}
+		b.StartTimer()
+
+		require.NoError(b, sym.Symbolize(ctx, locations))
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(fetcher.calls)/float64(b.N), "debuginfo_opens/op")
+}
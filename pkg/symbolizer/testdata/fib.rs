@@ -0,0 +1,24 @@
+#![no_std]
+#![no_main]
+
+mod fib {
+    #[inline(never)]
+    pub fn fibonacci(n: u64) -> u64 {
+        if n < 2 {
+            return n;
+        }
+        fibonacci(n - 1) + fibonacci(n - 2)
+    }
+}
+
+#[no_mangle]
+pub extern "C" fn _start() -> ! {
+    let n = unsafe { core::ptr::read_volatile(&30u64) };
+    unsafe { core::ptr::write_volatile(&mut 0u64 as *mut u64, fib::fibonacci(n)) };
+    loop {}
+}
+
+#[panic_handler]
+fn panic(_: &core::panic::PanicInfo) -> ! {
+    loop {}
+}
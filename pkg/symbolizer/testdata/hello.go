@@ -0,0 +1,10 @@
+package main
+
+//go:noinline
+func square(x int) int {
+	return x * x
+}
+
+func main() {
+	println(square(3))
+}
@@ -0,0 +1,20 @@
+#include <stdio.h>
+#include <stdlib.h>
+
+__attribute__((noinline)) int work(int x) {
+	return x * 31 + 7;
+}
+
+static inline __attribute__((always_inline)) int leaf(int x) {
+	return work(x) + 1;
+}
+
+static inline __attribute__((always_inline)) int middle(int x) {
+	return leaf(x) * 2;
+}
+
+int main(int argc, char **argv) {
+	int n = argc > 1 ? atoi(argv[1]) : 0;
+	printf("%d\n", middle(n));
+	return 0;
+}
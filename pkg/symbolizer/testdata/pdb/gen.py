@@ -0,0 +1,121 @@
+#!/usr/bin/env python3
+# Generates hello.pdb and hello.exe from hello.yaml:
+#
+#   llvm-pdbutil yaml2pdb -pdb=/tmp/hello.pdb hello.yaml
+#   python3 gen.py /tmp/hello.pdb
+#
+# yaml2pdb doesn't write the section headers of the image, so they are added
+# as a new stream referred to by the optional debug header of the DBI stream.
+# hello.exe is a PE image without code, only with the CodeView record that
+# refers to the PDB file.
+import struct
+import sys
+import uuid
+
+MAGIC = b"Microsoft C/C++ MSF 7.00\r\n\x1aDS\x00\x00\x00"
+BLOCK_SIZE = 512
+TEXT_RVA = 0x1000
+RDATA_RVA = 0x2000
+
+
+def read_msf(data):
+    block_size, _, _, dir_bytes, _, block_map = struct.unpack_from("<6I", data, len(MAGIC))
+    blocks = lambda n: (n + block_size - 1) // block_size
+    read = lambda bl, n: b"".join(data[b * block_size:(b + 1) * block_size] for b in bl)[:n]
+    dir_blocks = struct.unpack_from("<%dI" % blocks(dir_bytes), data, block_map * block_size)
+    d = read(dir_blocks, dir_bytes)
+    n = struct.unpack_from("<I", d)[0]
+    sizes = struct.unpack_from("<%dI" % n, d, 4)
+    off, streams = 4 + 4 * n, []
+    for size in sizes:
+        if size == 0xFFFFFFFF:
+            streams.append(None)
+            continue
+        bl = struct.unpack_from("<%dI" % blocks(size), d, off)
+        off += 4 * len(bl)
+        streams.append(read(bl, size))
+    return streams
+
+
+def write_msf(streams):
+    blocks = lambda n: (n + BLOCK_SIZE - 1) // BLOCK_SIZE
+    # Block 0 is the superblock, 1 and 2 are the free block maps.
+    out = [b"", b"", b""]
+    directory = struct.pack("<I", len(streams))
+    directory += b"".join(struct.pack("<I", 0xFFFFFFFF if s is None else len(s)) for s in streams)
+    for s in streams:
+        for i in range(blocks(len(s or b""))):
+            directory += struct.pack("<I", len(out))
+            out.append(s[i * BLOCK_SIZE:(i + 1) * BLOCK_SIZE])
+    dir_blocks = []
+    for i in range(blocks(len(directory))):
+        dir_blocks.append(len(out))
+        out.append(directory[i * BLOCK_SIZE:(i + 1) * BLOCK_SIZE])
+    block_map = len(out)
+    out.append(b"".join(struct.pack("<I", b) for b in dir_blocks))
+
+    # Set bits of the free block map mark free blocks.
+    fpm = bytearray(b"\xff" * BLOCK_SIZE)
+    for i in range(len(out)):
+        fpm[i // 8] &= ~(1 << (i % 8)) & 0xFF
+    out[1] = bytes(fpm)
+    out[0] = MAGIC + struct.pack("<6I", BLOCK_SIZE, 1, len(out), len(directory), 0, block_map)
+    return b"".join(b.ljust(BLOCK_SIZE, b"\0") for b in out)
+
+
+def section_header(name, rva, size, characteristics):
+    return struct.pack("<8sIIIIIIHHI", name, size, rva, size, 0, 0, 0, 0, 0, characteristics)
+
+
+def add_section_headers(streams):
+    sections = section_header(b".text", TEXT_RVA, 0x1000, 0x60000020)
+    sections += section_header(b".rdata", RDATA_RVA, 0x1000, 0x40000040)
+    streams.append(sections)
+
+    dbi = bytearray(streams[3])
+    # The optional debug header follows the other substreams.
+    sizes = struct.unpack_from("<5i", dbi, 24)
+    dbg_size, ec_size = struct.unpack_from("<2i", dbi, 48)
+    dbg_off = 64 + sum(sizes) + ec_size
+    dbg = [0xFFFF] * 11
+    dbg[5] = len(streams) - 1
+    dbi[dbg_off:dbg_off + dbg_size] = struct.pack("<11H", *dbg)
+    struct.pack_into("<i", dbi, 48, 22)
+    streams[3] = bytes(dbi)
+
+
+def pe(guid, age):
+    cv = b"RSDS" + guid + struct.pack("<I", age) + b"hello.pdb\0"
+    debug_dir = struct.pack("<IIHHIIII", 0, 0, 0, 0, 2, len(cv), RDATA_RVA + 28, 0x400 + 28)
+    rdata = (debug_dir + cv).ljust(0x200, b"\0")
+
+    dos = b"MZ".ljust(0x3C, b"\0") + struct.pack("<I", 0x40)
+    coff = struct.pack("<HHIIIHH", 0x8664, 1, 0, 0, 0, 240, 0x22)
+    dirs = [(0, 0)] * 16
+    dirs[6] = (RDATA_RVA, 28)
+    opt = struct.pack("<HBBIIIII", 0x20B, 14, 0, 0, 0x200, 0, TEXT_RVA, TEXT_RVA)
+    opt += struct.pack("<QIIHHHHHHIIIIHHQQQQII", 0x140000000, 0x1000, 0x200, 6, 0, 0, 0, 6, 0,
+                       0, 0x3000, 0x400, 0, 3, 0x8160, 0x100000, 0x1000, 0x100000, 0x1000, 0, 16)
+    opt += b"".join(struct.pack("<II", *d) for d in dirs)
+    sections = section_header(b".rdata", RDATA_RVA, 0x200, 0x40000040)
+    sections = sections[:20] + struct.pack("<I", 0x400) + sections[24:]
+    headers = (dos + b"PE\0\0" + coff + opt + sections).ljust(0x400, b"\0")
+    return headers + rdata
+
+
+def main():
+    streams = read_msf(open(sys.argv[1], "rb").read())
+    add_section_headers(streams)
+    with open("../hello.pdb", "wb") as f:
+        f.write(write_msf(streams))
+
+    pdb = streams[1]
+    guid = pdb[12:28]
+    age = struct.unpack_from("<I", streams[3], 8)[0]
+    with open("../hello.exe", "wb") as f:
+        f.write(pe(guid, age))
+    print("build ID", str(uuid.UUID(bytes_le=guid)).replace("-", "") + "%08x" % age)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,13 @@
+int work(int x);
+
+static inline __attribute__((always_inline)) int leaf(int x) {
+	return work(x) + 1;
+}
+
+static inline __attribute__((always_inline)) int middle(int x) {
+	return leaf(x) * 2;
+}
+
+int main(void) {
+	return middle(3);
+}
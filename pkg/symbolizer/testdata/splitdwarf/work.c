@@ -0,0 +1,3 @@
+__attribute__((noinline)) int work(int x) {
+	return x * 31 + 7;
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tenant carries the tenant of a request from the gRPC metadata of
+// the request to the storage, which isolates the data of every tenant. The
+// metastore is shared by all tenants, as its contents are content addressed
+// and only ever read by the stacktraces stored for a tenant.
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Header is the header, or gRPC metadata key, carrying the tenant of a request.
+const Header = "X-Scope-OrgID"
+
+// The tenant of requests without a tenant. All data written before tenants
+// were introduced belongs to it.
+const Default = ""
+
+const maxLength = 150
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the tenant.
+func NewContext(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenant)
+}
+
+// FromContext returns the tenant carried by the context, or the default
+// tenant if there is none.
+func FromContext(ctx context.Context) string {
+	tenant, ok := ctx.Value(contextKey{}).(string)
+	if !ok {
+		return Default
+	}
+	return tenant
+}
+
+// FromIncomingContext returns the tenant of the gRPC metadata of the context,
+// or the default tenant if there is none.
+func FromIncomingContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Default, nil
+	}
+
+	values := md.Get(Header)
+	switch len(values) {
+	case 0:
+		return Default, nil
+	case 1:
+	default:
+		return "", fmt.Errorf("multiple tenants given in %s", Header)
+	}
+
+	if err := Validate(values[0]); err != nil {
+		return "", err
+	}
+	return values[0], nil
+}
+
+// Validate returns an error if the tenant is not a valid tenant ID. Tenant IDs
+// are limited to 150 letters, digits and the characters !-_.*'().
+func Validate(tenant string) error {
+	if tenant == "" {
+		return fmt.Errorf("empty tenant given in %s", Header)
+	}
+	if len(tenant) > maxLength {
+		return fmt.Errorf("tenant given in %s is longer than %d characters", Header, maxLength)
+	}
+	if tenant == "." || tenant == ".." {
+		return fmt.Errorf("invalid tenant %q given in %s", tenant, Header)
+	}
+	for _, r := range tenant {
+		if !isValidRune(r) {
+			return fmt.Errorf("tenant given in %s contains invalid character %q", Header, r)
+		}
+	}
+	return nil
+}
+
+func isValidRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("!-_.*'()", r)
+}
+
+// UnaryServerInterceptor returns an interceptor adding the tenant of the gRPC
+// metadata of a request to its context.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		tenant, err := FromIncomingContext(ctx)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return handler(NewContext(ctx, tenant), req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor adding the tenant of the gRPC
+// metadata of a stream to its context.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		tenant, err := FromIncomingContext(ss.Context())
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = NewContext(ss.Context(), tenant)
+		return handler(srv, wrapped)
+	}
+}
+
+// HeaderMatcher forwards the tenant header of HTTP requests to the gRPC
+// metadata, in addition to the headers forwarded by default.
+func HeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, Header) {
+		return Header, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
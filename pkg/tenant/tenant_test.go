@@ -0,0 +1,75 @@
+// Copyright 2022 The Parca Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return FromContext(ctx), nil
+	}
+
+	for _, tc := range []struct {
+		name   string
+		md     metadata.MD
+		tenant string
+		code   codes.Code
+	}{
+		{name: "no_metadata", tenant: Default},
+		{name: "no_tenant", md: metadata.Pairs("other", "value"), tenant: Default},
+		{name: "tenant", md: metadata.Pairs(Header, "team-a"), tenant: "team-a"},
+		{name: "lowercase_header", md: metadata.Pairs("x-scope-orgid", "team-a"), tenant: "team-a"},
+		{name: "multiple_tenants", md: metadata.Pairs(Header, "a", Header, "b"), code: codes.InvalidArgument},
+		{name: "empty_tenant", md: metadata.Pairs(Header, ""), code: codes.InvalidArgument},
+		{name: "invalid_character", md: metadata.Pairs(Header, "a/b"), code: codes.InvalidArgument},
+		{name: "dot_dot", md: metadata.Pairs(Header, ".."), code: codes.InvalidArgument},
+		{name: "too_long", md: metadata.Pairs(Header, strings.Repeat("a", maxLength+1)), code: codes.InvalidArgument},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tc.md)
+			}
+
+			res, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			if tc.code != codes.OK {
+				require.Equal(t, tc.code, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.tenant, res)
+		})
+	}
+}
+
+func TestHeaderMatcher(t *testing.T) {
+	key, ok := HeaderMatcher("X-Scope-Orgid")
+	require.True(t, ok)
+	require.Equal(t, Header, key)
+
+	_, ok = HeaderMatcher("X-Unknown")
+	require.False(t, ok)
+}